@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/lmittmann/tint"
@@ -13,6 +19,7 @@ import (
 	"github.com/willbicks/epigram/internal/logutils"
 
 	quoteserver "github.com/willbicks/epigram/internal/server/http"
+	"github.com/willbicks/epigram/internal/server/http/paths"
 	"github.com/willbicks/epigram/internal/service"
 	"github.com/willbicks/epigram/internal/storage/inmemory"
 	"github.com/willbicks/epigram/internal/storage/sqlite"
@@ -20,6 +27,9 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// webhookRetryInterval is how often failed webhook deliveries are checked for retry.
+const webhookRetryInterval = 15 * time.Second
+
 func main() {
 	// Initialize logger
 	lvl := new(slog.LevelVar)
@@ -48,14 +58,44 @@ func main() {
 	log.Debug("Parsed config", "config", cfg)
 
 	var userRepo service.UserRepository
+	var userIdentityRepo service.UserIdentityRepository
 	var userSessionRepo service.UserSessionRepository
 	var quoteRepo service.QuoteRepository
+	var auditLogRepo service.AuditLogRepository
+	var apiTokenRepo service.APITokenRepository
+	var webhookDeliveryRepo service.WebhookDeliveryRepository
+	var chatLinkRepo service.ChatLinkRepository
+	var reactionRepo service.ReactionRepository
+	var commentRepo service.CommentRepository
+	var personRepo service.PersonRepository
+	var membershipRepo service.MembershipRepository
+	var inviteRepo service.InviteRepository
+	var quizSessionRepo service.QuizSessionRepository
+	var accessRequestRepo service.AccessRequestRepository
+	var quizAttemptRepo service.QuizAttemptRepository
+	var quizQuestionRepo service.QuizQuestionRepository
+	var transactor service.Transactor
 
 	switch cfg.Repo {
 	case config.InMemory:
 		userRepo = inmemory.NewUserRepository()
+		userIdentityRepo = inmemory.NewUserIdentityRepository()
 		userSessionRepo = inmemory.NewUserSessionRepository()
 		quoteRepo = inmemory.NewQuoteRepository()
+		auditLogRepo = inmemory.NewAuditLogRepository()
+		apiTokenRepo = inmemory.NewAPITokenRepository()
+		webhookDeliveryRepo = inmemory.NewWebhookDeliveryRepository()
+		chatLinkRepo = inmemory.NewChatLinkRepository()
+		reactionRepo = inmemory.NewReactionRepository()
+		commentRepo = inmemory.NewCommentRepository()
+		personRepo = inmemory.NewPersonRepository()
+		membershipRepo = inmemory.NewMembershipRepository()
+		inviteRepo = inmemory.NewInviteRepository()
+		quizSessionRepo = inmemory.NewQuizSessionRepository()
+		accessRequestRepo = inmemory.NewAccessRequestRepository()
+		quizAttemptRepo = inmemory.NewQuizAttemptRepository()
+		quizQuestionRepo = inmemory.NewQuizQuestionRepository()
+		transactor = inmemory.NewTransactor()
 	case config.SQLite:
 		mc := &sqlite.MigrationController{}
 		db, err := sql.Open("sqlite3", fmt.Sprint("file:", cfg.DBLoc, "?cache=shared&mode=rwc"))
@@ -77,6 +117,12 @@ func main() {
 			os.Exit(1)
 		}
 
+		userIdentityRepo, err = sqlite.NewUserIdentityRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create user identity repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
 		quoteRepo, err = sqlite.NewQuoteRepository(db, mc)
 		if err != nil {
 			log.Error("unable to create quote repo", logutils.Error(err))
@@ -88,15 +134,178 @@ func main() {
 			log.Error("unable to create user sess repo", logutils.Error(err))
 			os.Exit(1)
 		}
+
+		auditLogRepo, err = sqlite.NewAuditLogRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create audit log repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		apiTokenRepo, err = sqlite.NewAPITokenRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create API token repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		webhookDeliveryRepo, err = sqlite.NewWebhookDeliveryRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create webhook delivery repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		chatLinkRepo, err = sqlite.NewChatLinkRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create chat link repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		reactionRepo, err = sqlite.NewReactionRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create reaction repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		commentRepo, err = sqlite.NewCommentRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create comment repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		personRepo, err = sqlite.NewPersonRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create person repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		membershipRepo, err = sqlite.NewMembershipRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create membership repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		inviteRepo, err = sqlite.NewInviteRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create invite repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		quizSessionRepo, err = sqlite.NewQuizSessionRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create quiz session repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		accessRequestRepo, err = sqlite.NewAccessRequestRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create access request repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		quizAttemptRepo, err = sqlite.NewQuizAttemptRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create quiz attempt repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		quizQuestionRepo, err = sqlite.NewQuizQuestionRepository(db, mc)
+		if err != nil {
+			log.Error("unable to create quiz question repo", logutils.Error(err))
+			os.Exit(1)
+		}
+
+		transactor = sqlite.NewTransactor(db)
 	}
 
 	// Quote Server Initialization
+	auditService := service.NewAuditLogService(auditLogRepo)
+	communityService, err := service.NewCommunityService(cfg.AllCommunities(), membershipRepo, quizSessionRepo,
+		quizQuestionRepo, auditService)
+	if err != nil {
+		log.Error("invalid community configuration", logutils.Error(err))
+		os.Exit(1)
+	}
+	// Entry questions are only read from the configuration until they are managed by admins
+	if n, err := communityService.SeedQuizQuestions(context.Background()); err != nil {
+		log.Error("unable to store entry quiz questions", logutils.Error(err))
+		os.Exit(1)
+	} else if n > 0 {
+		log.Info("Stored configured entry quiz questions", "questions", n)
+	}
+	sessionService := service.NewUserSessionService(userSessionRepo, service.SessionPolicy{
+		Lifetime:    cfg.SessionLifetime,
+		Sliding:     cfg.SessionSliding,
+		MaxLifetime: cfg.SessionMaxLifetime,
+	})
+	// A negative attempt limit disables lockout, which the policy represents as zero
+	quizPolicy := service.QuizPolicy{
+		MaxAttempts:   max(cfg.QuizMaxAttempts, 0),
+		Cooldown:      cfg.QuizCooldown,
+		RecordAnswers: cfg.QuizRecordAnswers,
+	}
+	webhookTargets := make([]service.WebhookTarget, 0, len(cfg.Webhooks))
+	for _, w := range cfg.Webhooks {
+		if w.Community != "" {
+			if _, err := communityService.GetCommunity(w.Community); err != nil {
+				log.Error("invalid webhook configuration", "webhook", w.Name, "community", w.Community,
+					logutils.Error(err))
+				os.Exit(1)
+			}
+		}
+		webhookTargets = append(webhookTargets, service.WebhookTarget{
+			Name:      w.Name,
+			URL:       w.URL,
+			Format:    service.WebhookFormat(strings.ToLower(w.Format)),
+			Secret:    w.Secret,
+			Template:  w.Template,
+			Community: w.Community,
+		})
+	}
+	webhookService, err := service.NewWebhookService(webhookDeliveryRepo, webhookTargets,
+		strings.TrimSuffix(cfg.BaseURL, "/")+paths.Default().Quotes)
+	if err != nil {
+		log.Error("invalid webhook configuration", logutils.Error(err))
+		os.Exit(1)
+	}
+	quoteService := service.NewQuoteService(quoteRepo, reactionRepo, commentRepo, personRepo, transactor,
+		cfg.QuoteEditWindow, auditService, webhookService, log)
+	personService := service.NewPersonService(personRepo, quoteRepo, userRepo, auditService)
+	// Quotes submitted before quotes were attributed to people are attributed to them here
+	if n, err := personService.MigrateQuotees(context.Background()); err != nil {
+		log.Error("unable to attribute quotes to people", logutils.Error(err))
+		os.Exit(1)
+	} else if n > 0 {
+		log.Info("Attributed existing quotes to people", "quotes", n)
+	}
+	chatService, err := service.NewChatService(chatLinkRepo, userRepo, quoteService, communityService)
+	if err != nil {
+		log.Error("unable to create chat service", logutils.Error(err))
+		os.Exit(1)
+	}
 	cs := quoteserver.QuoteServer{
-		QuoteService: service.NewQuoteService(quoteRepo),
-		UserService:  service.NewUserService(userRepo, userSessionRepo),
-		QuizService:  service.NewEntryQuizService(cfg.EntryQuestions),
-		Logger:       log,
-		Config:       cfg,
+		QuoteService: quoteService,
+		UserService: service.NewUserService(userRepo, userIdentityRepo, membershipRepo, quizAttemptRepo, quoteRepo,
+			service.UserReferences{
+				APITokens:      apiTokenRepo,
+				ChatLinks:      chatLinkRepo,
+				Reactions:      reactionRepo,
+				Comments:       commentRepo,
+				People:         personRepo,
+				AccessRequests: accessRequestRepo,
+				Invites:        inviteRepo,
+				QuizSessions:   quizSessionRepo,
+			}, transactor, sessionService, auditService, quizPolicy),
+		CommunityService: communityService,
+		AuditService:     auditService,
+		APITokenService:  service.NewAPITokenService(apiTokenRepo, userRepo),
+		ChatService:      chatService,
+		ReactionService:  service.NewReactionService(reactionRepo, quoteRepo, cfg.Reactions),
+		CommentService:   service.NewCommentService(commentRepo, quoteRepo, userRepo, auditService),
+		PersonService:    personService,
+		InviteService:    service.NewInviteService(inviteRepo, membershipRepo, userRepo, communityService, auditService),
+		AccessRequestService: service.NewAccessRequestService(accessRequestRepo, membershipRepo, userRepo, auditService,
+			quizPolicy),
+		Logger: log,
+		Config: cfg,
 	}
 
 	if err := cs.Init(); err != nil {
@@ -104,6 +313,35 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Background tasks run until the server receives an interrupt or termination signal
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sessionService.RunJanitor(ctx, cfg.SessionPurgeInterval, func(deleted int, err error) {
+			if err != nil {
+				log.Error("unable to purge expired sessions", logutils.Error(err))
+				return
+			}
+			log.Debug("Purged expired sessions", "deleted", deleted)
+		})
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		webhookService.RunDispatcher(ctx, webhookRetryInterval, func(delivered, failed int, err error) {
+			if err != nil {
+				log.Warn("unable to deliver webhooks", "delivered", delivered, "failed", failed, logutils.Error(err))
+				return
+			}
+			log.Debug("Delivered webhooks", "delivered", delivered)
+		})
+	}()
+
 	addr := fmt.Sprintf("%s:%d", cfg.Address, cfg.Port)
 	log.Info("Server starting", "addr", addr)
 	s := http.Server{
@@ -114,9 +352,27 @@ func main() {
 		ReadHeaderTimeout: 2 * time.Second,
 		Handler:           cs,
 	}
-	err = s.ListenAndServe()
-	if err != nil {
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
 		log.Error("unable to listen and serve", logutils.Error(err))
+		stop()
+		wg.Wait()
 		os.Exit(1)
+	case <-ctx.Done():
+		log.Info("Server shutting down")
 	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error("unable to shut down server gracefully", logutils.Error(err))
+	}
+
+	wg.Wait()
 }
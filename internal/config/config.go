@@ -7,6 +7,9 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
+
+	"github.com/willbicks/epigram/internal/model"
 )
 
 // Repository selects one of a few options for data persistence
@@ -33,16 +36,65 @@ func repoFromString(str string) Repository {
 
 // OIDCProvider provides configuration to initialize a singular OIDC provider
 type OIDCProvider struct {
-	Name         string `yaml:"name"`
+	Name string `yaml:"name"`
+	// DisplayName is the name of the provider shown to users on the login page. If blank, Name is used instead.
+	DisplayName  string `yaml:"displayName"`
 	IssuerURL    string `yaml:"issuerURL"`
 	ClientID     string `yaml:"clientID"`
 	ClientSecret string `yaml:"clientSecret"`
 }
 
+// Webhook provides configuration for an outgoing webhook, which is notified when a new quote is submitted
+type Webhook struct {
+	// Name identifies the webhook, and must be unique.
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	// Format dictates the payload sent to the webhook, either "generic" (the default), "slack", or "discord".
+	Format string `yaml:"format"`
+	// Secret is used to sign payloads, allowing the receiver to verify that they were sent by this server.
+	Secret string `yaml:"secret"`
+	// Template is a Go text/template which overrides the message text of slack and discord payloads.
+	Template string `yaml:"template"`
+	// Community is the ID of the community whose new quotes are announced. If blank, the default community is used.
+	Community string `yaml:"community"`
+}
+
+// ChatCommand provides configuration for an incoming chat slash command, used to submit quotes from a chat platform
+type ChatCommand struct {
+	// Platform is the chat platform sending the command, either "slack" or "mattermost".
+	Platform string `yaml:"platform"`
+	// Secret verifies that requests were sent by the platform. For Slack, this is the app's signing secret, while for
+	// Mattermost, it is the slash command's token.
+	Secret string `yaml:"secret"`
+	// Community is the ID of the community to which quotes are submitted. If blank, the default community is used.
+	Community string `yaml:"community"`
+}
+
 // EntryQuestion is a question the user must answer before being granted entrance to the application
 type EntryQuestion struct {
 	Question string `yaml:"question"`
 	Answer   string `yaml:"answer"`
+	// Answers are accepted in addition to Answer.
+	Answers []string `yaml:"answers"`
+	// Normalize lists differences between a response and the answers which are ignored in addition to case, from
+	// "punctuation", "whitespace", and "diacritics".
+	Normalize []string `yaml:"normalize"`
+}
+
+// Community provides configuration for a community hosted by the server, which has its own quotes and members
+type Community struct {
+	// ID uniquely identifies the community, and must not be changed once quotes have been submitted to it.
+	ID          string `yaml:"id"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	// EntryQuestions are the questions users must answer to join the community.
+	EntryQuestions []EntryQuestion `yaml:"entryQuestions"`
+	// QuizSize is the number of EntryQuestions drawn at random for each attempt at the entry quiz. If zero, every
+	// question is asked.
+	QuizSize int `yaml:"quizSize"`
+	// Gate determines how users gain access to the community: "quiz", "approval", "quiz-then-approval", or "open". If
+	// blank, users must pass the entry quiz.
+	Gate string `yaml:"gate"`
 }
 
 // Application represents the root configuration struct for the server.
@@ -65,17 +117,53 @@ type Application struct {
 	TrustProxy bool `yaml:"trustProxy"`
 	// LogJSON enables JSON logging, otherwise logs are printed in a human-readable format (which is slower).
 	LogJSON bool `yaml:"logJSON"`
-	// OIDCProvider is the OIDC provider used to authenticate users.
+	// OIDCProvider is an OIDC provider used to authenticate users. It is retained for compatibility with existing
+	// configurations, and is offered in addition to OIDCProviders.
 	OIDCProvider OIDCProvider `yaml:"OIDCProvider"`
+	// OIDCProviders is a list of OIDC providers which users may choose between to authenticate.
+	OIDCProviders []OIDCProvider `yaml:"OIDCProviders"`
+	// Webhooks is a list of outgoing webhooks which are notified when a new quote is submitted.
+	Webhooks []Webhook `yaml:"webhooks"`
+	// Reactions is the set of emoji with which users may react to quotes.
+	Reactions []string `yaml:"reactions"`
+	// ChatCommands is a list of chat platforms from which quotes may be submitted using a slash command.
+	ChatCommands []ChatCommand `yaml:"chatCommands"`
 	// EntryQuestions is an array of questions.
 	EntryQuestions []EntryQuestion `yaml:"entryQuestions"`
+	// QuizSize is the number of EntryQuestions drawn at random for each attempt at the entry quiz. If zero, every
+	// question is asked.
+	QuizSize int `yaml:"quizSize"`
+	// Gate determines how users gain access to the default community, as for Community.Gate.
+	Gate string `yaml:"gate"`
+	// Communities are hosted in addition to the default community, which is described by Title, Description,
+	// EntryQuestions, QuizSize, and Gate.
+	Communities []Community `yaml:"communities"`
 	// DevMode dictates whether the application should run in development mode, which disables asset embedding and caching for easier frontend development.
 	DevMode bool `yaml:"devMode"`
+	// QuoteEditWindow is the amount of time after submission during which a user may edit or delete their own quote.
+	QuoteEditWindow time.Duration `yaml:"quoteEditWindow"`
+	// QuizMaxAttempts is the number of times a user may submit the entry quiz of a community without passing before
+	// they are locked out, and must request access from its admins. If negative, the number of attempts is unlimited.
+	QuizMaxAttempts int `yaml:"quizMaxAttempts"`
+	// QuizCooldown is the amount of time a user must wait between attempts at the entry quiz of a community.
+	QuizCooldown time.Duration `yaml:"quizCooldown"`
+	// QuizRecordAnswers enables recording the responses users submit to the entry quiz in their quiz history, which
+	// is otherwise limited to which questions they answered incorrectly.
+	QuizRecordAnswers bool `yaml:"quizRecordAnswers"`
+	// SessionPurgeInterval is how often expired user sessions are deleted from the repository.
+	SessionPurgeInterval time.Duration `yaml:"sessionPurgeInterval"`
+	// SessionLifetime is the amount of time after sign in (or renewal) at which a user session expires.
+	SessionLifetime time.Duration `yaml:"sessionLifetime"`
+	// SessionSliding enables renewal of user sessions which are used after half of their lifetime has elapsed.
+	SessionSliding bool `yaml:"sessionSliding"`
+	// SessionMaxLifetime is the absolute amount of time after sign in at which a user session expires, regardless of
+	// renewal. If zero, sessions may be renewed indefinitely.
+	SessionMaxLifetime time.Duration `yaml:"sessionMaxLifetime"`
 }
 
 // merge applies all non-nil / non-default values from the provided layer to the base layer, and returns the result.
 //
-// Boolean values (like DevMode and TrustProxy) are merged by ORing the two values together, and as such, a false value
+// Boolean values (like DevMode, TrustProxy, SessionSliding, and QuizRecordAnswers) are merged by ORing the two values together, and as such, a false value
 // in the layer will not override a true value in the base.
 func (base Application) merge(layer Application) Application {
 	if layer.Address != "" {
@@ -105,18 +193,86 @@ func (base Application) merge(layer Application) Application {
 	if layer.OIDCProvider != (OIDCProvider{}) {
 		base.OIDCProvider = layer.OIDCProvider
 	}
+	if len(layer.OIDCProviders) > 0 {
+		base.OIDCProviders = layer.OIDCProviders
+	}
+	if len(layer.Webhooks) > 0 {
+		base.Webhooks = layer.Webhooks
+	}
+	if len(layer.Reactions) > 0 {
+		base.Reactions = layer.Reactions
+	}
+	if len(layer.ChatCommands) > 0 {
+		base.ChatCommands = layer.ChatCommands
+	}
 	if len(layer.EntryQuestions) > 0 {
 		base.EntryQuestions = layer.EntryQuestions
 	}
+	if layer.QuizSize != 0 {
+		base.QuizSize = layer.QuizSize
+	}
+	if layer.Gate != "" {
+		base.Gate = layer.Gate
+	}
+	if len(layer.Communities) > 0 {
+		base.Communities = layer.Communities
+	}
 	if layer.DevMode {
 		base.DevMode = layer.DevMode
 	}
 	if layer.LogJSON {
 		base.LogJSON = layer.LogJSON
 	}
+	if layer.QuoteEditWindow != 0 {
+		base.QuoteEditWindow = layer.QuoteEditWindow
+	}
+	if layer.QuizMaxAttempts != 0 {
+		base.QuizMaxAttempts = layer.QuizMaxAttempts
+	}
+	if layer.QuizCooldown != 0 {
+		base.QuizCooldown = layer.QuizCooldown
+	}
+	if layer.QuizRecordAnswers {
+		base.QuizRecordAnswers = layer.QuizRecordAnswers
+	}
+	if layer.SessionPurgeInterval != 0 {
+		base.SessionPurgeInterval = layer.SessionPurgeInterval
+	}
+	if layer.SessionLifetime != 0 {
+		base.SessionLifetime = layer.SessionLifetime
+	}
+	if layer.SessionSliding {
+		base.SessionSliding = layer.SessionSliding
+	}
+	if layer.SessionMaxLifetime != 0 {
+		base.SessionMaxLifetime = layer.SessionMaxLifetime
+	}
 	return base
 }
 
+// AllOIDCProviders returns every configured OIDC provider, beginning with OIDCProvider (if set), followed by
+// OIDCProviders.
+func (a Application) AllOIDCProviders() []OIDCProvider {
+	var providers []OIDCProvider
+	if a.OIDCProvider != (OIDCProvider{}) {
+		providers = append(providers, a.OIDCProvider)
+	}
+	return append(providers, a.OIDCProviders...)
+}
+
+// AllCommunities returns every community hosted by the server, beginning with the default community (described by
+// Title, Description, EntryQuestions, QuizSize, and Gate), followed by Communities.
+func (a Application) AllCommunities() []Community {
+	return append([]Community{{
+		ID:             model.DefaultCommunityID,
+		Title:          a.Title,
+		Description:    a.Description,
+		EntryQuestions: a.EntryQuestions,
+		QuizSize:       a.QuizSize,
+		Gate:           a.Gate,
+	}}, a.Communities...)
+}
+
 // Parse layers three config sources to return the final application configuration. First, the default configuration is
 // loaded (which varied based on system operating system). Then, a .yml configuration file is loaded. If the EP_CONFIG
 // env var is set, the yml file is loaded from there, otherwise, it is loaded from the default config location (again,
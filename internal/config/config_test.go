@@ -7,6 +7,7 @@ package config
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestApplication_merge(t *testing.T) {
@@ -55,6 +56,12 @@ func TestApplication_merge(t *testing.T) {
 				Repo:        Default.Repo,
 				DBLoc:       Default.DBLoc,
 				TrustProxy:  Default.TrustProxy,
+
+				QuoteEditWindow:      Default.QuoteEditWindow,
+				QuizMaxAttempts:      Default.QuizMaxAttempts,
+				SessionPurgeInterval: Default.SessionPurgeInterval,
+				SessionLifetime:      Default.SessionLifetime,
+				Reactions:            Default.Reactions,
 				OIDCProvider: OIDCProvider{
 					Name:         "test",
 					IssuerURL:    "https://accounts.google.com",
@@ -85,12 +92,36 @@ func TestApplication_merge(t *testing.T) {
 				Repo:        SQLite,
 				DBLoc:       "/var/rando",
 				TrustProxy:  true,
+
+				QuoteEditWindow:      time.Hour,
+				QuizMaxAttempts:      -1,
+				QuizCooldown:         10 * time.Minute,
+				QuizRecordAnswers:    true,
+				SessionPurgeInterval: 5 * time.Minute,
+				SessionLifetime:      24 * time.Hour,
+				SessionSliding:       true,
+				SessionMaxLifetime:   30 * 24 * time.Hour,
+				Reactions:            []string{"⭐"},
 				OIDCProvider: OIDCProvider{
 					Name:         "test",
 					IssuerURL:    "https://accounts.google.com",
 					ClientID:     "ididid",
 					ClientSecret: "secretsecret",
 				},
+				Webhooks: []Webhook{
+					{
+						Name:   "slack",
+						URL:    "https://hooks.slack.com/services/T000/B000/XXXX",
+						Format: "slack",
+						Secret: "shh",
+					},
+				},
+				ChatCommands: []ChatCommand{
+					{
+						Platform: "slack",
+						Secret:   "signing-secret",
+					},
+				},
 				EntryQuestions: []EntryQuestion{
 					{
 						Question: "Question 1",
@@ -101,6 +132,8 @@ func TestApplication_merge(t *testing.T) {
 						Answer:   "George Washington",
 					},
 				},
+				QuizSize: 1,
+				Gate:     "quiz-then-approval",
 			},
 			want: Application{
 				Address:     "1.2.3.4",
@@ -111,12 +144,36 @@ func TestApplication_merge(t *testing.T) {
 				Repo:        SQLite,
 				DBLoc:       "/var/rando",
 				TrustProxy:  true,
+
+				QuoteEditWindow:      time.Hour,
+				QuizMaxAttempts:      -1,
+				QuizCooldown:         10 * time.Minute,
+				QuizRecordAnswers:    true,
+				SessionPurgeInterval: 5 * time.Minute,
+				SessionLifetime:      24 * time.Hour,
+				SessionSliding:       true,
+				SessionMaxLifetime:   30 * 24 * time.Hour,
+				Reactions:            []string{"⭐"},
 				OIDCProvider: OIDCProvider{
 					Name:         "test",
 					IssuerURL:    "https://accounts.google.com",
 					ClientID:     "ididid",
 					ClientSecret: "secretsecret",
 				},
+				Webhooks: []Webhook{
+					{
+						Name:   "slack",
+						URL:    "https://hooks.slack.com/services/T000/B000/XXXX",
+						Format: "slack",
+						Secret: "shh",
+					},
+				},
+				ChatCommands: []ChatCommand{
+					{
+						Platform: "slack",
+						Secret:   "signing-secret",
+					},
+				},
 				EntryQuestions: []EntryQuestion{
 					{
 						Question: "Question 1",
@@ -127,6 +184,8 @@ func TestApplication_merge(t *testing.T) {
 						Answer:   "George Washington",
 					},
 				},
+				QuizSize: 1,
+				Gate:     "quiz-then-approval",
 			},
 		},
 	}
@@ -138,3 +197,72 @@ func TestApplication_merge(t *testing.T) {
 		})
 	}
 }
+
+func TestApplication_AllOIDCProviders(t *testing.T) {
+	single := OIDCProvider{Name: "google", IssuerURL: "https://accounts.google.com"}
+	dex := OIDCProvider{Name: "dex", IssuerURL: "https://dex.example.com"}
+	microsoft := OIDCProvider{Name: "microsoft", IssuerURL: "https://login.microsoftonline.com/common/v2.0"}
+
+	tests := []struct {
+		name string
+		app  Application
+		want []OIDCProvider
+	}{
+		{
+			name: "none",
+			app:  Application{},
+			want: nil,
+		},
+		{
+			name: "single",
+			app:  Application{OIDCProvider: single},
+			want: []OIDCProvider{single},
+		},
+		{
+			name: "list",
+			app:  Application{OIDCProviders: []OIDCProvider{dex, microsoft}},
+			want: []OIDCProvider{dex, microsoft},
+		},
+		{
+			name: "single-and-list",
+			app:  Application{OIDCProvider: single, OIDCProviders: []OIDCProvider{dex, microsoft}},
+			want: []OIDCProvider{single, dex, microsoft},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.app.AllOIDCProviders(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Application.AllOIDCProviders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplication_AllCommunities(t *testing.T) {
+	questions := []EntryQuestion{{Question: "What is the best color?", Answer: "purple"}}
+	hiking := Community{ID: "hiking", Title: "Hiking Club"}
+
+	tests := []struct {
+		name string
+		app  Application
+		want []Community
+	}{
+		{
+			name: "default only",
+			app:  Application{Title: "Epigram", Description: "Quotes", EntryQuestions: questions, QuizSize: 1, Gate: "open"},
+			want: []Community{{ID: "default", Title: "Epigram", Description: "Quotes", EntryQuestions: questions, QuizSize: 1, Gate: "open"}},
+		},
+		{
+			name: "additional",
+			app:  Application{Title: "Epigram", Communities: []Community{hiking}},
+			want: []Community{{ID: "default", Title: "Epigram"}, hiking},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.app.AllCommunities(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Application.AllCommunities() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
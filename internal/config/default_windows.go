@@ -2,17 +2,24 @@
 
 package config
 
+import "time"
+
 // configDir is the default location to search for config files in
 const configDir = "."
 
 // Default is a default configuration, used as a base for additional configurations to be merged on top of.
 var Default = Application{
-	Address:     "0.0.0.0",
-	Port:        80,
-	Title:       "Epigram",
-	Description: "Epigram is a simple web service for communities to immortalize the enlightening, funny, or downright dumb quotes that they hear.",
-	TrustProxy:  false,
-	LogJSON:     false,
-	Repo:        SQLite,
-	DBLoc:       "./epigram.db",
+	Address:              "0.0.0.0",
+	Port:                 80,
+	Title:                "Epigram",
+	Description:          "Epigram is a simple web service for communities to immortalize the enlightening, funny, or downright dumb quotes that they hear.",
+	TrustProxy:           false,
+	LogJSON:              false,
+	Repo:                 SQLite,
+	DBLoc:                "./epigram.db",
+	QuoteEditWindow:      15 * time.Minute,
+	QuizMaxAttempts:      5,
+	SessionPurgeInterval: time.Hour,
+	SessionLifetime:      14 * 24 * time.Hour,
+	Reactions:            []string{"👍", "😂", "❤️"},
 }
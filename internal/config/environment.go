@@ -5,6 +5,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // EnvironmentPrefix is a string that is prefixed to environment variables seperated by an underscore.
@@ -37,17 +38,42 @@ func fromEnvironment() Application {
 	trustProxy, _ := strconv.ParseBool(getEnvVar("TrustProxy"))
 	logJSON, _ := strconv.ParseBool(getEnvVar("LogJSON"))
 	devMode, _ := strconv.ParseBool(getEnvVar("DevMode"))
+	quoteEditWindow, _ := time.ParseDuration(getEnvVar("QuoteEditWindow"))
+	quizMaxAttempts, _ := strconv.Atoi(getEnvVar("QuizMaxAttempts"))
+	quizCooldown, _ := time.ParseDuration(getEnvVar("QuizCooldown"))
+	quizRecordAnswers, _ := strconv.ParseBool(getEnvVar("QuizRecordAnswers"))
+	sessionPurgeInterval, _ := time.ParseDuration(getEnvVar("SessionPurgeInterval"))
+	sessionLifetime, _ := time.ParseDuration(getEnvVar("SessionLifetime"))
+	sessionSliding, _ := strconv.ParseBool(getEnvVar("SessionSliding"))
+	sessionMaxLifetime, _ := time.ParseDuration(getEnvVar("SessionMaxLifetime"))
+
+	var reactions []string
+	for _, r := range strings.Split(getEnvVar("Reactions"), ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			reactions = append(reactions, r)
+		}
+	}
 
 	return Application{
-		Title:       getEnvVar("Title"),
-		Description: getEnvVar("Description"),
-		Address:     getEnvVar("Address"),
-		Port:        port,
-		BaseURL:     getEnvVar("BaseURL"),
-		Repo:        repoFromString(getEnvVar("Repo")),
-		DBLoc:       getEnvVar("DBLoc"),
-		TrustProxy:  trustProxy,
-		LogJSON:     logJSON,
-		DevMode:     devMode,
+		Title:                getEnvVar("Title"),
+		Description:          getEnvVar("Description"),
+		Gate:                 getEnvVar("Gate"),
+		Address:              getEnvVar("Address"),
+		Port:                 port,
+		BaseURL:              getEnvVar("BaseURL"),
+		Repo:                 repoFromString(getEnvVar("Repo")),
+		DBLoc:                getEnvVar("DBLoc"),
+		TrustProxy:           trustProxy,
+		LogJSON:              logJSON,
+		DevMode:              devMode,
+		QuoteEditWindow:      quoteEditWindow,
+		QuizMaxAttempts:      quizMaxAttempts,
+		QuizCooldown:         quizCooldown,
+		QuizRecordAnswers:    quizRecordAnswers,
+		SessionPurgeInterval: sessionPurgeInterval,
+		SessionLifetime:      sessionLifetime,
+		SessionSliding:       sessionSliding,
+		SessionMaxLifetime:   sessionMaxLifetime,
+		Reactions:            reactions,
 	}
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestParseYAML(t *testing.T) {
@@ -54,6 +55,99 @@ OIDCProvider:
 			},
 			wantErr: false,
 		},
+		{
+			name: "oidc-providers",
+			yaml: `
+OIDCProviders:
+  - name: google
+    displayName: Google
+    issuerURL: https://accounts.google.com
+    clientID: google-client
+    clientSecret: google-secret
+  - name: dex
+    issuerURL: https://dex.example.com
+    clientID: dex-client
+    clientSecret: dex-secret`,
+			want: Application{
+				OIDCProviders: []OIDCProvider{
+					{
+						Name:         "google",
+						DisplayName:  "Google",
+						IssuerURL:    "https://accounts.google.com",
+						ClientID:     "google-client",
+						ClientSecret: "google-secret",
+					},
+					{
+						Name:         "dex",
+						IssuerURL:    "https://dex.example.com",
+						ClientID:     "dex-client",
+						ClientSecret: "dex-secret",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "webhooks",
+			yaml: `
+webhooks:
+  - name: general
+    url: https://hooks.slack.com/services/T000/B000/XXXX
+    format: slack
+    secret: shh
+    template: "{{.Quotee}} said something"
+  - name: archive
+    url: https://example.com/hook`,
+			want: Application{
+				Webhooks: []Webhook{
+					{
+						Name:     "general",
+						URL:      "https://hooks.slack.com/services/T000/B000/XXXX",
+						Format:   "slack",
+						Secret:   "shh",
+						Template: "{{.Quotee}} said something",
+					},
+					{
+						Name: "archive",
+						URL:  "https://example.com/hook",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "chat-commands",
+			yaml: `
+chatCommands:
+  - platform: slack
+    secret: signing-secret
+  - platform: mattermost
+    secret: command-token`,
+			want: Application{
+				ChatCommands: []ChatCommand{
+					{
+						Platform: "slack",
+						Secret:   "signing-secret",
+					},
+					{
+						Platform: "mattermost",
+						Secret:   "command-token",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "reactions",
+			yaml: `
+reactions:
+  - "👍"
+  - "🔥"`,
+			want: Application{
+				Reactions: []string{"👍", "🔥"},
+			},
+			wantErr: false,
+		},
 		{
 			name:    "repo-error",
 			yaml:    `repo: invalid`,
@@ -76,13 +170,56 @@ OIDCProvider:
 			},
 			wantErr: false,
 		},
+		{
+			name: "quote-edit-window",
+			yaml: `quoteEditWindow: 1h30m`,
+			want: Application{
+				QuoteEditWindow: 90 * time.Minute,
+			},
+			wantErr: false,
+		},
+		{
+			name: "quiz-limits",
+			yaml: `quizMaxAttempts: 3
+quizCooldown: 10m
+quizRecordAnswers: true`,
+			want: Application{
+				QuizMaxAttempts:   3,
+				QuizCooldown:      10 * time.Minute,
+				QuizRecordAnswers: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "session-purge-interval",
+			yaml: `sessionPurgeInterval: 30m`,
+			want: Application{
+				SessionPurgeInterval: 30 * time.Minute,
+			},
+			wantErr: false,
+		},
+		{
+			name: "session-lifetime",
+			yaml: `sessionLifetime: 72h
+sessionSliding: true
+sessionMaxLifetime: 720h`,
+			want: Application{
+				SessionLifetime:    72 * time.Hour,
+				SessionSliding:     true,
+				SessionMaxLifetime: 720 * time.Hour,
+			},
+			wantErr: false,
+		},
 		{
 			name: "entryquestions",
 			yaml: `entryQuestions: 
   - question: Question 1
     answer: ALIGATOR
   - question: Who was the first President?
-    answer: George Washington`,
+    answer: George Washington
+    answers: [Washington]
+    normalize: [punctuation, whitespace]
+quizSize: 1`,
 			want: Application{
 				EntryQuestions: []EntryQuestion{
 					{
@@ -90,8 +227,41 @@ OIDCProvider:
 						Answer:   "ALIGATOR",
 					},
 					{
-						Question: "Who was the first President?",
-						Answer:   "George Washington",
+						Question:  "Who was the first President?",
+						Answer:    "George Washington",
+						Answers:   []string{"Washington"},
+						Normalize: []string{"punctuation", "whitespace"},
+					},
+				},
+				QuizSize: 1,
+			},
+			wantErr: false,
+		},
+		{
+			name: "communities",
+			yaml: `communities:
+  - id: hiking
+    title: Hiking Club
+    description: Quotes from the trail.
+    quizSize: 1
+    gate: approval
+    entryQuestions:
+      - question: Tallest peak we climbed?
+        answer: Rainier`,
+			want: Application{
+				Communities: []Community{
+					{
+						ID:          "hiking",
+						Title:       "Hiking Club",
+						Description: "Quotes from the trail.",
+						EntryQuestions: []EntryQuestion{
+							{
+								Question: "Tallest peak we climbed?",
+								Answer:   "Rainier",
+							},
+						},
+						QuizSize: 1,
+						Gate:     "approval",
 					},
 				},
 			},
@@ -1,6 +1,6 @@
 // Package ctxval is used by the server and service tiers to store request-scoped
 // information that is ancillary to the request being made (eg: authenticated user,
-// request IP)
+// current community, request IP)
 package ctxval
 
 import (
@@ -13,6 +13,8 @@ type contextKey int
 
 const userKey contextKey = 0
 const ipKey contextKey = 1
+const communityKey contextKey = 2
+const membershipKey contextKey = 3
 
 // ContextWithUser returns copy of the provided context with the user set
 func ContextWithUser(ctx context.Context, u model.User) context.Context {
@@ -41,3 +43,31 @@ func IPFromContext(ctx context.Context) string {
 	}
 	return ip
 }
+
+// ContextWithCommunity returns a copy of the provided context with the current community set
+func ContextWithCommunity(ctx context.Context, c model.Community) context.Context {
+	return context.WithValue(ctx, communityKey, c)
+}
+
+// CommunityFromContext returns the current community associated with the provided context
+func CommunityFromContext(ctx context.Context) model.Community {
+	c, ok := ctx.Value(communityKey).(model.Community)
+	if !ok {
+		return model.Community{}
+	}
+	return c
+}
+
+// ContextWithMembership returns a copy of the provided context with the user's membership of the current community set
+func ContextWithMembership(ctx context.Context, m model.Membership) context.Context {
+	return context.WithValue(ctx, membershipKey, m)
+}
+
+// MembershipFromContext returns the user's membership of the current community associated with the provided context
+func MembershipFromContext(ctx context.Context) model.Membership {
+	m, ok := ctx.Value(membershipKey).(model.Membership)
+	if !ok {
+		return model.Membership{}
+	}
+	return m
+}
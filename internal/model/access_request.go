@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// AccessRequestStatus is the state of an AccessRequest.
+type AccessRequestStatus string
+
+const (
+	// AccessRequestPending requests are awaiting a decision from an admin of the community.
+	AccessRequestPending AccessRequestStatus = "pending"
+	// AccessRequestApproved requests were approved, granting the user access to the community.
+	AccessRequestApproved AccessRequestStatus = "approved"
+	// AccessRequestDenied requests were denied, and the user remains without access to the community.
+	AccessRequestDenied AccessRequestStatus = "denied"
+)
+
+// AccessRequest is a request from a User who is unable to pass the entry quiz of a Community to be granted access to
+// it by its admins. Each user may make one request per community.
+type AccessRequest struct {
+	CommunityID string
+	UserID      string
+	// Message is written by the user to explain who they are.
+	Message string
+	Status  AccessRequestStatus
+	Created time.Time
+	// Resolved is the time at which the request was approved or denied by the admin with the ID ResolverID.
+	Resolved   time.Time
+	ResolverID string
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// APIToken permits a user's scripts and integrations to access the API on their behalf. The token itself is only
+// revealed to the user when it is created, and only a hash of it is stored.
+type APIToken struct {
+	ID     string
+	UserID string
+	// CommunityID is the ID of the Community in which the token was created, to which its requests are scoped.
+	CommunityID string
+	// Name describes the purpose of the token, and is chosen by the user.
+	Name string
+	// Hash is the hex encoded SHA-256 hash of the token.
+	Hash    string
+	Created time.Time
+}
@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+// AuditAction identifies a type of privileged action recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditBanUser         AuditAction = "user.ban"
+	AuditUnbanUser       AuditAction = "user.unban"
+	AuditPromoteUser     AuditAction = "user.promote"
+	AuditDemoteUser      AuditAction = "user.demote"
+	AuditResetQuiz       AuditAction = "user.reset_quiz"
+	AuditApproveAccess   AuditAction = "user.approve_access"
+	AuditDenyAccess      AuditAction = "user.deny_access"
+	AuditApproveUser     AuditAction = "user.approve"
+	AuditRejectUser      AuditAction = "user.reject"
+	AuditRevokeSessions  AuditAction = "user.revoke_sessions"
+	AuditMergeUsers      AuditAction = "user.merge"
+	AuditEditQuote       AuditAction = "quote.edit"
+	AuditDeleteQuote     AuditAction = "quote.delete"
+	AuditEditComment     AuditAction = "comment.edit"
+	AuditDeleteComment   AuditAction = "comment.delete"
+	AuditEditPerson      AuditAction = "person.edit"
+	AuditMergePeople     AuditAction = "person.merge"
+	AuditRenameTag       AuditAction = "tag.rename"
+	AuditCreateInvite    AuditAction = "invite.create"
+	AuditRevokeInvite    AuditAction = "invite.revoke"
+	AuditCreateQuestion  AuditAction = "quiz.create_question"
+	AuditEditQuestion    AuditAction = "quiz.edit_question"
+	AuditEnableQuestion  AuditAction = "quiz.enable_question"
+	AuditDisableQuestion AuditAction = "quiz.disable_question"
+)
+
+// AuditActions is a list of all AuditActions, in the order they should be presented.
+var AuditActions = []AuditAction{
+	AuditBanUser,
+	AuditUnbanUser,
+	AuditPromoteUser,
+	AuditDemoteUser,
+	AuditResetQuiz,
+	AuditApproveAccess,
+	AuditDenyAccess,
+	AuditApproveUser,
+	AuditRejectUser,
+	AuditRevokeSessions,
+	AuditMergeUsers,
+	AuditEditQuote,
+	AuditDeleteQuote,
+	AuditEditComment,
+	AuditDeleteComment,
+	AuditEditPerson,
+	AuditMergePeople,
+	AuditRenameTag,
+	AuditCreateInvite,
+	AuditRevokeInvite,
+	AuditCreateQuestion,
+	AuditEditQuestion,
+	AuditEnableQuestion,
+	AuditDisableQuestion,
+}
+
+// AuditLogEntry records a privileged action taken by a user (typically an admin), such that it is possible to
+// determine who did what, and when.
+type AuditLogEntry struct {
+	ID string
+	// ActorID is the ID of the user who performed the action.
+	ActorID string
+	Action  AuditAction
+	// TargetID is the ID of the object (user, quote, etc.) which the action was performed on.
+	TargetID string
+	// Details optionally describes the action or its target, such as the text of a deleted quote.
+	Details string
+	// IP is the IP address from which the action was requested.
+	IP      string
+	Created time.Time
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// ChatLink associates a user of a chat platform (such as Slack or Mattermost) with a User, permitting them to submit
+// quotes using a chat slash command.
+type ChatLink struct {
+	// ID uniquely identifies the chat user, and is composed of the Platform, TeamID, and ChatUserID.
+	ID       string
+	Platform string
+	// TeamID identifies the workspace or team of the chat platform to which the chat user belongs.
+	TeamID     string
+	ChatUserID string
+	// ChatUserName is the name of the chat user at the time they were linked.
+	ChatUserName string
+	UserID       string
+	Created      time.Time
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Comment is a message left by a User on a Quote, optionally in reply to another Comment on the same Quote.
+type Comment struct {
+	ID      string
+	QuoteID string
+	// ParentID is the ID of the Comment being replied to, or empty if the Comment is not a reply.
+	ParentID string
+	AuthorID string
+	Text     string
+	Created  time.Time
+	// Edited is the time at which the Comment was last edited, or the zero time if it never has been.
+	Edited time.Time
+	// Deleted is true if the Comment has been deleted, but is retained (without its Text) to preserve its replies.
+	Deleted bool
+}
@@ -0,0 +1,42 @@
+package model
+
+// DefaultCommunityID is the ID of the community described by the top level of the configuration, to which all data
+// created before communities were introduced belongs.
+const DefaultCommunityID = "default"
+
+// Community is a group of users who share a collection of quotes, with its own title, entry quiz, and members.
+type Community struct {
+	// ID uniquely identifies the community, and is chosen in the configuration.
+	ID          string
+	Title       string
+	Description string
+	// Gate determines how users gain access to the community.
+	Gate GateMode
+}
+
+// GateMode determines what users must do to gain access to a community. The zero value is equivalent to GateQuiz.
+type GateMode string
+
+const (
+	// GateQuiz communities admit users who pass their entry quiz.
+	GateQuiz GateMode = "quiz"
+	// GateApproval communities admit users who are approved by their admins.
+	GateApproval GateMode = "approval"
+	// GateQuizThenApproval communities admit users who pass their entry quiz, and are then approved by their admins.
+	GateQuizThenApproval GateMode = "quiz-then-approval"
+	// GateOpen communities admit every signed in user.
+	GateOpen GateMode = "open"
+)
+
+// GateModes is a list of all GateModes.
+var GateModes = []GateMode{GateQuiz, GateApproval, GateQuizThenApproval, GateOpen}
+
+// RequiresQuiz returns true if users must pass the entry quiz to gain access.
+func (g GateMode) RequiresQuiz() bool {
+	return g == "" || g == GateQuiz || g == GateQuizThenApproval
+}
+
+// RequiresApproval returns true if users must be approved by an admin to gain access.
+func (g GateMode) RequiresApproval() bool {
+	return g == GateApproval || g == GateQuizThenApproval
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Invite permits users to join a Community without passing its entry quiz, by following a link containing its code.
+type Invite struct {
+	ID          string
+	CommunityID string
+	// Code is the secret included in the invite's link.
+	Code string
+	// CreatorID is the ID of the admin who created the invite.
+	CreatorID string
+	// MaxUses is the number of users who may redeem the invite, or zero if it may be redeemed by any number of users.
+	MaxUses int
+	// Expires is the time after which the invite may no longer be redeemed, or the zero time if it never expires.
+	Expires time.Time
+	Created time.Time
+	// Redemptions records each user who has redeemed the invite, from oldest to newest.
+	Redemptions []InviteRedemption
+}
+
+// InviteRedemption records that a user joined a community using an Invite.
+type InviteRedemption struct {
+	InviteID string
+	UserID   string
+	Redeemed time.Time
+}
+
+// IsRedeemable returns true if the invite has not expired at the provided time, and has not been used up.
+func (i Invite) IsRedeemable(now time.Time) bool {
+	if !i.Expires.IsZero() && !now.Before(i.Expires) {
+		return false
+	}
+	return i.MaxUses == 0 || len(i.Redemptions) < i.MaxUses
+}
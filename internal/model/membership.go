@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Membership records a User's access to a Community. A user becomes a member of a community when they first attempt
+// its entry quiz, or when they first visit it if it has no quiz.
+type Membership struct {
+	CommunityID string
+	UserID      string
+	QuizPassed  bool
+	// QuizAttempts represents the number of times the user has submitted the community's entry quiz.
+	QuizAttempts int8
+	// LastQuizAttempt is the time at which the user last submitted the community's entry quiz.
+	LastQuizAttempt time.Time
+	// Approved and Rejected record the decision of the community's admins, if its gate requires their approval.
+	Approved bool
+	Rejected bool
+	Banned   bool
+	// Admin grants administration of the community, such as banning its members or moderating its quotes.
+	Admin  bool
+	Joined time.Time
+}
+
+// PassedGate returns true if the member has done what the provided gate requires to gain access to the community.
+func (m Membership) PassedGate(gate GateMode) bool {
+	return (m.QuizPassed || !gate.RequiresQuiz()) && (m.Approved || !gate.RequiresApproval())
+}
+
+// AwaitingApproval returns true if the member is waiting for the admins of a community with the provided gate to
+// approve or reject them.
+func (m Membership) AwaitingApproval(gate GateMode) bool {
+	return gate.RequiresApproval() && !m.Approved && !m.Rejected && (m.QuizPassed || !gate.RequiresQuiz())
+}
+
+// IsAuthorized returns true if the member is authorized to access a community with the provided gate (they have
+// passed it and are not banned, or they are an admin)
+func (m Membership) IsAuthorized(gate GateMode) bool {
+	return (m.PassedGate(gate) && !m.Banned) || m.Admin
+}
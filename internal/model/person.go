@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Person is someone who may be quoted, regardless of whether they have an account. Quotes are attributed to people
+// by their ID, such that different spellings of the same person's name refer to the same Person. Each community has its
+// own directory of people.
+type Person struct {
+	ID          string
+	CommunityID string
+	Name        string
+	// Aliases are other names by which the Person is known, such as nicknames or misspellings of their name.
+	Aliases []string
+	// UserID is the ID of the User who is this Person, or empty if they are not linked to a User.
+	UserID  string
+	Created time.Time
+}
+
+// Names returns the Person's name followed by their aliases.
+func (p Person) Names() []string {
+	return append([]string{p.Name}, p.Aliases...)
+}
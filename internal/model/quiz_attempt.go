@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// QuizAttempt records a single submission of the entry quiz of a community by a user.
+type QuizAttempt struct {
+	ID          string
+	CommunityID string
+	UserID      string
+	IP          string
+	Submitted   time.Time
+	// QuestionIDs are the questions presented in the attempt, of which WrongQuestionIDs were answered incorrectly.
+	QuestionIDs      []int
+	WrongQuestionIDs []int
+	Passed           bool
+	// Answers are the responses submitted to each question by ID, which are only recorded if enabled by the
+	// configuration.
+	Answers map[int]string
+}
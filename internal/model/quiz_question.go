@@ -0,0 +1,18 @@
+package model
+
+// QuizQuestion is a question of the entry quiz of a Community, which users may be asked to answer before joining it.
+type QuizQuestion struct {
+	CommunityID string
+	// ID identifies the question within its community, and is never reused.
+	ID       int
+	Question string
+	// Answers are the accepted responses to the question.
+	Answers []string
+	// Normalize lists the differences between a response and the answers which are ignored, from "punctuation",
+	// "whitespace", and "diacritics".
+	Normalize []string
+	// Position orders the questions of a community, from lowest to highest.
+	Position int
+	// Enabled is true if the question may be drawn for new attempts at the quiz.
+	Enabled bool
+}
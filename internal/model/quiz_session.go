@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// QuizSession records the questions a User was asked when they began an attempt at the entry quiz of a Community, so
+// that their answers are checked against the questions they were shown.
+type QuizSession struct {
+	CommunityID string
+	UserID      string
+	// QuestionIDs are the IDs of the questions drawn for the attempt, in the order they are presented.
+	QuestionIDs []int
+	Started     time.Time
+}
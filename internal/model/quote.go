@@ -1,13 +1,71 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Quote is a quote submitted by a user to the application
 type Quote struct {
-	ID          string
+	ID string
+	// CommunityID is the ID of the Community to which the quote was submitted.
+	CommunityID string
 	SubmitterID string
-	Quotee      string
-	Context     string
-	Quote       string
-	Created     time.Time
+	// QuoteeID is the ID of the Person who said the quote, and Quotee is their name.
+	QuoteeID string
+	Quotee   string
+	Context  string
+	Quote    string
+	// Lines are the lines of a conversation between several people, or empty if the quote was said by one person.
+	// The Quotee of a conversation is its first speaker, and its Quote is the conversation as text, with each line
+	// prefixed by its speaker.
+	Lines []QuoteLine
+	// Tags are the normalized names of the tags on the quote, in alphabetical order.
+	Tags    []string
+	Created time.Time
+}
+
+// QuoteLine is one line of a conversation quote.
+type QuoteLine struct {
+	// SpeakerID is the ID of the Person who said the line, and Speaker is their name.
+	SpeakerID string
+	Speaker   string
+	Text      string
+}
+
+// IsConversation returns true if the quote is a conversation between several people.
+func (q Quote) IsConversation() bool {
+	return len(q.Lines) > 0
+}
+
+// ConversationText returns the lines of a conversation quote as text, each prefixed with the name of its speaker.
+func (q Quote) ConversationText() string {
+	text := make([]string, len(q.Lines))
+	for i, l := range q.Lines {
+		text[i] = l.Speaker + ": " + l.Text
+	}
+	return strings.Join(text, "\n")
+}
+
+// HasSpeaker returns true if the Person with the provided ID is the quotee of the quote, or a speaker in it.
+func (q Quote) HasSpeaker(personID string) bool {
+	if q.QuoteeID == personID {
+		return true
+	}
+	for _, l := range q.Lines {
+		if l.SpeakerID == personID {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTag returns true if the quote is tagged with the provided tag.
+func (q Quote) HasTag(tag string) bool {
+	for _, t := range q.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
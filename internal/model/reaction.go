@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// Reaction is an emoji with which a User has reacted to a Quote. Each user may react to a quote with each emoji
+// at most once.
+type Reaction struct {
+	QuoteID string
+	UserID  string
+	Emoji   string
+	Created time.Time
+}
@@ -2,30 +2,19 @@ package model
 
 import "time"
 
-// MaxQuizAttempts is the maximum number of times a user can submit an entry quiz.
-// If this number is exceeded, the user will be blocked from continuing.
-const MaxQuizAttempts = 5
-
-// User is a user of the application
+// User is a user of the application. Their access to each community is recorded by a Membership.
 type User struct {
 	ID         string
 	Name       string
 	Email      string
 	PictureURL string
 	Created    time.Time
-	QuizPassed bool
-	// QuizAttempts represents the number of times the user has submitted an entry quiz.
-	QuizAttempts int8
-	Banned       bool
-	Admin        bool
-}
-
-// IsAuthorized returns true if the user is authorized to access the application (they have passed the quiz and are not banned, or they are an admin)
-func (u User) IsAuthorized() bool {
-	return (u.QuizPassed && !u.Banned) || u.Admin
+	// Admin grants administration of the instance, including every community hosted by it, and is intended for those
+	// who operate the server. Admins of a single community are instead recorded by their Membership.
+	Admin bool
 }
 
-// IsAdmin returns true if the user is an admin
+// IsAdmin returns true if the user is an admin of the instance
 func (u User) IsAdmin() bool {
 	return u.Admin
 }
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// UserIdentity links an identity asserted by an OIDC provider to a User, allowing a single User to sign in through
+// multiple providers.
+type UserIdentity struct {
+	// ID uniquely identifies the identity, and is derived from the domain of the provider's issuer and the subject
+	// of the identity (e.g. accounts.google.com/1234567890).
+	ID     string
+	UserID string
+	// Domain is the domain of the provider's issuer, used to describe the identity to users.
+	Domain string
+	// Email is the email address reported by the provider when the identity was linked.
+	Email   string
+	Created time.Time
+}
@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
 
 // UserSession describes a session belonging to a user, and allows a cryptographically unique token (ID)
 // to be used to identify them. A UserSession also tracks additional information about the user's session,
@@ -17,3 +21,16 @@ type UserSession struct {
 func (us UserSession) IsExpired(now time.Time) bool {
 	return (us.Expires.IsZero() || us.Expires.Before(now))
 }
+
+// Handle returns a non-secret identifier of the session, as described by SessionHandle.
+func (us UserSession) Handle() string {
+	return SessionHandle(us.ID)
+}
+
+// SessionHandle returns the hex encoded SHA-256 hash of the session ID, which identifies the session without
+// revealing its ID, such that sessions can be listed and revoked without exposing the credential which authenticates
+// them.
+func SessionHandle(id string) string {
+	h := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(h[:])
+}
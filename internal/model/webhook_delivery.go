@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// WebhookDelivery is a payload queued for delivery to an outgoing webhook, which is retried until it succeeds or too
+// many attempts have been made.
+type WebhookDelivery struct {
+	ID string
+	// Webhook is the name of the webhook to which the payload should be delivered.
+	Webhook string
+	// Payload is the JSON body sent to the webhook.
+	Payload string
+	// Attempts is the number of unsuccessful delivery attempts made so far.
+	Attempts int
+	// NextAttempt is the time at or after which delivery should next be attempted.
+	NextAttempt time.Time
+	// LastError describes why the most recent delivery attempt failed.
+	LastError string
+	Created   time.Time
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/server/http/frontend"
+	"github.com/willbicks/epigram/internal/service"
+)
+
+// clearSessionCookie instructs the client to discard its session cookie.
+func clearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+		MaxAge:   -1,
+	})
+}
+
+// logoutHandler responds to POST requests by ending the current session, clearing the session cookie, and
+// redirecting to the home page.
+func (s *QuoteServer) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if c, err := r.Cookie(sessionCookieName); err == nil {
+			if err := s.UserService.EndUserSession(r.Context(), c.Value); err != nil {
+				s.serverError(w, r, err)
+				return
+			}
+		}
+
+		clearSessionCookie(w, r)
+		http.Redirect(w, r, s.paths.Home, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// renderAccountPage renders the account page, populating the remaining fields of the provided page (which may include
+// an Error or NewToken).
+func (s *QuoteServer) renderAccountPage(w http.ResponseWriter, r *http.Request, page frontend.AccountPage) {
+	sessions, err := s.UserService.GetUserSessions(r.Context())
+	if err != nil {
+		s.serviceError(w, r, err)
+		return
+	}
+
+	identities, err := s.UserService.GetUserIdentities(r.Context())
+	if err != nil {
+		s.serviceError(w, r, err)
+		return
+	}
+
+	tokens, err := s.APITokenService.GetAPITokens(r.Context())
+	if err != nil {
+		s.serviceError(w, r, err)
+		return
+	}
+
+	chatLinks, err := s.ChatService.GetChatLinks(r.Context())
+	if err != nil {
+		s.serviceError(w, r, err)
+		return
+	}
+
+	page.User = ctxval.UserFromContext(r.Context())
+	page.Membership = ctxval.MembershipFromContext(r.Context())
+	page.Sessions = sessions
+	page.Identities = identities
+	page.Providers = s.OIDCServices
+	page.Tokens = tokens
+	page.ChatLinks = chatLinks
+	if c, err := r.Cookie(sessionCookieName); err == nil {
+		page.CurrentSessionHandle = model.SessionHandle(c.Value)
+	}
+
+	if err := s.tmpl.RenderPage(r.Context(), w, page); err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+}
+
+// accountHandler renders the account page in response to GET requests
+func (s *QuoteServer) accountHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		s.renderAccountPage(w, r, frontend.AccountPage{})
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// accountRevokeSessionHandler responds to POST requests by revoking the current user's session identified by the
+// handle form value. If the current session is revoked, the session cookie is cleared and the user is redirected to the home
+// page, otherwise they are returned to the account page.
+func (s *QuoteServer) accountRevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		handle := r.FormValue("handle")
+		err := s.UserService.RevokeUserSession(r.Context(), handle)
+
+		var serr service.Error
+		if errors.As(err, &serr) && serr.StatusCode == http.StatusNotFound {
+			s.renderAccountPage(w, r, frontend.AccountPage{Error: err})
+			return
+		} else if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		if c, err := r.Cookie(sessionCookieName); err == nil && model.SessionHandle(c.Value) == handle {
+			clearSessionCookie(w, r)
+			http.Redirect(w, r, s.paths.Home, http.StatusSeeOther)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Account, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// accountCreateTokenHandler responds to POST requests by creating an API token for the current user, named by the name
+// form value, and rendering the account page with the token's secret.
+func (s *QuoteServer) accountCreateTokenHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		_, secret, err := s.APITokenService.CreateAPIToken(r.Context(), r.FormValue("name"))
+
+		var serr service.Error
+		if errors.As(err, &serr) && serr.StatusCode == http.StatusBadRequest {
+			s.renderAccountPage(w, r, frontend.AccountPage{Error: err})
+			return
+		} else if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		s.renderAccountPage(w, r, frontend.AccountPage{NewToken: secret})
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// accountRevokeTokenHandler responds to POST requests by revoking the current user's API token identified by the id
+// form value, and returning them to the account page.
+func (s *QuoteServer) accountRevokeTokenHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		err := s.APITokenService.RevokeAPIToken(r.Context(), r.FormValue("id"))
+
+		var serr service.Error
+		if errors.As(err, &serr) && serr.StatusCode == http.StatusNotFound {
+			s.renderAccountPage(w, r, frontend.AccountPage{Error: err})
+			return
+		} else if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Account, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
@@ -1,28 +1,312 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
 	"github.com/willbicks/epigram/internal/server/http/frontend"
+	"github.com/willbicks/epigram/internal/service"
 )
 
-// privacyHandler renders the privacy policy page in response to GET requests
+// renderAdminMainPage renders the admin page, including the provided error (if any).
+func (s *QuoteServer) renderAdminMainPage(w http.ResponseWriter, r *http.Request, pageErr error) {
+	members, err := s.UserService.GetMembers(r.Context())
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	pending, err := s.UserService.GetPendingMembers(r.Context())
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	invites, err := s.InviteService.GetInvites(r.Context())
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	requests, err := s.AccessRequestService.GetPendingAccessRequests(r.Context())
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	page := frontend.AdminMainPage{
+		Error:          pageErr,
+		Members:        members,
+		PendingMembers: pending,
+		InstanceAdmin:  ctxval.UserFromContext(r.Context()).Admin,
+		Invites:        invites,
+		InviteURL:      s.Config.BaseURL + s.inviteURL(""),
+		AccessRequests: requests,
+	}
+	if page.InstanceAdmin {
+		if page.Users, err = s.UserService.GetAllUsers(r.Context()); err != nil {
+			s.serverError(w, r, err)
+			return
+		}
+	}
+
+	err = s.tmpl.RenderPage(r.Context(), w, page)
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+}
+
+// adminMainHandler renders the admin page in response to GET requests
 func (s *QuoteServer) adminMainHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
-		users, err := s.UserService.GetAllUsers(r.Context())
+		s.renderAdminMainPage(w, r, nil)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// adminUserActionHandler returns a handler which responds to POST requests by performing the provided action on
+// the user (or other object, such as an invite) identified by the id form value, and then redirecting to the admin
+// page. If the action is rejected by the service, the admin page is rendered with the error.
+func (s *QuoteServer) adminUserActionHandler(action func(ctx context.Context, id string) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			if err := r.ParseForm(); err != nil {
+				s.clientError(w, r, err, http.StatusBadRequest)
+				return
+			}
+
+			err := action(r.Context(), r.FormValue("id"))
+
+			var serr service.Error
+			if errors.As(err, &serr) && serr.StatusCode == http.StatusBadRequest {
+				s.renderAdminMainPage(w, r, err)
+				return
+			} else if err != nil {
+				s.serviceError(w, r, err)
+				return
+			}
+
+			http.Redirect(w, r, s.paths.Admin, http.StatusSeeOther)
+		default:
+			s.methodNotAllowedError(w, r)
+			return
+		}
+	})
+}
+
+// adminMergeUsersHandler responds to POST requests by merging the user identified by the from form value into the
+// user identified by the into form value, and then redirecting to the admin page. If the merge is rejected by the
+// service, the admin page is rendered with the error.
+func (s *QuoteServer) adminMergeUsersHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		err := s.UserService.MergeUsers(r.Context(), r.FormValue("from"), r.FormValue("into"))
+
+		var serr service.Error
+		if errors.As(err, &serr) && serr.StatusCode == http.StatusBadRequest {
+			s.renderAdminMainPage(w, r, err)
+			return
+		} else if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Admin, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// adminUserHandler renders the details and quiz history of the member of the current community whose ID follows the
+// AdminUser path in response to GET requests.
+func (s *QuoteServer) adminUserHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, s.paths.AdminUser)
+	if id == "" || strings.Contains(id, "/") {
+		s.notFoundError(w, r)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		m, err := s.UserService.GetMember(r.Context(), id)
+		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		attempts, err := s.UserService.GetQuizAttempts(r.Context(), id)
+		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		questions, err := s.CommunityService.Quiz(r.Context()).GetQuestions(r.Context())
 		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		page := frontend.AdminUserPage{
+			Member:       m,
+			QuizAttempts: attempts,
+			Questions:    make(map[int]string, len(questions)),
+		}
+		for _, q := range questions {
+			page.Questions[q.ID] = q.Question
+		}
+
+		if err := s.tmpl.RenderPage(r.Context(), w, page); err != nil {
 			s.serverError(w, r, err)
 			return
 		}
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// renderAdminQuizPage renders the entry quiz questions page, including the provided error (if any).
+func (s *QuoteServer) renderAdminQuizPage(w http.ResponseWriter, r *http.Request, pageErr error) {
+	questions, err := s.CommunityService.Quiz(r.Context()).GetQuestions(r.Context())
+	if err != nil {
+		s.serviceError(w, r, err)
+		return
+	}
+
+	page := frontend.AdminQuizPage{
+		Error:     pageErr,
+		Questions: questions,
+	}
+
+	if err := s.tmpl.RenderPage(r.Context(), w, page); err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+}
+
+// adminQuizHandler renders the entry quiz questions page in response to GET requests.
+func (s *QuoteServer) adminQuizHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		s.renderAdminQuizPage(w, r, nil)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
 
-		err = s.tmpl.RenderPage(w, frontend.AdminMainPage{
-			Users: users,
-		})
+// adminQuizActionHandler returns a handler which responds to POST requests by performing the provided action on the
+// entry quiz of the current community, and then redirecting to the entry quiz questions page. If the action is
+// rejected by the service, the page is rendered with the error.
+func (s *QuoteServer) adminQuizActionHandler(action func(ctx context.Context, quiz service.EntryQuiz, r *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			if err := r.ParseForm(); err != nil {
+				s.clientError(w, r, err, http.StatusBadRequest)
+				return
+			}
+
+			err := action(r.Context(), s.CommunityService.Quiz(r.Context()), r)
+
+			var serr service.Error
+			if errors.As(err, &serr) && serr.StatusCode == http.StatusBadRequest {
+				s.renderAdminQuizPage(w, r, err)
+				return
+			} else if err != nil {
+				s.serviceError(w, r, err)
+				return
+			}
+
+			http.Redirect(w, r, s.paths.AdminQuiz, http.StatusSeeOther)
+		default:
+			s.methodNotAllowedError(w, r)
+			return
+		}
+	})
+}
+
+// quizQuestionID returns the question ID in the id form value of the provided request, or -1 (which is never the ID
+// of a question) if it is not a number.
+func quizQuestionID(r *http.Request) int {
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		return -1
+	}
+	return id
+}
+
+// quizQuestionFromForm returns the quiz question described by the form values of the provided request, with one
+// accepted answer on each line of the answers value.
+func quizQuestionFromForm(r *http.Request) model.QuizQuestion {
+	return model.QuizQuestion{
+		ID:        quizQuestionID(r),
+		Question:  r.FormValue("question"),
+		Answers:   strings.Split(r.FormValue("answers"), "\n"),
+		Normalize: r.Form["normalize"],
+	}
+}
+
+// auditLogPageSize is the maximum number of entries shown on the audit log page.
+const auditLogPageSize = 250
+
+// adminAuditHandler renders the audit log page in response to GET requests, filtered by the actor, action, and
+// target URL parameters.
+func (s *QuoteServer) adminAuditHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		query := service.AuditLogQuery{
+			Limit:    auditLogPageSize,
+			ActorID:  r.URL.Query().Get("actor"),
+			Action:   model.AuditAction(r.URL.Query().Get("action")),
+			TargetID: r.URL.Query().Get("target"),
+		}
+
+		entries, err := s.AuditService.QueryAuditLog(r.Context(), query)
+		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		users, err := s.UserService.GetAllUsers(r.Context())
 		if err != nil {
 			s.serverError(w, r, err)
 			return
 		}
+
+		page := frontend.AdminAuditPage{
+			Query:   query,
+			Entries: entries,
+			Actions: model.AuditActions,
+			Users:   make(map[string]model.User),
+		}
+		for _, u := range users {
+			page.Users[u.ID] = u
+			if u.Admin {
+				page.Admins = append(page.Admins, u)
+			}
+		}
+
+		if err := s.tmpl.RenderPage(r.Context(), w, page); err != nil {
+			s.serverError(w, r, err)
+			return
+		}
 	default:
 		s.methodNotAllowedError(w, r)
 		return
@@ -0,0 +1,309 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/logutils"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+)
+
+const (
+	// apiMaxPageSize is the maximum number of quotes which may be requested in a single page from the API.
+	apiMaxPageSize = 100
+
+	// apiMaxBodySize is the maximum size in bytes of a request body accepted by the API.
+	apiMaxBodySize = 64 << 10
+)
+
+// apiQuote is the JSON representation of a quote returned by the API.
+type apiQuote struct {
+	ID     string `json:"id"`
+	Quote  string `json:"quote"`
+	Quotee string `json:"quotee"`
+	// QuoteeID is the ID of the person to whom the quote is attributed.
+	QuoteeID string `json:"quoteeID"`
+	Context  string `json:"context"`
+	// Lines are the lines of a conversation quote, and are omitted for quotes said by one person.
+	Lines []apiQuoteLine `json:"lines,omitempty"`
+	// Tags are the normalized names of the tags on the quote, in alphabetical order.
+	Tags    []string  `json:"tags"`
+	Created time.Time `json:"created"`
+	// SubmitterID is only included for admins, and for the user's own quotes.
+	SubmitterID string `json:"submitterID,omitempty"`
+	// Modifiable indicates whether the user may edit the quote.
+	Modifiable bool `json:"modifiable"`
+}
+
+// apiQuoteLine is the JSON representation of one line of a conversation quote.
+type apiQuoteLine struct {
+	Speaker string `json:"speaker"`
+	// SpeakerID is the ID of the person who said the line, and is ignored in input.
+	SpeakerID string `json:"speakerID,omitempty"`
+	Text      string `json:"text"`
+}
+
+// apiQuoteInput is the JSON request body used to create or edit a quote. When editing, omitted fields are left
+// unchanged.
+type apiQuoteInput struct {
+	Quote   *string         `json:"quote"`
+	Quotee  *string         `json:"quotee"`
+	Context *string         `json:"context"`
+	Lines   *[]apiQuoteLine `json:"lines"`
+	Tags    *[]string       `json:"tags"`
+}
+
+// apply sets the fields of q which were provided in the input.
+func (in apiQuoteInput) apply(q *model.Quote) {
+	if in.Quote != nil {
+		q.Quote = *in.Quote
+	}
+	if in.Quotee != nil {
+		q.Quotee = *in.Quotee
+	}
+	if in.Context != nil {
+		q.Context = *in.Context
+	}
+	if in.Lines != nil {
+		q.Lines = nil
+		for _, l := range *in.Lines {
+			q.Lines = append(q.Lines, model.QuoteLine{Speaker: l.Speaker, Text: l.Text})
+		}
+	}
+	if in.Tags != nil {
+		q.Tags = *in.Tags
+	}
+}
+
+// apiQuoteList is the JSON representation of a page of quotes returned by the API.
+type apiQuoteList struct {
+	Quotes []apiQuote `json:"quotes"`
+	// Next is a cursor which may be provided as the after parameter to retrieve the next page, if there is one.
+	Next string `json:"next,omitempty"`
+}
+
+// apiErrorBody is the JSON representation of an error returned by the API.
+type apiErrorBody struct {
+	Issues []string `json:"issues"`
+}
+
+// toAPIQuote converts a quote to its API representation, from the perspective of the user on the context.
+func (s *QuoteServer) toAPIQuote(ctx context.Context, q model.Quote) apiQuote {
+	aq := apiQuote{
+		ID:         q.ID,
+		Quote:      q.Quote,
+		Quotee:     q.Quotee,
+		QuoteeID:   q.QuoteeID,
+		Context:    q.Context,
+		Tags:       append([]string{}, q.Tags...),
+		Created:    q.Created,
+		Modifiable: s.QuoteService.CanModifyQuote(ctx, q),
+	}
+
+	for _, l := range q.Lines {
+		aq.Lines = append(aq.Lines, apiQuoteLine{Speaker: l.Speaker, SpeakerID: l.SpeakerID, Text: l.Text})
+	}
+
+	if isAdmin(ctx) || ctxval.UserFromContext(ctx).ID == q.SubmitterID {
+		aq.SubmitterID = q.SubmitterID
+	}
+
+	return aq
+}
+
+// writeJSON writes v as the JSON body of a response with the provided status code.
+func (s *QuoteServer) writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.Logger.WarnContext(r.Context(), "unable to write JSON response", logutils.Error(err))
+	}
+}
+
+// apiError writes an error response to an API request. The issues of a service.Error with a client error status
+// code are returned to the client, while other errors are logged and reported as a generic internal server error.
+func (s *QuoteServer) apiError(w http.ResponseWriter, r *http.Request, err error) {
+	var serr service.Error
+	if errors.As(err, &serr) && serr.StatusCode >= 400 && serr.StatusCode < 500 {
+		s.Logger.DebugContext(r.Context(), "api client error", "status", serr.StatusCode, logutils.Error(err))
+		s.writeJSON(w, r, serr.StatusCode, apiErrorBody{Issues: serr.Issues})
+		return
+	}
+
+	s.Logger.ErrorContext(r.Context(), "api internal server error", logutils.Error(err))
+	s.writeJSON(w, r, http.StatusInternalServerError, apiErrorBody{
+		Issues: []string{http.StatusText(http.StatusInternalServerError)},
+	})
+}
+
+// apiClientError writes an error response to an API request with the provided status code and issue.
+func (s *QuoteServer) apiClientError(w http.ResponseWriter, r *http.Request, code int, issue string) {
+	s.apiError(w, r, service.Error{
+		Issues:     []string{issue},
+		StatusCode: code,
+	})
+}
+
+// apiMethodNotAllowed writes a 405 method not allowed error response to an API request.
+func (s *QuoteServer) apiMethodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	s.apiClientError(w, r, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+}
+
+// decodeAPIQuoteInput decodes the JSON body of an API request into an apiQuoteInput.
+func decodeAPIQuoteInput(w http.ResponseWriter, r *http.Request) (apiQuoteInput, error) {
+	var in apiQuoteInput
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		return apiQuoteInput{}, service.Error{
+			Issues:     []string{"Request body must be a valid JSON quote: " + err.Error()},
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return in, nil
+}
+
+// requireAPIToken authenticates API requests using the API token provided in the Authorization header as a bearer
+// token, replacing any user identified by a session cookie, and the community the token was created in. Requests
+// without a valid token are rejected.
+func (s *QuoteServer) requireAPIToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			s.apiClientError(w, r, http.StatusUnauthorized, "An API token must be provided as a bearer token.")
+			return
+		}
+
+		u, communityID, err := s.APITokenService.GetUserFromAPIToken(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			s.apiError(w, r, err)
+			return
+		}
+
+		// requests are made in the community the token was created in, rather than that of any cookie
+		ctx, err := s.CommunityService.ContextWithCommunity(ctxval.ContextWithUser(r.Context(), u), communityID)
+		if err != nil {
+			s.apiError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// apiQuotesHandler handles API requests to the quotes collection, either GET requests to list quotes, filtered by the
+// same parameters as the quotes page and limited by the limit parameter, or POST requests to create a new quote.
+func (s *QuoteServer) apiQuotesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		params := r.URL.Query()
+
+		limit := quotesPageSize
+		if l := params.Get("limit"); l != "" {
+			var err error
+			limit, err = strconv.Atoi(l)
+			if err != nil || limit < 1 || limit > apiMaxPageSize {
+				s.apiClientError(w, r, http.StatusBadRequest, "Limit must be a number between 1 and "+
+					strconv.Itoa(apiMaxPageSize)+".")
+				return
+			}
+		}
+
+		query, _, err := quoteQueryFromParams(params, limit)
+		if err != nil {
+			s.apiError(w, r, err)
+			return
+		}
+
+		quotes, next, err := s.QuoteService.QueryQuotes(r.Context(), query)
+		if err != nil {
+			s.apiError(w, r, err)
+			return
+		}
+
+		list := apiQuoteList{
+			Quotes: make([]apiQuote, 0, len(quotes)),
+		}
+		for _, q := range quotes {
+			list.Quotes = append(list.Quotes, s.toAPIQuote(r.Context(), q))
+		}
+		if next != nil {
+			list.Next = next.String()
+		}
+
+		s.writeJSON(w, r, http.StatusOK, list)
+	case "POST":
+		in, err := decodeAPIQuoteInput(w, r)
+		if err != nil {
+			s.apiError(w, r, err)
+			return
+		}
+
+		var q model.Quote
+		in.apply(&q)
+
+		if err := s.QuoteService.CreateQuote(r.Context(), &q); err != nil {
+			s.apiError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Location", s.paths.APIQuote+q.ID)
+		s.writeJSON(w, r, http.StatusCreated, s.toAPIQuote(r.Context(), q))
+	default:
+		s.apiMethodNotAllowed(w, r, "GET", "POST")
+	}
+}
+
+// apiQuoteHandler handles API requests to an individual quote identified by the path following APIQuote, either GET
+// requests to retrieve the quote, or PATCH requests to edit it.
+func (s *QuoteServer) apiQuoteHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, s.paths.APIQuote)
+	if id == "" || strings.Contains(id, "/") {
+		s.apiError(w, r, service.ErrQuoteNotFound)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		q, err := s.QuoteService.GetQuote(r.Context(), id)
+		if err != nil {
+			s.apiError(w, r, err)
+			return
+		}
+
+		s.writeJSON(w, r, http.StatusOK, s.toAPIQuote(r.Context(), q))
+	case "PATCH":
+		in, err := decodeAPIQuoteInput(w, r)
+		if err != nil {
+			s.apiError(w, r, err)
+			return
+		}
+
+		q, err := s.QuoteService.GetQuoteForEdit(r.Context(), id)
+		if err != nil {
+			s.apiError(w, r, err)
+			return
+		}
+		in.apply(&q)
+
+		if err := s.QuoteService.EditQuote(r.Context(), &q); err != nil {
+			s.apiError(w, r, err)
+			return
+		}
+
+		s.writeJSON(w, r, http.StatusOK, s.toAPIQuote(r.Context(), q))
+	default:
+		s.apiMethodNotAllowed(w, r, "GET", "PATCH")
+	}
+}
@@ -1,16 +1,19 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/willbicks/epigram/internal/ctxval"
 	"github.com/willbicks/epigram/internal/logutils"
 )
 
 // interpretSession wraps the request's context with the authenticated user, if they are known.
-// Otherwise, execution passes to the next handler.
+// Otherwise, execution passes to the next handler. If the session is renewed, the session cookie is
+// re-issued with its new expiry.
 func (s *QuoteServer) interpretSession(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		c, err := r.Cookie(sessionCookieName)
@@ -21,7 +24,7 @@ func (s *QuoteServer) interpretSession(next http.Handler) http.Handler {
 			return
 		}
 
-		u, err := s.UserService.GetUserFromSessionID(r.Context(), c.Value)
+		u, sess, renewed, err := s.UserService.ResumeUserSession(r.Context(), c.Value)
 		if err != nil {
 			// session token is invalid
 			s.Logger.WarnContext(r.Context(), "unable to get user from session", logutils.Error(err))
@@ -29,11 +32,51 @@ func (s *QuoteServer) interpretSession(next http.Handler) http.Handler {
 			return
 		}
 
+		if renewed {
+			setSessionCookie(w, r, sess)
+		}
+
 		ctx := ctxval.ContextWithUser(r.Context(), u)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// communityCookieName is the name of the cookie which stores the ID of the community the user is browsing.
+const communityCookieName = "community"
+
+// setCommunityCookie instructs the client to browse the community with the provided ID.
+func setCommunityCookie(w http.ResponseWriter, r *http.Request, id string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     communityCookieName,
+		Value:    id,
+		Path:     "/",
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+		MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+	})
+}
+
+// interpretCommunity wraps the request's context with the community identified by the community cookie, along with
+// the user's membership of it. If the cookie is not set, or identifies a community which is no longer hosted, the
+// default community is used.
+func (s *QuoteServer) interpretCommunity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := s.CommunityService.DefaultCommunity().ID
+		if c, err := r.Cookie(communityCookieName); err == nil {
+			if _, err := s.CommunityService.GetCommunity(c.Value); err == nil {
+				id = c.Value
+			}
+		}
+
+		ctx, err := s.CommunityService.ContextWithCommunity(r.Context(), id)
+		if err != nil {
+			s.serverError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // requireLoggedIn requires that the request has a valid session which has been translated to a user.
 // If the user is not logged in, they will be redirected to the login page.
 func (s *QuoteServer) requireLoggedIn(next http.Handler) http.Handler {
@@ -47,24 +90,56 @@ func (s *QuoteServer) requireLoggedIn(next http.Handler) http.Handler {
 	})
 }
 
-// requireQuizPassed requires that the user has passed the entry quiz before proceeding, and if not,
-// redirects them to the quiz page.
-func (s *QuoteServer) requireQuizPassed(next http.Handler) http.Handler {
+// joinCommunity makes the signed in user a member of the current community when they first visit it, if it has no
+// entry quiz (communities with a quiz are joined by attempting it), such that communities which require approval list
+// them as pending.
+func (s *QuoteServer) joinCommunity(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		u := ctxval.UserFromContext(r.Context())
-		if u.QuizPassed {
+		m := ctxval.MembershipFromContext(r.Context())
+		if u.ID != "" && m.Joined.IsZero() && !ctxval.CommunityFromContext(r.Context()).Gate.RequiresQuiz() {
+			m, err := s.UserService.JoinCommunity(r.Context())
+			if err != nil {
+				s.serverError(w, r, err)
+				return
+			}
+			r = r.WithContext(ctxval.ContextWithMembership(r.Context(), m))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAccess requires that the user has passed the gate of the current community before proceeding. Users who
+// must pass its entry quiz are redirected to the quiz page, while those awaiting approval from its admins (or who were
+// rejected) are redirected to the pending page. Admins of the community, and of the instance, may always proceed.
+func (s *QuoteServer) requireAccess(next http.Handler) http.Handler {
+	return s.joinCommunity(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := ctxval.UserFromContext(r.Context())
+		m := ctxval.MembershipFromContext(r.Context())
+		gate := ctxval.CommunityFromContext(r.Context()).Gate
+		switch {
+		case u.ID == "":
+			http.Redirect(w, r, s.paths.Login, http.StatusSeeOther)
+		case u.Admin || m.Admin || m.PassedGate(gate):
 			next.ServeHTTP(w, r)
-		} else {
+		case gate.RequiresQuiz() && !m.QuizPassed:
 			http.Redirect(w, r, s.paths.Quiz, http.StatusSeeOther)
+		default:
+			http.Redirect(w, r, s.paths.Pending, http.StatusSeeOther)
 		}
-	})
+	}))
 }
 
-// requireAdmin requires that the user be an admin, otherwise a 401 error is returned.
+// isAdmin returns true if the user on the context is an admin of the current community, or of the instance.
+func isAdmin(ctx context.Context) bool {
+	return ctxval.UserFromContext(ctx).Admin || ctxval.MembershipFromContext(ctx).Admin
+}
+
+// requireAdmin requires that the user be an admin of the current community (or of the instance), otherwise a 401 error
+// is returned.
 func (s *QuoteServer) requireAdmin(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		u := ctxval.UserFromContext(r.Context())
-		if u.Admin {
+		if isAdmin(r.Context()) {
 			next.ServeHTTP(w, r)
 		} else {
 			s.clientError(w, r, errors.New("you are not authorized to access this page"), http.StatusUnauthorized)
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/willbicks/epigram/internal/config"
+	"github.com/willbicks/epigram/internal/server/http/frontend"
+	"github.com/willbicks/epigram/internal/service"
+)
+
+const (
+	chatPlatformSlack      = "slack"
+	chatPlatformMattermost = "mattermost"
+
+	// slackMaxRequestAge is the maximum age of a Slack request, beyond which it is rejected to prevent replay attacks.
+	slackMaxRequestAge = 5 * time.Minute
+
+	// chatMaxBodySize is the maximum size in bytes of a slash command request body.
+	chatMaxBodySize = 16 << 10
+)
+
+// chatResponse is the JSON response to a slash command, understood by both Slack and Mattermost.
+type chatResponse struct {
+	// ResponseType is either "ephemeral" (only shown to the user who invoked the command), or "in_channel".
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// verifySlackRequest returns true if the request body was signed by Slack using the provided signing secret. See
+// https://api.slack.com/authentication/verifying-requests-from-slack
+func verifySlackRequest(h http.Header, body []byte, secret string, now time.Time) bool {
+	ts := h.Get("X-Slack-Request-Timestamp")
+	sent, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(sent, 0)); age > slackMaxRequestAge || age < -slackMaxRequestAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(h.Get("X-Slack-Signature")), []byte(want))
+}
+
+// verifyMattermostRequest returns true if the request includes the provided slash command token, either in the
+// Authorization header or the token form value.
+func verifyMattermostRequest(h http.Header, form url.Values, token string) bool {
+	got, ok := strings.CutPrefix(h.Get("Authorization"), "Token ")
+	if !ok {
+		got = form.Get("token")
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// escapeChatText escapes the control characters of chat messages sent to the provided platform.
+func escapeChatText(platform string, text string) string {
+	if platform != chatPlatformSlack {
+		return text
+	}
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// chatCommandHandler handles POST requests from the slash command of the provided chat platform, verifying that they
+// were sent by the platform, and then responding to the command:
+//   - "help" (or no text): describes how to use the command
+//   - "link": returns a link which the user may follow to link their chat account to their account
+//   - otherwise: submits the text as a quote on behalf of the linked user
+func (s *QuoteServer) chatCommandHandler(c config.ChatCommand) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			s.methodNotAllowedError(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, chatMaxBodySize))
+		if err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		var verified bool
+		switch c.Platform {
+		case chatPlatformSlack:
+			verified = verifySlackRequest(r.Header, body, c.Secret, time.Now())
+		case chatPlatformMattermost:
+			verified = verifyMattermostRequest(r.Header, form, c.Secret)
+		}
+		if !verified {
+			s.clientError(w, r, errors.New("unable to verify request"), http.StatusUnauthorized)
+			return
+		}
+
+		cu := service.ChatUser{
+			Platform: c.Platform,
+			TeamID:   form.Get("team_id"),
+			UserID:   form.Get("user_id"),
+			UserName: form.Get("user_name"),
+		}
+		command := form.Get("command")
+		if command == "" {
+			command = "/quote"
+		}
+		text := strings.TrimSpace(form.Get("text"))
+
+		reply := func(responseType string, text string) {
+			s.writeJSON(w, r, http.StatusOK, chatResponse{
+				ResponseType: responseType,
+				Text:         escapeChatText(c.Platform, text),
+			})
+		}
+
+		switch strings.ToLower(text) {
+		case "", "help":
+			reply("ephemeral", "Submit a quote with: "+command+" "+service.ChatQuoteUsage+"\n"+
+				"Before submitting quotes, link your chat account to your account with: "+command+" link")
+			return
+		case "link":
+			code, err := s.ChatService.CreateLinkCode(cu)
+			if err != nil {
+				s.serverError(w, r, err)
+				return
+			}
+			reply("ephemeral", "Follow this link to link your chat account to your account: "+
+				s.Config.BaseURL+s.paths.AccountLinkChat+"?code="+url.QueryEscape(code))
+			return
+		}
+
+		q, err := s.ChatService.SubmitChatQuote(r.Context(), cu, c.Community, text)
+
+		var serr service.Error
+		if errors.As(err, &serr) && (serr.StatusCode == http.StatusForbidden || serr.StatusCode == http.StatusUnauthorized) {
+			// either the chat user is not linked, or the linked user may not submit quotes
+			reply("ephemeral", "Your chat account is not linked to an account, or you are not permitted to submit "+
+				"quotes. Link your chat account with: "+command+" link")
+			return
+		} else if errors.As(err, &serr) && serr.StatusCode >= 400 && serr.StatusCode < 500 {
+			reply("ephemeral", strings.Join(serr.Issues, " ")+"\nUsage: "+command+" "+service.ChatQuoteUsage)
+			return
+		} else if err != nil {
+			s.serverError(w, r, err)
+			return
+		}
+
+		msg := "Submitted: \"" + q.Quote + "\" — " + q.Quotee
+		if q.Context != "" {
+			msg += " (" + q.Context + ")"
+		}
+		reply("ephemeral", msg)
+	})
+}
+
+// accountLinkChatHandler handles requests to link a chat account to the current user, either GET requests to render
+// a confirmation page for the code query parameter, or POST requests to link the chat account identified by the code
+// form value.
+func (s *QuoteServer) accountLinkChatHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		code := r.URL.Query().Get("code")
+		page := frontend.ChatLinkPage{Code: code}
+
+		cu, err := s.ChatService.ParseLinkCode(code)
+		if err != nil {
+			page.Error = err
+		} else {
+			page.ChatUser = cu
+		}
+
+		if err := s.tmpl.RenderPage(r.Context(), w, page); err != nil {
+			s.serverError(w, r, err)
+		}
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		_, err := s.ChatService.LinkChatUser(r.Context(), r.FormValue("code"))
+
+		var serr service.Error
+		if errors.As(err, &serr) && serr.StatusCode == http.StatusBadRequest {
+			if err := s.tmpl.RenderPage(r.Context(), w, frontend.ChatLinkPage{Error: err}); err != nil {
+				s.serverError(w, r, err)
+			}
+			return
+		} else if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Account, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// accountUnlinkChatHandler responds to POST requests by unlinking the current user's chat account identified by the
+// id form value, and returning them to the account page.
+func (s *QuoteServer) accountUnlinkChatHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		err := s.ChatService.UnlinkChatUser(r.Context(), r.FormValue("id"))
+
+		var serr service.Error
+		if errors.As(err, &serr) && serr.StatusCode == http.StatusNotFound {
+			s.renderAccountPage(w, r, frontend.AccountPage{Error: err})
+			return
+		} else if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Account, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
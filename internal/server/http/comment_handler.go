@@ -0,0 +1,102 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+)
+
+// commentCreateHandler handles POST requests to comment on the quote specified by the quote form value, optionally in
+// reply to the comment specified by the parent form value, and then returns the user to the quote.
+func (s *QuoteServer) commentCreateHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+		c := model.Comment{
+			QuoteID:  r.FormValue("quote"),
+			ParentID: r.FormValue("parent"),
+			Text:     r.FormValue("text"),
+		}
+
+		createErr := s.CommentService.CreateComment(r.Context(), &c)
+
+		var serr service.Error
+		if errors.As(createErr, &serr) && serr.StatusCode == http.StatusBadRequest {
+			// validation issues are presented alongside the quote
+			page, err := s.getQuotePage(r.Context(), c.QuoteID)
+			if err != nil {
+				s.serviceError(w, r, err)
+				return
+			}
+			page.Comment = c
+			page.Error = createErr
+
+			if err := s.tmpl.RenderPage(r.Context(), w, page); err != nil {
+				s.serverError(w, r, err)
+			}
+			return
+		} else if createErr != nil {
+			s.serviceError(w, r, createErr)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Quote+c.QuoteID+"#comment-"+c.ID, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// commentEditHandler handles POST requests to replace the text of the comment specified by the id form value with the
+// text form value, and then returns the user to the quote.
+func (s *QuoteServer) commentEditHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+		c := model.Comment{
+			ID:   r.FormValue("id"),
+			Text: r.FormValue("text"),
+		}
+
+		if err := s.CommentService.EditComment(r.Context(), &c); err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Quote+c.QuoteID+"#comment-"+c.ID, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// commentDeleteHandler handles POST requests to delete the comment specified by the id form value, and then returns
+// the user to the quote.
+func (s *QuoteServer) commentDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		quoteID, err := s.CommentService.DeleteComment(r.Context(), r.FormValue("id"))
+		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Quote+quoteID, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/willbicks/epigram/internal/server/http/frontend"
+)
+
+// communitiesHandler renders the communities page, listing each community and the user's membership of it, in
+// response to GET requests.
+func (s *QuoteServer) communitiesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		communities, err := s.CommunityService.GetCommunityMemberships(r.Context())
+		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		if err := s.tmpl.RenderPage(r.Context(), w, frontend.CommunitiesPage{
+			Communities: communities,
+		}); err != nil {
+			s.serverError(w, r, err)
+			return
+		}
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// communitySwitchHandler responds to POST requests by switching to the community identified by the id form value,
+// and redirecting to its quotes page (or its quiz, if the user is yet to pass it).
+func (s *QuoteServer) communitySwitchHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		c, err := s.CommunityService.GetCommunity(r.FormValue("id"))
+		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		setCommunityCookie(w, r, c.ID)
+		http.Redirect(w, r, s.paths.Quotes, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
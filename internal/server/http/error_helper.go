@@ -1,11 +1,13 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"runtime/debug"
 
 	"github.com/willbicks/epigram/internal/logutils"
+	"github.com/willbicks/epigram/internal/service"
 )
 
 // serverError writes an error message and stack trace to the errorLog,
@@ -43,3 +45,15 @@ func (s *QuoteServer) notFoundError(w http.ResponseWriter, r *http.Request) {
 func (s *QuoteServer) methodNotAllowedError(w http.ResponseWriter, r *http.Request) {
 	s.clientError(w, r, nil, http.StatusMethodNotAllowed)
 }
+
+// serviceError is a helper which inspects an error returned by a service. If it is a service.Error with a client
+// error status code, it is written using clientError, otherwise it is treated as a serverError.
+func (s *QuoteServer) serviceError(w http.ResponseWriter, r *http.Request, err error) {
+	var serr service.Error
+	if errors.As(err, &serr) && serr.StatusCode >= 400 && serr.StatusCode < 500 {
+		s.clientError(w, r, serr, serr.StatusCode)
+		return
+	}
+
+	s.serverError(w, r, err)
+}
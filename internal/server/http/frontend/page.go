@@ -1,6 +1,8 @@
 package frontend
 
 import (
+	"time"
+
 	"github.com/willbicks/epigram/internal/model"
 	"github.com/willbicks/epigram/internal/service"
 )
@@ -26,7 +28,16 @@ func (PrivacyPage) viewName() string {
 	return "privacy.gohtml"
 }
 
-// QuotesPage lists all quotes by year
+// LoginPage lists the OIDC providers which users may sign in with
+type LoginPage struct {
+	Providers []service.OIDC
+}
+
+func (LoginPage) viewName() string {
+	return "login.gohtml"
+}
+
+// QuotesPage lists a page of quotes by year
 type QuotesPage struct {
 	// RenderAdmin is true if the page should render admin controls / info
 	RenderAdmin bool
@@ -35,30 +46,283 @@ type QuotesPage struct {
 	Quote  model.Quote
 	Quotes []model.Quote
 
+	// Search, Quotee, SubmitterID, Tag and Year are the filters applied to Quotes, if any
+	Search      string
+	Quotee      string
+	SubmitterID string
+	Tag         string
+	Year        int
+	// Sort is the order in which Quotes are listed, either empty (newest first) or "loved" (most reactions first)
+	Sort string
+	// Paged is true if Quotes does not begin with the first matching quote
+	Paged bool
+	// NextPage is the URL of the next page of quotes, or empty if there are no more quotes
+	NextPage string
+
 	// Users is a map of user ID to user, and should only be populated if RenderAdmin is true
 	Users map[string]model.User
+
+	// Modifiable is a set of IDs of quotes which the current user may edit or delete
+	Modifiable map[string]bool
+
+	// Reactions is a map of quote ID to a summary of the reactions to the quote with each available emoji
+	Reactions map[string][]service.ReactionSummary
+	// ReturnURL is the URL of this page, to which the user is returned after reacting to a quote
+	ReturnURL string
+
+	// Quotees are the names and aliases of people who have been quoted, suggested when submitting a quote
+	Quotees []string
 }
 
 func (QuotesPage) viewName() string {
 	return "quotes.gohtml"
 }
 
+// QuotePage presents a single quote, and the comments on it
+type QuotePage struct {
+	// RenderAdmin is true if the page should render admin controls / info
+	RenderAdmin bool
+
+	Quote model.Quote
+	// Submitter is the user who submitted the quote, and should only be populated if RenderAdmin is true
+	Submitter model.User
+	// Modifiable is true if the current user may edit or delete the quote
+	Modifiable bool
+	Reactions  []service.ReactionSummary
+
+	Comments []service.CommentThread
+	// Error and Comment are an error which occurred while submitting a comment, and the comment which was submitted
+	Error   error
+	Comment model.Comment
+}
+
+func (QuotePage) viewName() string {
+	return "quote.gohtml"
+}
+
+// QuoteEditPage presents a form to edit an existing quote
+type QuoteEditPage struct {
+	Error error
+	Quote model.Quote
+	// Quotees are the names and aliases of people who have been quoted, suggested when editing the quote
+	Quotees []string
+}
+
+func (QuoteEditPage) viewName() string {
+	return "quote_edit.gohtml"
+}
+
+// PeoplePage lists the people who have been quoted
+type PeoplePage struct {
+	// RenderAdmin is true if the page should render admin controls / info
+	RenderAdmin bool
+
+	Error  error
+	People []service.PersonSummary
+}
+
+func (PeoplePage) viewName() string {
+	return "people.gohtml"
+}
+
+// TagsPage lists the tags on quotes
+type TagsPage struct {
+	// RenderAdmin is true if the page should render admin controls / info
+	RenderAdmin bool
+
+	Error error
+	Tags  []service.TagSummary
+}
+
+func (TagsPage) viewName() string {
+	return "tags.gohtml"
+}
+
+// PersonPage presents a person, and lists a page of the quotes attributed to them
+type PersonPage struct {
+	// RenderAdmin is true if the page should render admin controls / info
+	RenderAdmin bool
+
+	Person model.Person
+	// User is the user linked to the person, and should only be populated if RenderAdmin is true
+	User model.User
+
+	Quotes []model.Quote
+	// Paged is true if Quotes does not begin with the person's newest quote
+	Paged bool
+	// NextPage is the URL of the next page of quotes, or empty if there are no more quotes
+	NextPage string
+
+	// Users is a map of user ID to user, and should only be populated if RenderAdmin is true
+	Users map[string]model.User
+	// Modifiable is a set of IDs of quotes which the current user may edit or delete
+	Modifiable map[string]bool
+	// Reactions is a map of quote ID to a summary of the reactions to the quote with each available emoji
+	Reactions map[string][]service.ReactionSummary
+	// ReturnURL is the URL of this page, to which the user is returned after reacting to a quote
+	ReturnURL string
+}
+
+func (PersonPage) viewName() string {
+	return "person.gohtml"
+}
+
+// PersonEditPage presents a form for admins to edit a person
+type PersonEditPage struct {
+	Error  error
+	Person model.Person
+	// Users are the users which the person may be linked to
+	Users []model.User
+}
+
+func (PersonEditPage) viewName() string {
+	return "person_edit.gohtml"
+}
+
 // QuizPage presents a quiz (list of questions)
 type QuizPage struct {
 	Error        error
 	NumQuestions int
 	Questions    []service.QuizQuestion
+	// LockedOut is true if the user has used all of their attempts, in which case no questions are presented, and
+	// AccessRequest contains their request for access (if they have made one).
+	LockedOut     bool
+	AccessRequest model.AccessRequest
+	// Wait is the time the user must wait before attempting the quiz again, during which no questions are presented.
+	Wait time.Duration
 }
 
 func (QuizPage) viewName() string {
 	return "quiz.gohtml"
 }
 
-// AdminMainPage lists the users
+// PendingPage informs the user that they are awaiting approval from the admins of the current community, or that
+// they were rejected
+type PendingPage struct {
+	Rejected bool
+}
+
+func (PendingPage) viewName() string {
+	return "pending.gohtml"
+}
+
+// AdminMainPage lists the members of the current community, and provides controls to manage them
 type AdminMainPage struct {
-	Users []model.User
+	Error   error
+	Members []service.Member
+	// PendingMembers are the members of the current community awaiting approval from its admins.
+	PendingMembers []service.Member
+	// InstanceAdmin is true if the page should render controls reserved for admins of the instance, in which case
+	// Users contains every user which may be merged.
+	InstanceAdmin bool
+	Users         []model.User
+	// Invites are the invites to the current community, whose links consist of InviteURL followed by their code.
+	Invites   []service.InviteSummary
+	InviteURL string
+	// AccessRequests are the requests for access to the current community awaiting a decision.
+	AccessRequests []service.PendingAccessRequest
 }
 
 func (AdminMainPage) viewName() string {
 	return "admin_main.gohtml"
 }
+
+// AccountPage presents the current user's account, and lists their active sessions, linked logins, and API tokens
+type AccountPage struct {
+	Error error
+	User  model.User
+	// Membership is the user's membership of the current community
+	Membership model.Membership
+	Sessions   []service.SessionSummary
+	// CurrentSessionHandle is the handle of the session used to make the request
+	CurrentSessionHandle string
+
+	Identities []model.UserIdentity
+	// Providers are the OIDC providers which the user may link another login from
+	Providers []service.OIDC
+
+	Tokens []model.APIToken
+	// NewToken is the secret of a newly created API token, which is only presented once
+	NewToken string
+
+	ChatLinks []model.ChatLink
+}
+
+func (AccountPage) viewName() string {
+	return "account.gohtml"
+}
+
+// CommunitiesPage lists the communities hosted by the server, and allows the user to switch between them
+type CommunitiesPage struct {
+	Communities []service.CommunityMembership
+}
+
+func (CommunitiesPage) viewName() string {
+	return "communities.gohtml"
+}
+
+// ChatLinkPage asks the user to confirm that a chat user should be linked to their account
+type ChatLinkPage struct {
+	Error error
+	// Code is the chat link code identifying the chat user, which is submitted to confirm the link
+	Code     string
+	ChatUser service.ChatUser
+}
+
+func (ChatLinkPage) viewName() string {
+	return "chat_link.gohtml"
+}
+
+// InvitePage presents an invite to join a community, which signed in users may redeem, and other users may sign in to
+// redeem.
+type InvitePage struct {
+	Error     error
+	Code      string
+	Community model.Community
+	SignedIn  bool
+	// Providers are the OIDC providers which users who are not signed in may sign in with
+	Providers []service.OIDC
+}
+
+func (InvitePage) viewName() string {
+	return "invite.gohtml"
+}
+
+// AdminUserPage presents the details of a member of the current community, and their history of attempts at its entry
+// quiz
+type AdminUserPage struct {
+	Member       service.Member
+	QuizAttempts []model.QuizAttempt
+	// Questions is a map of question ID to question, used to display the questions of each attempt
+	Questions map[int]string
+}
+
+func (AdminUserPage) viewName() string {
+	return "admin_user.gohtml"
+}
+
+// AdminQuizPage lists every question of the entry quiz of the current community, and provides controls to create,
+// edit, reorder, enable, and disable them
+type AdminQuizPage struct {
+	Error     error
+	Questions []model.QuizQuestion
+}
+
+func (AdminQuizPage) viewName() string {
+	return "admin_quiz.gohtml"
+}
+
+// AdminAuditPage lists entries in the audit log, and provides controls to filter them
+type AdminAuditPage struct {
+	Query   service.AuditLogQuery
+	Entries []model.AuditLogEntry
+	// Actions and Admins are the options presented to filter by action and actor
+	Actions []model.AuditAction
+	Admins  []model.User
+	// Users is a map of user ID to user, used to display the names of actors and targets
+	Users map[string]model.User
+}
+
+func (AdminAuditPage) viewName() string {
+	return "admin_audit.gohtml"
+}
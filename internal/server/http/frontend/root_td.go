@@ -1,6 +1,7 @@
 package frontend
 
 import (
+	"github.com/willbicks/epigram/internal/model"
 	"github.com/willbicks/epigram/internal/server/http/paths"
 )
 
@@ -9,6 +10,9 @@ type RootTD struct {
 	Title       string
 	Description string
 	Paths       paths.Paths
+	// Communities are all communities hosted by the server, and Community is the one the page is rendered in.
+	Communities []model.Community
+	Community   model.Community
 	Page        Page
 }
 
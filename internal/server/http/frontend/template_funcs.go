@@ -1,6 +1,7 @@
 package frontend
 
 import (
+	"errors"
 	"fmt"
 	"html/template"
 	"net/url"
@@ -26,13 +27,9 @@ var templateFuncs template.FuncMap = template.FuncMap{
 
 		return serr.Issues
 	},
-	// quotesByYear takes a slice of quotes, and returns them as a map where the key is the year.
+	// quotesByYear takes a slice of quotes (ordered from newest to oldest), and returns them as a map where the key
+	// is the year.
 	"quotesByYear": func(quotes []model.Quote) map[int][]model.Quote {
-		// sort quotes from newest to oldest
-		sort.Slice(quotes, func(i, j int) bool {
-			return quotes[i].Created.After(quotes[j].Created)
-		})
-
 		byYear := make(map[int][]model.Quote)
 
 		for _, q := range quotes {
@@ -55,6 +52,24 @@ var templateFuncs template.FuncMap = template.FuncMap{
 		})
 		return years
 	},
+	// dict accepts an even number of arguments, alternating between string keys and values of any type, and
+	// returns them as a map. It is used to pass multiple values to a template.
+	"dict": func(pairs ...any) (map[string]any, error) {
+		if len(pairs)%2 != 0 {
+			return nil, errors.New("dict requires an even number of arguments")
+		}
+
+		m := make(map[string]any, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			k, ok := pairs[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("dict key %v is not a string", pairs[i])
+			}
+			m[k] = pairs[i+1]
+		}
+
+		return m, nil
+	},
 	// sizeImage accepts a url of an image, and attempts to resize it by modifying the urlparams of the url,
 	// depending on the image hosting service. Currently supports googleusercontent. Returns the url of the
 	// sizedImage, or returns a url to a not found image placeholder if not a valid URL.
@@ -1,11 +1,14 @@
 package frontend
 
 import (
+	"context"
 	"errors"
 	"html/template"
 	"io"
 	"io/fs"
 	"path/filepath"
+
+	"github.com/willbicks/epigram/internal/ctxval"
 )
 
 // TemplateEngine is responsible for storing cached html templates, and rendering them on-demand with
@@ -69,8 +72,9 @@ func (e *TemplateEngine) initViews() error {
 	return nil
 }
 
-// RenderPage renders the specified view with the provided data joined to the RootTD.
-func (e TemplateEngine) RenderPage(w io.Writer, page Page) error {
+// RenderPage renders the specified view with the provided data joined to the RootTD. If the context has a current
+// community, the page is titled and described as it.
+func (e TemplateEngine) RenderPage(ctx context.Context, w io.Writer, page Page) error {
 
 	// If devMode is true, reload the templates on every render
 	if e.DevMode {
@@ -85,5 +89,12 @@ func (e TemplateEngine) RenderPage(w io.Writer, page Page) error {
 		return errors.New("template not found in views")
 	}
 
-	return t.ExecuteTemplate(w, page.viewName(), e.rootTD.joinPage(page))
+	td := e.rootTD.joinPage(page)
+	if c := ctxval.CommunityFromContext(ctx); c.ID != "" {
+		td.Community = c
+		td.Title = c.Title
+		td.Description = c.Description
+	}
+
+	return t.ExecuteTemplate(w, page.viewName(), td)
 }
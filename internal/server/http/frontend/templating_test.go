@@ -2,9 +2,13 @@ package frontend
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/willbicks/epigram/internal/ctxval"
 	"github.com/willbicks/epigram/internal/model"
 	"github.com/willbicks/epigram/internal/service"
 )
@@ -45,28 +49,503 @@ func Test_TemplateEngine_RenderPage(t *testing.T) {
 				},
 			},
 		},
+		QuotesPage{
+			Quotes: []model.Quote{
+				{
+					ID:      "q123",
+					Quotee:  "Test Quotee",
+					Quote:   "Test Quote",
+					Context: "Test Context",
+				},
+			},
+			Modifiable: map[string]bool{
+				"q123": true,
+			},
+		},
+		QuotesPage{
+			Search:      "test",
+			Quotee:      "Test Quotee",
+			SubmitterID: "x123",
+			Tag:         "test tag",
+			Year:        2022,
+			Paged:       true,
+			NextPage:    "/quotes?after=abc",
+			Quotes: []model.Quote{
+				{
+					ID:     "q123",
+					Quotee: "Test Quotee",
+					Quote:  "Test Quote",
+					Tags:   []string{"other tag", "test tag"},
+				},
+			},
+		},
+		QuotesPage{
+			Sort:      "loved",
+			Paged:     true,
+			NextPage:  "/quotes?sort=loved&after=abc",
+			ReturnURL: "/quotes?sort=loved",
+			Quotes: []model.Quote{
+				{
+					ID:     "q123",
+					Quotee: "Test Quotee",
+					Quote:  "Test Quote",
+				},
+			},
+			Reactions: map[string][]service.ReactionSummary{
+				"q123": {
+					{Emoji: "👍", Count: 2, Reacted: true},
+					{Emoji: "😂", Count: 0},
+				},
+			},
+		},
+		QuotePage{
+			Quote: model.Quote{
+				ID:      "q123",
+				Quotee:  "Test Quotee",
+				Quote:   "Test Quote",
+				Context: "Test Context",
+			},
+		},
+		QuotePage{
+			RenderAdmin: true,
+			Modifiable:  true,
+			Quote: model.Quote{
+				ID:          "q123",
+				Quotee:      "Test Quotee",
+				Quote:       "Test Quote",
+				SubmitterID: "x123",
+			},
+			Submitter: model.User{ID: "x123", Name: "Test User"},
+			Reactions: []service.ReactionSummary{{Emoji: "👍", Count: 1, Reacted: true}},
+			Comments: []service.CommentThread{
+				{
+					Comment: model.Comment{ID: "c1", Deleted: true},
+					Replies: []service.CommentThread{
+						{
+							Comment:    model.Comment{ID: "c2", ParentID: "c1", Text: "Test Reply", Edited: time.Now()},
+							AuthorName: "Test User",
+							Modifiable: true,
+						},
+					},
+				},
+				{
+					Comment:    model.Comment{ID: "c3", Text: "Test Comment"},
+					AuthorName: "Test User",
+				},
+			},
+			Error:   errors.New("test error"),
+			Comment: model.Comment{ParentID: "c3", Text: "Test Draft"},
+		},
+		QuoteEditPage{
+			Error: errors.New("test error"),
+			Quote: model.Quote{
+				ID:     "q456",
+				Quotee: "Test Quotee",
+				Lines: []model.QuoteLine{
+					{SpeakerID: "p123", Speaker: "Test Quotee", Text: "Test Line"},
+					{Speaker: "Other Quotee"},
+				},
+			},
+		},
+		QuotesPage{
+			Error: errors.New("test error"),
+			Quote: model.Quote{
+				Lines: []model.QuoteLine{{Speaker: "Test Quotee", Text: "Test Line"}, {Text: "Unattributed Line"}},
+			},
+			Quotes: []model.Quote{
+				{
+					ID:       "q456",
+					QuoteeID: "p123",
+					Quotee:   "Test Quotee",
+					Quote:    "Test Quotee: Test Line\nOther Quotee: Other Line",
+					Lines: []model.QuoteLine{
+						{SpeakerID: "p123", Speaker: "Test Quotee", Text: "Test Line"},
+						{SpeakerID: "p456", Speaker: "Other Quotee", Text: "Other Line"},
+					},
+				},
+			},
+		},
+		QuotePage{
+			Quote: model.Quote{
+				ID:       "q456",
+				QuoteeID: "p123",
+				Quotee:   "Test Quotee",
+				Lines: []model.QuoteLine{
+					{SpeakerID: "p123", Speaker: "Test Quotee", Text: "Test Line"},
+					{SpeakerID: "p456", Speaker: "Other Quotee", Text: "Other Line"},
+				},
+			},
+		},
+		QuoteEditPage{
+			Quote: model.Quote{
+				ID:       "q123",
+				QuoteeID: "p123",
+				Quotee:   "Test Quotee",
+				Quote:    "Test Quote",
+				Context:  "Test Context",
+				Tags:     []string{"test tag"},
+			},
+			Quotees: []string{"Test Quotee", "Test Alias"},
+		},
+		TagsPage{
+			Tags: []service.TagSummary{
+				{Name: "test tag", Quotes: 1},
+				{Name: "other tag", Quotes: 2},
+			},
+		},
+		TagsPage{
+			RenderAdmin: true,
+			Error:       errors.New("test error"),
+		},
+		PeoplePage{
+			People: []service.PersonSummary{
+				{Person: model.Person{ID: "p123", Name: "Test Quotee", Aliases: []string{"Test Alias"}}, Quotes: 1},
+				{Person: model.Person{ID: "p456", Name: "Other Quotee"}},
+			},
+		},
+		PeoplePage{
+			RenderAdmin: true,
+			Error:       errors.New("test error"),
+			People: []service.PersonSummary{
+				{Person: model.Person{ID: "p123", Name: "Test Quotee"}, Quotes: 2},
+			},
+		},
+		PersonPage{
+			Person: model.Person{ID: "p123", Name: "Test Quotee", Aliases: []string{"Test Alias"}},
+			Quotes: []model.Quote{
+				{
+					ID:       "q123",
+					QuoteeID: "p123",
+					Quotee:   "Test Quotee",
+					Quote:    "Test Quote",
+				},
+			},
+			NextPage: "/people/p123?after=abc",
+		},
+		PersonPage{
+			RenderAdmin: true,
+			Paged:       true,
+			Person:      model.Person{ID: "p123", Name: "Test Quotee", UserID: "x123"},
+			User:        model.User{ID: "x123", Name: "Test User"},
+		},
+		PersonEditPage{
+			Error:  errors.New("test error"),
+			Person: model.Person{ID: "p123", Name: "Test Quotee", Aliases: []string{"Test Alias", "TQ"}, UserID: "x123"},
+			Users:  []model.User{{ID: "x123", Name: "Test User"}, {ID: "x456", Name: "Other User"}},
+		},
 		QuizPage{
 			Questions: []service.QuizQuestion{
 				{
 					ID:       1,
 					Question: "Test Question",
-					Answer:   "Answer",
+					Answers:  []string{"Answer"},
 					Length:   6,
 				},
+				{
+					ID:       4,
+					Question: "Varying Question",
+					Answers:  []string{"Answer", "Response"},
+				},
+			},
+		},
+		QuizPage{
+			Error:     errors.New("test error"),
+			LockedOut: true,
+		},
+		QuizPage{
+			LockedOut: true,
+			AccessRequest: model.AccessRequest{
+				Message: "Test Message",
+				Status:  model.AccessRequestPending,
+				Created: time.Now(),
+			},
+		},
+		QuizPage{
+			LockedOut:     true,
+			AccessRequest: model.AccessRequest{Status: model.AccessRequestDenied},
+		},
+		QuizPage{
+			Wait: 90 * time.Second,
+		},
+		PendingPage{},
+		PendingPage{Rejected: true},
+		LoginPage{
+			Providers: []service.OIDC{
+				{Name: "google", DisplayName: "Google"},
+				{Name: "dex"},
+			},
+		},
+		AccountPage{
+			Error: errors.New("test error"),
+			User: model.User{
+				ID:    "x123",
+				Name:  "Test User",
+				Email: "test@example.com",
+			},
+			Membership: model.Membership{
+				QuizPassed: true,
+			},
+			Sessions: []service.SessionSummary{
+				{
+					Handle:  "abc123",
+					Created: time.Now(),
+					Expires: time.Now().Add(time.Hour),
+					IP:      "192.168.0.1",
+				},
+			},
+			CurrentSessionHandle: "abc123",
+			Identities: []model.UserIdentity{
+				{
+					ID:      "accounts.google.com/1234",
+					UserID:  "x123",
+					Domain:  "accounts.google.com",
+					Email:   "test@example.com",
+					Created: time.Now(),
+				},
+			},
+			Providers: []service.OIDC{
+				{Name: "google", DisplayName: "Google"},
+			},
+			Tokens: []model.APIToken{
+				{
+					ID:      "tok1",
+					UserID:  "x123",
+					Name:    "CI",
+					Created: time.Now(),
+				},
+			},
+			NewToken: "ep_secret",
+			ChatLinks: []model.ChatLink{
+				{
+					ID:           "slack:T1:U1",
+					Platform:     "slack",
+					TeamID:       "T1",
+					ChatUserID:   "U1",
+					ChatUserName: "testuser",
+					UserID:       "x123",
+					Created:      time.Now(),
+				},
+			},
+		},
+		ChatLinkPage{
+			Code: "code",
+			ChatUser: service.ChatUser{
+				Platform: "slack",
+				TeamID:   "T1",
+				UserID:   "U1",
+				UserName: "testuser",
+			},
+		},
+		ChatLinkPage{
+			Error: errors.New("test error"),
+		},
+		AdminMainPage{
+			Error: errors.New("test error"),
+			Members: []service.Member{
+				{
+					User: model.User{
+						ID:    "x123",
+						Name:  "Test User",
+						Email: "test@example.com",
+					},
+				},
+				{
+					User: model.User{
+						ID:    "x456",
+						Name:  "Test Banned User",
+						Email: "banned@example.com",
+					},
+					Membership: model.Membership{
+						QuizAttempts: 6,
+						Banned:       true,
+					},
+					LockedOut: true,
+				},
+				{
+					User: model.User{
+						ID:    "x789",
+						Name:  "Test Admin",
+						Email: "admin@example.com",
+					},
+					Membership: model.Membership{
+						QuizPassed: true,
+						Admin:      true,
+					},
+				},
+			},
+		},
+		AdminMainPage{
+			AccessRequests: []service.PendingAccessRequest{
+				{
+					AccessRequest: model.AccessRequest{
+						UserID:  "x456",
+						Message: "Test Message\nSecond Line",
+						Status:  model.AccessRequestPending,
+						Created: time.Now(),
+					},
+					User:       model.User{ID: "x456", Name: "Test Locked Out User", Email: "locked@example.com"},
+					Membership: model.Membership{QuizAttempts: 5},
+				},
 			},
 		},
 		AdminMainPage{
+			InviteURL: "https://epigram.example.com/invite?code=",
+			Invites: []service.InviteSummary{
+				{
+					Invite: model.Invite{
+						ID:      "i123",
+						Code:    "code",
+						MaxUses: 2,
+						Expires: time.Now().Add(time.Hour),
+						Created: time.Now(),
+						Redemptions: []model.InviteRedemption{
+							{InviteID: "i123", UserID: "x123", Redeemed: time.Now()},
+							{InviteID: "i123", UserID: "x456", Redeemed: time.Now()},
+						},
+					},
+					CreatorName: "Test Admin",
+					Redeemers: []service.InviteRedeemer{
+						{InviteRedemption: model.InviteRedemption{UserID: "x123", Redeemed: time.Now()}, Name: "Test User"},
+						{InviteRedemption: model.InviteRedemption{UserID: "x456", Redeemed: time.Now()}},
+					},
+				},
+				{
+					Invite: model.Invite{ID: "i456", Code: "other", Created: time.Now()},
+				},
+			},
+		},
+		AdminMainPage{
+			InstanceAdmin: true,
+			Members: []service.Member{
+				{
+					User: model.User{
+						ID:    "x789",
+						Name:  "Test Instance Admin",
+						Email: "admin@example.com",
+						Admin: true,
+					},
+				},
+			},
 			Users: []model.User{
 				{
-					ID:    "x123",
-					Name:  "Test User",
-					Email: "test@example.com",
+					ID:    "x789",
+					Name:  "Test Instance Admin",
+					Email: "admin@example.com",
+					Admin: true,
+				},
+			},
+		},
+		CommunitiesPage{
+			Communities: []service.CommunityMembership{
+				{
+					Community: model.Community{
+						ID:          model.DefaultCommunityID,
+						Title:       "Test Community",
+						Description: "Test Description",
+					},
+					Membership: model.Membership{
+						QuizPassed: true,
+						Admin:      true,
+					},
+				},
+				{
+					Community: model.Community{
+						ID:    "other",
+						Title: "Other Community",
+					},
+				},
+			},
+		},
+		InvitePage{
+			Code:      "code",
+			Community: model.Community{ID: model.DefaultCommunityID, Title: "Test Community"},
+			Providers: []service.OIDC{
+				{Name: "google", DisplayName: "Google"},
+			},
+		},
+		InvitePage{
+			Code:      "code",
+			Community: model.Community{ID: model.DefaultCommunityID, Title: "Test Community"},
+			SignedIn:  true,
+		},
+		InvitePage{
+			Code:  "expired",
+			Error: errors.New("test error"),
+		},
+		AdminUserPage{
+			Member: service.Member{
+				User:       model.User{ID: "x456", Name: "Test Locked Out User", Email: "locked@example.com"},
+				Membership: model.Membership{QuizAttempts: 2, Joined: time.Now()},
+				LockedOut:  true,
+			},
+			QuizAttempts: []model.QuizAttempt{
+				{
+					ID:               "a2",
+					IP:               "192.168.0.1",
+					Submitted:        time.Now(),
+					QuestionIDs:      []int{1, 7},
+					WrongQuestionIDs: []int{7},
+					Answers:          map[int]string{1: "Answer", 7: "Wrong"},
+				},
+				{
+					ID:               "a1",
+					Submitted:        time.Now().Add(-time.Hour),
+					QuestionIDs:      []int{0, 1},
+					WrongQuestionIDs: []int{0, 1},
+				},
+			},
+			Questions: map[int]string{0: "Test Question", 1: "Other Question"},
+		},
+		AdminUserPage{
+			Member: service.Member{User: model.User{ID: "x123", Name: "Test User"}},
+		},
+		AdminQuizPage{
+			Questions: []model.QuizQuestion{
+				{ID: 0, Question: "Test Question", Answers: []string{"answer"}, Enabled: true},
+				{ID: 2, Question: "Disabled Question", Answers: []string{"one", "two"},
+					Normalize: []string{"punctuation", "diacritics"}},
+			},
+		},
+		AdminQuizPage{
+			Error: errors.New("test error"),
+		},
+		AdminAuditPage{
+			Query: service.AuditLogQuery{
+				ActorID: "x789",
+				Action:  model.AuditBanUser,
+			},
+			Entries: []model.AuditLogEntry{
+				{
+					ID:       "a001",
+					ActorID:  "x789",
+					Action:   model.AuditBanUser,
+					TargetID: "x456",
+					Details:  "Test Banned User",
+					IP:       "192.168.0.1",
+				},
+			},
+			Actions: model.AuditActions,
+			Admins: []model.User{
+				{
+					ID:    "x789",
+					Name:  "Test Admin",
+					Admin: true,
+				},
+			},
+			Users: map[string]model.User{
+				"x789": {
+					ID:    "x789",
+					Name:  "Test Admin",
+					Admin: true,
 				},
 			},
 		},
 	}
 
-	te, err := NewTemplateEngine(RootTD{})
+	// multiple communities are hosted, so that links between them are rendered
+	te, err := NewTemplateEngine(RootTD{
+		Communities: []model.Community{{ID: model.DefaultCommunityID}, {ID: "other"}},
+	})
 	if err != nil {
 		t.Error("NewTemplateEngine() returned error:", err)
 	}
@@ -74,7 +553,7 @@ func Test_TemplateEngine_RenderPage(t *testing.T) {
 	for _, p := range tests {
 		t.Run(p.viewName(), func(t *testing.T) {
 			var buf bytes.Buffer
-			err := te.RenderPage(&buf, p)
+			err := te.RenderPage(context.Background(), &buf, p)
 			if err != nil {
 				t.Errorf("RenderPage() for %s returned error: %s", p.viewName(), err)
 			}
@@ -85,3 +564,59 @@ func Test_TemplateEngine_RenderPage(t *testing.T) {
 		})
 	}
 }
+
+func Test_TemplateEngine_RenderPage_ApprovalGate(t *testing.T) {
+	tests := []Page{
+		AdminMainPage{
+			PendingMembers: []service.Member{
+				{
+					User:             model.User{ID: "x123", Name: "Test Pending User", Email: "pending@example.com"},
+					Membership:       model.Membership{Joined: time.Now()},
+					AwaitingApproval: true,
+				},
+			},
+			Members: []service.Member{
+				{
+					User:             model.User{ID: "x123", Name: "Test Pending User", Email: "pending@example.com"},
+					Membership:       model.Membership{Joined: time.Now()},
+					AwaitingApproval: true,
+				},
+				{
+					User:       model.User{ID: "x456", Name: "Test Rejected User", Email: "rejected@example.com"},
+					Membership: model.Membership{Joined: time.Now(), Rejected: true},
+				},
+			},
+		},
+		AdminMainPage{},
+		AdminUserPage{
+			Member: service.Member{
+				User:       model.User{ID: "x456", Name: "Test Rejected User"},
+				Membership: model.Membership{Joined: time.Now(), Rejected: true},
+			},
+		},
+	}
+
+	te, err := NewTemplateEngine(RootTD{})
+	if err != nil {
+		t.Error("NewTemplateEngine() returned error:", err)
+	}
+
+	// the pending members of communities which require approval are only rendered for them
+	ctx := ctxval.ContextWithCommunity(context.Background(), model.Community{
+		ID:    "approval",
+		Title: "Test Community",
+		Gate:  model.GateApproval,
+	})
+	for _, p := range tests {
+		t.Run(p.viewName(), func(t *testing.T) {
+			var buf bytes.Buffer
+			err := te.RenderPage(ctx, &buf, p)
+			if err != nil {
+				t.Errorf("RenderPage() for %s returned error: %s", p.viewName(), err)
+			}
+			if !strings.Contains(buf.String(), "Test Community") {
+				t.Errorf("RenderPage() for %s does not render the current community", p.viewName())
+			}
+		})
+	}
+}
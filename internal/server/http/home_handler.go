@@ -13,7 +13,7 @@ func (s *QuoteServer) homeHandler(w http.ResponseWriter, r *http.Request) {
 			s.notFoundError(w, r)
 			return
 		}
-		err := s.tmpl.RenderPage(w, frontend.HomePage{})
+		err := s.tmpl.RenderPage(r.Context(), w, frontend.HomePage{})
 		if err != nil {
 			s.serverError(w, r, err)
 			return
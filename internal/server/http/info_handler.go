@@ -10,7 +10,7 @@ import (
 func (s *QuoteServer) privacyHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
-		err := s.tmpl.RenderPage(w, frontend.PrivacyPage{})
+		err := s.tmpl.RenderPage(r.Context(), w, frontend.PrivacyPage{})
 		if err != nil {
 			s.serverError(w, r, err)
 			return
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/logutils"
+	"github.com/willbicks/epigram/internal/server/http/frontend"
+	"github.com/willbicks/epigram/internal/service"
+)
+
+// inviteCookieName is the name of the cookie which stores the code of an invite followed by a user who was not signed
+// in, such that it can be redeemed once they sign in.
+const inviteCookieName = "invite"
+
+// setInviteCookie stores the provided invite code on the client until it signs in. The cookie is only sent to the
+// provider login urls.
+func setInviteCookie(w http.ResponseWriter, r *http.Request, code string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     inviteCookieName,
+		Value:    code,
+		Path:     "/login",
+		MaxAge:   int(time.Hour.Seconds()),
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+	})
+}
+
+// clearInviteCookie instructs the client to discard its invite cookie.
+func clearInviteCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:   inviteCookieName,
+		Path:   "/login",
+		MaxAge: -1,
+	})
+}
+
+// inviteURL returns the URL of the invite page for the invite with the provided code.
+func (s *QuoteServer) inviteURL(code string) string {
+	return s.paths.Invite + "?code=" + url.QueryEscape(code)
+}
+
+// renderInvitePage renders the invite page for the invite with the provided code, including the provided error (if
+// any). Users who are not signed in are offered the chance to sign in, and the code is stored so that the invite is
+// redeemed once they do.
+func (s *QuoteServer) renderInvitePage(w http.ResponseWriter, r *http.Request, code string, pageErr error) {
+	page := frontend.InvitePage{
+		Error:    pageErr,
+		Code:     code,
+		SignedIn: ctxval.UserFromContext(r.Context()).ID != "",
+	}
+
+	inv, err := s.InviteService.FindInvite(r.Context(), code)
+	var serr service.Error
+	if errors.As(err, &serr) {
+		page.Error = err
+	} else if err != nil {
+		s.serverError(w, r, err)
+		return
+	} else if page.Community, err = s.CommunityService.GetCommunity(inv.CommunityID); err != nil {
+		page.Error = service.ErrInvalidInvite
+	} else if !page.SignedIn {
+		page.Providers = s.OIDCServices
+		setInviteCookie(w, r, code)
+	}
+
+	if err := s.tmpl.RenderPage(r.Context(), w, page); err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+}
+
+// inviteHandler renders the invite page for the invite identified by the code URL parameter in response to GET
+// requests, and responds to POST requests by redeeming the invite identified by the code form value for the signed
+// in user, and redirecting to the quotes page of the community they joined.
+func (s *QuoteServer) inviteHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		s.renderInvitePage(w, r, r.URL.Query().Get("code"), nil)
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		code := r.FormValue("code")
+		if ctxval.UserFromContext(r.Context()).ID == "" {
+			http.Redirect(w, r, s.inviteURL(code), http.StatusSeeOther)
+			return
+		}
+
+		inv, err := s.InviteService.RedeemInvite(r.Context(), code)
+		var serr service.Error
+		if errors.As(err, &serr) {
+			s.renderInvitePage(w, r, code, err)
+			return
+		} else if err != nil {
+			s.serverError(w, r, err)
+			return
+		}
+
+		setCommunityCookie(w, r, inv.CommunityID)
+		http.Redirect(w, r, s.paths.Quotes, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// redeemInviteCookie redeems the invite stored in the invite cookie (if any) for the user who just signed in, and
+// returns the path they should be redirected to: the quotes page of the community they joined, or the invite page if
+// it could not be redeemed. If there is no invite cookie, the quotes page is returned.
+func (s *QuoteServer) redeemInviteCookie(w http.ResponseWriter, r *http.Request) string {
+	c, err := r.Cookie(inviteCookieName)
+	if err != nil || c.Value == "" {
+		return s.paths.Quotes
+	}
+	clearInviteCookie(w)
+
+	inv, err := s.InviteService.RedeemInvite(r.Context(), c.Value)
+	if err != nil {
+		s.Logger.WarnContext(r.Context(), "unable to redeem invite after sign in", logutils.Error(err))
+		return s.inviteURL(c.Value)
+	}
+
+	setCommunityCookie(w, r, inv.CommunityID)
+	return s.paths.Quotes
+}
+
+// adminCreateInviteHandler responds to POST requests by creating an invite to the current community, which expires
+// after the duration in the expires form value (or never, if blank), and may be redeemed by the number of users in the
+// uses form value (or any number, if blank), and then redirecting to the admin page. If the form is invalid, the admin
+// page is rendered with the error.
+func (s *QuoteServer) adminCreateInviteHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		var expires time.Duration
+		if v := strings.TrimSpace(r.FormValue("expires")); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				s.renderAdminMainPage(w, r, errors.New("invite expiry must be a duration, such as 24h"))
+				return
+			}
+			expires = d
+		}
+
+		var uses int
+		if v := strings.TrimSpace(r.FormValue("uses")); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				s.renderAdminMainPage(w, r, errors.New("invite use limit must be a number"))
+				return
+			}
+			uses = n
+		}
+
+		_, err := s.InviteService.CreateInvite(r.Context(), expires, uses)
+
+		var serr service.Error
+		if errors.As(err, &serr) && serr.StatusCode == http.StatusBadRequest {
+			s.renderAdminMainPage(w, r, err)
+			return
+		} else if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Admin, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
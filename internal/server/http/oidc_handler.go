@@ -3,19 +3,73 @@ package http
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
 	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/server/http/frontend"
 	"github.com/willbicks/epigram/internal/service"
 )
 
 const sessionCookieName = "sess"
 
+// linkCookieName is the name of the cookie which indicates that an OIDC flow was started by a signed in user to link
+// another identity to their account, rather than to sign in.
+const linkCookieName = "link"
+
+// setSessionCookie issues the provided session to the client as a cookie.
+func setSessionCookie(w http.ResponseWriter, r *http.Request, sess model.UserSession) {
+	http.SetCookie(w, &http.Cookie{
+		Name:   sessionCookieName,
+		Value:  sess.ID,
+		Path:   "/",
+		Secure: r.TLS != nil,
+		//SameSite: http.SameSiteStrictMode, // breaks redirect from after oidc callback?
+		HttpOnly: true,
+		// Session expires on client one hour before server to account for sync differences.
+		Expires: sess.Expires.Add(-time.Hour),
+	})
+}
+
+// loginHandler renders the login page, listing each OIDC provider users may sign in with, in response to GET
+// requests. Users who are already signed in are redirected to the quotes page.
+func (s *QuoteServer) loginHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		if ctxval.UserFromContext(r.Context()).ID != "" {
+			http.Redirect(w, r, s.paths.Quotes, http.StatusSeeOther)
+			return
+		}
+
+		if err := s.tmpl.RenderPage(r.Context(), w, frontend.LoginPage{
+			Providers: s.OIDCServices,
+		}); err != nil {
+			s.serverError(w, r, err)
+			return
+		}
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// clearLinkCookie instructs the client to discard its link cookie, which is set with the default path of the
+// provider login urls.
+func clearLinkCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:   linkCookieName,
+		Path:   "/login",
+		MaxAge: -1,
+	})
+}
+
 // oidcLoginHandler generates state and nonce keys, adds them to the client, and redirects to the
-// oidc provider for authentication
+// oidc provider for authentication. Signed in users may provide the link URL parameter to link
+// the identity they authenticate with to their account.
 func (s *QuoteServer) oidcLoginHandler(oidc service.OIDC) http.Handler {
 
 	// randString is a helper function used by OIDC to generate random strings for state and nonce.
@@ -41,8 +95,10 @@ func (s *QuoteServer) oidcLoginHandler(oidc service.OIDC) http.Handler {
 
 	// http handler func to set cookies and redirect to provider
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// first, check if user is already signed in. If so, redirect to the quotes page.
-		if ctxval.UserFromContext(r.Context()).ID != "" {
+		// first, check if user is already signed in. If so, redirect to the quotes page, unless they are linking
+		// another identity to their account.
+		link := r.URL.Query().Has("link")
+		if ctxval.UserFromContext(r.Context()).ID != "" && !link {
 			http.Redirect(w, r, s.paths.Quotes, http.StatusSeeOther)
 			return
 		}
@@ -59,12 +115,18 @@ func (s *QuoteServer) oidcLoginHandler(oidc service.OIDC) http.Handler {
 		}
 		setCallbackCookie(w, r, "state", state)
 		setCallbackCookie(w, r, "nonce", nonce)
+		if link {
+			setCallbackCookie(w, r, linkCookieName, "1")
+		} else {
+			clearLinkCookie(w)
+		}
 
 		http.Redirect(w, r, oidc.RedirectURL(state, nonce), http.StatusSeeOther)
 	})
 }
 
-// oidcCallbackHandler handles callbacks from the OIDC provider
+// oidcCallbackHandler handles callbacks from the OIDC provider, either signing the user in (and redeeming the invite
+// they followed, if any), or linking the identity to the signed in user's account if the flow was started to do so.
 func (s *QuoteServer) oidcCallbackHandler(oidc service.OIDC) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token, err := oidc.ValidateCallback(*r)
@@ -73,6 +135,24 @@ func (s *QuoteServer) oidcCallbackHandler(oidc service.OIDC) http.Handler {
 			return
 		}
 
+		if c, err := r.Cookie(linkCookieName); err == nil && c.Value != "" && ctxval.UserFromContext(r.Context()).ID != "" {
+			clearLinkCookie(w)
+
+			err := s.UserService.LinkIDToken(r.Context(), token)
+
+			var serr service.Error
+			if errors.As(err, &serr) && serr.StatusCode == http.StatusConflict {
+				s.renderAccountPage(w, r, frontend.AccountPage{Error: err})
+				return
+			} else if err != nil {
+				s.serviceError(w, r, err)
+				return
+			}
+
+			http.Redirect(w, r, s.paths.Account, http.StatusSeeOther)
+			return
+		}
+
 		user, err := s.UserService.GetUserFromIDToken(r.Context(), token)
 		if err != nil {
 			s.serverError(w, r, fmt.Errorf("getting user from OIDC token: %v", err))
@@ -87,16 +167,10 @@ func (s *QuoteServer) oidcCallbackHandler(oidc service.OIDC) http.Handler {
 			return
 		}
 
-		http.SetCookie(w, &http.Cookie{
-			Name:   sessionCookieName,
-			Value:  sess.ID,
-			Path:   "/",
-			Secure: r.TLS != nil,
-			//SameSite: http.SameSiteStrictMode, // breaks redirect from after oidc callback?
-			HttpOnly: true,
-			// Session expires on client one hour before server to account for sync differences.
-			Expires: sess.Expires.Add(-time.Hour),
-		})
-		http.Redirect(w, r, s.paths.Quotes, http.StatusSeeOther)
+		setSessionCookie(w, r, sess)
+
+		// users who followed an invite before signing in redeem it now
+		r = r.WithContext(ctxval.ContextWithUser(r.Context(), user))
+		http.Redirect(w, r, s.redeemInviteCookie(w, r), http.StatusSeeOther)
 	})
 }
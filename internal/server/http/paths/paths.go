@@ -3,22 +3,148 @@ package paths
 // Paths stores url paths to each page to prevent hard coding paths in
 // multiple places.
 type Paths struct {
-	Home    string
-	Quotes  string
-	Quiz    string
+	Home   string
+	Quotes string
+	// Quote is followed by the ID of a quote to view it and its comments.
+	Quote       string
+	QuoteEdit   string
+	QuoteDelete string
+	QuoteReact  string
+	Quiz        string
+	// QuizRequestAccess requests access to a community from its admins after being locked out of its entry quiz.
+	QuizRequestAccess string
+	// Pending informs users that they are awaiting approval from the admins of a community, or were rejected.
+	Pending string
 	Login   string
+	Logout  string
 	Privacy string
 	Admin   string
+
+	CommentCreate string
+	CommentEdit   string
+	CommentDelete string
+
+	People string
+	// Person is followed by the ID of a person to view them and their quotes.
+	Person      string
+	PersonEdit  string
+	PeopleMerge string
+
+	Tags      string
+	TagRename string
+
+	Communities     string
+	CommunitySwitch string
+
+	// Invite is followed by the code of an invite as the code URL parameter to view and redeem it.
+	Invite string
+
+	Account              string
+	AccountRevokeSession string
+	AccountCreateToken   string
+	AccountRevokeToken   string
+	AccountLinkChat      string
+	AccountUnlinkChat    string
+
+	// AdminUser is followed by the ID of a member of the current community to view their details and quiz history.
+	AdminUser           string
+	AdminBanUser        string
+	AdminUnbanUser      string
+	AdminPromoteUser    string
+	AdminDemoteUser     string
+	AdminResetQuiz      string
+	AdminRevokeSessions string
+	AdminMergeUsers     string
+	AdminAudit          string
+	AdminCreateInvite   string
+	AdminRevokeInvite   string
+	AdminApproveAccess  string
+	AdminDenyAccess     string
+	AdminApproveUser    string
+	AdminRejectUser     string
+	AdminResetAccess    string
+	AdminQuiz           string
+	AdminQuizCreate     string
+	AdminQuizEdit       string
+	AdminQuizMove       string
+	AdminQuizEnable     string
+	AdminQuizDisable    string
+
+	// APIQuotes lists and creates quotes, while individual quotes are addressed by their ID following APIQuote.
+	APIQuotes string
+	APIQuote  string
+
+	// ChatCommand is followed by the name of a chat platform to receive its slash commands.
+	ChatCommand string
 }
 
 // Default returns the default paths assignments to be used in the application
 func Default() Paths {
 	return Paths{
-		Home:    "/",
-		Quotes:  "/quotes",
-		Quiz:    "/quiz",
-		Login:   "/login",
-		Privacy: "/privacy",
-		Admin:   "/admin",
+		Home:              "/",
+		Quotes:            "/quotes",
+		Quote:             "/quotes/",
+		QuoteEdit:         "/quotes/edit",
+		QuoteDelete:       "/quotes/delete",
+		QuoteReact:        "/quotes/react",
+		Quiz:              "/quiz",
+		QuizRequestAccess: "/quiz/request-access",
+		Pending:           "/pending",
+		Login:             "/login",
+		Logout:            "/logout",
+		Privacy:           "/privacy",
+		Admin:             "/admin",
+
+		CommentCreate: "/comments/create",
+		CommentEdit:   "/comments/edit",
+		CommentDelete: "/comments/delete",
+
+		People:      "/people",
+		Person:      "/people/",
+		PersonEdit:  "/people/edit",
+		PeopleMerge: "/people/merge",
+
+		Tags:      "/tags",
+		TagRename: "/tags/rename",
+
+		Communities:     "/communities",
+		CommunitySwitch: "/communities/switch",
+
+		Invite: "/invite",
+
+		Account:              "/account",
+		AccountRevokeSession: "/account/sessions/revoke",
+		AccountCreateToken:   "/account/tokens/create",
+		AccountRevokeToken:   "/account/tokens/revoke",
+		AccountLinkChat:      "/account/chat/link",
+		AccountUnlinkChat:    "/account/chat/unlink",
+
+		AdminUser:           "/admin/users/",
+		AdminBanUser:        "/admin/users/ban",
+		AdminUnbanUser:      "/admin/users/unban",
+		AdminPromoteUser:    "/admin/users/promote",
+		AdminDemoteUser:     "/admin/users/demote",
+		AdminResetQuiz:      "/admin/users/reset-quiz",
+		AdminRevokeSessions: "/admin/users/revoke-sessions",
+		AdminMergeUsers:     "/admin/users/merge",
+		AdminAudit:          "/admin/audit",
+		AdminCreateInvite:   "/admin/invites/create",
+		AdminRevokeInvite:   "/admin/invites/revoke",
+		AdminApproveAccess:  "/admin/access/approve",
+		AdminDenyAccess:     "/admin/access/deny",
+		AdminApproveUser:    "/admin/users/approve",
+		AdminRejectUser:     "/admin/users/reject",
+		AdminResetAccess:    "/admin/access/reset",
+		AdminQuiz:           "/admin/quiz",
+		AdminQuizCreate:     "/admin/quiz/create",
+		AdminQuizEdit:       "/admin/quiz/edit",
+		AdminQuizMove:       "/admin/quiz/move",
+		AdminQuizEnable:     "/admin/quiz/enable",
+		AdminQuizDisable:    "/admin/quiz/disable",
+
+		APIQuotes: "/api/v1/quotes",
+		APIQuote:  "/api/v1/quotes/",
+
+		ChatCommand: "/chat/command/",
 	}
 }
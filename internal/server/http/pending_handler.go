@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/server/http/frontend"
+)
+
+// pendingHandler renders the pending page in response to GET requests from users awaiting approval from the admins
+// of the current community, or who were rejected by them. Users who have access are redirected to the quotes page,
+// and those who must first pass the entry quiz to the quiz page.
+func (s *QuoteServer) pendingHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		u := ctxval.UserFromContext(r.Context())
+		m := ctxval.MembershipFromContext(r.Context())
+		gate := ctxval.CommunityFromContext(r.Context()).Gate
+		switch {
+		case u.Admin || m.Admin || m.PassedGate(gate):
+			http.Redirect(w, r, s.paths.Quotes, http.StatusSeeOther)
+			return
+		case gate.RequiresQuiz() && !m.QuizPassed:
+			http.Redirect(w, r, s.paths.Quiz, http.StatusSeeOther)
+			return
+		}
+
+		err := s.tmpl.RenderPage(r.Context(), w, frontend.PendingPage{Rejected: m.Rejected})
+		if err != nil {
+			s.serverError(w, r, err)
+			return
+		}
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
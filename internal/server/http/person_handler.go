@@ -0,0 +1,247 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/server/http/frontend"
+	"github.com/willbicks/epigram/internal/service"
+)
+
+// quoteeNames returns the names and aliases of every person, to be suggested when submitting or editing a quote.
+func (s *QuoteServer) quoteeNames(ctx context.Context) ([]string, error) {
+	people, err := s.PersonService.GetPeople(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, p := range people {
+		names = append(names, p.Names()...)
+	}
+	return names, nil
+}
+
+// renderPeoplePage renders the people page, presenting the provided error if not nil.
+func (s *QuoteServer) renderPeoplePage(w http.ResponseWriter, r *http.Request, pageErr error) {
+	people, err := s.PersonService.GetPeople(r.Context())
+	if err != nil {
+		s.serviceError(w, r, err)
+		return
+	}
+
+	err = s.tmpl.RenderPage(r.Context(), w, frontend.PeoplePage{
+		RenderAdmin: isAdmin(r.Context()),
+		Error:       pageErr,
+		People:      people,
+	})
+	if err != nil {
+		s.serverError(w, r, err)
+	}
+}
+
+// peopleHandler renders the people page in response to GET requests.
+func (s *QuoteServer) peopleHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		s.renderPeoplePage(w, r, nil)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// getPersonPage builds a PersonPage presenting the person with the specified ID, and one page of the quotes
+// attributed to them, beginning after the cursor in the after URL parameter.
+func (s *QuoteServer) getPersonPage(ctx context.Context, id string, params url.Values) (frontend.PersonPage, error) {
+	p, err := s.PersonService.GetPerson(ctx, id)
+	if err != nil {
+		return frontend.PersonPage{}, err
+	}
+
+	query := service.QuoteQuery{
+		Limit:    quotesPageSize,
+		QuoteeID: p.ID,
+	}
+	if after := params.Get("after"); after != "" {
+		c, err := service.ParseQuoteCursor(after)
+		if err != nil {
+			return frontend.PersonPage{}, err
+		}
+		query.After = &c
+	}
+
+	quotes, next, err := s.QuoteService.QueryQuotes(ctx, query)
+	if err != nil {
+		return frontend.PersonPage{}, err
+	}
+
+	ids := make([]string, len(quotes))
+	for i, q := range quotes {
+		ids[i] = q.ID
+	}
+	reactions, err := s.ReactionService.GetReactions(ctx, ids)
+	if err != nil {
+		return frontend.PersonPage{}, err
+	}
+
+	page := frontend.PersonPage{
+		Person:     p,
+		Quotes:     quotes,
+		Paged:      query.After != nil,
+		Modifiable: make(map[string]bool),
+		Reactions:  reactions,
+		ReturnURL:  s.paths.Person + p.ID,
+	}
+
+	if query.After != nil {
+		page.ReturnURL += "?" + url.Values{"after": {params.Get("after")}}.Encode()
+	}
+
+	if next != nil {
+		page.NextPage = s.paths.Person + p.ID + "?" + url.Values{"after": {next.String()}}.Encode()
+	}
+
+	for _, q := range quotes {
+		if s.QuoteService.CanModifyQuote(ctx, q) {
+			page.Modifiable[q.ID] = true
+		}
+	}
+
+	if isAdmin(ctx) {
+		page.RenderAdmin = true
+
+		users, err := s.UserService.GetAllUsers(ctx)
+		if err != nil {
+			return frontend.PersonPage{}, err
+		}
+
+		page.Users = make(map[string]model.User)
+		for _, u := range users {
+			page.Users[u.ID] = u
+		}
+		page.User = page.Users[p.UserID]
+	}
+
+	return page, nil
+}
+
+// personHandler handles GET requests to view the person whose ID follows the person path, and their quotes.
+func (s *QuoteServer) personHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, s.paths.Person)
+	if id == "" || strings.Contains(id, "/") {
+		s.notFoundError(w, r)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		page, err := s.getPersonPage(r.Context(), id, r.URL.Query())
+		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		if err := s.tmpl.RenderPage(r.Context(), w, page); err != nil {
+			s.serverError(w, r, err)
+		}
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// renderPersonEditPage renders the edit form for the provided person, presenting the provided error if not nil.
+func (s *QuoteServer) renderPersonEditPage(w http.ResponseWriter, r *http.Request, p model.Person, pageErr error) {
+	users, err := s.UserService.GetAllUsers(r.Context())
+	if err != nil {
+		s.serviceError(w, r, err)
+		return
+	}
+
+	err = s.tmpl.RenderPage(r.Context(), w, frontend.PersonEditPage{
+		Error:  pageErr,
+		Person: p,
+		Users:  users,
+	})
+	if err != nil {
+		s.serverError(w, r, err)
+	}
+}
+
+// personEditHandler handles requests from admins to edit a person, either GET requests to render the edit form for
+// the person specified by the id query parameter, or POST requests to submit the changes. Aliases are submitted as
+// a single comma separated form value.
+func (s *QuoteServer) personEditHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		p, err := s.PersonService.GetPerson(r.Context(), r.URL.Query().Get("id"))
+		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		s.renderPersonEditPage(w, r, p, nil)
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+		p := model.Person{
+			ID:      r.FormValue("id"),
+			Name:    r.FormValue("name"),
+			Aliases: strings.Split(r.FormValue("aliases"), ","),
+			UserID:  r.FormValue("user"),
+		}
+
+		editErr := s.PersonService.UpdatePerson(r.Context(), p)
+
+		var serr service.Error
+		if errors.As(editErr, &serr) && serr.StatusCode == http.StatusBadRequest {
+			// validation issues are presented alongside the submitted form
+			s.renderPersonEditPage(w, r, p, editErr)
+			return
+		} else if editErr != nil {
+			s.serviceError(w, r, editErr)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Person+p.ID, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// peopleMergeHandler handles POST requests from admins to merge the person specified by the from form value into
+// the person specified by the into form value.
+func (s *QuoteServer) peopleMergeHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		into := r.FormValue("into")
+		err := s.PersonService.MergePeople(r.Context(), r.FormValue("from"), into)
+
+		var serr service.Error
+		if errors.As(err, &serr) && serr.StatusCode == http.StatusBadRequest {
+			s.renderPeoplePage(w, r, err)
+			return
+		} else if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Person+into, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
@@ -7,21 +7,59 @@ import (
 
 	"github.com/willbicks/epigram/internal/ctxval"
 	"github.com/willbicks/epigram/internal/server/http/frontend"
+	"github.com/willbicks/epigram/internal/service"
 )
 
-// quizHandler handles requests to the quizPage, either GET requests to render the page,
+// renderQuizPage renders the questions of the user's current attempt at the provided quiz, starting a new attempt if
+// they have not got one, along with the provided error (if any). If the user may not currently attempt the quiz, the
+// page instead explains why, and allows locked out users to request access.
+func (s *QuoteServer) renderQuizPage(w http.ResponseWriter, r *http.Request, quiz service.EntryQuiz, pageErr error) {
+	status, err := s.UserService.GetQuizStatus(r.Context())
+	if err != nil {
+		s.serviceError(w, r, err)
+		return
+	}
+
+	page := frontend.QuizPage{
+		Error:     pageErr,
+		LockedOut: status.LockedOut,
+		Wait:      status.Wait(),
+	}
+	switch {
+	case page.LockedOut:
+		page.AccessRequest, err = s.AccessRequestService.GetAccessRequest(r.Context())
+		var serr service.Error
+		if errors.As(err, &serr) && serr.StatusCode == http.StatusNotFound {
+			err = nil
+		}
+	case page.Wait == 0:
+		page.Questions, err = quiz.Start(r.Context())
+		page.NumQuestions = len(page.Questions)
+	}
+	if err != nil {
+		s.serviceError(w, r, err)
+		return
+	}
+
+	err = s.tmpl.RenderPage(r.Context(), w, page)
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+}
+
+// quizHandler handles requests to the quizPage of the current community, either GET requests to render the page,
 // or POST requests to submit attempts.
 func (s *QuoteServer) quizHandler(w http.ResponseWriter, r *http.Request) {
+	if !ctxval.CommunityFromContext(r.Context()).Gate.RequiresQuiz() {
+		http.Redirect(w, r, s.paths.Quotes, http.StatusSeeOther)
+		return
+	}
+	quiz := s.CommunityService.Quiz(r.Context())
+
 	switch r.Method {
 	case "GET":
-		err := s.tmpl.RenderPage(w, frontend.QuizPage{
-			NumQuestions: len(s.QuizService.Questions),
-			Questions:    s.QuizService.Questions,
-		})
-		if err != nil {
-			s.serverError(w, r, err)
-			return
-		}
+		s.renderQuizPage(w, r, quiz, nil)
 	case "POST":
 		if err := r.ParseForm(); err != nil {
 			s.clientError(w, r, err, http.StatusBadRequest)
@@ -38,32 +76,59 @@ func (s *QuoteServer) quizHandler(w http.ResponseWriter, r *http.Request) {
 			answers[id] = value[0]
 		}
 
-		u := ctxval.UserFromContext(r.Context())
-		passed, err := s.QuizService.VerifyAnswers(r.Context(), answers)
-		if err != nil {
+		result, err := quiz.VerifyAnswers(r.Context(), answers)
+		var serr service.Error
+		if errors.As(err, &serr) {
+			s.renderQuizPage(w, r, quiz, err)
+			return
+		} else if err != nil {
 			s.serverError(w, r, err)
+			return
 		}
 
-		failReason, err := s.UserService.RecordQuizAttempt(r.Context(), &u, passed)
-		if err != nil {
-			s.serverError(w, r, err)
+		m, failReason, err := s.UserService.RecordQuizAttempt(r.Context(), result)
+		if errors.As(err, &serr) {
+			s.renderQuizPage(w, r, quiz, err)
+			return
+		} else if err != nil {
+			s.serviceError(w, r, err)
+			return
 		}
 
-		if u.QuizPassed {
+		if m.QuizPassed {
 			http.Redirect(w, r, s.paths.Quotes, http.StatusSeeOther)
 			return
 		}
 
-		err = s.tmpl.RenderPage(w, frontend.QuizPage{
-			NumQuestions: len(s.QuizService.Questions),
-			Questions:    s.QuizService.Questions,
-			Error:        errors.New(failReason),
-		})
-		if err != nil {
+		s.renderQuizPage(w, r, quiz, errors.New(failReason))
+
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// quizRequestAccessHandler handles POST requests from users locked out of the entry quiz of the current community to
+// request access from its admins.
+func (s *QuoteServer) quizRequestAccessHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		_, err := s.AccessRequestService.RequestAccess(r.Context(), r.PostForm.Get("message"))
+		var serr service.Error
+		if errors.As(err, &serr) {
+			s.renderQuizPage(w, r, s.CommunityService.Quiz(r.Context()), err)
+			return
+		} else if err != nil {
 			s.serverError(w, r, err)
 			return
 		}
 
+		http.Redirect(w, r, s.paths.Quiz, http.StatusSeeOther)
 	default:
 		s.methodNotAllowedError(w, r)
 		return
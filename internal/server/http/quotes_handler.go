@@ -2,24 +2,158 @@ package http
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/willbicks/epigram/internal/ctxval"
 	"github.com/willbicks/epigram/internal/model"
 	"github.com/willbicks/epigram/internal/server/http/frontend"
+	"github.com/willbicks/epigram/internal/service"
 )
 
-func (s *QuoteServer) getQuotesPage(ctx context.Context) (frontend.QuotesPage, error) {
-	quotes, err := s.QuoteService.GetAllQuotes(ctx)
+const (
+	// quotesPageSize is the maximum number of quotes rendered on each page of the quotes page.
+	quotesPageSize = 60
+
+	// quotesSortLoved is the value of the sort parameter which orders quotes by their reactions.
+	quotesSortLoved = "loved"
+)
+
+// quoteQueryFromParams builds a QuoteQuery returning up to limit quotes, filtered according to the provided URL
+// parameters:
+//   - q: a search query
+//   - quotee: the name of the person who said the quote
+//   - person: the ID of the person who said the quote
+//   - submitter: the ID of the user who submitted the quote
+//   - tag: a tag on the quote
+//   - year: the year in which the quote was submitted
+//   - after: a cursor returned by a previous page, after which quotes should be listed
+//
+// The parsed year is also returned, or zero if no year was specified.
+func quoteQueryFromParams(params url.Values, limit int) (query service.QuoteQuery, year int, err error) {
+	query = service.QuoteQuery{
+		Limit:       limit,
+		Search:      strings.TrimSpace(params.Get("q")),
+		Quotee:      strings.TrimSpace(params.Get("quotee")),
+		QuoteeID:    params.Get("person"),
+		SubmitterID: params.Get("submitter"),
+		Tag:         strings.TrimSpace(params.Get("tag")),
+	}
+
+	year, _ = strconv.Atoi(params.Get("year"))
+	if year > 0 {
+		query.CreatedFrom = time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+		query.CreatedBefore = query.CreatedFrom.AddDate(1, 0, 0)
+	}
+
+	if after := params.Get("after"); after != "" {
+		c, err := service.ParseQuoteCursor(after)
+		if err != nil {
+			return service.QuoteQuery{}, 0, err
+		}
+		query.After = &c
+	}
+
+	return query, year, nil
+}
+
+// quoteFromForm returns the Quote submitted using the quote form. The lines of a conversation are submitted as
+// repeated speaker and line values, in order, while tags are submitted as a single comma separated value.
+func quoteFromForm(form url.Values) model.Quote {
+	q := model.Quote{
+		Quotee:  form.Get("quotee"),
+		Quote:   form.Get("quote"),
+		Context: form.Get("context"),
+	}
+
+	if tags := strings.TrimSpace(form.Get("tags")); tags != "" {
+		q.Tags = strings.Split(tags, ",")
+	}
+
+	speakers, lines := form["speaker"], form["line"]
+	for i := 0; i < len(speakers) && i < len(lines); i++ {
+		q.Lines = append(q.Lines, model.QuoteLine{
+			Speaker: speakers[i],
+			Text:    lines[i],
+		})
+	}
+
+	return q
+}
+
+// getQuotesPage builds a QuotesPage listing one page of quotes, filtered according to the provided URL parameters
+// (see quoteQueryFromParams), and ordered by the sort parameter: either newest first, or "loved" for those with the
+// most reactions first.
+func (s *QuoteServer) getQuotesPage(ctx context.Context, params url.Values) (frontend.QuotesPage, error) {
+	query, year, err := quoteQueryFromParams(params, quotesPageSize)
+	if err != nil {
+		return frontend.QuotesPage{}, err
+	}
+
+	var sortBy string
+	var quotes []model.Quote
+	var next *service.QuoteCursor
+	if params.Get("sort") == quotesSortLoved {
+		sortBy = quotesSortLoved
+		quotes, next, err = s.QuoteService.QueryLovedQuotes(ctx, query)
+	} else {
+		quotes, next, err = s.QuoteService.QueryQuotes(ctx, query)
+	}
+	if err != nil {
+		return frontend.QuotesPage{}, err
+	}
+
+	ids := make([]string, len(quotes))
+	for i, q := range quotes {
+		ids[i] = q.ID
+	}
+	reactions, err := s.ReactionService.GetReactions(ctx, ids)
 	if err != nil {
 		return frontend.QuotesPage{}, err
 	}
 
 	page := frontend.QuotesPage{
-		Quotes: quotes,
+		Search:      query.Search,
+		Quotee:      query.Quotee,
+		SubmitterID: query.SubmitterID,
+		Tag:         query.Tag,
+		Year:        year,
+		Sort:        sortBy,
+		Paged:       query.After != nil,
+		Quotes:      quotes,
+		Modifiable:  make(map[string]bool),
+		Reactions:   reactions,
+		ReturnURL:   s.paths.Quotes,
+	}
+
+	if len(params) > 0 {
+		page.ReturnURL += "?" + params.Encode()
+	}
+
+	if next != nil {
+		nextParams := url.Values{}
+		for k, v := range params {
+			nextParams[k] = v
+		}
+		nextParams.Set("after", next.String())
+		page.NextPage = s.paths.Quotes + "?" + nextParams.Encode()
+	}
+
+	for _, q := range quotes {
+		if s.QuoteService.CanModifyQuote(ctx, q) {
+			page.Modifiable[q.ID] = true
+		}
+	}
+
+	page.Quotees, err = s.quoteeNames(ctx)
+	if err != nil {
+		return frontend.QuotesPage{}, err
 	}
 
-	if ctxval.UserFromContext(ctx).Admin {
+	if isAdmin(ctx) {
 		page.RenderAdmin = true
 
 		users, err := s.UserService.GetAllUsers(ctx)
@@ -41,13 +175,13 @@ func (s *QuoteServer) getQuotesPage(ctx context.Context) (frontend.QuotesPage, e
 func (s *QuoteServer) quotesHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
-		page, err := s.getQuotesPage(r.Context())
+		page, err := s.getQuotesPage(r.Context(), r.URL.Query())
 		if err != nil {
-			s.serverError(w, r, err)
+			s.serviceError(w, r, err)
 			return
 		}
 
-		err = s.tmpl.RenderPage(w, page)
+		err = s.tmpl.RenderPage(r.Context(), w, page)
 		if err != nil {
 			s.serverError(w, r, err)
 		}
@@ -56,16 +190,12 @@ func (s *QuoteServer) quotesHandler(w http.ResponseWriter, r *http.Request) {
 			s.serverError(w, r, err)
 			return
 		}
-		q := model.Quote{
-			Quotee:  r.FormValue("quotee"),
-			Quote:   r.FormValue("quote"),
-			Context: r.FormValue("context"),
-		}
+		q := quoteFromForm(r.Form)
 
 		createErr := s.QuoteService.CreateQuote(r.Context(), &q)
 
 		if createErr != nil {
-			page, err := s.getQuotesPage(r.Context())
+			page, err := s.getQuotesPage(r.Context(), url.Values{})
 			if err != nil {
 				s.serverError(w, r, err)
 				return
@@ -73,7 +203,7 @@ func (s *QuoteServer) quotesHandler(w http.ResponseWriter, r *http.Request) {
 			page.Quote = q
 			page.Error = createErr
 
-			err = s.tmpl.RenderPage(w, page)
+			err = s.tmpl.RenderPage(r.Context(), w, page)
 			if err != nil {
 				s.serverError(w, r, err)
 				return
@@ -86,3 +216,178 @@ func (s *QuoteServer) quotesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// getQuotePage builds a QuotePage presenting the quote with the specified ID, and the comments on it.
+func (s *QuoteServer) getQuotePage(ctx context.Context, id string) (frontend.QuotePage, error) {
+	q, err := s.QuoteService.GetQuote(ctx, id)
+	if err != nil {
+		return frontend.QuotePage{}, err
+	}
+
+	reactions, err := s.ReactionService.GetReactions(ctx, []string{q.ID})
+	if err != nil {
+		return frontend.QuotePage{}, err
+	}
+
+	comments, err := s.CommentService.GetComments(ctx, q.ID)
+	if err != nil {
+		return frontend.QuotePage{}, err
+	}
+
+	page := frontend.QuotePage{
+		Quote:      q,
+		Modifiable: s.QuoteService.CanModifyQuote(ctx, q),
+		Reactions:  reactions[q.ID],
+		Comments:   comments,
+	}
+
+	if isAdmin(ctx) {
+		page.RenderAdmin = true
+
+		page.Submitter, err = s.UserService.FindUserByID(ctx, q.SubmitterID)
+		if err != nil {
+			// the quote is still presented if its submitter cannot be found
+			page.Submitter = model.User{ID: q.SubmitterID}
+		}
+	}
+
+	return page, nil
+}
+
+// quoteHandler handles GET requests to view the quote whose ID follows the quote path, and the comments on it.
+func (s *QuoteServer) quoteHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, s.paths.Quote)
+	if id == "" || strings.Contains(id, "/") {
+		s.notFoundError(w, r)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		page, err := s.getQuotePage(r.Context(), id)
+		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		if err := s.tmpl.RenderPage(r.Context(), w, page); err != nil {
+			s.serverError(w, r, err)
+		}
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// quoteEditHandler handles requests to edit an existing quote, either GET requests to render the edit form for the
+// quote specified by the id query parameter, or POST requests to submit the changes.
+func (s *QuoteServer) quoteEditHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		q, err := s.QuoteService.GetQuoteForEdit(r.Context(), r.URL.Query().Get("id"))
+		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		quotees, err := s.quoteeNames(r.Context())
+		if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		err = s.tmpl.RenderPage(r.Context(), w, frontend.QuoteEditPage{
+			Quote:   q,
+			Quotees: quotees,
+		})
+		if err != nil {
+			s.serverError(w, r, err)
+		}
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+		q := quoteFromForm(r.Form)
+		q.ID = r.FormValue("id")
+
+		editErr := s.QuoteService.EditQuote(r.Context(), &q)
+
+		var serr service.Error
+		if errors.As(editErr, &serr) && serr.StatusCode == http.StatusBadRequest {
+			// validation issues are presented alongside the submitted form
+			quotees, err := s.quoteeNames(r.Context())
+			if err != nil {
+				s.serviceError(w, r, err)
+				return
+			}
+
+			err = s.tmpl.RenderPage(r.Context(), w, frontend.QuoteEditPage{
+				Quote:   q,
+				Error:   editErr,
+				Quotees: quotees,
+			})
+			if err != nil {
+				s.serverError(w, r, err)
+			}
+			return
+		} else if editErr != nil {
+			s.serviceError(w, r, editErr)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Quotes, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// quoteDeleteHandler handles POST requests to delete the quote specified by the id form value.
+func (s *QuoteServer) quoteDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		if err := s.QuoteService.DeleteQuote(r.Context(), r.FormValue("id")); err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Quotes, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// quoteReactHandler handles POST requests to toggle the current user's reaction with the emoji form value to the
+// quote specified by the id form value, and then returns them to the quotes or person page specified by the return
+// form value.
+func (s *QuoteServer) quoteReactHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		if _, err := s.ReactionService.ToggleReaction(r.Context(), r.FormValue("id"), r.FormValue("emoji")); err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		// only return to the quotes or person pages, to avoid redirecting users to other sites
+		ret := r.FormValue("return")
+		if !strings.HasPrefix(ret, s.paths.Quotes) && !strings.HasPrefix(ret, s.paths.Person) {
+			ret = s.paths.Quotes
+		}
+		http.Redirect(w, r, ret, http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
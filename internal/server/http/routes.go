@@ -1,8 +1,11 @@
 package http
 
 import (
+	"context"
 	"io/fs"
 	"net/http"
+
+	"github.com/willbicks/epigram/internal/service"
 )
 
 // routes initializes the mux in the server struct with all application routes
@@ -10,16 +13,103 @@ func (s *QuoteServer) routes(pubFS fs.FS) {
 	s.mux.Handle("/favicon.ico", http.FileServer(http.FS(pubFS)))
 
 	s.mux.Handle(s.paths.Home, http.HandlerFunc(s.homeHandler))
-	s.mux.Handle(s.paths.Quotes, s.requireQuizPassed(http.HandlerFunc(s.quotesHandler)))
+	s.mux.Handle(s.paths.Quotes, s.requireAccess(http.HandlerFunc(s.quotesHandler)))
+	s.mux.Handle(s.paths.QuoteEdit, s.requireAccess(http.HandlerFunc(s.quoteEditHandler)))
+	s.mux.Handle(s.paths.QuoteDelete, s.requireAccess(http.HandlerFunc(s.quoteDeleteHandler)))
+	s.mux.Handle(s.paths.QuoteReact, s.requireAccess(http.HandlerFunc(s.quoteReactHandler)))
+	s.mux.Handle(s.paths.Quote, s.requireAccess(http.HandlerFunc(s.quoteHandler)))
+	s.mux.Handle(s.paths.CommentCreate, s.requireAccess(http.HandlerFunc(s.commentCreateHandler)))
+	s.mux.Handle(s.paths.CommentEdit, s.requireAccess(http.HandlerFunc(s.commentEditHandler)))
+	s.mux.Handle(s.paths.CommentDelete, s.requireAccess(http.HandlerFunc(s.commentDeleteHandler)))
+	s.mux.Handle(s.paths.People, s.requireAccess(http.HandlerFunc(s.peopleHandler)))
+	s.mux.Handle(s.paths.Person, s.requireAccess(http.HandlerFunc(s.personHandler)))
+	s.mux.Handle(s.paths.PersonEdit, s.requireLoggedIn(s.requireAdmin(http.HandlerFunc(s.personEditHandler))))
+	s.mux.Handle(s.paths.PeopleMerge, s.requireLoggedIn(s.requireAdmin(http.HandlerFunc(s.peopleMergeHandler))))
+	s.mux.Handle(s.paths.Tags, s.requireAccess(http.HandlerFunc(s.tagsHandler)))
+	s.mux.Handle(s.paths.TagRename, s.requireLoggedIn(s.requireAdmin(http.HandlerFunc(s.tagRenameHandler))))
+	s.mux.Handle(s.paths.Communities, s.requireLoggedIn(http.HandlerFunc(s.communitiesHandler)))
+	s.mux.Handle(s.paths.CommunitySwitch, s.requireLoggedIn(http.HandlerFunc(s.communitySwitchHandler)))
+	s.mux.Handle(s.paths.Invite, http.HandlerFunc(s.inviteHandler))
 	s.mux.Handle(s.paths.Quiz, s.requireLoggedIn(http.HandlerFunc(s.quizHandler)))
+	s.mux.Handle(s.paths.Pending, s.requireLoggedIn(s.joinCommunity(http.HandlerFunc(s.pendingHandler))))
+	s.mux.Handle(s.paths.QuizRequestAccess, s.requireLoggedIn(http.HandlerFunc(s.quizRequestAccessHandler)))
+	s.mux.Handle(s.paths.Account, s.requireLoggedIn(http.HandlerFunc(s.accountHandler)))
+	s.mux.Handle(s.paths.AccountRevokeSession, s.requireLoggedIn(http.HandlerFunc(s.accountRevokeSessionHandler)))
+	s.mux.Handle(s.paths.AccountCreateToken, s.requireAccess(http.HandlerFunc(s.accountCreateTokenHandler)))
+	s.mux.Handle(s.paths.AccountRevokeToken, s.requireLoggedIn(http.HandlerFunc(s.accountRevokeTokenHandler)))
+	s.mux.Handle(s.paths.AccountLinkChat, s.requireAccess(http.HandlerFunc(s.accountLinkChatHandler)))
+	s.mux.Handle(s.paths.AccountUnlinkChat, s.requireLoggedIn(http.HandlerFunc(s.accountUnlinkChatHandler)))
+
+	s.mux.Handle(s.paths.APIQuotes, s.requireAPIToken(http.HandlerFunc(s.apiQuotesHandler)))
+	s.mux.Handle(s.paths.APIQuote, s.requireAPIToken(http.HandlerFunc(s.apiQuoteHandler)))
+	for _, c := range s.Config.ChatCommands {
+		s.mux.Handle(s.paths.ChatCommand+c.Platform, s.chatCommandHandler(c))
+	}
 
 	s.mux.Handle(s.paths.Admin, s.requireLoggedIn(s.requireAdmin(http.HandlerFunc(s.adminMainHandler))))
+	s.mux.Handle(s.paths.AdminUser, s.requireLoggedIn(s.requireAdmin(http.HandlerFunc(s.adminUserHandler))))
+	s.mux.Handle(s.paths.AdminBanUser, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		func(ctx context.Context, id string) error { return s.UserService.SetUserBanned(ctx, id, true) }))))
+	s.mux.Handle(s.paths.AdminUnbanUser, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		func(ctx context.Context, id string) error { return s.UserService.SetUserBanned(ctx, id, false) }))))
+	s.mux.Handle(s.paths.AdminPromoteUser, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		func(ctx context.Context, id string) error { return s.UserService.SetUserAdmin(ctx, id, true) }))))
+	s.mux.Handle(s.paths.AdminDemoteUser, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		func(ctx context.Context, id string) error { return s.UserService.SetUserAdmin(ctx, id, false) }))))
+	s.mux.Handle(s.paths.AdminApproveUser, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		func(ctx context.Context, id string) error { return s.UserService.SetUserApproved(ctx, id, true) }))))
+	s.mux.Handle(s.paths.AdminRejectUser, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		func(ctx context.Context, id string) error { return s.UserService.SetUserApproved(ctx, id, false) }))))
+	s.mux.Handle(s.paths.AdminResetQuiz, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		s.UserService.ResetQuizAttempts))))
+	s.mux.Handle(s.paths.AdminRevokeSessions, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		s.UserService.RevokeAllUserSessions))))
+	s.mux.Handle(s.paths.AdminMergeUsers, s.requireLoggedIn(s.requireAdmin(http.HandlerFunc(s.adminMergeUsersHandler))))
+	s.mux.Handle(s.paths.AdminAudit, s.requireLoggedIn(s.requireAdmin(http.HandlerFunc(s.adminAuditHandler))))
+	s.mux.Handle(s.paths.AdminCreateInvite, s.requireLoggedIn(s.requireAdmin(http.HandlerFunc(s.adminCreateInviteHandler))))
+	s.mux.Handle(s.paths.AdminRevokeInvite, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		s.InviteService.RevokeInvite))))
+	s.mux.Handle(s.paths.AdminApproveAccess, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		s.AccessRequestService.ApproveAccessRequest))))
+	s.mux.Handle(s.paths.AdminDenyAccess, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		s.AccessRequestService.DenyAccessRequest))))
+	s.mux.Handle(s.paths.AdminResetAccess, s.requireLoggedIn(s.requireAdmin(s.adminUserActionHandler(
+		s.AccessRequestService.ResetAccessRequest))))
+	s.mux.Handle(s.paths.AdminQuiz, s.requireLoggedIn(s.requireAdmin(http.HandlerFunc(s.adminQuizHandler))))
+	s.mux.Handle(s.paths.AdminQuizCreate, s.requireLoggedIn(s.requireAdmin(s.adminQuizActionHandler(
+		func(ctx context.Context, quiz service.EntryQuiz, r *http.Request) error {
+			_, err := quiz.CreateQuestion(ctx, quizQuestionFromForm(r))
+			return err
+		}))))
+	s.mux.Handle(s.paths.AdminQuizEdit, s.requireLoggedIn(s.requireAdmin(s.adminQuizActionHandler(
+		func(ctx context.Context, quiz service.EntryQuiz, r *http.Request) error {
+			return quiz.UpdateQuestion(ctx, quizQuestionFromForm(r))
+		}))))
+	s.mux.Handle(s.paths.AdminQuizMove, s.requireLoggedIn(s.requireAdmin(s.adminQuizActionHandler(
+		func(ctx context.Context, quiz service.EntryQuiz, r *http.Request) error {
+			return quiz.MoveQuestion(ctx, quizQuestionID(r), r.FormValue("direction") == "up")
+		}))))
+	s.mux.Handle(s.paths.AdminQuizEnable, s.requireLoggedIn(s.requireAdmin(s.adminQuizActionHandler(
+		func(ctx context.Context, quiz service.EntryQuiz, r *http.Request) error {
+			return quiz.SetQuestionEnabled(ctx, quizQuestionID(r), true)
+		}))))
+	s.mux.Handle(s.paths.AdminQuizDisable, s.requireLoggedIn(s.requireAdmin(s.adminQuizActionHandler(
+		func(ctx context.Context, quiz service.EntryQuiz, r *http.Request) error {
+			return quiz.SetQuestionEnabled(ctx, quizQuestionID(r), false)
+		}))))
 
-	// TODO: factor out into registerOIDCService(service.OIDC) method to prepare
-	// for multiple OIDC providers
-	s.mux.Handle(s.paths.Login, s.oidcLoginHandler(s.OIDCService))
-	s.mux.Handle(s.OIDCService.CallbackURL(), s.oidcCallbackHandler(s.OIDCService))
+	s.mux.Handle(s.paths.Login, http.HandlerFunc(s.loginHandler))
+	for _, o := range s.OIDCServices {
+		s.registerOIDCService(o)
+	}
+	s.mux.Handle(s.paths.Logout, http.HandlerFunc(s.logoutHandler))
 
 	s.mux.Handle(s.paths.Privacy, http.HandlerFunc(s.privacyHandler))
 	s.mux.Handle("/static/", s.staticHandler(pubFS))
 }
+
+// registerOIDCService registers the login and callback routes for the provided OIDC service
+func (s *QuoteServer) registerOIDCService(o service.OIDC) {
+	s.mux.Handle(o.LoginURL(), s.oidcLoginHandler(o))
+	s.mux.Handle(o.CallbackURL(), s.oidcCallbackHandler(o))
+}
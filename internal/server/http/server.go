@@ -1,12 +1,16 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/klauspost/compress/gzhttp"
 
 	"github.com/willbicks/epigram/internal/config"
+	"github.com/willbicks/epigram/internal/model"
 	"github.com/willbicks/epigram/internal/server/http/frontend"
 	"github.com/willbicks/epigram/internal/server/http/paths"
 	"github.com/willbicks/epigram/internal/service"
@@ -21,8 +25,21 @@ type QuoteServer struct {
 
 	QuoteService service.Quote
 	UserService  service.User
-	QuizService  service.EntryQuiz
-	OIDCService  service.OIDC
+	// CommunityService provides the communities hosted by the server, and their entry quizzes.
+	CommunityService service.Community
+	// OIDCServices are the OIDC providers which users may sign in with, initialized from Config by Init.
+	OIDCServices []service.OIDC
+	AuditService service.AuditLog
+	// APITokenService authenticates requests to the JSON API.
+	APITokenService service.APIToken
+	ChatService     service.Chat
+	ReactionService service.Reaction
+	CommentService  service.Comment
+	PersonService   service.Person
+	// InviteService issues invites which allow users to join a community without passing its entry quiz.
+	InviteService service.Invite
+	// AccessRequestService allows users locked out of an entry quiz to request access from the community's admins.
+	AccessRequestService service.AccessRequest
 
 	// paths is a struct which stores the url paths to each page,
 	// and should be used in place of magic strings to represent rout
@@ -37,22 +54,63 @@ func (s *QuoteServer) Init() error {
 	// Initialize paths
 	s.paths = paths.Default()
 
-	// Initialize service for OpenID Connect
-	s.OIDCService = service.OIDC{
-		Name:         s.Config.OIDCProvider.Name,
-		IssuerURL:    s.Config.OIDCProvider.IssuerURL,
-		ClientID:     s.Config.OIDCProvider.ClientID,
-		ClientSecret: s.Config.OIDCProvider.ClientSecret,
+	// Initialize services for OpenID Connect
+	providers := s.Config.AllOIDCProviders()
+	if len(providers) == 0 {
+		return errors.New("at least one OIDC provider must be configured")
 	}
-	if err := s.OIDCService.Init(s.Config.BaseURL); err != nil {
-		return err
+	s.OIDCServices = make([]service.OIDC, 0, len(providers))
+	names := make(map[string]bool, len(providers))
+	for _, p := range providers {
+		if names[p.Name] {
+			return fmt.Errorf("OIDC provider name %q is used more than once", p.Name)
+		}
+		names[p.Name] = true
+
+		o := service.OIDC{
+			Name:         p.Name,
+			DisplayName:  p.DisplayName,
+			IssuerURL:    p.IssuerURL,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+		}
+		if err := o.Init(s.Config.BaseURL); err != nil {
+			return fmt.Errorf("initializing OIDC provider %q: %w", p.Name, err)
+		}
+		s.OIDCServices = append(s.OIDCServices, o)
+	}
+
+	// Validate chat slash commands
+	commands := make([]config.ChatCommand, 0, len(s.Config.ChatCommands))
+	platforms := make(map[string]bool, len(s.Config.ChatCommands))
+	for _, c := range s.Config.ChatCommands {
+		c.Platform = strings.ToLower(c.Platform)
+		if c.Platform != chatPlatformSlack && c.Platform != chatPlatformMattermost {
+			return fmt.Errorf("chat command platform %q is not supported", c.Platform)
+		}
+		if platforms[c.Platform] {
+			return fmt.Errorf("chat command platform %q is configured more than once", c.Platform)
+		}
+		if c.Secret == "" {
+			return fmt.Errorf("chat command platform %q requires a secret", c.Platform)
+		}
+		if c.Community == "" {
+			c.Community = model.DefaultCommunityID
+		}
+		if _, err := s.CommunityService.GetCommunity(c.Community); err != nil {
+			return fmt.Errorf("chat command platform %q submits to unknown community %q", c.Platform, c.Community)
+		}
+		platforms[c.Platform] = true
+		commands = append(commands, c)
 	}
+	s.Config.ChatCommands = commands
 
 	// Initialize template engine
 	tmpl, err := frontend.NewTemplateEngine(frontend.RootTD{
 		Title:       s.Config.Title,
 		Description: s.Config.Description,
 		Paths:       s.paths,
+		Communities: s.CommunityService.GetCommunities(),
 	})
 	if err != nil {
 		return err
@@ -77,5 +135,5 @@ func (s *QuoteServer) Init() error {
 // ServeHTTP serves as the entrypoint for HTTP requests to the quote server. It applies the appropriate global middleware,
 // and then serves request responses using the http ServeMux
 func (s QuoteServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	gzhttp.GzipHandler(s.interpretSession(s.getIP(s.mux))).ServeHTTP(w, r)
+	gzhttp.GzipHandler(s.interpretSession(s.interpretCommunity(s.getIP(s.mux)))).ServeHTTP(w, r)
 }
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/willbicks/epigram/internal/server/http/frontend"
+	"github.com/willbicks/epigram/internal/service"
+)
+
+// renderTagsPage renders the tags page, presenting the provided error if not nil.
+func (s *QuoteServer) renderTagsPage(w http.ResponseWriter, r *http.Request, pageErr error) {
+	tags, err := s.QuoteService.GetTags(r.Context())
+	if err != nil {
+		s.serviceError(w, r, err)
+		return
+	}
+
+	err = s.tmpl.RenderPage(r.Context(), w, frontend.TagsPage{
+		RenderAdmin: isAdmin(r.Context()),
+		Error:       pageErr,
+		Tags:        tags,
+	})
+	if err != nil {
+		s.serverError(w, r, err)
+	}
+}
+
+// tagsHandler renders the tags page in response to GET requests.
+func (s *QuoteServer) tagsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		s.renderTagsPage(w, r, nil)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
+
+// tagRenameHandler handles POST requests from admins to rename the tag specified by the from form value to the to
+// form value, merging the two if to is already in use.
+func (s *QuoteServer) tagRenameHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			s.clientError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		to := r.FormValue("to")
+		err := s.QuoteService.RenameTag(r.Context(), r.FormValue("from"), to)
+
+		var serr service.Error
+		if errors.As(err, &serr) && serr.StatusCode == http.StatusBadRequest {
+			s.renderTagsPage(w, r, err)
+			return
+		} else if err != nil {
+			s.serviceError(w, r, err)
+			return
+		}
+
+		http.Redirect(w, r, s.paths.Quotes+"?"+url.Values{"tag": {to}}.Encode(), http.StatusSeeOther)
+	default:
+		s.methodNotAllowedError(w, r)
+		return
+	}
+}
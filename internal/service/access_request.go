@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// maxAccessRequestMessageLength is the maximum number of characters in the message of an access request.
+const maxAccessRequestMessageLength = 1000
+
+// ErrAccessRequestNotFound is returned when a requested access request does not exist, or has already been resolved.
+var ErrAccessRequestNotFound = Error{
+	Issues:     []string{"Access request not found."},
+	StatusCode: 404,
+}
+
+// AccessRequestRepository provides methods for storing, manipulating, and retrieving AccessRequests, of which each
+// user has at most one per community.
+type AccessRequestRepository interface {
+	Create(ctx context.Context, r model.AccessRequest) error
+	Update(ctx context.Context, r model.AccessRequest) error
+	Delete(ctx context.Context, communityID string, userID string) error
+	Find(ctx context.Context, communityID string, userID string) (model.AccessRequest, error)
+	// FindByCommunityID returns the access requests to the specified community with the specified status, from
+	// oldest to newest.
+	FindByCommunityID(ctx context.Context, communityID string, status model.AccessRequestStatus) ([]model.AccessRequest, error)
+	// ReassignUser changes the UserID of every AccessRequest made by the user fromID, and the ResolverID of every
+	// AccessRequest resolved by them, to toID. Requests to communities which toID has already requested access to are
+	// removed instead.
+	ReassignUser(ctx context.Context, fromID string, toID string) error
+}
+
+// PendingAccessRequest is an access request awaiting a decision, along with the user who made it and their
+// membership of the community.
+type PendingAccessRequest struct {
+	model.AccessRequest
+	User       model.User
+	Membership model.Membership
+}
+
+// AccessRequest is a service which allows users who are locked out of the entry quiz of a community to request
+// access from its admins, who may approve or deny the request, or allow the user to attempt the quiz again.
+type AccessRequest struct {
+	repo  AccessRequestRepository
+	mr    MembershipRepository
+	ur    UserRepository
+	audit AuditLog
+	quiz  QuizPolicy
+}
+
+// NewAccessRequestService returns a new AccessRequest service which stores requests in the provided
+// AccessRequestRepository, grants access by updating memberships in the provided MembershipRepository, and records
+// decisions with the provided AuditLog service. Users may only request access when they are locked out of the quiz
+// by the provided QuizPolicy.
+func NewAccessRequestService(repo AccessRequestRepository, mr MembershipRepository, ur UserRepository, audit AuditLog,
+	quiz QuizPolicy) AccessRequest {
+	return AccessRequest{
+		repo:  repo,
+		mr:    mr,
+		ur:    ur,
+		audit: audit,
+		quiz:  quiz,
+	}
+}
+
+// GetAccessRequest returns the access request made by the user on the context to the current community, or
+// ErrAccessRequestNotFound if they have not made one.
+func (s AccessRequest) GetAccessRequest(ctx context.Context) (model.AccessRequest, error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return model.AccessRequest{}, err
+	}
+
+	r, err := s.repo.Find(ctx, ctxval.CommunityFromContext(ctx).ID, ctxval.UserFromContext(ctx).ID)
+	if err == storage.ErrNotFound {
+		return model.AccessRequest{}, ErrAccessRequestNotFound
+	}
+	return r, err
+}
+
+// RequestAccess requests access to the current community for the user on the context, who must be locked out of its
+// entry quiz, with a message to its admins. Users whose requests are pending or were denied cannot request again.
+func (s AccessRequest) RequestAccess(ctx context.Context, message string) (model.AccessRequest, error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return model.AccessRequest{}, err
+	}
+	communityID := ctxval.CommunityFromContext(ctx).ID
+	userID := ctxval.UserFromContext(ctx).ID
+
+	m, err := s.mr.Find(ctx, communityID, userID)
+	if err != nil && err != storage.ErrNotFound {
+		return model.AccessRequest{}, fmt.Errorf("finding membership: %w", err)
+	}
+	if err == storage.ErrNotFound || !s.quiz.status(m).LockedOut {
+		return model.AccessRequest{}, Error{
+			Issues:     []string{"Access may only be requested after using all of your attempts at the entry quiz."},
+			StatusCode: 400,
+		}
+	}
+
+	message = strings.TrimSpace(message)
+	var serr Error
+	if message == "" {
+		serr.addIssue("Please include a message explaining who you are.")
+	}
+	if utf8.RuneCountInString(message) > maxAccessRequestMessageLength {
+		serr.addIssue(fmt.Sprintf("Message must not be longer than %d characters.", maxAccessRequestMessageLength))
+	}
+	if serr.HasIssues() {
+		serr.StatusCode = 400
+		return model.AccessRequest{}, serr
+	}
+
+	existing, err := s.repo.Find(ctx, communityID, userID)
+	if err != nil && err != storage.ErrNotFound {
+		return model.AccessRequest{}, fmt.Errorf("finding access request: %w", err)
+	}
+	switch {
+	case err == nil && existing.Status == model.AccessRequestPending:
+		return model.AccessRequest{}, Error{
+			Issues:     []string{"You have already requested access, please wait for an administrator to respond."},
+			StatusCode: 400,
+		}
+	case err == nil && existing.Status == model.AccessRequestDenied:
+		return model.AccessRequest{}, Error{
+			Issues:     []string{"Your request for access was denied."},
+			StatusCode: 403,
+		}
+	}
+
+	r := model.AccessRequest{
+		CommunityID: communityID,
+		UserID:      userID,
+		Message:     message,
+		Status:      model.AccessRequestPending,
+		Created:     time.Now(),
+	}
+	if err == nil {
+		// a previously approved request is replaced, as the user has since lost access
+		err = s.repo.Update(ctx, r)
+	} else {
+		err = s.repo.Create(ctx, r)
+	}
+	if err != nil {
+		return model.AccessRequest{}, fmt.Errorf("storing access request: %w", err)
+	}
+
+	return r, nil
+}
+
+// GetPendingAccessRequests returns the access requests to the current community which are awaiting a decision, from
+// oldest to newest, and can only be accessed by admins. Requests from users who have since passed the quiz are
+// omitted.
+func (s AccessRequest) GetPendingAccessRequests(ctx context.Context) ([]PendingAccessRequest, error) {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return nil, err
+	}
+
+	requests, err := s.repo.FindByCommunityID(ctx, ctxval.CommunityFromContext(ctx).ID, model.AccessRequestPending)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]PendingAccessRequest, 0, len(requests))
+	for _, r := range requests {
+		u, m, err := s.findRequester(ctx, r)
+		if err == storage.ErrNotFound {
+			// requests of deleted users are ignored
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("finding requester: %w", err)
+		}
+
+		if m.QuizPassed {
+			continue
+		}
+
+		pending = append(pending, PendingAccessRequest{
+			AccessRequest: r,
+			User:          u,
+			Membership:    m,
+		})
+	}
+
+	return pending, nil
+}
+
+// findRequester returns the user who made the provided request, and their membership of its community, or
+// storage.ErrNotFound if either no longer exists.
+func (s AccessRequest) findRequester(ctx context.Context, r model.AccessRequest) (model.User, model.Membership, error) {
+	u, err := s.ur.FindByID(ctx, r.UserID)
+	if err != nil {
+		return model.User{}, model.Membership{}, err
+	}
+
+	m, err := s.mr.Find(ctx, r.CommunityID, r.UserID)
+	return u, m, err
+}
+
+// resolve finds the pending access request made to the current community by the user with the specified ID, applies
+// the provided decision to it and the user's membership, stores the results, and records the action in the audit
+// log. If the decision returns true, the request is deleted instead of updated. It can only be used by admins.
+func (s AccessRequest) resolve(ctx context.Context, userID string, action model.AuditAction,
+	decide func(r *model.AccessRequest, m *model.Membership) (remove bool)) error {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return err
+	}
+
+	c := ctxval.CommunityFromContext(ctx)
+	r, err := s.repo.Find(ctx, c.ID, userID)
+	if err == storage.ErrNotFound || (err == nil && r.Status != model.AccessRequestPending) {
+		return ErrAccessRequestNotFound
+	} else if err != nil {
+		return fmt.Errorf("finding access request: %w", err)
+	}
+
+	u, m, err := s.findRequester(ctx, r)
+	if err == storage.ErrNotFound {
+		return ErrAccessRequestNotFound
+	} else if err != nil {
+		return fmt.Errorf("finding requester: %w", err)
+	}
+
+	remove := decide(&r, &m)
+
+	if err := s.mr.Update(ctx, m); err != nil {
+		return fmt.Errorf("updating membership: %w", err)
+	}
+	if remove {
+		err = s.repo.Delete(ctx, r.CommunityID, r.UserID)
+	} else {
+		err = s.repo.Update(ctx, r)
+	}
+	if err != nil {
+		return fmt.Errorf("updating access request: %w", err)
+	}
+
+	return s.audit.record(ctx, action, u.ID, u.Name+" in "+c.Title)
+}
+
+// ApproveAccessRequest approves the pending access request made to the current community by the user with the
+// specified ID, granting them access as though they passed the entry quiz (and were approved, if the community
+// requires it). It can only be used by admins.
+func (s AccessRequest) ApproveAccessRequest(ctx context.Context, userID string) error {
+	return s.resolve(ctx, userID, model.AuditApproveAccess, func(r *model.AccessRequest, m *model.Membership) bool {
+		r.Status = model.AccessRequestApproved
+		r.Resolved = time.Now()
+		r.ResolverID = ctxval.UserFromContext(ctx).ID
+		m.QuizPassed = true
+		m.Approved = true
+		m.Rejected = false
+		return false
+	})
+}
+
+// DenyAccessRequest denies the pending access request made to the current community by the user with the specified
+// ID, such that they remain locked out, and cannot request access again. It can only be used by admins.
+func (s AccessRequest) DenyAccessRequest(ctx context.Context, userID string) error {
+	return s.resolve(ctx, userID, model.AuditDenyAccess, func(r *model.AccessRequest, m *model.Membership) bool {
+		r.Status = model.AccessRequestDenied
+		r.Resolved = time.Now()
+		r.ResolverID = ctxval.UserFromContext(ctx).ID
+		return false
+	})
+}
+
+// ResetAccessRequest dismisses the pending access request made to the current community by the user with the
+// specified ID, and resets their quiz attempts, allowing them to attempt the quiz again. It can only be used by
+// admins.
+func (s AccessRequest) ResetAccessRequest(ctx context.Context, userID string) error {
+	return s.resolve(ctx, userID, model.AuditResetQuiz, func(r *model.AccessRequest, m *model.Membership) bool {
+		m.QuizAttempts = 0
+		m.LastQuizAttempt = time.Time{}
+		return true
+	})
+}
@@ -0,0 +1,144 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+// newAccessRequestService returns an AccessRequest service applying testQuizPolicy, along with its membership
+// repository, in which the provided user is locked out of testCommunity.
+func newAccessRequestService(t *testing.T, lockedOut model.User, users ...model.User) (service.AccessRequest, service.MembershipRepository) {
+	t.Helper()
+
+	userRepo := inmemory.NewUserRepository()
+	for _, u := range append(users, lockedOut) {
+		if err := userRepo.Create(context.Background(), u); err != nil {
+			t.Fatalf("creating user %v: %v", u.ID, err)
+		}
+	}
+
+	membershipRepo := inmemory.NewMembershipRepository()
+	err := membershipRepo.Create(context.Background(), model.Membership{
+		CommunityID:  testCommunity.ID,
+		UserID:       lockedOut.ID,
+		QuizAttempts: int8(testQuizPolicy.MaxAttempts),
+	})
+	if err != nil {
+		t.Fatalf("creating membership: %v", err)
+	}
+
+	return service.NewAccessRequestService(inmemory.NewAccessRequestRepository(), membershipRepo, userRepo,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), testQuizPolicy), membershipRepo
+}
+
+func TestAccessRequest_RequestAccess(t *testing.T) {
+	is := is.New(t)
+	newcomer := model.User{ID: "newcomer", Name: "Newcomer"}
+	requests, _ := newAccessRequestService(t, newcomer, otherUser)
+	ctx := signedInContext(newcomer)
+
+	_, err := requests.RequestAccess(signedInContext(otherUser), "Let me in")
+	is.Equal(err.(service.Error).StatusCode, 400) // users who are not locked out should not be able to request access
+
+	_, err = requests.GetAccessRequest(ctx)
+	is.Equal(err, service.ErrAccessRequestNotFound) // users should have no request before making one
+
+	_, err = requests.RequestAccess(ctx, "  ")
+	is.Equal(err.(service.Error).StatusCode, 400) // requests without a message should be rejected
+
+	r, err := requests.RequestAccess(ctx, " I'm new here ")
+	is.NoErr(err)                                  // locked out users should be able to request access
+	is.Equal(r.Message, "I'm new here")            // message should be trimmed
+	is.Equal(r.Status, model.AccessRequestPending) // new request should be pending
+	is.Equal(r.CommunityID, testCommunity.ID)      // request should be to the current community
+
+	got, err := requests.GetAccessRequest(ctx)
+	is.NoErr(err)
+	is.Equal(got.Message, r.Message) // user should be able to see their request
+
+	_, err = requests.RequestAccess(ctx, "Hello?")
+	is.Equal(err.(service.Error).StatusCode, 400) // users should not be able to request access twice
+}
+
+func TestAccessRequest_ApproveAccessRequest(t *testing.T) {
+	is := is.New(t)
+	newcomer := model.User{ID: "newcomer", Name: "Newcomer"}
+	requests, membershipRepo := newAccessRequestService(t, newcomer, adminUser, submitter)
+	ctxAdmin := userContext(adminUser)
+
+	_, err := requests.RequestAccess(signedInContext(newcomer), "I'm new here")
+	is.NoErr(err)
+
+	_, err = requests.GetPendingAccessRequests(userContext(submitter))
+	is.Equal(err, service.ErrNotAuthorized)                                                                // non-admins should not be able to list requests
+	is.Equal(requests.ApproveAccessRequest(userContext(submitter), newcomer.ID), service.ErrNotAuthorized) // non-admins should not be able to approve requests
+
+	pending, err := requests.GetPendingAccessRequests(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(pending), 1)                     // admins should see the pending request
+	is.Equal(pending[0].User.Name, newcomer.Name) // request should include its requester
+
+	is.NoErr(requests.ApproveAccessRequest(ctxAdmin, newcomer.ID)) // admins should be able to approve requests
+
+	m, err := membershipRepo.Find(context.Background(), testCommunity.ID, newcomer.ID)
+	is.NoErr(err)
+	is.True(m.QuizPassed) // approved user should be granted access
+
+	r, err := requests.GetAccessRequest(signedInContext(newcomer))
+	is.NoErr(err)
+	is.Equal(r.Status, model.AccessRequestApproved) // request should be approved
+	is.Equal(r.ResolverID, adminUser.ID)            // request should record who approved it
+
+	pending, err = requests.GetPendingAccessRequests(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(pending), 0) // approved request should no longer be pending
+
+	is.Equal(requests.ApproveAccessRequest(ctxAdmin, newcomer.ID), service.ErrAccessRequestNotFound) // resolved requests should not be approvable again
+}
+
+func TestAccessRequest_DenyAccessRequest(t *testing.T) {
+	is := is.New(t)
+	newcomer := model.User{ID: "newcomer", Name: "Newcomer"}
+	requests, membershipRepo := newAccessRequestService(t, newcomer, adminUser)
+	ctxAdmin := userContext(adminUser)
+	ctx := signedInContext(newcomer)
+
+	_, err := requests.RequestAccess(ctx, "I'm new here")
+	is.NoErr(err)
+
+	is.NoErr(requests.DenyAccessRequest(ctxAdmin, newcomer.ID)) // admins should be able to deny requests
+
+	m, err := membershipRepo.Find(context.Background(), testCommunity.ID, newcomer.ID)
+	is.NoErr(err)
+	is.True(!m.QuizPassed) // denied user should not be granted access
+
+	_, err = requests.RequestAccess(ctx, "Please?")
+	is.Equal(err.(service.Error).StatusCode, 403) // denied users should not be able to request access again
+}
+
+func TestAccessRequest_ResetAccessRequest(t *testing.T) {
+	is := is.New(t)
+	newcomer := model.User{ID: "newcomer", Name: "Newcomer"}
+	requests, membershipRepo := newAccessRequestService(t, newcomer, adminUser)
+	ctxAdmin := userContext(adminUser)
+	ctx := signedInContext(newcomer)
+
+	_, err := requests.RequestAccess(ctx, "I'm new here")
+	is.NoErr(err)
+
+	is.NoErr(requests.ResetAccessRequest(ctxAdmin, newcomer.ID)) // admins should be able to reset locked out users
+
+	m, err := membershipRepo.Find(context.Background(), testCommunity.ID, newcomer.ID)
+	is.NoErr(err)
+	is.Equal(m.QuizAttempts, int8(0)) // quiz attempts should be reset
+	is.True(!m.QuizPassed)            // reset user should not be granted access
+
+	_, err = requests.GetAccessRequest(ctx)
+	is.Equal(err, service.ErrAccessRequestNotFound) // reset request should be dismissed
+}
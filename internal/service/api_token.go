@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+
+	"github.com/rs/xid"
+)
+
+const (
+	// _apiTokenRandBytes represents the number of cryptographically secure random bytes that should be generated for
+	// each APIToken.
+	_apiTokenRandBytes = 24
+
+	// _apiTokenPrefix is prepended to each APIToken, making them easy to identify (e.g. by secret scanners).
+	_apiTokenPrefix = "ep_"
+
+	// _maxAPITokenNameLength is the maximum length of the name of an APIToken.
+	_maxAPITokenNameLength = 64
+)
+
+// ErrAPITokenNotFound is returned when a requested APIToken does not exist, or does not belong to the current user.
+var ErrAPITokenNotFound = Error{
+	Issues:     []string{"API token not found."},
+	StatusCode: 404,
+}
+
+// ErrInvalidAPIToken is returned when a request is made with an API token which is not valid.
+var ErrInvalidAPIToken = Error{
+	Issues:     []string{"Invalid API token."},
+	StatusCode: 401,
+}
+
+// APITokenRepository provides methods for storing and retrieving APITokens.
+type APITokenRepository interface {
+	Create(ctx context.Context, t model.APIToken) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (model.APIToken, error)
+	FindByHash(ctx context.Context, hash string) (model.APIToken, error)
+	// FindByUserID returns all APITokens belonging to the specified user, from newest to oldest.
+	FindByUserID(ctx context.Context, userID string) ([]model.APIToken, error)
+	// ReassignUser changes the UserID of every APIToken belonging to the user fromID to toID.
+	ReassignUser(ctx context.Context, fromID string, toID string) error
+}
+
+// APIToken is a service for issuing and verifying APITokens.
+type APIToken struct {
+	repo APITokenRepository
+	ur   UserRepository
+}
+
+// NewAPITokenService returns a new APIToken service with the provided APITokenRepository, and the UserRepository
+// used to find the users tokens belong to.
+func NewAPITokenService(repo APITokenRepository, ur UserRepository) APIToken {
+	return APIToken{
+		repo: repo,
+		ur:   ur,
+	}
+}
+
+// hashAPIToken returns the hex encoded SHA-256 hash of the provided token.
+func hashAPIToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// CreateAPIToken creates a new APIToken with the provided name for the user on the context, scoped to the current
+// community, and returns it along with the token itself, which cannot be retrieved again.
+func (s APIToken) CreateAPIToken(ctx context.Context, name string) (model.APIToken, string, error) {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return model.APIToken{}, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > _maxAPITokenNameLength {
+		return model.APIToken{}, "", Error{
+			Issues:     []string{fmt.Sprintf("API token name must be between 1 and %d characters.", _maxAPITokenNameLength)},
+			StatusCode: 400,
+		}
+	}
+
+	randBytes := make([]byte, _apiTokenRandBytes)
+	if _, err := rand.Read(randBytes); err != nil {
+		return model.APIToken{}, "", fmt.Errorf("generate randBytes for APIToken: %w", err)
+	}
+	secret := _apiTokenPrefix + base64.RawURLEncoding.EncodeToString(randBytes)
+
+	t := model.APIToken{
+		ID:          xid.New().String(),
+		UserID:      ctxval.UserFromContext(ctx).ID,
+		CommunityID: ctxval.CommunityFromContext(ctx).ID,
+		Name:        name,
+		Hash:        hashAPIToken(secret),
+		Created:     time.Now(),
+	}
+
+	if err := s.repo.Create(ctx, t); err != nil {
+		return model.APIToken{}, "", err
+	}
+
+	return t, secret, nil
+}
+
+// GetAPITokens returns the APITokens belonging to the user on the context, from newest to oldest.
+func (s APIToken) GetAPITokens(ctx context.Context) ([]model.APIToken, error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByUserID(ctx, ctxval.UserFromContext(ctx).ID)
+}
+
+// RevokeAPIToken deletes the APIToken with the specified ID, provided that it belongs to the user on the context.
+func (s APIToken) RevokeAPIToken(ctx context.Context, id string) error {
+	if err := verifySignedIn(ctx); err != nil {
+		return err
+	}
+
+	t, err := s.repo.FindByID(ctx, id)
+	if err == storage.ErrNotFound || (err == nil && t.UserID != ctxval.UserFromContext(ctx).ID) {
+		return ErrAPITokenNotFound
+	} else if err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// GetUserFromAPIToken returns the user to whom the provided token belongs, and the ID of the community to which it is
+// scoped.
+func (s APIToken) GetUserFromAPIToken(ctx context.Context, token string) (model.User, string, error) {
+	if !strings.HasPrefix(token, _apiTokenPrefix) {
+		return model.User{}, "", ErrInvalidAPIToken
+	}
+
+	t, err := s.repo.FindByHash(ctx, hashAPIToken(token))
+	if err == storage.ErrNotFound {
+		return model.User{}, "", ErrInvalidAPIToken
+	} else if err != nil {
+		return model.User{}, "", fmt.Errorf("finding API token: %w", err)
+	}
+
+	u, err := s.ur.FindByID(ctx, t.UserID)
+	if err == storage.ErrNotFound {
+		return model.User{}, "", ErrInvalidAPIToken
+	} else if err != nil {
+		return model.User{}, "", fmt.Errorf("finding user of API token: %w", err)
+	}
+
+	return u, t.CommunityID, nil
+}
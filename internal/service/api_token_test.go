@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+func newAPITokenService(t *testing.T, users ...model.User) service.APIToken {
+	t.Helper()
+
+	userRepo := inmemory.NewUserRepository()
+	for _, u := range users {
+		if err := userRepo.Create(context.Background(), u); err != nil {
+			t.Fatalf("creating user %v: %v", u.ID, err)
+		}
+	}
+
+	return service.NewAPITokenService(inmemory.NewAPITokenRepository(), userRepo)
+}
+
+func TestAPIToken_CreateAPIToken(t *testing.T) {
+	is := is.New(t)
+	tokens := newAPITokenService(t, submitter)
+	ctxSubmitter := userContext(submitter)
+
+	tok, secret, err := tokens.CreateAPIToken(ctxSubmitter, "  CI  ")
+	is.NoErr(err)                                // quiz passed user should be able to create a token
+	is.Equal(tok.Name, "CI")                     // token name should be trimmed
+	is.Equal(tok.UserID, submitter.ID)           // token should belong to the current user
+	is.Equal(tok.CommunityID, testCommunity.ID)  // token should be scoped to the current community
+	is.True(strings.HasPrefix(secret, "ep_"))    // secret should be prefixed
+	is.True(!strings.Contains(tok.Hash, secret)) // secret should not be stored
+
+	u, communityID, err := tokens.GetUserFromAPIToken(context.Background(), secret)
+	is.NoErr(err)                           // secret should authenticate
+	is.Equal(u.ID, submitter.ID)            // secret should authenticate as the token's owner
+	is.Equal(communityID, testCommunity.ID) // secret should authenticate in the token's community
+
+	_, _, err = tokens.CreateAPIToken(ctxSubmitter, " ")
+	is.Equal(err.(service.Error).StatusCode, 400) // blank name should be rejected
+
+	ctxNew := ctxval.ContextWithUser(context.Background(), model.User{ID: "new"})
+	_, _, err = tokens.CreateAPIToken(ctxNew, "CI")
+	is.Equal(err, service.ErrNotAuthorized) // user who has not passed the quiz should not be able to create a token
+}
+
+func TestAPIToken_GetUserFromAPIToken(t *testing.T) {
+	is := is.New(t)
+	tokens := newAPITokenService(t, submitter)
+
+	_, _, err := tokens.GetUserFromAPIToken(context.Background(), "ep_unknown")
+	is.Equal(err, service.ErrInvalidAPIToken) // unknown token should be rejected
+
+	_, _, err = tokens.GetUserFromAPIToken(context.Background(), "")
+	is.Equal(err, service.ErrInvalidAPIToken) // empty token should be rejected
+
+	ctxOrphan := userContext(model.User{ID: "deleted"})
+	_, secret, err := tokens.CreateAPIToken(ctxOrphan, "orphan")
+	is.NoErr(err)
+	_, _, err = tokens.GetUserFromAPIToken(context.Background(), secret)
+	is.Equal(err, service.ErrInvalidAPIToken) // token of a user which no longer exists should be rejected
+}
+
+func TestAPIToken_RevokeAPIToken(t *testing.T) {
+	is := is.New(t)
+	tokens := newAPITokenService(t, submitter, otherUser)
+	ctxSubmitter := userContext(submitter)
+	ctxOther := userContext(otherUser)
+
+	tok, secret, err := tokens.CreateAPIToken(ctxSubmitter, "CI")
+	is.NoErr(err)
+
+	list, err := tokens.GetAPITokens(ctxSubmitter)
+	is.NoErr(err)
+	is.Equal(len(list), 1) // owner should see their token
+
+	list, err = tokens.GetAPITokens(ctxOther)
+	is.NoErr(err)
+	is.Equal(len(list), 0) // other users should not see the token
+
+	err = tokens.RevokeAPIToken(ctxOther, tok.ID)
+	is.Equal(err, service.ErrAPITokenNotFound) // other users should not be able to revoke the token
+
+	is.NoErr(tokens.RevokeAPIToken(ctxSubmitter, tok.ID)) // owner should be able to revoke the token
+
+	_, _, err = tokens.GetUserFromAPIToken(context.Background(), secret)
+	is.Equal(err, service.ErrInvalidAPIToken) // revoked token should be rejected
+
+	err = tokens.RevokeAPIToken(ctxSubmitter, tok.ID)
+	is.Equal(err, service.ErrAPITokenNotFound) // revoking a token twice should fail
+}
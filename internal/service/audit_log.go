@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+
+	"github.com/rs/xid"
+)
+
+// AuditLogRepository provides methods for storing and retrieving AuditLogEntries.
+type AuditLogRepository interface {
+	Create(ctx context.Context, e model.AuditLogEntry) error
+	// Query returns the AuditLogEntries matching the provided AuditLogQuery, from newest to oldest.
+	Query(ctx context.Context, q AuditLogQuery) ([]model.AuditLogEntry, error)
+}
+
+// AuditLogQuery specifies which AuditLogEntries should be returned by AuditLogRepository.Query. Zero values of each
+// field are ignored, and as such, an empty AuditLogQuery matches every entry.
+type AuditLogQuery struct {
+	// Limit is the maximum number of entries to return.
+	Limit    int
+	ActorID  string
+	Action   model.AuditAction
+	TargetID string
+}
+
+// AuditLog is a service for recording and reviewing privileged actions.
+type AuditLog struct {
+	repo AuditLogRepository
+}
+
+// NewAuditLogService returns a new AuditLog service with the provided AuditLogRepository.
+func NewAuditLogService(repo AuditLogRepository) AuditLog {
+	return AuditLog{
+		repo,
+	}
+}
+
+// record adds an entry to the audit log for an action performed on the specified target by the user on the context,
+// from the IP on the context.
+func (s AuditLog) record(ctx context.Context, action model.AuditAction, targetID string, details string) error {
+	err := s.repo.Create(ctx, model.AuditLogEntry{
+		ID:       xid.New().String(),
+		ActorID:  ctxval.UserFromContext(ctx).ID,
+		Action:   action,
+		TargetID: targetID,
+		Details:  details,
+		IP:       ctxval.IPFromContext(ctx),
+		Created:  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("recording %v in audit log: %w", action, err)
+	}
+	return nil
+}
+
+// QueryAuditLog returns the AuditLogEntries matching the provided AuditLogQuery, from newest to oldest, and can only
+// be accessed by admins of the instance, as it records the actions taken in every community.
+func (s AuditLog) QueryAuditLog(ctx context.Context, q AuditLogQuery) ([]model.AuditLogEntry, error) {
+	if err := verifyInstanceAdminPrivilege(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Query(ctx, q)
+}
@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+func TestAuditLog_RecordsAdminActions(t *testing.T) {
+	is := is.New(t)
+
+	member := model.User{ID: "member", Name: "Member"}
+
+	userRepo := inmemory.NewUserRepository()
+	is.NoErr(userRepo.Create(context.Background(), member))
+	is.NoErr(userRepo.Create(context.Background(), adminUser))
+
+	membershipRepo := inmemory.NewMembershipRepository()
+	is.NoErr(membershipRepo.Create(context.Background(), model.Membership{
+		CommunityID: testCommunity.ID,
+		UserID:      member.ID,
+		QuizPassed:  true,
+	}))
+
+	audit := service.NewAuditLogService(inmemory.NewAuditLogRepository())
+	userService := service.NewUserService(
+		userRepo,
+		inmemory.NewUserIdentityRepository(),
+		membershipRepo,
+		inmemory.NewQuizAttemptRepository(),
+		inmemory.NewQuoteRepository(),
+		newUserReferences(),
+		inmemory.NewTransactor(),
+		service.NewUserSessionService(inmemory.NewUserSessionRepository(), service.SessionPolicy{}),
+		audit,
+		service.QuizPolicy{},
+	)
+
+	ctxAdmin := ctxval.ContextWithIP(userContext(adminUser), "192.168.0.1")
+	is.NoErr(userService.SetUserBanned(ctxAdmin, member.ID, true))
+
+	entries, err := audit.QueryAuditLog(ctxAdmin, service.AuditLogQuery{})
+	is.NoErr(err)
+	is.Equal(len(entries), 1)                       // banning a user should record one entry
+	is.Equal(entries[0].ActorID, adminUser.ID)      // entry should record the acting admin
+	is.Equal(entries[0].Action, model.AuditBanUser) // entry should record the action
+	is.Equal(entries[0].TargetID, member.ID)        // entry should record the target
+	is.Equal(entries[0].IP, "192.168.0.1")          // entry should record the request IP
+	is.True(!entries[0].Created.IsZero())           // entry should record the time
+
+	ctxMember := userContext(member)
+	_, err = audit.QueryAuditLog(ctxMember, service.AuditLogQuery{})
+	is.Equal(err, service.ErrNotAuthorized) // non-admins should not be able to view the audit log
+}
+
+func TestAuditLog_RecordsQuoteModeration(t *testing.T) {
+	is := is.New(t)
+
+	audit := service.NewAuditLogService(inmemory.NewAuditLogRepository())
+	quoteService := service.NewQuoteService(inmemory.NewQuoteRepository(), inmemory.NewReactionRepository(),
+		inmemory.NewCommentRepository(), inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour, audit,
+		service.Webhook{}, discardLogger)
+
+	ctxSubmitter := userContext(submitter)
+	own := model.Quote{Quotee: "AJBR", Quote: "I'll delete this myself"}
+	is.NoErr(quoteService.CreateQuote(ctxSubmitter, &own))
+	is.NoErr(quoteService.DeleteQuote(ctxSubmitter, own.ID))
+
+	moderated := model.Quote{Quotee: "AJBR", Quote: "An admin will delete this"}
+	is.NoErr(quoteService.CreateQuote(ctxSubmitter, &moderated))
+
+	ctxAdmin := userContext(adminUser)
+	is.NoErr(quoteService.DeleteQuote(ctxAdmin, moderated.ID))
+
+	entries, err := audit.QueryAuditLog(ctxAdmin, service.AuditLogQuery{})
+	is.NoErr(err)
+	is.Equal(len(entries), 1)                           // only moderation by others should be recorded
+	is.Equal(entries[0].Action, model.AuditDeleteQuote) // entry should record the deletion
+	is.Equal(entries[0].TargetID, moderated.ID)         // entry should record the deleted quote
+}
@@ -18,6 +18,25 @@ var ErrNotAuthorized = Error{
 	StatusCode: 401,
 }
 
+// isAuthorized returns true if the user on the Context is authorized to access the current community, either as an
+// authorized member of it, or as an admin of the instance.
+func isAuthorized(ctx context.Context) bool {
+	u := ctxval.UserFromContext(ctx)
+	if u.ID == "" {
+		return false
+	}
+	return u.IsAdmin() || ctxval.MembershipFromContext(ctx).IsAuthorized(ctxval.CommunityFromContext(ctx).Gate)
+}
+
+// isAdmin returns true if the user on the Context is an admin of the current community, or of the instance.
+func isAdmin(ctx context.Context) bool {
+	u := ctxval.UserFromContext(ctx)
+	if u.ID == "" {
+		return false
+	}
+	return u.IsAdmin() || ctxval.MembershipFromContext(ctx).Admin
+}
+
 // verifySignedIn returns ErrNotAuthenticated if the context lacks an authenticated user
 func verifySignedIn(ctx context.Context) error {
 	if u := ctxval.UserFromContext(ctx); u.ID == "" {
@@ -26,16 +45,25 @@ func verifySignedIn(ctx context.Context) error {
 	return nil
 }
 
-// verifyUserPrivilege returns ErrNotAuthorized if the user on the Context is not authorized
+// verifyUserPrivilege returns ErrNotAuthorized if the user on the Context is not authorized to access the current
+// community
 func verifyUserPrivilege(ctx context.Context) error {
-	if !ctxval.UserFromContext(ctx).IsAuthorized() {
+	if !isAuthorized(ctx) {
 		return ErrNotAuthorized
 	}
 	return nil
 }
 
-// verifyAdminPrivilege returns ErrNotAuthorized if the user on the Context is not an admin
+// verifyAdminPrivilege returns ErrNotAuthorized if the user on the Context is not an admin of the current community
 func verifyAdminPrivilege(ctx context.Context) error {
+	if !isAdmin(ctx) {
+		return ErrNotAuthorized
+	}
+	return nil
+}
+
+// verifyInstanceAdminPrivilege returns ErrNotAuthorized if the user on the Context is not an admin of the instance
+func verifyInstanceAdminPrivilege(ctx context.Context) error {
 	if !ctxval.UserFromContext(ctx).IsAdmin() {
 		return ErrNotAuthorized
 	}
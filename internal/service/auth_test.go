@@ -8,6 +8,17 @@ import (
 	"github.com/willbicks/epigram/internal/model"
 )
 
+// memberContext returns a context in which a user with the provided membership of the default community is signed
+// in to it.
+func memberContext(m model.Membership) context.Context {
+	m.CommunityID = model.DefaultCommunityID
+	m.UserID = "f000"
+
+	ctx := ctxval.ContextWithUser(context.Background(), model.User{ID: m.UserID})
+	ctx = ctxval.ContextWithCommunity(ctx, model.Community{ID: m.CommunityID})
+	return ctxval.ContextWithMembership(ctx, m)
+}
+
 func Test_notSignedIn(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -69,11 +80,18 @@ func Test_noUserPrivilege(t *testing.T) {
 			ErrNotAuthorized,
 		},
 		{
-			"Context with quiz passed user",
-			ctxval.ContextWithUser(context.Background(), model.User{
-				ID:         "f000",
-				QuizPassed: true,
-			}),
+			"Context with quiz passed member",
+			memberContext(model.Membership{QuizPassed: true}),
+			nil,
+		},
+		{
+			"Context with banned member",
+			memberContext(model.Membership{QuizPassed: true, Banned: true}),
+			ErrNotAuthorized,
+		},
+		{
+			"Context with community admin",
+			memberContext(model.Membership{Admin: true}),
 			nil,
 		},
 		{
@@ -122,13 +140,15 @@ func Test_noAdminPrivilege(t *testing.T) {
 			ErrNotAuthorized,
 		},
 		{
-			"Context with quiz passed user",
-			ctxval.ContextWithUser(context.Background(), model.User{
-				ID:         "f000",
-				QuizPassed: true,
-			}),
+			"Context with quiz passed member",
+			memberContext(model.Membership{QuizPassed: true}),
 			ErrNotAuthorized,
 		},
+		{
+			"Context with community admin",
+			memberContext(model.Membership{Admin: true}),
+			nil,
+		},
 		{
 			"Context with admin user",
 			ctxval.ContextWithUser(context.Background(), model.User{
@@ -150,3 +170,36 @@ func Test_noAdminPrivilege(t *testing.T) {
 		})
 	}
 }
+
+func Test_noInstanceAdminPrivilege(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{
+			"Context with community admin",
+			memberContext(model.Membership{Admin: true}),
+			ErrNotAuthorized,
+		},
+		{
+			"Context with admin user",
+			ctxval.ContextWithUser(context.Background(), model.User{
+				ID:    "f000",
+				Admin: true,
+			}),
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyInstanceAdminPrivilege(tt.ctx)
+			if (tt.wantErr == nil) != (err == nil) {
+				t.Errorf("verifyInstanceAdminPrivilege() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && tt.wantErr.Error() != err.Error() {
+				t.Errorf("verifyInstanceAdminPrivilege() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+const (
+	// _chatLinkCodeExpiry is the amount of time for which a chat link code may be used.
+	_chatLinkCodeExpiry = 15 * time.Minute
+
+	// ChatQuoteUsage describes the syntax of quotes submitted using a chat slash command.
+	ChatQuoteUsage = `"text" — Name (context)`
+)
+
+// ErrChatUserNotLinked is returned when a quote is submitted by a chat user who has not been linked to a User.
+var ErrChatUserNotLinked = Error{
+	Issues:     []string{"Your chat account is not linked to an account. Use the link command to link it."},
+	StatusCode: 403,
+}
+
+// ErrChatLinkNotFound is returned when a requested ChatLink does not exist, or does not belong to the current user.
+var ErrChatLinkNotFound = Error{
+	Issues:     []string{"Chat link not found."},
+	StatusCode: 404,
+}
+
+// ErrInvalidChatLinkCode is returned when a chat link code is malformed, has been tampered with, or has expired.
+var ErrInvalidChatLinkCode = Error{
+	Issues:     []string{"This link is invalid or has expired. Use the link command to generate a new one."},
+	StatusCode: 400,
+}
+
+// ChatLinkRepository provides methods for storing and retrieving ChatLinks.
+type ChatLinkRepository interface {
+	Create(ctx context.Context, l model.ChatLink) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (model.ChatLink, error)
+	// FindByUserID returns all ChatLinks belonging to the specified user, from oldest to newest.
+	FindByUserID(ctx context.Context, userID string) ([]model.ChatLink, error)
+	// ReassignUser links every ChatLink linked to the user fromID to the user toID instead.
+	ReassignUser(ctx context.Context, fromID string, toID string) error
+}
+
+// ChatUser identifies a user of a chat platform who has invoked a slash command.
+type ChatUser struct {
+	Platform string `json:"p"`
+	TeamID   string `json:"t"`
+	UserID   string `json:"u"`
+	UserName string `json:"n"`
+}
+
+// ID returns the ID of the ChatLink associating the chat user with a User.
+func (cu ChatUser) ID() string {
+	return cu.Platform + ":" + cu.TeamID + ":" + cu.UserID
+}
+
+// chatLinkClaims is the signed content of a chat link code.
+type chatLinkClaims struct {
+	ChatUser
+	Expires int64 `json:"e"`
+}
+
+// chatQuoteRegexp matches the syntax of quotes submitted using a chat slash command, capturing the quote, quotee,
+// and optional context. Both straight and curly quotation marks are accepted, and the quotee may be preceded by an
+// em dash, en dash, or one or two hyphens.
+var chatQuoteRegexp = regexp.MustCompile(`^["“](.+)["”]\s*(?:—|–|--?)\s*([^()]+?)\s*(?:\((.*)\))?$`)
+
+// ParseChatQuote parses a quote submitted using a chat slash command, in the form described by ChatQuoteUsage.
+func ParseChatQuote(text string) (model.Quote, error) {
+	m := chatQuoteRegexp.FindStringSubmatch(strings.TrimSpace(text))
+	if m == nil {
+		return model.Quote{}, Error{
+			Issues:     []string{"Quotes must be written as " + ChatQuoteUsage + "."},
+			StatusCode: 400,
+		}
+	}
+
+	return model.Quote{
+		Quote:   strings.TrimSpace(m[1]),
+		Quotee:  strings.TrimSpace(m[2]),
+		Context: strings.TrimSpace(m[3]),
+	}, nil
+}
+
+// Chat is a service which permits users of chat platforms to submit quotes using a slash command, once they have
+// linked their chat account to a User.
+type Chat struct {
+	repo        ChatLinkRepository
+	ur          UserRepository
+	quotes      Quote
+	communities Community
+	// linkKey is used to sign chat link codes.
+	linkKey []byte
+}
+
+// NewChatService returns a new Chat service with the provided ChatLinkRepository, UserRepository used to find the
+// users chat accounts are linked to, Quote service used to submit quotes, and Community service used to find the
+// communities they are submitted to. Chat link codes are signed with a random key, and as such, are invalidated when
+// the server is restarted.
+func NewChatService(repo ChatLinkRepository, ur UserRepository, quotes Quote, communities Community) (Chat, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return Chat{}, fmt.Errorf("generate chat link key: %w", err)
+	}
+
+	return Chat{
+		repo:        repo,
+		ur:          ur,
+		quotes:      quotes,
+		communities: communities,
+		linkKey:     key,
+	}, nil
+}
+
+// sign returns the signature of the provided chat link code payload.
+func (s Chat) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.linkKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// CreateLinkCode returns a short-lived code which may be provided to LinkChatUser by a signed in user, to link the
+// chat user to their account.
+func (s Chat) CreateLinkCode(cu ChatUser) (string, error) {
+	b, err := json.Marshal(chatLinkClaims{
+		ChatUser: cu,
+		Expires:  time.Now().Add(_chatLinkCodeExpiry).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return payload + "." + s.sign(payload), nil
+}
+
+// ParseLinkCode returns the chat user identified by a code returned by CreateLinkCode, provided that it is valid.
+func (s Chat) ParseLinkCode(code string) (ChatUser, error) {
+	payload, sig, ok := strings.Cut(code, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(payload))) {
+		return ChatUser{}, ErrInvalidChatLinkCode
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return ChatUser{}, ErrInvalidChatLinkCode
+	}
+
+	var claims chatLinkClaims
+	if err := json.Unmarshal(b, &claims); err != nil || time.Now().Unix() > claims.Expires {
+		return ChatUser{}, ErrInvalidChatLinkCode
+	}
+
+	return claims.ChatUser, nil
+}
+
+// LinkChatUser links the chat user identified by the provided code to the user on the context. If the chat user is
+// already linked to another user, the link is replaced.
+func (s Chat) LinkChatUser(ctx context.Context, code string) (model.ChatLink, error) {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return model.ChatLink{}, err
+	}
+
+	cu, err := s.ParseLinkCode(code)
+	if err != nil {
+		return model.ChatLink{}, err
+	}
+
+	l := model.ChatLink{
+		ID:           cu.ID(),
+		Platform:     cu.Platform,
+		TeamID:       cu.TeamID,
+		ChatUserID:   cu.UserID,
+		ChatUserName: cu.UserName,
+		UserID:       ctxval.UserFromContext(ctx).ID,
+		Created:      time.Now(),
+	}
+
+	if err := s.repo.Delete(ctx, l.ID); err != nil && err != storage.ErrNotFound {
+		return model.ChatLink{}, err
+	}
+
+	return l, s.repo.Create(ctx, l)
+}
+
+// GetChatLinks returns the ChatLinks belonging to the user on the context, from oldest to newest.
+func (s Chat) GetChatLinks(ctx context.Context) ([]model.ChatLink, error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByUserID(ctx, ctxval.UserFromContext(ctx).ID)
+}
+
+// UnlinkChatUser deletes the ChatLink with the specified ID, provided that it belongs to the user on the context.
+func (s Chat) UnlinkChatUser(ctx context.Context, id string) error {
+	if err := verifySignedIn(ctx); err != nil {
+		return err
+	}
+
+	l, err := s.repo.FindByID(ctx, id)
+	if err == storage.ErrNotFound || (err == nil && l.UserID != ctxval.UserFromContext(ctx).ID) {
+		return ErrChatLinkNotFound
+	} else if err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// SubmitChatQuote parses and creates a quote submitted by a chat user using a slash command, on behalf of the User
+// to whom they are linked, in the community with the specified ID.
+func (s Chat) SubmitChatQuote(ctx context.Context, cu ChatUser, communityID string, text string) (model.Quote, error) {
+	l, err := s.repo.FindByID(ctx, cu.ID())
+	if err == storage.ErrNotFound {
+		return model.Quote{}, ErrChatUserNotLinked
+	} else if err != nil {
+		return model.Quote{}, fmt.Errorf("finding chat link: %w", err)
+	}
+
+	u, err := s.ur.FindByID(ctx, l.UserID)
+	if err == storage.ErrNotFound {
+		return model.Quote{}, ErrChatUserNotLinked
+	} else if err != nil {
+		return model.Quote{}, fmt.Errorf("finding user of chat link: %w", err)
+	}
+
+	q, err := ParseChatQuote(text)
+	if err != nil {
+		return model.Quote{}, err
+	}
+
+	uctx, err := s.communities.ContextWithCommunity(ctxval.ContextWithUser(ctx, u), communityID)
+	if err != nil {
+		return model.Quote{}, err
+	}
+
+	if err := s.quotes.CreateQuote(uctx, &q); err != nil {
+		return model.Quote{}, err
+	}
+
+	return q, nil
+}
@@ -0,0 +1,165 @@
+package service_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willbicks/epigram/internal/config"
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+var chatUser = service.ChatUser{
+	Platform: "slack",
+	TeamID:   "T123",
+	UserID:   "U456",
+	UserName: "jross",
+}
+
+func TestParseChatQuote(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    model.Quote
+		wantErr bool
+	}{
+		{
+			name: "em dash with context",
+			text: `"Isn't every truck a hand truck?" — Jaustin Ross (moving furniture)`,
+			want: model.Quote{Quote: "Isn't every truck a hand truck?", Quotee: "Jaustin Ross", Context: "moving furniture"},
+		},
+		{
+			name: "curly quotes without context",
+			text: `“Hello there” – Charlene`,
+			want: model.Quote{Quote: "Hello there", Quotee: "Charlene"},
+		},
+		{
+			name: "hyphens",
+			text: ` "It's a well-known fact" -- Josh `,
+			want: model.Quote{Quote: "It's a well-known fact", Quotee: "Josh"},
+		},
+		{
+			name:    "missing quotee",
+			text:    `"Hello there"`,
+			wantErr: true,
+		},
+		{
+			name:    "missing quotation marks",
+			text:    `Hello there — Charlene`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.ParseChatQuote(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChatQuote() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseChatQuote() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newChatService(t *testing.T, quoteRepo service.QuoteRepository, users ...model.User) service.Chat {
+	t.Helper()
+
+	userRepo := inmemory.NewUserRepository()
+	for _, u := range users {
+		if err := userRepo.Create(context.Background(), u); err != nil {
+			t.Fatalf("creating user %v: %v", u.ID, err)
+		}
+	}
+
+	quotes := service.NewQuoteService(quoteRepo, inmemory.NewReactionRepository(), inmemory.NewCommentRepository(),
+		inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), service.Webhook{}, discardLogger)
+	membershipRepo := inmemory.NewMembershipRepository()
+	for _, u := range users {
+		m := model.Membership{CommunityID: testCommunity.ID, UserID: u.ID, QuizPassed: true}
+		if err := membershipRepo.Create(context.Background(), m); err != nil {
+			t.Fatalf("creating membership %v: %v", u.ID, err)
+		}
+	}
+	communities, err := service.NewCommunityService([]config.Community{{ID: testCommunity.ID}}, membershipRepo,
+		inmemory.NewQuizSessionRepository(), inmemory.NewQuizQuestionRepository(),
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()))
+	if err != nil {
+		t.Fatalf("creating community service: %v", err)
+	}
+
+	chat, err := service.NewChatService(inmemory.NewChatLinkRepository(), userRepo, quotes, communities)
+	if err != nil {
+		t.Fatalf("creating chat service: %v", err)
+	}
+	return chat
+}
+
+func TestChat_LinkCode(t *testing.T) {
+	is := is.New(t)
+	chat := newChatService(t, inmemory.NewQuoteRepository())
+
+	code, err := chat.CreateLinkCode(chatUser)
+	is.NoErr(err)
+
+	got, err := chat.ParseLinkCode(code)
+	is.NoErr(err)           // valid code should parse
+	is.Equal(got, chatUser) // code should identify the chat user
+
+	payload, sig, _ := strings.Cut(code, ".")
+	_, err = chat.ParseLinkCode(payload + "x." + sig)
+	is.Equal(err, service.ErrInvalidChatLinkCode) // tampered code should be rejected
+
+	other := newChatService(t, inmemory.NewQuoteRepository())
+	_, err = other.ParseLinkCode(code)
+	is.Equal(err, service.ErrInvalidChatLinkCode) // code signed by another key should be rejected
+}
+
+func TestChat_SubmitChatQuote(t *testing.T) {
+	is := is.New(t)
+	quoteRepo := inmemory.NewQuoteRepository()
+	chat := newChatService(t, quoteRepo, submitter)
+
+	_, err := chat.SubmitChatQuote(context.Background(), chatUser, testCommunity.ID, `"Hello" — Charlene`)
+	is.Equal(err, service.ErrChatUserNotLinked) // unlinked chat user should not be able to submit quotes
+
+	code, err := chat.CreateLinkCode(chatUser)
+	is.NoErr(err)
+
+	ctxNew := ctxval.ContextWithUser(context.Background(), model.User{ID: "new"})
+	_, err = chat.LinkChatUser(ctxNew, code)
+	is.Equal(err, service.ErrNotAuthorized) // user who has not passed the quiz should not be able to link
+
+	ctxSubmitter := userContext(submitter)
+	l, err := chat.LinkChatUser(ctxSubmitter, code)
+	is.NoErr(err)                     // user should be able to link chat user
+	is.Equal(l.UserID, submitter.ID)  // link should belong to current user
+	is.Equal(l.ChatUserName, "jross") // link should record chat user name
+
+	q, err := chat.SubmitChatQuote(context.Background(), chatUser, testCommunity.ID, `"Hello" — Charlene (greeting)`)
+	is.NoErr(err)                         // linked chat user should be able to submit quotes
+	is.Equal(q.SubmitterID, submitter.ID) // quote should be submitted by linked user
+	is.Equal(q.Context, "greeting")
+
+	stored, err := quoteRepo.FindByID(context.Background(), q.ID)
+	is.NoErr(err) // quote should be stored
+	is.Equal(stored.Quotee, "Charlene")
+
+	_, err = chat.SubmitChatQuote(context.Background(), chatUser, testCommunity.ID, "Hello")
+	is.True(err != nil) // malformed quote should be rejected
+
+	ctxOther := userContext(otherUser)
+	is.Equal(chat.UnlinkChatUser(ctxOther, l.ID), service.ErrChatLinkNotFound) // other users should not be able to unlink
+	is.NoErr(chat.UnlinkChatUser(ctxSubmitter, l.ID))                          // owner should be able to unlink
+
+	_, err = chat.SubmitChatQuote(context.Background(), chatUser, testCommunity.ID, `"Hello" — Charlene`)
+	is.Equal(err, service.ErrChatUserNotLinked) // unlinked chat user should no longer be able to submit quotes
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+
+	"github.com/rs/xid"
+)
+
+// _commentMaxLength is the maximum number of characters in the text of a comment.
+const _commentMaxLength = 2000
+
+// ErrCommentNotFound is returned when a requested comment does not exist.
+var ErrCommentNotFound = Error{
+	Issues:     []string{"Comment not found."},
+	StatusCode: 404,
+}
+
+// ErrCommentNotModifiable is returned when a user attempts to edit or delete a comment which they are not permitted
+// to modify.
+var ErrCommentNotModifiable = Error{
+	Issues:     []string{"You may only edit or delete your own comments."},
+	StatusCode: 403,
+}
+
+// CommentRepository provides methods for storing and retrieving Comments.
+type CommentRepository interface {
+	Create(ctx context.Context, c model.Comment) error
+	Update(ctx context.Context, c model.Comment) error
+	Delete(ctx context.Context, id string) error
+	DeleteByQuoteID(ctx context.Context, quoteID string) error
+	FindByID(ctx context.Context, id string) (model.Comment, error)
+	// FindByQuoteID returns all Comments on the specified Quote, from oldest to newest.
+	FindByQuoteID(ctx context.Context, quoteID string) ([]model.Comment, error)
+	// ReassignUser changes the AuthorID of every Comment written by the user fromID to toID.
+	ReassignUser(ctx context.Context, fromID string, toID string) error
+}
+
+// CommentThread is a Comment and its replies, as presented to the user on the context.
+type CommentThread struct {
+	model.Comment
+	// AuthorName is the name of the user who wrote the comment, or empty if the comment has been deleted.
+	AuthorName string
+	// Modifiable is true if the user on the context may edit or delete the comment.
+	Modifiable bool
+	// Replies are the replies to the comment, from oldest to newest.
+	Replies []CommentThread
+}
+
+// Comment provides a service for commenting on Quotes.
+type Comment struct {
+	repo  CommentRepository
+	qr    QuoteRepository
+	ur    UserRepository
+	audit AuditLog
+}
+
+// NewCommentService returns a new Comment service with the provided CommentRepository, QuoteRepository used to find
+// the quotes being commented on, and UserRepository used to find the authors of comments. Modifications made by
+// admins to other users' comments are recorded using the provided AuditLog service.
+func NewCommentService(repo CommentRepository, qr QuoteRepository, ur UserRepository, audit AuditLog) Comment {
+	return Comment{
+		repo:  repo,
+		qr:    qr,
+		ur:    ur,
+		audit: audit,
+	}
+}
+
+// validateComment checks that the user provided fields of a Comment are valid, and returns an Error containing any
+// issues.
+func validateComment(c model.Comment) Error {
+	err := Error{
+		StatusCode: 400,
+	}
+	if c.Text == "" {
+		err.addIssue("Comment must not be blank.")
+	}
+	if utf8.RuneCountInString(c.Text) > _commentMaxLength {
+		err.addIssue(fmt.Sprintf("Comment must be at most %d characters.", _commentMaxLength))
+	}
+	return err
+}
+
+// CreateComment creates a new Comment on the Quote specified by c.QuoteID, in reply to the Comment specified by
+// c.ParentID if set, and sets its ID, Created, and AuthorID fields.
+func (s Comment) CreateComment(ctx context.Context, c *model.Comment) error {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return err
+	}
+
+	if err := validateComment(*c); err.HasIssues() {
+		return err
+	}
+
+	if _, err := findQuote(ctx, s.qr, c.QuoteID); err != nil {
+		return err
+	}
+
+	if c.ParentID != "" {
+		parent, err := s.repo.FindByID(ctx, c.ParentID)
+		if err == storage.ErrNotFound || (err == nil && parent.QuoteID != c.QuoteID) {
+			return Error{
+				Issues:     []string{"The comment being replied to does not exist."},
+				StatusCode: 400,
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+
+	c.ID = xid.New().String()
+	c.Created = time.Now()
+	c.Edited = time.Time{}
+	c.Deleted = false
+	c.AuthorID = ctxval.UserFromContext(ctx).ID
+
+	return s.repo.Create(ctx, *c)
+}
+
+// CanModifyComment returns true if the user on the context may edit or delete the provided Comment. Admins may
+// modify any comment, while other users may only modify their own.
+func (s Comment) CanModifyComment(ctx context.Context, c model.Comment) bool {
+	if c.Deleted {
+		return false
+	}
+
+	return isAdmin(ctx) || (isAuthorized(ctx) && ctxval.UserFromContext(ctx).ID == c.AuthorID)
+}
+
+// findModifiableComment returns the Comment with the specified ID, provided that the user on the context may modify
+// it.
+func (s Comment) findModifiableComment(ctx context.Context, id string) (model.Comment, error) {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return model.Comment{}, err
+	}
+
+	c, err := s.repo.FindByID(ctx, id)
+	if err == storage.ErrNotFound || (err == nil && c.Deleted) {
+		return model.Comment{}, ErrCommentNotFound
+	} else if err != nil {
+		return model.Comment{}, err
+	}
+
+	// comments on quotes of other communities are hidden from the user
+	if _, err := findQuote(ctx, s.qr, c.QuoteID); errors.As(err, &Error{}) {
+		return model.Comment{}, ErrCommentNotFound
+	} else if err != nil {
+		return model.Comment{}, err
+	}
+
+	if !s.CanModifyComment(ctx, c) {
+		return model.Comment{}, ErrCommentNotModifiable
+	}
+
+	return c, nil
+}
+
+// EditComment updates the Text of an existing Comment identified by c.ID. All other fields are preserved, and c is
+// updated to reflect the stored Comment.
+func (s Comment) EditComment(ctx context.Context, c *model.Comment) error {
+	existing, err := s.findModifiableComment(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateComment(*c); err.HasIssues() {
+		return err
+	}
+
+	existing.Text = c.Text
+	existing.Edited = time.Now()
+	*c = existing
+
+	if err := s.repo.Update(ctx, existing); err != nil {
+		return err
+	}
+
+	return s.recordModeration(ctx, model.AuditEditComment, existing)
+}
+
+// DeleteComment deletes the Comment with the specified ID, and returns the ID of the Quote it was on. Comments with
+// replies are marked as deleted rather than removed, so that their replies are preserved, and deleted comments are
+// removed once their last reply is.
+func (s Comment) DeleteComment(ctx context.Context, id string) (string, error) {
+	c, err := s.findModifiableComment(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	comments, err := s.repo.FindByQuoteID(ctx, c.QuoteID)
+	if err != nil {
+		return "", err
+	}
+
+	replies := make(map[string]int)
+	byID := make(map[string]model.Comment, len(comments))
+	for _, other := range comments {
+		replies[other.ParentID]++
+		byID[other.ID] = other
+	}
+
+	if replies[c.ID] > 0 {
+		deleted := c
+		deleted.Text = ""
+		deleted.Deleted = true
+		if err := s.repo.Update(ctx, deleted); err != nil {
+			return "", err
+		}
+	} else {
+		// remove the comment, and any deleted comments which no longer have replies as a result
+		for del := c; ; {
+			if err := s.repo.Delete(ctx, del.ID); err != nil {
+				return "", err
+			}
+			replies[del.ParentID]--
+
+			parent, ok := byID[del.ParentID]
+			if !ok || !parent.Deleted || replies[parent.ID] > 0 {
+				break
+			}
+			del = parent
+		}
+	}
+
+	return c.QuoteID, s.recordModeration(ctx, model.AuditDeleteComment, c)
+}
+
+// recordModeration records an action in the audit log if the comment was modified by someone other than its author.
+func (s Comment) recordModeration(ctx context.Context, action model.AuditAction, c model.Comment) error {
+	if ctxval.UserFromContext(ctx).ID == c.AuthorID {
+		return nil
+	}
+
+	return s.audit.record(ctx, action, c.ID, c.Text)
+}
+
+// GetComments returns the threads of Comments on the Quote with the specified ID, from oldest to newest.
+func (s Comment) GetComments(ctx context.Context, quoteID string) ([]CommentThread, error) {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return nil, err
+	}
+
+	if _, err := findQuote(ctx, s.qr, quoteID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.repo.FindByQuoteID(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	children := make(map[string][]model.Comment)
+	for _, c := range comments {
+		children[c.ParentID] = append(children[c.ParentID], c)
+
+		if _, ok := names[c.AuthorID]; ok || c.Deleted {
+			continue
+		}
+		u, err := s.ur.FindByID(ctx, c.AuthorID)
+		if err == storage.ErrNotFound {
+			names[c.AuthorID] = "Unknown user"
+		} else if err != nil {
+			return nil, fmt.Errorf("finding author of comment: %w", err)
+		} else {
+			names[c.AuthorID] = u.Name
+		}
+	}
+
+	var build func(parentID string) []CommentThread
+	build = func(parentID string) []CommentThread {
+		threads := make([]CommentThread, 0, len(children[parentID]))
+		for _, c := range children[parentID] {
+			t := CommentThread{
+				Comment:    c,
+				Modifiable: s.CanModifyComment(ctx, c),
+				Replies:    build(c.ID),
+			}
+			if !c.Deleted {
+				t.AuthorName = names[c.AuthorID]
+			}
+			threads = append(threads, t)
+		}
+		return threads
+	}
+
+	return build(""), nil
+}
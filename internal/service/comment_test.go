@@ -0,0 +1,170 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+// newCommentTest returns a Comment service, the AuditLog service it records moderation to, and the ID of a quote
+// which may be commented on.
+func newCommentTest(t *testing.T) (service.Comment, service.AuditLog, string) {
+	t.Helper()
+	is := is.New(t)
+
+	quoteRepo := inmemory.NewQuoteRepository()
+	userRepo := inmemory.NewUserRepository()
+	for _, u := range []model.User{submitter, otherUser, adminUser} {
+		u.Name = "Name of " + u.ID
+		is.NoErr(userRepo.Create(context.Background(), u))
+	}
+
+	q := model.Quote{ID: "q1", CommunityID: testCommunity.ID, SubmitterID: submitter.ID, Quotee: "AJBR", Quote: "Quote", Created: time.Now()}
+	is.NoErr(quoteRepo.Create(context.Background(), q))
+
+	audit := service.NewAuditLogService(inmemory.NewAuditLogRepository())
+	return service.NewCommentService(inmemory.NewCommentRepository(), quoteRepo, userRepo, audit), audit, q.ID
+}
+
+func TestComment_CreateComment(t *testing.T) {
+	is := is.New(t)
+	comments, _, quoteID := newCommentTest(t)
+
+	ctxSubmitter := userContext(submitter)
+	c := model.Comment{QuoteID: quoteID, Text: "I was there."}
+	is.NoErr(comments.CreateComment(ctxSubmitter, &c)) // users should be able to comment
+	is.True(c.ID != "")                                // comment should be assigned an ID
+	is.Equal(c.AuthorID, submitter.ID)                 // comment should be attributed to its author
+
+	ctxOther := userContext(otherUser)
+	reply := model.Comment{QuoteID: quoteID, ParentID: c.ID, Text: "So was I."}
+	is.NoErr(comments.CreateComment(ctxOther, &reply)) // users should be able to reply to comments
+
+	blank := model.Comment{QuoteID: quoteID}
+	is.True(comments.CreateComment(ctxSubmitter, &blank) != nil) // blank comments should be rejected
+
+	long := model.Comment{QuoteID: quoteID, Text: strings.Repeat("a", 2001)}
+	is.True(comments.CreateComment(ctxSubmitter, &long) != nil) // overly long comments should be rejected
+
+	missingQuote := model.Comment{QuoteID: "missing", Text: "Hello"}
+	is.Equal(comments.CreateComment(ctxSubmitter, &missingQuote), service.ErrQuoteNotFound) // quote must exist
+
+	missingParent := model.Comment{QuoteID: quoteID, ParentID: "missing", Text: "Hello"}
+	is.True(comments.CreateComment(ctxSubmitter, &missingParent) != nil) // parent must exist
+
+	ctxVisitor := ctxval.ContextWithUser(context.Background(), model.User{ID: "visitor"})
+	visitor := model.Comment{QuoteID: quoteID, Text: "Hello"}
+	is.True(comments.CreateComment(ctxVisitor, &visitor) != nil) // users who have not passed the quiz should not comment
+
+	threads, err := comments.GetComments(ctxSubmitter, quoteID)
+	is.NoErr(err)
+	is.Equal(len(threads), 1)                                   // reply should not be a top level thread
+	is.Equal(threads[0].AuthorName, "Name of "+submitter.ID)    // thread should include the author's name
+	is.True(threads[0].Modifiable)                              // author should be able to modify their comment
+	is.Equal(len(threads[0].Replies), 1)                        // reply should be nested under its parent
+	is.Equal(threads[0].Replies[0].ID, reply.ID)                // nested reply should be the reply
+	is.True(!threads[0].Replies[0].Modifiable)                  // author should not be able to modify others' replies
+	is.Equal(threads[0].Replies[0].AuthorName, "Name of other") // reply should include its author's name
+}
+
+func TestComment_EditComment(t *testing.T) {
+	is := is.New(t)
+	comments, audit, quoteID := newCommentTest(t)
+
+	ctxSubmitter := userContext(submitter)
+	c := model.Comment{QuoteID: quoteID, Text: "I was there."}
+	is.NoErr(comments.CreateComment(ctxSubmitter, &c))
+
+	edit := model.Comment{ID: c.ID, Text: "I was not there."}
+	is.NoErr(comments.EditComment(ctxSubmitter, &edit)) // author should be able to edit their comment
+	is.Equal(edit.AuthorID, submitter.ID)               // edit should preserve author
+	is.True(!edit.Edited.IsZero())                      // edit should be marked as edited
+
+	ctxOther := userContext(otherUser)
+	is.Equal(comments.EditComment(ctxOther, &edit), service.ErrCommentNotModifiable) // others should not edit
+
+	ctxAdmin := userContext(adminUser)
+	moderated := model.Comment{ID: c.ID, Text: "[removed]"}
+	is.NoErr(comments.EditComment(ctxAdmin, &moderated)) // admins should be able to edit any comment
+
+	entries, err := audit.QueryAuditLog(ctxAdmin, service.AuditLogQuery{})
+	is.NoErr(err)
+	is.Equal(len(entries), 1)                           // only moderation by others should be recorded
+	is.Equal(entries[0].Action, model.AuditEditComment) // entry should record the edit
+	is.Equal(entries[0].TargetID, c.ID)                 // entry should record the edited comment
+}
+
+func TestComment_DeleteComment(t *testing.T) {
+	is := is.New(t)
+	comments, audit, quoteID := newCommentTest(t)
+
+	ctxSubmitter := userContext(submitter)
+	ctxOther := userContext(otherUser)
+	ctxAdmin := userContext(adminUser)
+
+	parent := model.Comment{QuoteID: quoteID, Text: "I was there."}
+	is.NoErr(comments.CreateComment(ctxSubmitter, &parent))
+	reply := model.Comment{QuoteID: quoteID, ParentID: parent.ID, Text: "So was I."}
+	is.NoErr(comments.CreateComment(ctxOther, &reply))
+
+	_, err := comments.DeleteComment(ctxOther, parent.ID)
+	is.Equal(err, service.ErrCommentNotModifiable) // others should not delete comments
+
+	gotQuoteID, err := comments.DeleteComment(ctxSubmitter, parent.ID)
+	is.NoErr(err)                 // author should be able to delete their comment
+	is.Equal(gotQuoteID, quoteID) // deletion should return the quote commented on
+
+	threads, err := comments.GetComments(ctxSubmitter, quoteID)
+	is.NoErr(err)
+	is.Equal(len(threads), 1)            // comment with replies should be retained
+	is.True(threads[0].Deleted)          // retained comment should be marked as deleted
+	is.Equal(threads[0].Text, "")        // retained comment should not include its text
+	is.Equal(threads[0].AuthorName, "")  // retained comment should not include its author
+	is.True(!threads[0].Modifiable)      // deleted comment should not be modifiable
+	is.Equal(len(threads[0].Replies), 1) // replies should be preserved
+
+	_, err = comments.DeleteComment(ctxSubmitter, parent.ID)
+	is.Equal(err, service.ErrCommentNotFound) // deleted comment should not be deleted again
+
+	_, err = comments.DeleteComment(ctxAdmin, reply.ID)
+	is.NoErr(err) // admins should be able to delete any comment
+
+	threads, err = comments.GetComments(ctxSubmitter, quoteID)
+	is.NoErr(err)
+	is.Equal(len(threads), 0) // deleted comment should be removed with its last reply
+
+	entries, err := audit.QueryAuditLog(ctxAdmin, service.AuditLogQuery{})
+	is.NoErr(err)
+	is.Equal(len(entries), 1)                             // only moderation by others should be recorded
+	is.Equal(entries[0].Action, model.AuditDeleteComment) // entry should record the deletion
+	is.Equal(entries[0].TargetID, reply.ID)               // entry should record the deleted comment
+}
+
+func TestQuote_DeleteQuoteComments(t *testing.T) {
+	is := is.New(t)
+
+	quoteRepo := inmemory.NewQuoteRepository()
+	commentRepo := inmemory.NewCommentRepository()
+	audit := service.NewAuditLogService(inmemory.NewAuditLogRepository())
+	quoteService := service.NewQuoteService(quoteRepo, inmemory.NewReactionRepository(), commentRepo,
+		inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour, audit, service.Webhook{}, discardLogger)
+	comments := service.NewCommentService(commentRepo, quoteRepo, inmemory.NewUserRepository(), audit)
+
+	ctxSubmitter := userContext(submitter)
+	q := model.Quote{Quotee: "AJBR", Quote: "Quote"}
+	is.NoErr(quoteService.CreateQuote(ctxSubmitter, &q))
+	c := model.Comment{QuoteID: q.ID, Text: "I was there."}
+	is.NoErr(comments.CreateComment(ctxSubmitter, &c))
+
+	is.NoErr(quoteService.DeleteQuote(ctxSubmitter, q.ID))
+	_, err := commentRepo.FindByID(context.Background(), c.ID)
+	is.True(err != nil) // deleting a quote should delete its comments
+}
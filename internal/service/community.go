@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/willbicks/epigram/internal/config"
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// ErrCommunityNotFound is returned when a requested community is not hosted by the server.
+var ErrCommunityNotFound = Error{
+	Issues:     []string{"Community not found."},
+	StatusCode: 404,
+}
+
+// communityIDRegexp matches valid community IDs.
+var communityIDRegexp = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// MembershipRepository provides methods for storing, manipulating, and retrieving Memberships.
+type MembershipRepository interface {
+	Create(ctx context.Context, m model.Membership) error
+	Update(ctx context.Context, m model.Membership) error
+	Delete(ctx context.Context, communityID string, userID string) error
+	Find(ctx context.Context, communityID string, userID string) (model.Membership, error)
+	// FindByUserID returns the memberships of the specified user, in no particular order.
+	FindByUserID(ctx context.Context, userID string) ([]model.Membership, error)
+	// FindByCommunityID returns the memberships of the specified community, in no particular order.
+	FindByCommunityID(ctx context.Context, communityID string) ([]model.Membership, error)
+}
+
+// CommunityMembership is a community, and the current user's membership of it (which is empty if they are not a
+// member).
+type CommunityMembership struct {
+	model.Community
+	Membership model.Membership
+}
+
+// Community is a service which provides the communities hosted by the server, and the entry quiz of each.
+type Community struct {
+	communities []model.Community
+	quizzes     map[string]EntryQuiz
+	repo        MembershipRepository
+}
+
+// NewCommunityService returns a new Community service hosting the provided communities, the first of which is the
+// default community, storing their members in the provided MembershipRepository, and attempts at their entry quizzes
+// in the provided QuizSessionRepository. Their entry quiz questions are stored in the provided QuizQuestionRepository,
+// and changes to them are recorded with the provided AuditLog service. An error is returned if no communities are
+// provided, if any have a blank, invalid, or duplicate ID, an unknown gate, or if any entry quiz is invalid.
+func NewCommunityService(communities []config.Community, repo MembershipRepository, quizRepo QuizSessionRepository,
+	questionRepo QuizQuestionRepository, audit AuditLog) (Community, error) {
+	if len(communities) == 0 {
+		return Community{}, fmt.Errorf("at least one community must be configured")
+	}
+
+	s := Community{
+		communities: make([]model.Community, 0, len(communities)),
+		quizzes:     make(map[string]EntryQuiz, len(communities)),
+		repo:        repo,
+	}
+	for _, c := range communities {
+		if !communityIDRegexp.MatchString(c.ID) {
+			return Community{}, fmt.Errorf("community ID %q must consist of only lowercase letters, numbers, dashes, and underscores", c.ID)
+		}
+		if _, ok := s.quizzes[c.ID]; ok {
+			return Community{}, fmt.Errorf("community ID %q is used more than once", c.ID)
+		}
+
+		gate := model.GateQuiz
+		if c.Gate != "" {
+			gate = model.GateMode(strings.ToLower(strings.TrimSpace(c.Gate)))
+		}
+		if !slices.Contains(model.GateModes, gate) {
+			return Community{}, fmt.Errorf("community %q: unknown gate %q, must be quiz, approval, quiz-then-approval, or open", c.ID, c.Gate)
+		}
+
+		s.communities = append(s.communities, model.Community{
+			ID:          c.ID,
+			Title:       c.Title,
+			Description: c.Description,
+			Gate:        gate,
+		})
+
+		quiz, err := NewEntryQuizService(c.ID, c.EntryQuestions, c.QuizSize, quizRepo, questionRepo, audit)
+		if err != nil {
+			return Community{}, fmt.Errorf("community %q: %w", c.ID, err)
+		}
+		s.quizzes[c.ID] = quiz
+	}
+
+	return s, nil
+}
+
+// SeedQuizQuestions stores the configured entry quiz questions of each community which has none, such as when the
+// server first starts, and returns the number of questions stored.
+func (s Community) SeedQuizQuestions(ctx context.Context) (int, error) {
+	seeded := 0
+	for _, c := range s.communities {
+		n, err := s.quizzes[c.ID].Seed(ctx)
+		if err != nil {
+			return seeded, fmt.Errorf("community %q: %w", c.ID, err)
+		}
+		seeded += n
+	}
+	return seeded, nil
+}
+
+// GetCommunities returns every community hosted by the server, beginning with the default community.
+func (s Community) GetCommunities() []model.Community {
+	return s.communities
+}
+
+// GetCommunity returns the community with the specified ID.
+func (s Community) GetCommunity(id string) (model.Community, error) {
+	for _, c := range s.communities {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return model.Community{}, ErrCommunityNotFound
+}
+
+// DefaultCommunity returns the default community.
+func (s Community) DefaultCommunity() model.Community {
+	return s.communities[0]
+}
+
+// Quiz returns the entry quiz of the current community.
+func (s Community) Quiz(ctx context.Context) EntryQuiz {
+	return s.quizzes[ctxval.CommunityFromContext(ctx).ID]
+}
+
+// ContextWithCommunity returns a copy of the provided context in which the community with the specified ID is the
+// current community, along with the membership of the user on the context (if any) in it.
+func (s Community) ContextWithCommunity(ctx context.Context, id string) (context.Context, error) {
+	c, err := s.GetCommunity(id)
+	if err != nil {
+		return ctx, err
+	}
+
+	m := model.Membership{
+		CommunityID: c.ID,
+		UserID:      ctxval.UserFromContext(ctx).ID,
+	}
+	if m.UserID != "" {
+		found, err := s.repo.Find(ctx, c.ID, m.UserID)
+		if err == nil {
+			m = found
+		} else if err != storage.ErrNotFound {
+			return ctx, fmt.Errorf("finding membership: %w", err)
+		}
+	}
+
+	ctx = ctxval.ContextWithCommunity(ctx, c)
+	return ctxval.ContextWithMembership(ctx, m), nil
+}
+
+// GetCommunityMemberships returns every community hosted by the server, along with the membership of the user on the
+// context in each.
+func (s Community) GetCommunityMemberships(ctx context.Context) ([]CommunityMembership, error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.repo.FindByUserID(ctx, ctxval.UserFromContext(ctx).ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byCommunity := make(map[string]model.Membership, len(memberships))
+	for _, m := range memberships {
+		byCommunity[m.CommunityID] = m
+	}
+
+	cms := make([]CommunityMembership, len(s.communities))
+	for i, c := range s.communities {
+		cms[i] = CommunityMembership{
+			Community:  c,
+			Membership: byCommunity[c.ID],
+		}
+	}
+	return cms, nil
+}
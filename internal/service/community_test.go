@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/willbicks/epigram/internal/config"
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+func TestNewCommunityService(t *testing.T) {
+	tests := []struct {
+		name        string
+		communities []config.Community
+		wantErr     bool
+	}{
+		{
+			name:        "valid",
+			communities: []config.Community{{ID: "default"}, {ID: "book-club_2"}},
+		},
+		{
+			name:    "none",
+			wantErr: true,
+		},
+		{
+			name:        "blank ID",
+			communities: []config.Community{{ID: ""}},
+			wantErr:     true,
+		},
+		{
+			name:        "invalid ID",
+			communities: []config.Community{{ID: "Book Club"}},
+			wantErr:     true,
+		},
+		{
+			name:        "gates",
+			communities: []config.Community{{ID: "default", Gate: "approval"}, {ID: "open", Gate: "Open"}},
+		},
+		{
+			name:        "unknown gate",
+			communities: []config.Community{{ID: "default", Gate: "invite"}},
+			wantErr:     true,
+		},
+		{
+			name:        "duplicate ID",
+			communities: []config.Community{{ID: "default"}, {ID: "default"}},
+			wantErr:     true,
+		},
+		{
+			name: "invalid quiz",
+			communities: []config.Community{{ID: "default", EntryQuestions: []config.EntryQuestion{
+				{Question: "What is the best color?", Answer: "purple", Normalize: []string{"vowels"}},
+			}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.NewCommunityService(tt.communities, inmemory.NewMembershipRepository(),
+				inmemory.NewQuizSessionRepository(), inmemory.NewQuizQuestionRepository(),
+				service.NewAuditLogService(inmemory.NewAuditLogRepository()))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCommunityService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommunity_ContextWithCommunity(t *testing.T) {
+	is := is.New(t)
+
+	repo := inmemory.NewMembershipRepository()
+	m := model.Membership{CommunityID: "other", UserID: submitter.ID, QuizPassed: true}
+	is.NoErr(repo.Create(context.Background(), m))
+
+	communities, err := service.NewCommunityService([]config.Community{
+		{ID: model.DefaultCommunityID, Title: "Default"},
+		{ID: "other", Title: "Other"},
+	}, repo, inmemory.NewQuizSessionRepository(), inmemory.NewQuizQuestionRepository(),
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()))
+	is.NoErr(err)
+	is.Equal(communities.DefaultCommunity().ID, model.DefaultCommunityID) // first community should be the default
+
+	ctx, err := communities.ContextWithCommunity(ctxval.ContextWithUser(context.Background(), submitter), "other")
+	is.NoErr(err)
+	is.Equal(ctxval.CommunityFromContext(ctx).Title, "Other")       // community should be set on the context
+	is.Equal(ctxval.CommunityFromContext(ctx).Gate, model.GateQuiz) // communities should be gated by the quiz by default
+	is.Equal(ctxval.MembershipFromContext(ctx), m)                  // user's membership should be set on the context
+
+	ctx, err = communities.ContextWithCommunity(ctxval.ContextWithUser(context.Background(), submitter), model.DefaultCommunityID)
+	is.NoErr(err)
+	is.True(!ctxval.MembershipFromContext(ctx).IsAuthorized(model.GateQuiz)) // user should not be authorized in communities they have not joined
+
+	_, err = communities.ContextWithCommunity(context.Background(), "missing")
+	is.Equal(err, service.ErrCommunityNotFound) // unknown communities should not be found
+
+	cms, err := communities.GetCommunityMemberships(ctxval.ContextWithUser(context.Background(), submitter))
+	is.NoErr(err)
+	is.Equal(len(cms), 2)                  // every community should be listed
+	is.True(!cms[0].Membership.QuizPassed) // user should not be a member of the default community
+	is.True(cms[1].Membership.QuizPassed)  // user should be a member of the other community
+
+	_, err = communities.GetCommunityMemberships(context.Background())
+	is.Equal(err, service.ErrNotAuthenticated) // anonymous users have no memberships
+}
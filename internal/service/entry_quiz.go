@@ -2,59 +2,675 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/willbicks/epigram/internal/config"
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
 )
 
+// ErrQuizNotStarted is returned when answers are submitted for an attempt at the entry quiz which the user has not
+// started, or which has already been submitted.
+var ErrQuizNotStarted = Error{
+	Issues:     []string{"This attempt at the quiz has expired, please answer the questions below."},
+	StatusCode: 400,
+}
+
+// ErrQuizLockedOut is returned when a user attempts the entry quiz after using all of their attempts.
+var ErrQuizLockedOut = Error{
+	Issues:     []string{"Too many failed quiz attempts, you may request access from an administrator below."},
+	StatusCode: 403,
+}
+
+// ErrQuizCooldown is returned when a user attempts the entry quiz before waiting the cooldown since their last
+// attempt.
+var ErrQuizCooldown = Error{
+	Issues:     []string{"Please wait before attempting the quiz again."},
+	StatusCode: 429,
+}
+
+// ErrQuizNotRequired is returned when a user attempts the entry quiz of a community whose gate does not require it.
+var ErrQuizNotRequired = Error{
+	Issues:     []string{"This community does not have an entry quiz."},
+	StatusCode: 400,
+}
+
+// ErrQuizUnavailable is returned when a user attempts the entry quiz of a community which has no enabled questions,
+// rather than allowing them to pass without answering any.
+var ErrQuizUnavailable = Error{
+	Issues:     []string{"The entry quiz has no questions, please contact an administrator."},
+	StatusCode: 503,
+}
+
+// QuizPolicy limits the attempts users may make at the entry quiz of a community.
+type QuizPolicy struct {
+	// MaxAttempts is the number of times a user may submit the quiz without passing before they are locked out. If
+	// zero, the number of attempts is unlimited.
+	MaxAttempts int
+	// Cooldown is the amount of time a user must wait between attempts.
+	Cooldown time.Duration
+	// RecordAnswers enables recording the responses submitted in each attempt in the user's quiz history.
+	RecordAnswers bool
+}
+
+// status returns whether the provided member may attempt the quiz.
+func (p QuizPolicy) status(m model.Membership) QuizStatus {
+	st := QuizStatus{
+		LockedOut: !m.QuizPassed && p.MaxAttempts > 0 && int(m.QuizAttempts) >= p.MaxAttempts,
+	}
+	if p.Cooldown > 0 && !m.LastQuizAttempt.IsZero() {
+		st.NextAttempt = m.LastQuizAttempt.Add(p.Cooldown)
+	}
+	return st
+}
+
+// QuizStatus describes whether a user may attempt the entry quiz of a community.
+type QuizStatus struct {
+	// LockedOut is true if the user has used all of their attempts without passing, and must request access instead.
+	LockedOut bool
+	// NextAttempt is the time after which the user may next attempt the quiz, which may be in the past.
+	NextAttempt time.Time
+}
+
+// Wait returns the amount of time the user must wait before attempting the quiz, rounded up to the nearest second.
+func (st QuizStatus) Wait() time.Duration {
+	wait := time.Until(st.NextAttempt)
+	if wait <= 0 {
+		return 0
+	}
+	return wait.Truncate(time.Second) + time.Second
+}
+
+// verify returns ErrQuizLockedOut or ErrQuizCooldown if the user may not currently attempt the quiz.
+func (st QuizStatus) verify() error {
+	if st.LockedOut {
+		return ErrQuizLockedOut
+	}
+	if st.Wait() > 0 {
+		return ErrQuizCooldown
+	}
+	return nil
+}
+
+// QuizSessionRepository provides methods for storing and retrieving QuizSessions, of which each user has at most one
+// per community.
+type QuizSessionRepository interface {
+	Create(ctx context.Context, s model.QuizSession) error
+	Delete(ctx context.Context, communityID string, userID string) error
+	Find(ctx context.Context, communityID string, userID string) (model.QuizSession, error)
+	// ReassignUser changes the UserID of every QuizSession of the user fromID to toID. Sessions in communities in which
+	// toID already has a QuizSession are removed instead.
+	ReassignUser(ctx context.Context, fromID string, toID string) error
+}
+
+// QuizAttemptRepository provides methods for storing and retrieving QuizAttempts.
+type QuizAttemptRepository interface {
+	Create(ctx context.Context, a model.QuizAttempt) error
+	// FindByUserID returns the attempts of the specified user at the entry quiz of the specified community, from
+	// newest to oldest.
+	FindByUserID(ctx context.Context, communityID string, userID string) ([]model.QuizAttempt, error)
+	// ReassignUser changes the UserID of every QuizAttempt made by the user fromID to toID.
+	ReassignUser(ctx context.Context, fromID string, toID string) error
+}
+
+// QuizResult is the outcome of a submitted attempt at the entry quiz.
+type QuizResult struct {
+	// QuestionIDs are the questions presented in the attempt, of which WrongQuestionIDs were answered incorrectly.
+	QuestionIDs      []int
+	WrongQuestionIDs []int
+	// Answers are the responses submitted to each of the questions by ID.
+	Answers map[int]string
+}
+
+// Passed returns true if every question of the attempt was answered correctly.
+func (r QuizResult) Passed() bool {
+	return len(r.WrongQuestionIDs) == 0
+}
+
+// QuizQuestionRepository provides methods for storing, manipulating, and retrieving the QuizQuestions of each
+// community.
+type QuizQuestionRepository interface {
+	Create(ctx context.Context, q model.QuizQuestion) error
+	Update(ctx context.Context, q model.QuizQuestion) error
+	Find(ctx context.Context, communityID string, id int) (model.QuizQuestion, error)
+	// FindByCommunityID returns the questions of the specified community, ordered by their position.
+	FindByCommunityID(ctx context.Context, communityID string) ([]model.QuizQuestion, error)
+}
+
+// ErrQuestionNotFound is returned when a requested quiz question does not exist.
+var ErrQuestionNotFound = Error{
+	Issues:     []string{"Question not found."},
+	StatusCode: 404,
+}
+
 // QuizQuestion is a crossword style question presented to the user to verify them before
 // gaining access to the http.
 type QuizQuestion struct {
 	ID int
-	// Length contains the number of characters in the Answer
+	// Length contains the number of characters in the answers, or zero if it varies.
 	Length   int
 	Question string
-	Answer   string
+	// Answers are the accepted responses to the question.
+	Answers   []string
+	normalize normalization
+}
+
+// newQuizQuestion returns the QuizQuestion presenting the provided stored question, or an error if its normalization
+// is invalid.
+func newQuizQuestion(q model.QuizQuestion) (QuizQuestion, error) {
+	n, err := parseNormalization(q.Normalize)
+	if err != nil {
+		return QuizQuestion{}, err
+	}
+
+	return QuizQuestion{
+		ID:        q.ID,
+		Length:    answerLength(q.Answers, n),
+		Question:  q.Question,
+		Answers:   q.Answers,
+		normalize: n,
+	}, nil
+}
+
+// accepts returns true if the provided response matches any of the question's answers.
+func (q QuizQuestion) accepts(response string) bool {
+	response = q.normalize.apply(response)
+	for _, a := range q.Answers {
+		if strings.EqualFold(q.normalize.apply(a), response) {
+			return true
+		}
+	}
+	return false
 }
 
-// EntryQuiz contains a series of QuizQuestions new users must correctly answer before
-// gaining access to the application.
+// EntryQuiz draws a random selection of the enabled questions of a community for each attempt new users make at
+// gaining access to it, and allows its admins to manage those questions.
 type EntryQuiz struct {
-	Questions []QuizQuestion
+	communityID string
+	// Size is the number of questions drawn for each attempt, or zero if every enabled question is asked.
+	Size int
+	// seed are the questions stored when the community has none, such as when the server first starts.
+	seed      []model.QuizQuestion
+	repo      QuizSessionRepository
+	questions QuizQuestionRepository
+	audit     AuditLog
 }
 
-// NewEntryQuizService creates and initializes an EntryQuizService.
-func NewEntryQuizService(qs []config.EntryQuestion) EntryQuiz {
+// NewEntryQuizService creates and initializes an EntryQuizService for the specified community, which draws size
+// questions from those stored in the provided QuizQuestionRepository for each attempt (or all of them, if size is
+// zero), and stores attempts in progress in the provided QuizSessionRepository. The provided configured questions are
+// stored by Seed if the community has none, and changes made by admins are recorded with the provided AuditLog
+// service.
+func NewEntryQuizService(communityID string, qs []config.EntryQuestion, size int, repo QuizSessionRepository,
+	questions QuizQuestionRepository, audit AuditLog) (EntryQuiz, error) {
+	if size < 0 {
+		return EntryQuiz{}, fmt.Errorf("quiz size must not be negative")
+	}
+
 	quiz := EntryQuiz{
-		Questions: make([]QuizQuestion, len(qs)),
+		communityID: communityID,
+		Size:        size,
+		seed:        make([]model.QuizQuestion, len(qs)),
+		repo:        repo,
+		questions:   questions,
+		audit:       audit,
 	}
 
 	for i, q := range qs {
-		quiz.Questions[i] = QuizQuestion{
-			ID:       i,
-			Length:   len(q.Answer),
-			Question: q.Question,
-			Answer:   q.Answer,
+		seed := model.QuizQuestion{
+			CommunityID: communityID,
+			ID:          i,
+			Question:    q.Question,
+			Answers:     append([]string{q.Answer}, q.Answers...),
+			Normalize:   q.Normalize,
+			Position:    i,
+			Enabled:     true,
+		}
+		if err := cleanQuestion(&seed); err != nil {
+			return EntryQuiz{}, fmt.Errorf("entry question %q: %w", q.Question, err)
+		}
+		quiz.seed[i] = seed
+	}
+
+	return quiz, nil
+}
+
+// Seed stores the configured questions of the quiz if the community has no questions, and returns the number of
+// questions stored.
+func (eq EntryQuiz) Seed(ctx context.Context) (int, error) {
+	existing, err := eq.questions.FindByCommunityID(ctx, eq.communityID)
+	if err != nil {
+		return 0, fmt.Errorf("finding quiz questions: %w", err)
+	}
+	if len(existing) > 0 {
+		return 0, nil
+	}
+
+	for _, q := range eq.seed {
+		if err := eq.questions.Create(ctx, q); err != nil {
+			return 0, fmt.Errorf("creating quiz question: %w", err)
+		}
+	}
+	return len(eq.seed), nil
+}
+
+// cleanQuestion trims the provided question, removes its blank answers, and lowercases its normalization options,
+// returning a service Error if it has no question or answer, or an unknown normalization option.
+func cleanQuestion(q *model.QuizQuestion) error {
+	var serr Error
+
+	q.Question = strings.TrimSpace(q.Question)
+	if q.Question == "" {
+		serr.addIssue("Question must not be empty.")
+	}
+
+	answers := []string{}
+	for _, a := range q.Answers {
+		if a = strings.TrimSpace(a); a != "" {
+			answers = append(answers, a)
+		}
+	}
+	q.Answers = answers
+	if len(q.Answers) == 0 {
+		serr.addIssue("At least one answer is required.")
+	}
+
+	normalize := []string{}
+	for _, o := range q.Normalize {
+		o = strings.ToLower(strings.TrimSpace(o))
+		if _, err := parseNormalization([]string{o}); err != nil {
+			serr.addIssue(fmt.Sprintf("Unknown normalization %q, must be punctuation, whitespace, or diacritics.", o))
 		}
+		normalize = append(normalize, o)
 	}
+	q.Normalize = normalize
 
-	return quiz
+	if serr.HasIssues() {
+		serr.StatusCode = 400
+		return serr
+	}
+	return nil
+}
+
+// answerLength returns the number of characters in each of the provided answers, or zero if they differ in length,
+// or if the normalization allows responses of a different length.
+func answerLength(answers []string, n normalization) int {
+	if n.punctuation || n.whitespace || len(answers) == 0 {
+		return 0
+	}
+
+	length := utf8.RuneCountInString(answers[0])
+	for _, a := range answers[1:] {
+		if utf8.RuneCountInString(a) != length {
+			return 0
+		}
+	}
+	return length
+}
+
+// enabledQuestions returns the enabled questions of the quiz, in order, as they currently stand.
+func (eq EntryQuiz) enabledQuestions(ctx context.Context) ([]QuizQuestion, error) {
+	stored, err := eq.questions.FindByCommunityID(ctx, eq.communityID)
+	if err != nil {
+		return nil, fmt.Errorf("finding quiz questions: %w", err)
+	}
+
+	qs := make([]QuizQuestion, 0, len(stored))
+	for _, q := range stored {
+		if !q.Enabled {
+			continue
+		}
+		pq, err := newQuizQuestion(q)
+		if err != nil {
+			return nil, fmt.Errorf("quiz question %d: %w", q.ID, err)
+		}
+		qs = append(qs, pq)
+	}
+	return qs, nil
 }
 
-// VerifyAnswers accepts a map of question IDs and string responses, and checks them
-// against the correct answers, and returns whether they match the expectation.
-func (eq EntryQuiz) VerifyAnswers(ctx context.Context, answers map[int]string) (passed bool, err error) {
+// sessionQuestions returns the questions asked in the provided session from the provided enabled questions, or false
+// if any have since been disabled.
+func sessionQuestions(s model.QuizSession, enabled []QuizQuestion) ([]QuizQuestion, bool) {
+	byID := make(map[int]QuizQuestion, len(enabled))
+	for _, q := range enabled {
+		byID[q.ID] = q
+	}
 
+	qs := make([]QuizQuestion, len(s.QuestionIDs))
+	for i, id := range s.QuestionIDs {
+		q, ok := byID[id]
+		if !ok {
+			return nil, false
+		}
+		qs[i] = q
+	}
+	return qs, true
+}
+
+// Start returns the questions of the current user's attempt at the quiz. If they have not started an attempt, a new
+// one is started with questions drawn at random from those enabled. If no questions are enabled, ErrQuizUnavailable
+// is returned.
+func (eq EntryQuiz) Start(ctx context.Context) ([]QuizQuestion, error) {
 	if err := verifySignedIn(ctx); err != nil {
-		return false, err
+		return nil, err
+	}
+	userID := ctxval.UserFromContext(ctx).ID
+
+	enabled, err := eq.enabledQuestions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(enabled) == 0 {
+		return nil, ErrQuizUnavailable
 	}
 
-	var wrongAnswer bool
-	for _, q := range eq.Questions {
-		if !strings.EqualFold(q.Answer, answers[q.ID]) {
-			wrongAnswer = true
+	s, err := eq.repo.Find(ctx, eq.communityID, userID)
+	if err == nil {
+		if qs, ok := sessionQuestions(s, enabled); ok {
+			return qs, nil
 		}
+		// questions have been disabled since the attempt was started, so it is replaced with a new one
+		if err := eq.repo.Delete(ctx, eq.communityID, userID); err != nil && err != storage.ErrNotFound {
+			return nil, fmt.Errorf("deleting quiz session: %w", err)
+		}
+	} else if err != storage.ErrNotFound {
+		return nil, fmt.Errorf("finding quiz session: %w", err)
+	}
+
+	size := eq.Size
+	if size == 0 || size > len(enabled) {
+		size = len(enabled)
+	}
+
+	s = model.QuizSession{
+		CommunityID: eq.communityID,
+		UserID:      userID,
+		QuestionIDs: make([]int, size),
+		Started:     time.Now(),
+	}
+	for i, j := range rand.Perm(len(enabled))[:size] {
+		s.QuestionIDs[i] = enabled[j].ID
+	}
+	if err := eq.repo.Create(ctx, s); err == storage.ErrAlreadyExists {
+		// another request started an attempt concurrently, so its questions are used instead
+		return eq.Start(ctx)
+	} else if err != nil {
+		return nil, fmt.Errorf("creating quiz session: %w", err)
+	}
+
+	qs, _ := sessionQuestions(s, enabled)
+	return qs, nil
+}
+
+// VerifyAnswers accepts a map of question IDs and string responses, checks them against the answers to the questions
+// of the current user's attempt at the quiz, and returns the result, including which were answered incorrectly. The
+// attempt is ended, such that the next attempt is drawn anew. Attempts without any questions are never verified, and
+// ErrQuizUnavailable is returned instead.
+func (eq EntryQuiz) VerifyAnswers(ctx context.Context, answers map[int]string) (QuizResult, error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return QuizResult{}, err
+	}
+	userID := ctxval.UserFromContext(ctx).ID
+
+	s, err := eq.repo.Find(ctx, eq.communityID, userID)
+	if err == storage.ErrNotFound {
+		return QuizResult{}, ErrQuizNotStarted
+	} else if err != nil {
+		return QuizResult{}, fmt.Errorf("finding quiz session: %w", err)
+	}
+
+	// deleting the session first ensures that each attempt is only verified once
+	if err := eq.repo.Delete(ctx, eq.communityID, userID); err == storage.ErrNotFound {
+		return QuizResult{}, ErrQuizNotStarted
+	} else if err != nil {
+		return QuizResult{}, fmt.Errorf("deleting quiz session: %w", err)
+	}
+
+	enabled, err := eq.enabledQuestions(ctx)
+	if err != nil {
+		return QuizResult{}, err
+	}
+	qs, ok := sessionQuestions(s, enabled)
+	if !ok {
+		return QuizResult{}, ErrQuizNotStarted
+	}
+	if len(qs) == 0 {
+		return QuizResult{}, ErrQuizUnavailable
+	}
+
+	result := QuizResult{
+		QuestionIDs:      s.QuestionIDs,
+		WrongQuestionIDs: []int{},
+		Answers:          make(map[int]string, len(qs)),
+	}
+	for _, q := range qs {
+		result.Answers[q.ID] = answers[q.ID]
+		if !q.accepts(answers[q.ID]) {
+			result.WrongQuestionIDs = append(result.WrongQuestionIDs, q.ID)
+		}
+	}
+
+	return result, nil
+}
+
+// GetQuestions returns every question of the quiz, including those which are disabled, ordered by their position,
+// and can only be accessed by admins.
+func (eq EntryQuiz) GetQuestions(ctx context.Context) ([]model.QuizQuestion, error) {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return nil, err
+	}
+
+	return eq.questions.FindByCommunityID(ctx, eq.communityID)
+}
+
+// CreateQuestion adds the provided question to the end of the quiz, enabled, and returns it. It can only be used by
+// admins.
+func (eq EntryQuiz) CreateQuestion(ctx context.Context, q model.QuizQuestion) (model.QuizQuestion, error) {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return model.QuizQuestion{}, err
+	}
+	if err := cleanQuestion(&q); err != nil {
+		return model.QuizQuestion{}, err
+	}
+
+	existing, err := eq.questions.FindByCommunityID(ctx, eq.communityID)
+	if err != nil {
+		return model.QuizQuestion{}, fmt.Errorf("finding quiz questions: %w", err)
+	}
+
+	q.CommunityID = eq.communityID
+	q.ID, q.Position = 0, 0
+	q.Enabled = true
+	// IDs are never reused, so that attempts in progress and quiz history refer to the questions which were asked
+	for _, e := range existing {
+		q.ID = max(q.ID, e.ID+1)
+		q.Position = max(q.Position, e.Position+1)
+	}
+
+	if err := eq.questions.Create(ctx, q); err != nil {
+		return model.QuizQuestion{}, fmt.Errorf("creating quiz question: %w", err)
+	}
+
+	return q, eq.audit.record(ctx, model.AuditCreateQuestion, strconv.Itoa(q.ID), q.Question)
+}
+
+// modifyQuestion finds the question of the quiz with the specified ID, applies the provided modification to it,
+// stores the result, and records the action in the audit log. It can only be used by admins.
+func (eq EntryQuiz) modifyQuestion(ctx context.Context, id int, action model.AuditAction, modify func(q *model.QuizQuestion) error) error {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return err
+	}
+
+	q, err := eq.questions.Find(ctx, eq.communityID, id)
+	if err == storage.ErrNotFound {
+		return ErrQuestionNotFound
+	} else if err != nil {
+		return fmt.Errorf("finding quiz question: %w", err)
+	}
+
+	if err := modify(&q); err != nil {
+		return err
+	}
+
+	if err := eq.questions.Update(ctx, q); err != nil {
+		return fmt.Errorf("updating quiz question: %w", err)
+	}
+
+	return eq.audit.record(ctx, action, strconv.Itoa(q.ID), q.Question)
+}
+
+// UpdateQuestion replaces the question, answers, and normalization of the question of the quiz with the ID of the
+// provided question. Attempts in progress are checked against the updated answers. It can only be used by admins.
+func (eq EntryQuiz) UpdateQuestion(ctx context.Context, update model.QuizQuestion) error {
+	if err := cleanQuestion(&update); err != nil {
+		return err
+	}
+
+	return eq.modifyQuestion(ctx, update.ID, model.AuditEditQuestion, func(q *model.QuizQuestion) error {
+		q.Question = update.Question
+		q.Answers = update.Answers
+		q.Normalize = update.Normalize
+		return nil
+	})
+}
+
+// SetQuestionEnabled enables or disables the question of the quiz with the specified ID. Disabled questions are not
+// drawn for new attempts, and attempts in progress which include them must be started again. The last enabled
+// question cannot be disabled, as the quiz would then have no questions. It can only be used by admins.
+func (eq EntryQuiz) SetQuestionEnabled(ctx context.Context, id int, enabled bool) error {
+	action := model.AuditEnableQuestion
+	if !enabled {
+		action = model.AuditDisableQuestion
+	}
+
+	return eq.modifyQuestion(ctx, id, action, func(q *model.QuizQuestion) error {
+		if !enabled && q.Enabled {
+			qs, err := eq.questions.FindByCommunityID(ctx, eq.communityID)
+			if err != nil {
+				return fmt.Errorf("finding quiz questions: %w", err)
+			}
+			if !slices.ContainsFunc(qs, func(other model.QuizQuestion) bool { return other.Enabled && other.ID != q.ID }) {
+				return Error{
+					Issues:     []string{"The last enabled question cannot be disabled."},
+					StatusCode: 400,
+				}
+			}
+		}
+		q.Enabled = enabled
+		return nil
+	})
+}
+
+// MoveQuestion swaps the position of the question of the quiz with the specified ID with that of the question before
+// it (if up is true) or after it, and can only be used by admins. Moving the first question up, or the last question
+// down, has no effect.
+func (eq EntryQuiz) MoveQuestion(ctx context.Context, id int, up bool) error {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return err
+	}
+
+	qs, err := eq.questions.FindByCommunityID(ctx, eq.communityID)
+	if err != nil {
+		return fmt.Errorf("finding quiz questions: %w", err)
+	}
+
+	i := slices.IndexFunc(qs, func(q model.QuizQuestion) bool { return q.ID == id })
+	if i == -1 {
+		return ErrQuestionNotFound
 	}
+	j := i + 1
+	if up {
+		j = i - 1
+	}
+	if j < 0 || j >= len(qs) {
+		return nil
+	}
+
+	// positions are renumbered, as questions created concurrently may share a position
+	for k := range qs {
+		qs[k].Position = k
+	}
+	qs[i].Position, qs[j].Position = j, i
+	for _, k := range []int{i, j} {
+		if err := eq.questions.Update(ctx, qs[k]); err != nil {
+			return fmt.Errorf("updating quiz question: %w", err)
+		}
+	}
+	return nil
+}
+
+// normalization is a set of differences between a response and an answer which are ignored when comparing them.
+type normalization struct {
+	punctuation bool
+	whitespace  bool
+	diacritics  bool
+}
+
+// parseNormalization returns the normalization described by the provided options, each of which must be
+// "punctuation", "whitespace", or "diacritics".
+func parseNormalization(opts []string) (normalization, error) {
+	var n normalization
+	for _, o := range opts {
+		switch strings.ToLower(strings.TrimSpace(o)) {
+		case "punctuation":
+			n.punctuation = true
+		case "whitespace":
+			n.whitespace = true
+		case "diacritics":
+			n.diacritics = true
+		default:
+			return normalization{}, fmt.Errorf("unknown normalization %q, must be punctuation, whitespace, or diacritics", o)
+		}
+	}
+	return n, nil
+}
+
+// apply returns s with the differences ignored by the normalization removed.
+func (n normalization) apply(s string) string {
+	if n == (normalization{}) {
+		return s
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case n.punctuation && (unicode.IsPunct(r) || unicode.IsSymbol(r)):
+			continue
+		case n.whitespace && unicode.IsSpace(r):
+			continue
+		case n.diacritics && unicode.Is(unicode.Mn, r):
+			continue
+		}
 
-	return !wrongAnswer, nil
+		if f, ok := diacriticFolds[r]; ok && n.diacritics {
+			b.WriteString(f)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
 }
+
+// diacriticFolds maps precomposed Latin letters with diacritics to the letters without them. Combining marks (as found
+// in decomposed text) are removed separately.
+var diacriticFolds = func() map[rune]string {
+	from := []rune("ÀÁÂÃÄÅàáâãäåĀāĂăĄąÇçĆćĈĉĊċČčĎďĐđÈÉÊËèéêëĒēĔĕĖėĘęĚěĜĝĞğĠġĢģĤĥĦħÌÍÎÏìíîïĨĩĪīĬĭĮįİıĴĵĶķ" +
+		"ĹĺĻļĽľĿŀŁłÑñŃńŅņŇňÒÓÔÕÖØòóôõöøŌōŎŏŐőŔŕŖŗŘřŚśŜŝŞşŠšŢţŤťŦŧÙÚÛÜùúûüŨũŪūŬŭŮůŰűŲųŴŵÝýÿŶŷŸŹźŻżŽž")
+	to := []rune("AAAAAAaaaaaaAaAaAaCcCcCcCcCcDdDdEEEEeeeeEeEeEeEeEeGgGgGgGgHhHhIIIIiiiiIiIiIiIiIiJjKk" +
+		"LlLlLlLlLlNnNnNnNnOOOOOOooooooOoOoOoRrRrRrSsSsSsSsTtTtTtUUUUuuuuUuUuUuUuUuUuWwYyyYyYZzZzZz")
+
+	folds := map[rune]string{'ß': "ss", 'Æ': "AE", 'æ': "ae", 'Œ': "OE", 'œ': "oe"}
+	for i, r := range from {
+		folds[r] = string(to[i])
+	}
+	return folds
+}()
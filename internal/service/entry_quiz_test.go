@@ -1,15 +1,37 @@
-package service
+package service_test
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/willbicks/epigram/internal/config"
-
 	"github.com/willbicks/epigram/internal/ctxval"
 	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
 )
 
+// newEntryQuiz returns an EntryQuiz for testCommunity which draws size questions from the provided pool, failing the
+// test if it is invalid.
+func newEntryQuiz(t *testing.T, qs []config.EntryQuestion, size int) service.EntryQuiz {
+	t.Helper()
+
+	quiz, err := service.NewEntryQuizService(testCommunity.ID, qs, size, inmemory.NewQuizSessionRepository(),
+		inmemory.NewQuizQuestionRepository(), service.NewAuditLogService(inmemory.NewAuditLogRepository()))
+	if err != nil {
+		t.Fatalf("creating entry quiz: %v", err)
+	}
+	if _, err := quiz.Seed(context.Background()); err != nil {
+		t.Fatalf("seeding entry quiz: %v", err)
+	}
+	return quiz
+}
+
 func TestNewEntryQuizService(t *testing.T) {
 
 	// intSliceContains is a  simple helper to check if a slice contains an element with the provided value
@@ -25,6 +47,9 @@ func TestNewEntryQuizService(t *testing.T) {
 	tests := []struct {
 		name           string
 		entryQuestions []config.EntryQuestion
+		size           int
+		wantSize       int
+		wantErr        bool
 	}{
 		{
 			name:           "No questions",
@@ -38,6 +63,7 @@ func TestNewEntryQuizService(t *testing.T) {
 					Answer:   "Three",
 				},
 			},
+			wantSize: 1,
 		},
 		{
 			name: "Three questions",
@@ -55,20 +81,114 @@ func TestNewEntryQuizService(t *testing.T) {
 					Answer:   "washington",
 				},
 			},
+			wantSize: 3,
+		},
+		{
+			name: "Three questions, two drawn",
+			entryQuestions: []config.EntryQuestion{
+				{
+					Question: "the best place to find a fox",
+					Answer:   "wooods",
+				},
+				{
+					Question: "How many chickens can lay an egg?",
+					Answer:   "Three",
+				},
+				{
+					Question: "The US's first president",
+					Answer:   "washington",
+				},
+			},
+			size:     2,
+			wantSize: 2,
+		},
+		{
+			name: "Size larger than pool",
+			entryQuestions: []config.EntryQuestion{
+				{
+					Question: "How many chickens can lay an egg?",
+					Answer:   "Three",
+				},
+			},
+			size:     5,
+			wantSize: 1,
+		},
+		{
+			name: "Negative size",
+			entryQuestions: []config.EntryQuestion{
+				{
+					Question: "How many chickens can lay an egg?",
+					Answer:   "Three",
+				},
+			},
+			size:    -1,
+			wantErr: true,
+		},
+		{
+			name: "No answer",
+			entryQuestions: []config.EntryQuestion{
+				{
+					Question: "How many chickens can lay an egg?",
+					Answers:  []string{" "},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown normalization",
+			entryQuestions: []config.EntryQuestion{
+				{
+					Question:  "How many chickens can lay an egg?",
+					Answer:    "Three",
+					Normalize: []string{"punctuation", "vowels"},
+				},
+			},
+			wantErr: true,
 		},
 	}
+	ctx := ctxval.ContextWithUser(context.Background(), model.User{ID: "f00"})
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewEntryQuizService(tt.entryQuestions)
+			got, err := service.NewEntryQuizService(testCommunity.ID, tt.entryQuestions, tt.size,
+				inmemory.NewQuizSessionRepository(), inmemory.NewQuizQuestionRepository(),
+				service.NewAuditLogService(inmemory.NewAuditLogRepository()))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewEntryQuizService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
 
-			// Check that number of questions matches provided
-			if len(tt.entryQuestions) != len(got.Questions) {
-				t.Errorf("Unexpected number of questions, got %v, want %v", len(got.Questions), len(tt.entryQuestions))
+			// Check that every configured question is stored
+			n, err := got.Seed(ctx)
+			if err != nil {
+				t.Fatalf("EntryQuiz.Seed() unexpected error: %v", err)
+			}
+			if n != len(tt.entryQuestions) {
+				t.Errorf("Unexpected number of questions, got %v, want %v", n, len(tt.entryQuestions))
+			}
+
+			questions, err := got.Start(ctx)
+			if len(tt.entryQuestions) == 0 {
+				// Check that quizzes without questions cannot be started, rather than passed without answering
+				if !reflect.DeepEqual(err, service.ErrQuizUnavailable) {
+					t.Errorf("EntryQuiz.Start() error = %v, want %v", err, service.ErrQuizUnavailable)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EntryQuiz.Start() unexpected error: %v", err)
+			}
+
+			// Check that the number of questions drawn is limited by the pool
+			if len(questions) != tt.wantSize {
+				t.Errorf("Unexpected quiz size, got %v, want %v", len(questions), tt.wantSize)
 			}
 
 			// Check that IDs are unique
 			ids := []int{}
-			for _, q := range got.Questions {
+			for _, q := range questions {
 				if intSliceContains(ids, q.ID) {
 					t.Errorf("Question id %v is non-unique", q.ID)
 				}
@@ -76,9 +196,9 @@ func TestNewEntryQuizService(t *testing.T) {
 			}
 
 			// Check that answer lengths are correct
-			for i, q := range got.Questions {
-				if len(tt.entryQuestions[i].Answer) != q.Length {
-					t.Errorf("Unexpected answer length, got %v, want %v", q.Length, len(tt.entryQuestions[i].Answer))
+			for _, q := range questions {
+				if len(tt.entryQuestions[q.ID].Answer) != q.Length {
+					t.Errorf("Unexpected answer length, got %v, want %v", q.Length, len(tt.entryQuestions[q.ID].Answer))
 				}
 			}
 		})
@@ -90,14 +210,14 @@ func TestEntryQuiz_VerifyAnswers(t *testing.T) {
 	ctxNoUser := context.Background()
 	ctxSignedIn := ctxval.ContextWithUser(context.Background(), model.User{ID: "f00"})
 
-	service1 := NewEntryQuizService([]config.EntryQuestion{
+	questions1 := []config.EntryQuestion{
 		{
 			Question: "the best place to find a fox",
 			Answer:   "panel",
 		},
-	})
+	}
 
-	service3 := NewEntryQuizService([]config.EntryQuestion{
+	questions3 := []config.EntryQuestion{
 		{
 			Question: "the best place to find a fox",
 			Answer:   "woods",
@@ -105,16 +225,19 @@ func TestEntryQuiz_VerifyAnswers(t *testing.T) {
 		{
 			Question: "How many chickens can lay an egg?",
 			Answer:   "Three",
+			Answers:  []string{"3"},
 		},
 		{
-			Question: "The US's first president",
-			Answer:   "washington",
+			Question:  "The US's first president",
+			Answer:    "washington",
+			Answers:   []string{"George Washington"},
+			Normalize: []string{"punctuation", "whitespace", "diacritics"},
 		},
-	})
+	}
 
 	tests := []struct {
 		name       string
-		eq         EntryQuiz
+		questions  []config.EntryQuestion
 		ctx        context.Context
 		answers    map[int]string
 		wantPassed bool
@@ -123,15 +246,15 @@ func TestEntryQuiz_VerifyAnswers(t *testing.T) {
 		{
 			name:       "1 Question - no answers",
 			ctx:        ctxSignedIn,
-			eq:         service1,
+			questions:  questions1,
 			answers:    map[int]string{},
 			wantPassed: false,
 			wantErr:    false,
 		},
 		{
-			name: "1 Question - wrong",
-			ctx:  ctxSignedIn,
-			eq:   service1,
+			name:      "1 Question - wrong",
+			ctx:       ctxSignedIn,
+			questions: questions1,
 			answers: map[int]string{
 				0: "ocean",
 			},
@@ -139,9 +262,9 @@ func TestEntryQuiz_VerifyAnswers(t *testing.T) {
 			wantErr:    false,
 		},
 		{
-			name: "1 Question - too many answers",
-			ctx:  ctxSignedIn,
-			eq:   service1,
+			name:      "1 Question - too many answers",
+			ctx:       ctxSignedIn,
+			questions: questions1,
 			answers: map[int]string{
 				0: "ocean",
 				1: "claimant",
@@ -150,27 +273,37 @@ func TestEntryQuiz_VerifyAnswers(t *testing.T) {
 			wantErr:    false,
 		},
 		{
-			name: "1 Question - right answer",
-			ctx:  ctxSignedIn,
-			eq:   service1,
+			name:      "1 Question - right answer",
+			ctx:       ctxSignedIn,
+			questions: questions1,
 			answers: map[int]string{
 				0: "PANeL",
 			},
 			wantPassed: true,
 			wantErr:    false,
 		},
+		{
+			name:      "1 Question - not normalized",
+			ctx:       ctxSignedIn,
+			questions: questions1,
+			answers: map[int]string{
+				0: "pan el",
+			},
+			wantPassed: false,
+			wantErr:    false,
+		},
 		{
 			name:       "3 Question - no answer",
 			ctx:        ctxSignedIn,
-			eq:         service3,
+			questions:  questions3,
 			answers:    map[int]string{},
 			wantPassed: false,
 			wantErr:    false,
 		},
 		{
-			name: "3 Question - not enough answers",
-			ctx:  ctxSignedIn,
-			eq:   service3,
+			name:      "3 Question - not enough answers",
+			ctx:       ctxSignedIn,
+			questions: questions3,
 			answers: map[int]string{
 				0: "woods",
 				2: "washington",
@@ -179,9 +312,9 @@ func TestEntryQuiz_VerifyAnswers(t *testing.T) {
 			wantErr:    false,
 		},
 		{
-			name: "3 Question - wrong 1",
-			ctx:  ctxSignedIn,
-			eq:   service3,
+			name:      "3 Question - wrong 1",
+			ctx:       ctxSignedIn,
+			questions: questions3,
 			answers: map[int]string{
 				0: "woods",
 				1: "chair",
@@ -191,9 +324,9 @@ func TestEntryQuiz_VerifyAnswers(t *testing.T) {
 			wantErr:    false,
 		},
 		{
-			name: "3 Question - too many answers",
-			ctx:  ctxSignedIn,
-			eq:   service3,
+			name:      "3 Question - too many answers",
+			ctx:       ctxSignedIn,
+			questions: questions3,
 			answers: map[int]string{
 				0: "ocean",
 				1: "claimant",
@@ -204,9 +337,9 @@ func TestEntryQuiz_VerifyAnswers(t *testing.T) {
 			wantErr:    false,
 		},
 		{
-			name: "3 Question - right",
-			ctx:  ctxSignedIn,
-			eq:   service3,
+			name:      "3 Question - right",
+			ctx:       ctxSignedIn,
+			questions: questions3,
 			answers: map[int]string{
 				0: "woods",
 				1: "THREE",
@@ -216,9 +349,45 @@ func TestEntryQuiz_VerifyAnswers(t *testing.T) {
 			wantErr:    false,
 		},
 		{
-			name: "3 Question - right, not signed in",
-			ctx:  ctxNoUser,
-			eq:   service3,
+			name:      "3 Question - right, alternative answers",
+			ctx:       ctxSignedIn,
+			questions: questions3,
+			answers: map[int]string{
+				0: "woods",
+				1: "3",
+				2: "GEORGE WASHINGTON",
+			},
+			wantPassed: true,
+			wantErr:    false,
+		},
+		{
+			name:      "3 Question - right, normalized",
+			ctx:       ctxSignedIn,
+			questions: questions3,
+			answers: map[int]string{
+				0: "woods",
+				1: "three",
+				2: "  Géorge\tWashingtön.",
+			},
+			wantPassed: true,
+			wantErr:    false,
+		},
+		{
+			name:      "3 Question - right, decomposed diacritics",
+			ctx:       ctxSignedIn,
+			questions: questions3,
+			answers: map[int]string{
+				0: "woods",
+				1: "three",
+				2: "wa\u0301shington",
+			},
+			wantPassed: true,
+			wantErr:    false,
+		},
+		{
+			name:      "3 Question - right, not signed in",
+			ctx:       ctxNoUser,
+			questions: questions3,
 			answers: map[int]string{
 				0: "woods",
 				1: "THREE",
@@ -230,13 +399,235 @@ func TestEntryQuiz_VerifyAnswers(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotPassed, err := tt.eq.VerifyAnswers(tt.ctx, tt.answers)
+			eq := newEntryQuiz(t, tt.questions, 0)
+			if _, err := eq.Start(ctxSignedIn); err != nil {
+				t.Fatalf("EntryQuiz.Start() unexpected error: %v", err)
+			}
+
+			got, err := eq.VerifyAnswers(tt.ctx, tt.answers)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("EntryQuiz.VerifyAnswers() unexpected error value")
 			}
-			if gotPassed != tt.wantPassed {
+			if gotPassed := err == nil && got.Passed(); gotPassed != tt.wantPassed {
 				t.Errorf("EntryQuiz.VerifyAnswers() = %v, want %v", gotPassed, tt.wantPassed)
 			}
 		})
 	}
 }
+
+func TestEntryQuiz_Start(t *testing.T) {
+	is := is.New(t)
+
+	pool := []config.EntryQuestion{
+		{Question: "Q0", Answer: "a0"},
+		{Question: "Q1", Answer: "a1"},
+		{Question: "Q2", Answer: "a2"},
+		{Question: "Q3", Answer: "a3"},
+		{Question: "Q4", Answer: "a4"},
+	}
+	quiz := newEntryQuiz(t, pool, 2)
+	ctx := ctxval.ContextWithUser(context.Background(), submitter)
+
+	_, err := quiz.Start(context.Background())
+	is.Equal(err, service.ErrNotAuthenticated) // anonymous users should not be able to start the quiz
+
+	questions, err := quiz.Start(ctx)
+	is.NoErr(err)
+	is.Equal(len(questions), 2)                 // attempt should draw the configured number of questions
+	is.True(questions[0].ID != questions[1].ID) // attempt should not repeat questions
+
+	again, err := quiz.Start(ctx)
+	is.NoErr(err)
+	is.Equal(again, questions) // reloading the quiz should not draw new questions
+
+	answers := map[int]string{}
+	for _, q := range questions {
+		answers[q.ID] = q.Answers[0]
+	}
+	result, err := quiz.VerifyAnswers(ctx, answers)
+	is.NoErr(err)
+	is.True(result.Passed())                                              // answering only the drawn questions should pass
+	is.Equal(result.QuestionIDs, []int{questions[0].ID, questions[1].ID}) // result should list the drawn questions
+
+	_, err = quiz.VerifyAnswers(ctx, answers)
+	is.Equal(err, service.ErrQuizNotStarted) // an attempt should only be submitted once
+
+	_, err = quiz.VerifyAnswers(ctxval.ContextWithUser(context.Background(), otherUser), answers)
+	is.Equal(err, service.ErrQuizNotStarted) // answers should not be accepted without an attempt
+}
+
+func TestEntryQuiz_Start_Random(t *testing.T) {
+	is := is.New(t)
+
+	pool := make([]config.EntryQuestion, 10)
+	for i := range pool {
+		pool[i] = config.EntryQuestion{Question: "Question", Answer: "answer"}
+	}
+	quiz := newEntryQuiz(t, pool, 3)
+	ctx := ctxval.ContextWithUser(context.Background(), submitter)
+
+	// with 720 possible ordered draws, twenty identical attempts in a row are vanishingly unlikely
+	var first []service.QuizQuestion
+	varied := false
+	for i := 0; i < 20 && !varied; i++ {
+		questions, err := quiz.Start(ctx)
+		is.NoErr(err)
+		_, err = quiz.VerifyAnswers(ctx, nil)
+		is.NoErr(err)
+
+		if first == nil {
+			first = questions
+		} else {
+			for j := range questions {
+				if questions[j].ID != first[j].ID {
+					varied = true
+				}
+			}
+		}
+	}
+	is.True(varied) // each attempt should draw its questions at random
+}
+
+func TestEntryQuiz_Seed(t *testing.T) {
+	is := is.New(t)
+
+	questions := inmemory.NewQuizQuestionRepository()
+	pool := []config.EntryQuestion{{Question: "Question", Answer: "answer", Answers: []string{" ", "other"}}}
+	quiz, err := service.NewEntryQuizService(testCommunity.ID, pool, 0, inmemory.NewQuizSessionRepository(), questions,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()))
+	is.NoErr(err)
+
+	n, err := quiz.Seed(context.Background())
+	is.NoErr(err)
+	is.Equal(n, 1) // configured questions should be stored when there are none
+
+	q, err := questions.Find(context.Background(), testCommunity.ID, 0)
+	is.NoErr(err)
+	is.Equal(q.Answers, []string{"answer", "other"}) // blank answers should not be stored
+	is.True(q.Enabled)                               // seeded questions should be enabled
+
+	is.NoErr(questions.Update(context.Background(), model.QuizQuestion{
+		CommunityID: testCommunity.ID, ID: 0, Question: "Edited", Answers: []string{"answer"},
+	}))
+	n, err = quiz.Seed(context.Background())
+	is.NoErr(err)
+	is.Equal(n, 0) // configured questions should not replace stored questions
+
+	q, err = questions.Find(context.Background(), testCommunity.ID, 0)
+	is.NoErr(err)
+	is.Equal(q.Question, "Edited") // stored questions should be preserved
+}
+
+func TestEntryQuiz_ManageQuestions(t *testing.T) {
+	is := is.New(t)
+
+	audit := service.NewAuditLogService(inmemory.NewAuditLogRepository())
+	quiz, err := service.NewEntryQuizService(testCommunity.ID, []config.EntryQuestion{
+		{Question: "the best place to find a fox", Answer: "woods"},
+	}, 0, inmemory.NewQuizSessionRepository(), inmemory.NewQuizQuestionRepository(), audit)
+	is.NoErr(err)
+	_, err = quiz.Seed(context.Background())
+	is.NoErr(err)
+
+	ctxAdmin := userContext(adminUser)
+	ctxMember := userContext(submitter)
+
+	_, err = quiz.GetQuestions(ctxMember)
+	is.True(err != nil) // members should not be able to view the answers
+	_, err = quiz.CreateQuestion(ctxMember, model.QuizQuestion{Question: "Question", Answers: []string{"answer"}})
+	is.True(err != nil) // members should not be able to create questions
+
+	_, err = quiz.CreateQuestion(ctxAdmin, model.QuizQuestion{Question: " ", Answers: []string{" "},
+		Normalize: []string{"vowels"}})
+	is.Equal(err, service.Error{
+		Issues: []string{
+			"Question must not be empty.",
+			"At least one answer is required.",
+			`Unknown normalization "vowels", must be punctuation, whitespace, or diacritics.`,
+		},
+		StatusCode: 400,
+	}) // invalid questions should be rejected with every issue
+
+	created, err := quiz.CreateQuestion(ctxAdmin, model.QuizQuestion{
+		ID:       0,
+		Question: "How many chickens can lay an egg? ",
+		Answers:  []string{"three", "", "3"},
+	})
+	is.NoErr(err)
+	is.Equal(created.ID, 1)                                         // created questions should not reuse IDs
+	is.Equal(created.Question, "How many chickens can lay an egg?") // question should be trimmed
+	is.Equal(created.Answers, []string{"three", "3"})               // blank answers should be removed
+	is.True(created.Enabled)                                        // created questions should be enabled
+
+	all, err := quiz.GetQuestions(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(all), 2)           // created question should be stored
+	is.Equal(all[1].ID, created.ID) // created question should be last
+
+	is.NoErr(quiz.MoveQuestion(ctxAdmin, created.ID, true))
+	all, err = quiz.GetQuestions(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(all[0].ID, created.ID)                                              // moved question should swap with the one before it
+	is.NoErr(quiz.MoveQuestion(ctxAdmin, created.ID, true))                      // moving the first question up should have no effect
+	is.Equal(quiz.MoveQuestion(ctxAdmin, 7, false), service.ErrQuestionNotFound) // moving missing questions should fail
+
+	is.NoErr(quiz.UpdateQuestion(ctxAdmin, model.QuizQuestion{ID: 0, Question: "Where are foxes?", Answers: []string{"forest"}}))
+	questions, err := quiz.Start(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(questions), 2) // every enabled question should be asked
+	for _, q := range questions {
+		if q.ID == 0 {
+			is.Equal(q.Question, "Where are foxes?") // attempts should ask the edited question
+			is.Equal(q.Length, 6)                    // attempts should show the length of the edited answer
+		}
+	}
+	is.Equal(quiz.UpdateQuestion(ctxAdmin, model.QuizQuestion{ID: 7, Question: "Q", Answers: []string{"a"}}),
+		service.ErrQuestionNotFound) // editing missing questions should fail
+
+	is.NoErr(quiz.SetQuestionEnabled(ctxAdmin, created.ID, false))
+	questions, err = quiz.Start(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(questions), 1)  // attempts including disabled questions should be drawn again
+	is.Equal(questions[0].ID, 0) // disabled questions should not be asked
+
+	all, err = quiz.GetQuestions(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(all), 2) // disabled questions should still be listed for admins
+
+	ctxInstanceAdmin := ctxval.ContextWithUser(context.Background(), adminUser)
+	for _, action := range []model.AuditAction{model.AuditCreateQuestion, model.AuditEditQuestion, model.AuditDisableQuestion} {
+		entries, err := audit.QueryAuditLog(ctxInstanceAdmin, service.AuditLogQuery{Action: action})
+		is.NoErr(err)
+		is.Equal(len(entries), 1) // changes to questions should be audited
+	}
+}
+
+func TestEntryQuiz_NoEnabledQuestions(t *testing.T) {
+	is := is.New(t)
+
+	quiz := newEntryQuiz(t, []config.EntryQuestion{{Question: "the best place to find a fox", Answer: "woods"}}, 0)
+	ctxAdmin := userContext(adminUser)
+
+	err := quiz.SetQuestionEnabled(ctxAdmin, 0, false)
+	var serr service.Error
+	is.True(errors.As(err, &serr) && serr.StatusCode == 400) // the last enabled question should not be disabled
+
+	questions, err := quiz.Start(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(questions), 1) // question should remain enabled
+
+	sessions := inmemory.NewQuizSessionRepository()
+	empty, err := service.NewEntryQuizService(testCommunity.ID, nil, 0, sessions, inmemory.NewQuizQuestionRepository(),
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()))
+	is.NoErr(err)
+	ctx := signedInContext(submitter)
+
+	_, err = empty.Start(ctx)
+	is.Equal(err, service.ErrQuizUnavailable) // quizzes without questions should not be started
+
+	is.NoErr(sessions.Create(context.Background(), model.QuizSession{
+		CommunityID: testCommunity.ID, UserID: submitter.ID, QuestionIDs: []int{}, Started: time.Now(),
+	}))
+	_, err = empty.VerifyAnswers(ctx, map[int]string{})
+	is.Equal(err, service.ErrQuizUnavailable) // attempts without questions should not pass
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+
+	"github.com/rs/xid"
+)
+
+// _inviteCodeRandBytes represents the number of cryptographically secure random bytes that should be generated for
+// the code of each Invite.
+const _inviteCodeRandBytes = 16
+
+// ErrInviteNotFound is returned when a requested Invite does not exist, or belongs to another community.
+var ErrInviteNotFound = Error{
+	Issues:     []string{"Invite not found."},
+	StatusCode: 404,
+}
+
+// ErrInvalidInvite is returned when an invite code is used which does not exist, has expired, or has been used up.
+var ErrInvalidInvite = Error{
+	Issues:     []string{"This invite is invalid, has expired, or has already been used."},
+	StatusCode: 404,
+}
+
+// InviteRepository provides methods for storing and retrieving Invites, and recording their redemptions.
+type InviteRepository interface {
+	// Create adds a new Invite to the repository, ignoring its Redemptions.
+	Create(ctx context.Context, i model.Invite) error
+	// Delete removes the specified Invite, along with its Redemptions.
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (model.Invite, error)
+	FindByCode(ctx context.Context, code string) (model.Invite, error)
+	// FindByCommunityID returns the invites of the specified community, from newest to oldest.
+	FindByCommunityID(ctx context.Context, communityID string) ([]model.Invite, error)
+	// AddRedemption records that an Invite was redeemed, returning storage.ErrNotFound if the invite does not exist,
+	// storage.ErrAlreadyExists if the user has already redeemed it, or storage.ErrUnavailable if it had expired or
+	// been used up at the time of the redemption. The expiry and use limit must be checked atomically with adding the
+	// redemption, such that concurrent redemptions cannot exceed the limit.
+	AddRedemption(ctx context.Context, r model.InviteRedemption) error
+	// ReassignUser changes the CreatorID of every Invite created by the user fromID, and the UserID of every redemption
+	// made by them, to toID. Redemptions of invites which toID has already redeemed are removed instead.
+	ReassignUser(ctx context.Context, fromID string, toID string) error
+}
+
+// InviteRedeemer is a user who redeemed an invite, and when they did so.
+type InviteRedeemer struct {
+	model.InviteRedemption
+	// Name is the name of the user, or empty if they no longer exist.
+	Name string
+}
+
+// InviteSummary is an Invite, along with the names of its creator and the users who redeemed it.
+type InviteSummary struct {
+	model.Invite
+	CreatorName string
+	Redeemers   []InviteRedeemer
+}
+
+// Invite is a service for issuing and redeeming Invites, which allow users to join a community without passing its
+// entry quiz, or being approved by its admins.
+type Invite struct {
+	repo        InviteRepository
+	mr          MembershipRepository
+	ur          UserRepository
+	communities Community
+	audit       AuditLog
+}
+
+// NewInviteService returns a new Invite service with the provided InviteRepository, the MembershipRepository in which
+// users who redeem invites are made members, the UserRepository used to find the names of creators and redeemers, the
+// Community service used to find the gates of the communities invited to, and the AuditLog service used to record
+// privileged actions.
+func NewInviteService(repo InviteRepository, mr MembershipRepository, ur UserRepository, communities Community,
+	audit AuditLog) Invite {
+	return Invite{
+		repo:        repo,
+		mr:          mr,
+		ur:          ur,
+		communities: communities,
+		audit:       audit,
+	}
+}
+
+// CreateInvite creates a new Invite to the current community, which expires after the provided duration (or never, if
+// zero), and may be redeemed by up to maxUses users (or any number, if zero). It can only be used by admins.
+func (s Invite) CreateInvite(ctx context.Context, expiresIn time.Duration, maxUses int) (model.Invite, error) {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return model.Invite{}, err
+	}
+
+	var issues []string
+	if expiresIn < 0 {
+		issues = append(issues, "Invite expiry must not be in the past.")
+	}
+	if maxUses < 0 {
+		issues = append(issues, "Invite use limit must not be negative.")
+	}
+	if len(issues) > 0 {
+		return model.Invite{}, Error{
+			Issues:     issues,
+			StatusCode: 400,
+		}
+	}
+
+	randBytes := make([]byte, _inviteCodeRandBytes)
+	if _, err := rand.Read(randBytes); err != nil {
+		return model.Invite{}, fmt.Errorf("generate randBytes for Invite: %w", err)
+	}
+
+	now := time.Now()
+	i := model.Invite{
+		ID:          xid.New().String(),
+		CommunityID: ctxval.CommunityFromContext(ctx).ID,
+		Code:        base64.RawURLEncoding.EncodeToString(randBytes),
+		CreatorID:   ctxval.UserFromContext(ctx).ID,
+		MaxUses:     maxUses,
+		Created:     now,
+		Redemptions: []model.InviteRedemption{},
+	}
+	if expiresIn > 0 {
+		i.Expires = now.Add(expiresIn)
+	}
+
+	if err := s.repo.Create(ctx, i); err != nil {
+		return model.Invite{}, err
+	}
+
+	return i, s.audit.record(ctx, model.AuditCreateInvite, i.ID, "")
+}
+
+// GetInvites returns a summary of each invite to the current community, from newest to oldest. It can only be
+// accessed by admins.
+func (s Invite) GetInvites(ctx context.Context) ([]InviteSummary, error) {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return nil, err
+	}
+
+	invites, err := s.repo.FindByCommunityID(ctx, ctxval.CommunityFromContext(ctx).ID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	name := func(id string) (string, error) {
+		if n, ok := names[id]; ok {
+			return n, nil
+		}
+		u, err := s.ur.FindByID(ctx, id)
+		if err != nil && err != storage.ErrNotFound {
+			return "", fmt.Errorf("finding user of invite: %w", err)
+		}
+		names[id] = u.Name
+		return u.Name, nil
+	}
+
+	summaries := make([]InviteSummary, len(invites))
+	for i, inv := range invites {
+		summaries[i] = InviteSummary{
+			Invite:    inv,
+			Redeemers: make([]InviteRedeemer, len(inv.Redemptions)),
+		}
+		if summaries[i].CreatorName, err = name(inv.CreatorID); err != nil {
+			return nil, err
+		}
+		for j, r := range inv.Redemptions {
+			summaries[i].Redeemers[j].InviteRedemption = r
+			if summaries[i].Redeemers[j].Name, err = name(r.UserID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return summaries, nil
+}
+
+// RevokeInvite deletes the invite to the current community with the specified ID, such that it can no longer be
+// redeemed. It can only be used by admins.
+func (s Invite) RevokeInvite(ctx context.Context, id string) error {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return err
+	}
+
+	i, err := s.repo.FindByID(ctx, id)
+	if err == storage.ErrNotFound || (err == nil && i.CommunityID != ctxval.CommunityFromContext(ctx).ID) {
+		return ErrInviteNotFound
+	} else if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return s.audit.record(ctx, model.AuditRevokeInvite, i.ID, fmt.Sprintf("redeemed by %d users", len(i.Redemptions)))
+}
+
+// FindInvite returns the invite with the provided code, provided that it can still be redeemed. Otherwise,
+// ErrInvalidInvite is returned.
+func (s Invite) FindInvite(ctx context.Context, code string) (model.Invite, error) {
+	if code == "" {
+		return model.Invite{}, ErrInvalidInvite
+	}
+
+	i, err := s.repo.FindByCode(ctx, code)
+	if err == storage.ErrNotFound {
+		return model.Invite{}, ErrInvalidInvite
+	} else if err != nil {
+		return model.Invite{}, fmt.Errorf("finding invite: %w", err)
+	}
+
+	if !i.IsRedeemable(time.Now()) {
+		return model.Invite{}, ErrInvalidInvite
+	}
+	return i, nil
+}
+
+// RedeemInvite redeems the invite with the provided code for the user on the context, making them a member of the
+// invite's community who has passed its entry quiz and been approved, and returns the invite. Members who have
+// already passed the community's gate do not use up the invite.
+func (s Invite) RedeemInvite(ctx context.Context, code string) (model.Invite, error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return model.Invite{}, err
+	}
+
+	i, err := s.FindInvite(ctx, code)
+	if err != nil {
+		return model.Invite{}, err
+	}
+
+	c, err := s.communities.GetCommunity(i.CommunityID)
+	if err != nil {
+		// the invite's community is no longer hosted
+		return model.Invite{}, ErrInvalidInvite
+	}
+
+	userID := ctxval.UserFromContext(ctx).ID
+	m, err := s.mr.Find(ctx, i.CommunityID, userID)
+	joined := err == storage.ErrNotFound
+	if joined {
+		m = model.Membership{
+			CommunityID: i.CommunityID,
+			UserID:      userID,
+			Joined:      time.Now(),
+		}
+	} else if err != nil {
+		return model.Invite{}, fmt.Errorf("finding membership: %w", err)
+	} else if m.PassedGate(c.Gate) {
+		return i, nil
+	}
+
+	err = s.repo.AddRedemption(ctx, model.InviteRedemption{
+		InviteID: i.ID,
+		UserID:   userID,
+		Redeemed: time.Now(),
+	})
+	if err == storage.ErrNotFound || err == storage.ErrUnavailable {
+		// the invite was revoked, or used up by other users, since it was found
+		return model.Invite{}, ErrInvalidInvite
+	} else if err != nil && err != storage.ErrAlreadyExists {
+		return model.Invite{}, fmt.Errorf("recording invite redemption: %w", err)
+	}
+
+	m.QuizPassed = true
+	m.Approved = true
+	m.Rejected = false
+	if joined {
+		err = s.mr.Create(ctx, m)
+	} else {
+		err = s.mr.Update(ctx, m)
+	}
+	if err != nil {
+		return model.Invite{}, fmt.Errorf("updating membership: %w", err)
+	}
+
+	return i, nil
+}
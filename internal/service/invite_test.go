@@ -0,0 +1,192 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/willbicks/epigram/internal/config"
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+func newInviteService(t *testing.T, users ...model.User) (service.Invite, service.MembershipRepository) {
+	t.Helper()
+
+	return newInviteServiceWithGate(t, "", users...)
+}
+
+// newInviteServiceWithGate returns an Invite service like newInviteService, in which testCommunity has the provided
+// gate.
+func newInviteServiceWithGate(t *testing.T, gate model.GateMode, users ...model.User) (service.Invite, service.MembershipRepository) {
+	t.Helper()
+
+	userRepo := inmemory.NewUserRepository()
+	for _, u := range users {
+		if err := userRepo.Create(context.Background(), u); err != nil {
+			t.Fatalf("creating user %v: %v", u.ID, err)
+		}
+	}
+
+	membershipRepo := inmemory.NewMembershipRepository()
+	audit := service.NewAuditLogService(inmemory.NewAuditLogRepository())
+	communities, err := service.NewCommunityService([]config.Community{{ID: testCommunity.ID, Gate: string(gate)}}, membershipRepo,
+		inmemory.NewQuizSessionRepository(), inmemory.NewQuizQuestionRepository(), audit)
+	if err != nil {
+		t.Fatalf("creating community service: %v", err)
+	}
+
+	return service.NewInviteService(inmemory.NewInviteRepository(), membershipRepo, userRepo, communities, audit),
+		membershipRepo
+}
+
+// signedInContext returns a context in which the provided user is signed in to testCommunity, without being a member
+// of it.
+func signedInContext(u model.User) context.Context {
+	return ctxval.ContextWithCommunity(ctxval.ContextWithUser(context.Background(), u), testCommunity)
+}
+
+func TestInvite_CreateInvite(t *testing.T) {
+	is := is.New(t)
+	invites, _ := newInviteService(t, adminUser)
+	ctxAdmin := userContext(adminUser)
+
+	i, err := invites.CreateInvite(ctxAdmin, time.Hour, 3)
+	is.NoErr(err)                             // admins should be able to create invites
+	is.Equal(i.CommunityID, testCommunity.ID) // invite should be to the current community
+	is.Equal(i.CreatorID, adminUser.ID)       // invite should record its creator
+	is.Equal(i.MaxUses, 3)                    // invite should record its use limit
+	is.True(i.Expires.After(time.Now()))      // invite should expire in the future
+	is.True(len(i.Code) > 16)                 // invite should have a long code
+	is.True(i.IsRedeemable(time.Now()))       // new invite should be redeemable
+	is.True(!i.IsRedeemable(i.Expires))       // invite should not be redeemable once expired
+
+	forever, err := invites.CreateInvite(ctxAdmin, 0, 0)
+	is.NoErr(err)
+	is.True(forever.Expires.IsZero()) // invite without expiry should never expire
+	is.True(forever.Code != i.Code)   // each invite should have a unique code
+
+	_, err = invites.CreateInvite(ctxAdmin, -time.Hour, -1)
+	is.Equal(err.(service.Error).StatusCode, 400) // negative expiry and use limit should be rejected
+
+	_, err = invites.CreateInvite(userContext(submitter), time.Hour, 1)
+	is.Equal(err, service.ErrNotAuthorized) // non-admins should not be able to create invites
+}
+
+func TestInvite_RedeemInvite(t *testing.T) {
+	is := is.New(t)
+	newcomer := model.User{ID: "newcomer", Name: "Newcomer"}
+	invites, membershipRepo := newInviteService(t, adminUser, newcomer, otherUser)
+	ctxAdmin := userContext(adminUser)
+
+	i, err := invites.CreateInvite(ctxAdmin, time.Hour, 1)
+	is.NoErr(err)
+
+	_, err = invites.RedeemInvite(ctxval.ContextWithCommunity(context.Background(), testCommunity), i.Code)
+	is.Equal(err, service.ErrNotAuthenticated) // anonymous users should not be able to redeem invites
+
+	_, err = invites.RedeemInvite(signedInContext(newcomer), "wrong")
+	is.Equal(err, service.ErrInvalidInvite) // unknown codes should be rejected
+
+	redeemed, err := invites.RedeemInvite(signedInContext(newcomer), i.Code)
+	is.NoErr(err)               // signed in users should be able to redeem invites
+	is.Equal(redeemed.ID, i.ID) // redeemed invite should be returned
+
+	m, err := membershipRepo.Find(context.Background(), testCommunity.ID, newcomer.ID)
+	is.NoErr(err)         // redeeming an invite should make the user a member
+	is.True(m.QuizPassed) // member should not need to pass the quiz
+	is.True(!m.Joined.IsZero())
+
+	_, err = invites.RedeemInvite(signedInContext(newcomer), i.Code)
+	is.Equal(err, service.ErrInvalidInvite) // used up invite should be rejected
+
+	_, err = invites.RedeemInvite(signedInContext(otherUser), i.Code)
+	is.Equal(err, service.ErrInvalidInvite) // used up invite should be rejected for other users
+
+	summaries, err := invites.GetInvites(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(summaries), 1)                          // admins should see the invite
+	is.Equal(len(summaries[0].Redeemers), 1)             // invite should list its redeemer
+	is.Equal(summaries[0].Redeemers[0].Name, "Newcomer") // redeemer should be named
+	is.Equal(summaries[0].Redeemers[0].UserID, newcomer.ID)
+}
+
+func TestInvite_RedeemInvite_ExistingMember(t *testing.T) {
+	is := is.New(t)
+	invites, membershipRepo := newInviteService(t, adminUser, submitter, otherUser)
+	ctxAdmin := userContext(adminUser)
+
+	is.NoErr(membershipRepo.Create(context.Background(), model.Membership{
+		CommunityID: testCommunity.ID, UserID: submitter.ID, QuizPassed: true,
+	}))
+	is.NoErr(membershipRepo.Create(context.Background(), model.Membership{
+		CommunityID: testCommunity.ID, UserID: otherUser.ID, QuizAttempts: 2,
+	}))
+
+	i, err := invites.CreateInvite(ctxAdmin, 0, 1)
+	is.NoErr(err)
+
+	_, err = invites.RedeemInvite(signedInContext(submitter), i.Code)
+	is.NoErr(err) // members who passed the quiz should be able to follow invites
+
+	_, err = invites.RedeemInvite(signedInContext(otherUser), i.Code)
+	is.NoErr(err) // members who have not passed the quiz should be able to redeem invites
+
+	m, err := membershipRepo.Find(context.Background(), testCommunity.ID, otherUser.ID)
+	is.NoErr(err)
+	is.True(m.QuizPassed)             // redeeming should pass the quiz for existing members
+	is.Equal(m.QuizAttempts, int8(2)) // redeeming should preserve existing membership
+
+	summaries, err := invites.GetInvites(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(summaries[0].Redeemers), 1) // members who already passed the quiz should not use up the invite
+}
+
+func TestInvite_RedeemInvite_Approval(t *testing.T) {
+	is := is.New(t)
+	invites, membershipRepo := newInviteServiceWithGate(t, model.GateApproval, adminUser, otherUser)
+
+	is.NoErr(membershipRepo.Create(context.Background(), model.Membership{
+		CommunityID: testCommunity.ID, UserID: otherUser.ID, Joined: time.Now(), Rejected: true,
+	}))
+
+	i, err := invites.CreateInvite(communityContext(adminUser, approvalCommunity), 0, 1)
+	is.NoErr(err)
+
+	_, err = invites.RedeemInvite(ctxval.ContextWithCommunity(ctxval.ContextWithUser(context.Background(), otherUser),
+		approvalCommunity), i.Code)
+	is.NoErr(err) // rejected members should be able to redeem invites
+
+	m, err := membershipRepo.Find(context.Background(), testCommunity.ID, otherUser.ID)
+	is.NoErr(err)
+	is.True(m.Approved && !m.Rejected)              // redeeming should approve the member
+	is.True(m.IsAuthorized(approvalCommunity.Gate)) // approved member should be authorized
+}
+
+func TestInvite_RevokeInvite(t *testing.T) {
+	is := is.New(t)
+	invites, _ := newInviteService(t, adminUser, submitter)
+	ctxAdmin := userContext(adminUser)
+
+	i, err := invites.CreateInvite(ctxAdmin, 0, 0)
+	is.NoErr(err)
+
+	_, err = invites.FindInvite(context.Background(), i.Code)
+	is.NoErr(err) // anyone should be able to find a valid invite
+
+	is.Equal(invites.RevokeInvite(userContext(submitter), i.ID), service.ErrNotAuthorized) // non-admins should not be able to revoke invites
+
+	ctxElsewhere := communityContext(adminUser, model.Community{ID: "other"})
+	is.Equal(invites.RevokeInvite(ctxElsewhere, i.ID), service.ErrInviteNotFound) // invites should not be revocable from other communities
+
+	is.NoErr(invites.RevokeInvite(ctxAdmin, i.ID)) // admins should be able to revoke invites
+
+	_, err = invites.FindInvite(context.Background(), i.Code)
+	is.Equal(err, service.ErrInvalidInvite) // revoked invite should no longer be valid
+
+	is.Equal(invites.RevokeInvite(ctxAdmin, i.ID), service.ErrInviteNotFound) // revoking an invite twice should fail
+}
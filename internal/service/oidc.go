@@ -5,17 +5,24 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"golang.org/x/oauth2"
 )
 
+// validProviderName matches names which may be safely used as a path segment in OIDC login and callback urls.
+var validProviderName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
 // OIDC contains the Oauth2 Config and OIDC IDTokenVerifier required to validate OIDC callbacks and provides methods
 // for authenticated users via OIDC.
 type OIDC struct {
-	// Name is a unique identifier used by this OIDC service to build a callback url.
+	// Name is a unique identifier used by this OIDC service to build login and callback urls.
 	Name string
 
+	// DisplayName is the name of the provider shown to users. If blank, Name is used instead.
+	DisplayName string
+
 	// IssuerURL is the URL for OIDC endpoint discovery.
 	IssuerURL string
 
@@ -31,9 +38,22 @@ func (o OIDC) RedirectURL(state string, nonce string) (url string) {
 	return o.config.AuthCodeURL(state, oidc.Nonce(nonce))
 }
 
+// LoginURL returns the partial URL at which users may begin to sign in with this OIDC service.
+func (o OIDC) LoginURL() string {
+	return "/login/" + o.Name
+}
+
 // CallbackURL returns the partial URL to be used for this OIDC service.
 func (o OIDC) CallbackURL() string {
-	return "/login/" + o.Name + "/callback"
+	return o.LoginURL() + "/callback"
+}
+
+// Label returns the name of this OIDC service to be shown to users.
+func (o OIDC) Label() string {
+	if o.DisplayName != "" {
+		return o.DisplayName
+	}
+	return o.Name
 }
 
 // Init initializes the OIDC service. Requires the baseURL of this server in order to build an oauth redirect URL.
@@ -42,6 +62,10 @@ func (o *OIDC) Init(baseURL string) error {
 		return errors.New("baseURL is required to generate oauth callback url")
 	}
 
+	if !validProviderName.MatchString(o.Name) {
+		return fmt.Errorf("OIDC provider name %q must consist of only lowercase letters, numbers, dashes, and underscores", o.Name)
+	}
+
 	if o.IssuerURL == "" || o.ClientID == "" || o.ClientSecret == "" {
 		return errors.New("at least one required field (IssuerURL, ClientID, ClientSecret) is missing from OIDC object")
 	}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+
+	"github.com/rs/xid"
+)
+
+// ErrPersonNotFound is returned when a requested person does not exist.
+var ErrPersonNotFound = Error{
+	Issues:     []string{"Person not found."},
+	StatusCode: 404,
+}
+
+// PersonRepository provides methods for storing and retrieving People.
+type PersonRepository interface {
+	Create(ctx context.Context, p model.Person) error
+	Update(ctx context.Context, p model.Person) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (model.Person, error)
+	// FindByCommunityID returns all People of the specified community, ordered by name regardless of capitalization.
+	FindByCommunityID(ctx context.Context, communityID string) ([]model.Person, error)
+	// ReassignUser links every Person linked to the user fromID to the user toID instead.
+	ReassignUser(ctx context.Context, fromID string, toID string) error
+}
+
+// PersonSummary is a Person and the number of quotes attributed to them.
+type PersonSummary struct {
+	model.Person
+	Quotes int
+}
+
+// cleanName returns the provided name with surrounding whitespace removed, and inner whitespace collapsed.
+func cleanName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// normalizeName returns the provided name in a form which is equal for all spellings of the name which differ only in
+// capitalization or whitespace.
+func normalizeName(name string) string {
+	return strings.ToLower(cleanName(name))
+}
+
+// findPersonByName returns the Person whose name or alias matches the provided name, regardless of capitalization or
+// whitespace, or false if there is no such Person.
+func findPersonByName(people []model.Person, name string) (model.Person, bool) {
+	n := normalizeName(name)
+	for _, p := range people {
+		for _, pn := range p.Names() {
+			if normalizeName(pn) == n {
+				return p, true
+			}
+		}
+	}
+	return model.Person{}, false
+}
+
+// resolvePerson returns the Person of the specified community whose name or alias matches the provided name,
+// creating a new Person with the name if there is no such Person.
+func resolvePerson(ctx context.Context, repo PersonRepository, communityID string, name string) (model.Person, error) {
+	people, err := repo.FindByCommunityID(ctx, communityID)
+	if err != nil {
+		return model.Person{}, fmt.Errorf("finding people: %w", err)
+	}
+
+	if p, ok := findPersonByName(people, name); ok {
+		return p, nil
+	}
+
+	p := model.Person{
+		ID:          xid.New().String(),
+		CommunityID: communityID,
+		Name:        cleanName(name),
+		Aliases:     []string{},
+		Created:     time.Now(),
+	}
+	if err := repo.Create(ctx, p); err != nil {
+		return model.Person{}, fmt.Errorf("creating person: %w", err)
+	}
+
+	return p, nil
+}
+
+// findPerson returns the Person with the specified ID from the provided repository, provided that it belongs to the
+// current community. Otherwise, ErrPersonNotFound is returned.
+func findPerson(ctx context.Context, repo PersonRepository, id string) (model.Person, error) {
+	p, err := repo.FindByID(ctx, id)
+	if err == storage.ErrNotFound || (err == nil && p.CommunityID != ctxval.CommunityFromContext(ctx).ID) {
+		return model.Person{}, ErrPersonNotFound
+	}
+	return p, err
+}
+
+// Person provides a service for browsing and managing the People to whom quotes are attributed.
+type Person struct {
+	repo  PersonRepository
+	qr    QuoteRepository
+	ur    UserRepository
+	audit AuditLog
+}
+
+// NewPersonService returns a new Person service with the provided PersonRepository, QuoteRepository used to
+// attribute quotes to people, and UserRepository used to find the users people are linked to. Changes made by admins
+// are recorded using the provided AuditLog service.
+func NewPersonService(repo PersonRepository, qr QuoteRepository, ur UserRepository, audit AuditLog) Person {
+	return Person{
+		repo:  repo,
+		qr:    qr,
+		ur:    ur,
+		audit: audit,
+	}
+}
+
+// GetPeople returns every Person of the current community and the number of quotes attributed to them, ordered by
+// name.
+func (s Person) GetPeople(ctx context.Context) ([]PersonSummary, error) {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return nil, err
+	}
+
+	people, err := s.repo.FindByCommunityID(ctx, ctxval.CommunityFromContext(ctx).ID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.qr.CountByQuoteeID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]PersonSummary, len(people))
+	for i, p := range people {
+		summaries[i] = PersonSummary{
+			Person: p,
+			Quotes: counts[p.ID],
+		}
+	}
+
+	return summaries, nil
+}
+
+// GetPerson returns the Person with the specified ID.
+func (s Person) GetPerson(ctx context.Context, id string) (model.Person, error) {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return model.Person{}, err
+	}
+
+	return findPerson(ctx, s.repo, id)
+}
+
+// UpdatePerson updates the Name, Aliases, and UserID of the Person identified by p.ID, and can only be performed by
+// admins. Names and aliases must not be used by any other Person, and a User may only be linked to one Person.
+func (s Person) UpdatePerson(ctx context.Context, p model.Person) error {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return err
+	}
+
+	existing, err := findPerson(ctx, s.repo, p.ID)
+	if err != nil {
+		return err
+	}
+
+	people, err := s.repo.FindByCommunityID(ctx, existing.CommunityID)
+	if err != nil {
+		return err
+	}
+
+	verr := Error{
+		StatusCode: 400,
+	}
+
+	existing.Name = cleanName(p.Name)
+	if existing.Name == "" {
+		verr.addIssue("Name must not be blank.")
+	}
+
+	// aliases are deduplicated, and those matching the name are dropped
+	seen := map[string]bool{normalizeName(existing.Name): true}
+	existing.Aliases = []string{}
+	for _, a := range p.Aliases {
+		a = cleanName(a)
+		if a == "" || seen[normalizeName(a)] {
+			continue
+		}
+		seen[normalizeName(a)] = true
+		existing.Aliases = append(existing.Aliases, a)
+	}
+
+	for _, n := range existing.Names() {
+		if other, ok := findPersonByName(people, n); ok && other.ID != existing.ID {
+			verr.addIssue(fmt.Sprintf("%q is already a name of %v.", n, other.Name))
+		}
+	}
+
+	existing.UserID = p.UserID
+	if existing.UserID != "" {
+		if _, err := s.ur.FindByID(ctx, existing.UserID); err == storage.ErrNotFound {
+			verr.addIssue("The linked user does not exist.")
+		} else if err != nil {
+			return err
+		}
+
+		for _, other := range people {
+			if other.UserID == existing.UserID && other.ID != existing.ID {
+				verr.addIssue(fmt.Sprintf("The linked user is already linked to %v.", other.Name))
+			}
+		}
+	}
+
+	if verr.HasIssues() {
+		return verr
+	}
+
+	if err := s.repo.Update(ctx, existing); err != nil {
+		return err
+	}
+
+	// quotes are updated to reflect any change to the person's name
+	if err := s.qr.ReassignQuotee(ctx, existing.ID, existing.ID, existing.Name); err != nil {
+		return fmt.Errorf("renaming quotes of person: %w", err)
+	}
+
+	return s.audit.record(ctx, model.AuditEditPerson, existing.ID, strings.Join(existing.Names(), ", "))
+}
+
+// MergePeople merges the Person fromID into the Person intoID, and can only be performed by admins. Quotes
+// attributed to fromID are reattributed to intoID, the names of fromID become aliases of intoID, and fromID is
+// deleted.
+func (s Person) MergePeople(ctx context.Context, fromID string, intoID string) error {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return err
+	}
+
+	if fromID == intoID {
+		return Error{
+			Issues:     []string{"A person cannot be merged into themselves."},
+			StatusCode: 400,
+		}
+	}
+
+	from, err := findPerson(ctx, s.repo, fromID)
+	if err != nil {
+		return err
+	}
+
+	into, err := findPerson(ctx, s.repo, intoID)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, n := range into.Names() {
+		seen[normalizeName(n)] = true
+	}
+	for _, n := range from.Names() {
+		if !seen[normalizeName(n)] {
+			seen[normalizeName(n)] = true
+			into.Aliases = append(into.Aliases, n)
+		}
+	}
+	if into.UserID == "" {
+		into.UserID = from.UserID
+	}
+
+	if err := s.repo.Update(ctx, into); err != nil {
+		return err
+	}
+
+	if err := s.qr.ReassignQuotee(ctx, from.ID, into.ID, into.Name); err != nil {
+		return fmt.Errorf("reassigning quotes: %w", err)
+	}
+
+	if err := s.repo.Delete(ctx, from.ID); err != nil {
+		return fmt.Errorf("deleting merged person: %w", err)
+	}
+
+	return s.audit.record(ctx, model.AuditMergePeople, into.ID, from.Name+" ("+from.ID+") into "+into.Name)
+}
+
+// MigrateQuotees attributes every quote which is not yet attributed to a Person to the Person matching its Quotee,
+// creating people in the quote's community as required, and returns the number of quotes which were attributed. It is
+// used to migrate quotes submitted before people were introduced, and may safely be run repeatedly.
+func (s Person) MigrateQuotees(ctx context.Context) (int, error) {
+	quotes, err := s.qr.Query(ctx, QuoteQuery{})
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, q := range quotes {
+		if q.QuoteeID != "" {
+			continue
+		}
+
+		p, err := resolvePerson(ctx, s.repo, q.CommunityID, q.Quotee)
+		if err != nil {
+			return n, err
+		}
+
+		q.QuoteeID = p.ID
+		q.Quotee = p.Name
+		if err := s.qr.Update(ctx, q); err != nil {
+			return n, fmt.Errorf("attributing quote %v: %w", q.ID, err)
+		}
+		n++
+	}
+
+	return n, nil
+}
@@ -0,0 +1,172 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+// newPersonTest returns a Quote service and a Person service which share their repositories, and the AuditLog
+// service to which they record.
+func newPersonTest(t *testing.T) (service.Quote, service.Person, service.AuditLog) {
+	t.Helper()
+	is := is.New(t)
+
+	quoteRepo := inmemory.NewQuoteRepository()
+	personRepo := inmemory.NewPersonRepository()
+	userRepo := inmemory.NewUserRepository()
+	for _, u := range []model.User{submitter, otherUser, adminUser} {
+		is.NoErr(userRepo.Create(context.Background(), u))
+	}
+
+	audit := service.NewAuditLogService(inmemory.NewAuditLogRepository())
+	quotes := service.NewQuoteService(quoteRepo, inmemory.NewReactionRepository(), inmemory.NewCommentRepository(),
+		personRepo, inmemory.NewTransactor(), time.Hour, audit, service.Webhook{}, discardLogger)
+	return quotes, service.NewPersonService(personRepo, quoteRepo, userRepo, audit), audit
+}
+
+// isBadRequest returns true if err is a service Error with a 400 status code.
+func isBadRequest(err error) bool {
+	var serr service.Error
+	return errors.As(err, &serr) && serr.StatusCode == 400
+}
+
+func TestQuote_CreateQuoteResolvesPerson(t *testing.T) {
+	is := is.New(t)
+	quotes, people, _ := newPersonTest(t)
+
+	ctxSubmitter := userContext(submitter)
+	q1 := model.Quote{Quotee: "Josh Smith", Quote: "First"}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &q1))
+	is.True(q1.QuoteeID != "") // quote should be attributed to a person
+
+	q2 := model.Quote{Quotee: "  josh   SMITH ", Quote: "Second"}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &q2))
+	is.Equal(q2.QuoteeID, q1.QuoteeID) // differently spelled names should refer to the same person
+	is.Equal(q2.Quotee, "Josh Smith")  // quotee should be the person's name
+
+	blank := model.Quote{Quotee: "   ", Quote: "Nobody"}
+	is.True(isBadRequest(quotes.CreateQuote(ctxSubmitter, &blank))) // blank quotees should be rejected
+
+	all, err := people.GetPeople(ctxSubmitter)
+	is.NoErr(err)
+	is.Equal(len(all), 1)      // only one person should be created
+	is.Equal(all[0].Quotes, 2) // both quotes should be counted
+
+	q2.Quotee = "Jaustin Ross"
+	is.NoErr(quotes.EditQuote(ctxSubmitter, &q2))
+	is.True(q2.QuoteeID != q1.QuoteeID) // changing the quotee should reattribute the quote
+}
+
+func TestPerson_UpdatePerson(t *testing.T) {
+	is := is.New(t)
+	quotes, people, _ := newPersonTest(t)
+
+	ctxSubmitter := userContext(submitter)
+	josh := model.Quote{Quotee: "Josh", Quote: "First"}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &josh))
+	ajbr := model.Quote{Quotee: "AJBR", Quote: "Second"}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &ajbr))
+
+	p := model.Person{ID: josh.QuoteeID, Name: "Josh Smith", Aliases: []string{" Josh", "js", "JS", ""}}
+	is.Equal(people.UpdatePerson(ctxSubmitter, p), service.ErrNotAuthorized) // only admins may edit people
+
+	ctxAdmin := userContext(adminUser)
+	is.NoErr(people.UpdatePerson(ctxAdmin, p))
+
+	got, err := people.GetPerson(ctxAdmin, p.ID)
+	is.NoErr(err)
+	is.Equal(got.Name, "Josh Smith")              // name should be updated
+	is.Equal(got.Aliases, []string{"Josh", "js"}) // aliases should be cleaned and deduplicated
+
+	renamed, err := quotes.GetQuote(ctxSubmitter, josh.ID)
+	is.NoErr(err)
+	is.Equal(renamed.Quotee, "Josh Smith") // quotes should be renamed with their person
+
+	josh.Quotee = "JOSH smith"
+	is.NoErr(quotes.EditQuote(ctxSubmitter, &josh))
+	is.Equal(josh.QuoteeID, p.ID)       // respelling the quotee should not reattribute the quote
+	is.Equal(josh.Quotee, "Josh Smith") // respelling the quotee should not rename the quote
+
+	viaAlias := model.Quote{Quotee: "JS", Quote: "Third"}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &viaAlias))
+	is.Equal(viaAlias.QuoteeID, p.ID) // quotes should be attributed to people by their aliases
+
+	taken := model.Person{ID: ajbr.QuoteeID, Name: "AJBR", Aliases: []string{"josh"}}
+	is.True(isBadRequest(people.UpdatePerson(ctxAdmin, taken))) // names of other people should be rejected
+
+	missingUser := model.Person{ID: ajbr.QuoteeID, Name: "AJBR", UserID: "missing"}
+	is.True(isBadRequest(people.UpdatePerson(ctxAdmin, missingUser))) // linked users must exist
+
+	is.NoErr(people.UpdatePerson(ctxAdmin, model.Person{ID: p.ID, Name: p.Name, UserID: otherUser.ID}))
+	linked := model.Person{ID: ajbr.QuoteeID, Name: "AJBR", UserID: otherUser.ID}
+	is.True(isBadRequest(people.UpdatePerson(ctxAdmin, linked))) // users may only be linked to one person
+
+	is.Equal(people.UpdatePerson(ctxAdmin, model.Person{ID: "missing", Name: "Nobody"}), service.ErrPersonNotFound)
+}
+
+func TestPerson_MergePeople(t *testing.T) {
+	is := is.New(t)
+	quotes, people, audit := newPersonTest(t)
+
+	ctxSubmitter := userContext(submitter)
+	from := model.Quote{Quotee: "Jaustin", Quote: "First"}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &from))
+	into := model.Quote{Quotee: "Jaustin Ross", Quote: "Second"}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &into))
+
+	is.Equal(people.MergePeople(ctxSubmitter, from.QuoteeID, into.QuoteeID), service.ErrNotAuthorized) // admin only
+
+	ctxAdmin := userContext(adminUser)
+	is.True(isBadRequest(people.MergePeople(ctxAdmin, into.QuoteeID, into.QuoteeID))) // people can't merge into self
+	is.NoErr(people.MergePeople(ctxAdmin, from.QuoteeID, into.QuoteeID))
+
+	_, err := people.GetPerson(ctxAdmin, from.QuoteeID)
+	is.Equal(err, service.ErrPersonNotFound) // merged person should be deleted
+
+	got, err := people.GetPerson(ctxAdmin, into.QuoteeID)
+	is.NoErr(err)
+	is.Equal(got.Aliases, []string{"Jaustin"}) // merged person's name should become an alias
+
+	moved, err := quotes.GetQuote(ctxSubmitter, from.ID)
+	is.NoErr(err)
+	is.Equal(moved.QuoteeID, into.QuoteeID) // quotes should be reattributed
+	is.Equal(moved.Quotee, "Jaustin Ross")  // quotes should take the name of the person they were merged into
+
+	entries, err := audit.QueryAuditLog(ctxAdmin, service.AuditLogQuery{Action: model.AuditMergePeople})
+	is.NoErr(err)
+	is.Equal(len(entries), 1) // merges should be audited
+}
+
+func TestPerson_MigrateQuotees(t *testing.T) {
+	is := is.New(t)
+
+	quoteRepo := inmemory.NewQuoteRepository()
+	for i, quotee := range []string{"Josh", "josh ", "AJBR"} {
+		q := model.Quote{ID: string(rune('a' + i)), CommunityID: testCommunity.ID, Quotee: quotee, Quote: "Quote", Created: time.Now()}
+		is.NoErr(quoteRepo.Create(context.Background(), q))
+	}
+
+	people := service.NewPersonService(inmemory.NewPersonRepository(), quoteRepo, inmemory.NewUserRepository(),
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()))
+
+	n, err := people.MigrateQuotees(context.Background())
+	is.NoErr(err)
+	is.Equal(n, 3) // all quotes should be attributed
+
+	ctxSubmitter := userContext(submitter)
+	all, err := people.GetPeople(ctxSubmitter)
+	is.NoErr(err)
+	is.Equal(len(all), 2) // quotees with the same name should be attributed to the same person
+
+	n, err = people.MigrateQuotees(context.Background())
+	is.NoErr(err)
+	is.Equal(n, 0) // attributed quotes should not be migrated again
+}
@@ -2,65 +2,517 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/logutils"
 	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
 
 	"github.com/rs/xid"
 )
 
+// ErrQuoteNotFound is returned when a requested quote does not exist.
+var ErrQuoteNotFound = Error{
+	Issues:     []string{"Quote not found."},
+	StatusCode: 404,
+}
+
+// ErrQuoteNotModifiable is returned when a user attempts to edit or delete a quote which they are not permitted to
+// modify.
+var ErrQuoteNotModifiable = Error{
+	Issues:     []string{"You may only edit or delete your own quotes shortly after submitting them."},
+	StatusCode: 403,
+}
+
 // QuoteRepository provides methods for storing, manipulating, and retrieving Quotes
 type QuoteRepository interface {
 	Create(ctx context.Context, q model.Quote) error
 	Update(ctx context.Context, q model.Quote) error
+	Delete(ctx context.Context, id string) error
 	FindByID(ctx context.Context, id string) (model.Quote, error)
-	FindAll(ctx context.Context) ([]model.Quote, error)
+	// ReassignSubmitter changes the SubmitterID of every Quote submitted by the user fromID to toID.
+	ReassignSubmitter(ctx context.Context, fromID string, toID string) error
+	// ReassignQuotee changes the QuoteeID of every Quote attributed to the person fromID, and the SpeakerID of every
+	// line they said, to toID, and the corresponding Quotee and Speaker to the provided name. The Quote of each
+	// conversation they spoke in is rewritten as its ConversationText.
+	ReassignQuotee(ctx context.Context, fromID string, toID string, quotee string) error
+	// CountByQuoteeID returns the number of Quotes said by each person who has said at least one Quote, either as
+	// its quotee or a speaker in it.
+	CountByQuoteeID(ctx context.Context) (map[string]int, error)
+	// RenameTag replaces the tag from with the tag to on every Quote of the specified community tagged with it,
+	// merging the tags on Quotes which are already tagged with both.
+	RenameTag(ctx context.Context, communityID string, from string, to string) error
+	// CountByTag returns the number of Quotes of the specified community tagged with each tag which is on at least
+	// one of them.
+	CountByTag(ctx context.Context, communityID string) (map[string]int, error)
+	// Query returns the Quotes matching the provided QuoteQuery, ordered from newest to oldest by Created, with ties
+	// broken by descending ID.
+	Query(ctx context.Context, q QuoteQuery) ([]model.Quote, error)
+}
+
+// QuoteQuery specifies which Quotes should be returned by QuoteRepository.Query. Zero values of each field are
+// ignored, and as such, an empty QuoteQuery matches every Quote.
+type QuoteQuery struct {
+	// CommunityID restricts results to Quotes submitted to the community with this ID.
+	CommunityID string
+	// Limit is the maximum number of Quotes to return.
+	Limit int
+	// After restricts results to Quotes which are ordered after (older than) the provided cursor.
+	After *QuoteCursor
+	// CreatedFrom restricts results to Quotes created at or after this time.
+	CreatedFrom time.Time
+	// CreatedBefore restricts results to Quotes created before this time.
+	CreatedBefore time.Time
+	// Quotee restricts results to Quotes attributed to this quotee, regardless of capitalization.
+	Quotee string
+	// QuoteeID restricts results to Quotes said by the person with this ID, either as its quotee or a speaker in it.
+	QuoteeID string
+	// SubmitterID restricts results to Quotes submitted by the user with this ID.
+	SubmitterID string
+	// Tag restricts results to Quotes tagged with this tag.
+	Tag string
+	// Search restricts results to Quotes whose Quote, Quotee, or Context match every term of the search query (as
+	// returned by storage.SearchTerms).
+	Search string
+}
+
+// QuoteCursor identifies a position in the stable ordering of Quotes, and is used to paginate through the results of
+// a QuoteQuery.
+type QuoteCursor struct {
+	// Reactions is the number of reactions to the Quote, which is only used when ordering quotes by reactions, and is
+	// ignored by QuoteRepository.Query.
+	Reactions int
+	Created   time.Time
+	ID        string
+}
+
+// CursorOf returns a QuoteCursor positioned at the provided Quote.
+func CursorOf(q model.Quote) QuoteCursor {
+	return QuoteCursor{
+		Created: q.Created,
+		ID:      q.ID,
+	}
+}
+
+// String encodes the QuoteCursor as an opaque string which can be safely included in URLs.
+func (c QuoteCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.Created.UnixNano(), 10) + ":" +
+		strconv.Itoa(c.Reactions) + ":" + c.ID))
+}
+
+// ParseQuoteCursor decodes a QuoteCursor previously encoded by QuoteCursor.String.
+func ParseQuoteCursor(str string) (QuoteCursor, error) {
+	errInvalid := Error{
+		Issues:     []string{"Invalid page cursor."},
+		StatusCode: 400,
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return QuoteCursor{}, errInvalid
+	}
+
+	parts := strings.SplitN(string(b), ":", 3)
+	if len(parts) != 3 {
+		return QuoteCursor{}, errInvalid
+	}
+
+	n, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return QuoteCursor{}, errInvalid
+	}
+
+	reactions, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return QuoteCursor{}, errInvalid
+	}
+
+	return QuoteCursor{
+		Reactions: reactions,
+		Created:   time.Unix(0, n),
+		ID:        parts[2],
+	}, nil
 }
 
 // Quote provides a service for interacting with Quotes
 type Quote struct {
 	repo QuoteRepository
+	rr   ReactionRepository
+	cr   CommentRepository
+	pr   PersonRepository
+	tx   Transactor
+	// editWindow is the amount of time after a quote is created during which its submitter may edit or delete it.
+	editWindow time.Duration
+	audit      AuditLog
+	webhooks   Webhook
+	log        *slog.Logger
 }
 
-// NewQuoteService returns a new QuoteService with the provided QuoteRepository
-func NewQuoteService(repo QuoteRepository) Quote {
+// NewQuoteService returns a new QuoteService with the provided QuoteRepository, ReactionRepository used to order
+// quotes by their reactions, CommentRepository used to remove the comments of deleted quotes, and PersonRepository
+// used to attribute quotes to people, allowing submitters to modify their own quotes for the duration of editWindow.
+// Quotes are deleted along with their reactions and comments in a transaction run by the Transactor. Modifications
+// made by admins to other users' quotes are recorded using the provided AuditLog service, and new quotes
+// are announced using the provided Webhook service, with failures to announce them logged to log.
+func NewQuoteService(repo QuoteRepository, rr ReactionRepository, cr CommentRepository, pr PersonRepository,
+	tx Transactor, editWindow time.Duration, audit AuditLog, webhooks Webhook, log *slog.Logger) Quote {
 	return Quote{
-		repo,
+		repo:       repo,
+		rr:         rr,
+		cr:         cr,
+		pr:         pr,
+		tx:         tx,
+		editWindow: editWindow,
+		audit:      audit,
+		webhooks:   webhooks,
+		log:        log,
 	}
 }
 
-// CreateQuote creates a new Quote, setting its ID, Created, and SubmitterID fields
-func (s *Quote) CreateQuote(ctx context.Context, q *model.Quote) error {
+// _maxQuoteLines is the maximum number of lines in a conversation quote.
+const _maxQuoteLines = 30
 
-	if err := verifyUserPrivilege(ctx); err != nil {
+// cleanLines removes blank lines from a conversation quote, and converts a conversation of a single line into a
+// quote said by one person.
+func cleanLines(q *model.Quote) {
+	var lines []model.QuoteLine
+	for _, l := range q.Lines {
+		l.Speaker = cleanName(l.Speaker)
+		l.Text = strings.TrimSpace(l.Text)
+		if l.Speaker != "" || l.Text != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	q.Lines = lines
+	if len(lines) == 1 {
+		q.Quotee = lines[0].Speaker
+		q.Quote = lines[0].Text
+		q.Lines = nil
+	}
+}
+
+// validateQuote checks that the user provided fields of a Quote are valid, and returns an Error containing any issues.
+func validateQuote(q model.Quote) Error {
+	err := Error{
+		StatusCode: 400,
+	}
+
+	validateTags(q.Tags, &err)
+
+	if q.IsConversation() {
+		if len(q.Lines) > _maxQuoteLines {
+			err.addIssue(fmt.Sprintf("Conversations must not have more than %d lines.", _maxQuoteLines))
+		}
+		for i, l := range q.Lines {
+			if l.Text == "" {
+				err.addIssue(fmt.Sprintf("Line %d must not be blank.", i+1))
+			}
+			if l.Speaker == "" {
+				err.addIssue(fmt.Sprintf("Line %d must be attributed to someone.", i+1))
+			}
+		}
 		return err
 	}
 
-	var err Error
 	if q.Quote == "" {
 		err.addIssue("Quote must not be blank.")
 	}
-	if q.Quotee == "" {
+	if cleanName(q.Quotee) == "" {
 		err.addIssue("This quote must be attributed to someone.")
 	}
+	return err
+}
 
-	if err.HasIssues() {
+// attributeLines attributes each line of a conversation quote to the person who said it, and sets the Quotee of the
+// quote to its first speaker, and its Quote to the conversation as text.
+func (s *Quote) attributeLines(ctx context.Context, q *model.Quote) error {
+	for i, l := range q.Lines {
+		p, err := resolvePerson(ctx, s.pr, ctxval.CommunityFromContext(ctx).ID, l.Speaker)
+		if err != nil {
+			return err
+		}
+
+		q.Lines[i].SpeakerID = p.ID
+		q.Lines[i].Speaker = p.Name
+	}
+
+	q.QuoteeID = q.Lines[0].SpeakerID
+	q.Quotee = q.Lines[0].Speaker
+	q.Quote = q.ConversationText()
+	return nil
+}
+
+// CreateQuote creates a new Quote, setting its ID, Created, and SubmitterID fields, and queues webhook deliveries
+// announcing it. If q has Lines, it is created as a conversation, and its Quote and Quotee are derived from them.
+func (s *Quote) CreateQuote(ctx context.Context, q *model.Quote) error {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return err
+	}
+
+	cleanLines(q)
+	q.Tags = cleanTags(q.Tags)
+	if err := validateQuote(*q); err.HasIssues() {
 		return err
 	}
 
+	if q.IsConversation() {
+		if err := s.attributeLines(ctx, q); err != nil {
+			return err
+		}
+	} else {
+		p, err := resolvePerson(ctx, s.pr, ctxval.CommunityFromContext(ctx).ID, q.Quotee)
+		if err != nil {
+			return err
+		}
+		q.QuoteeID = p.ID
+		q.Quotee = p.Name
+	}
+
 	q.ID = xid.New().String()
+	q.CommunityID = ctxval.CommunityFromContext(ctx).ID
 	q.Created = time.Now()
 	q.SubmitterID = ctxval.UserFromContext(ctx).ID
-	return s.repo.Create(ctx, *q)
+
+	if err := s.repo.Create(ctx, *q); err != nil {
+		return err
+	}
+
+	// the quote has already been saved, so failing to announce it does not fail its creation
+	if err := s.webhooks.QuoteCreated(ctx, *q); err != nil {
+		s.log.Warn("unable to queue webhooks for new quote", "quote", q.ID, logutils.Error(err))
+	}
+
+	return nil
+}
+
+// CanModifyQuote returns true if the user on the context may edit or delete the provided Quote. Admins may modify
+// any quote, while other users may only modify quotes they submitted, within the edit window.
+func (s *Quote) CanModifyQuote(ctx context.Context, q model.Quote) bool {
+	if isAdmin(ctx) {
+		return true
+	}
+	return isAuthorized(ctx) && ctxval.UserFromContext(ctx).ID == q.SubmitterID && time.Since(q.Created) < s.editWindow
+}
+
+// findQuote returns the Quote with the specified ID from the provided repository, provided that it belongs to the
+// current community. Otherwise, ErrQuoteNotFound is returned.
+func findQuote(ctx context.Context, repo QuoteRepository, id string) (model.Quote, error) {
+	q, err := repo.FindByID(ctx, id)
+	if err == storage.ErrNotFound || (err == nil && q.CommunityID != ctxval.CommunityFromContext(ctx).ID) {
+		return model.Quote{}, ErrQuoteNotFound
+	}
+	return q, err
 }
 
-// GetAllQuotes returns all Quotes
-func (s *Quote) GetAllQuotes(ctx context.Context) ([]model.Quote, error) {
+// findModifiableQuote returns the Quote with the specified ID, provided that the user on the context may modify it.
+func (s *Quote) findModifiableQuote(ctx context.Context, id string) (model.Quote, error) {
 	if err := verifyUserPrivilege(ctx); err != nil {
-		return nil, err
+		return model.Quote{}, err
+	}
+
+	q, err := findQuote(ctx, s.repo, id)
+	if err != nil {
+		return model.Quote{}, err
+	}
+
+	if !s.CanModifyQuote(ctx, q) {
+		return model.Quote{}, ErrQuoteNotModifiable
+	}
+
+	return q, nil
+}
+
+// GetQuote returns the Quote with the specified ID.
+func (s *Quote) GetQuote(ctx context.Context, id string) (model.Quote, error) {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return model.Quote{}, err
+	}
+
+	return findQuote(ctx, s.repo, id)
+}
+
+// GetQuoteForEdit returns the Quote with the specified ID, if the user on the context may edit it.
+func (s *Quote) GetQuoteForEdit(ctx context.Context, id string) (model.Quote, error) {
+	return s.findModifiableQuote(ctx, id)
+}
+
+// EditQuote updates the Quote, Quotee, Lines, Tags, and Context of an existing Quote identified by q.ID. All other
+// fields are preserved, and q is updated to reflect the stored Quote.
+func (s *Quote) EditQuote(ctx context.Context, q *model.Quote) error {
+	existing, err := s.findModifiableQuote(ctx, q.ID)
+	if err != nil {
+		return err
+	}
+
+	cleanLines(q)
+	q.Tags = cleanTags(q.Tags)
+	if err := validateQuote(*q); err.HasIssues() {
+		return err
+	}
+
+	if q.IsConversation() {
+		if err := s.attributeLines(ctx, q); err != nil {
+			return err
+		}
+		existing.QuoteeID = q.QuoteeID
+		existing.Quotee = q.Quotee
+	} else if existing.QuoteeID == "" || normalizeName(q.Quotee) != normalizeName(existing.Quotee) {
+		// the quote is only reattributed if the quotee was changed, rather than respelled
+		p, err := resolvePerson(ctx, s.pr, ctxval.CommunityFromContext(ctx).ID, q.Quotee)
+		if err != nil {
+			return err
+		}
+		existing.QuoteeID = p.ID
+		existing.Quotee = p.Name
+	}
+
+	existing.Quote = q.Quote
+	existing.Lines = q.Lines
+	existing.Tags = q.Tags
+	existing.Context = q.Context
+	*q = existing
+
+	if err := s.repo.Update(ctx, existing); err != nil {
+		return err
+	}
+
+	return s.recordModeration(ctx, model.AuditEditQuote, existing)
+}
+
+// DeleteQuote deletes the Quote with the specified ID, and any reactions and comments on it.
+func (s *Quote) DeleteQuote(ctx context.Context, id string) error {
+	q, err := s.findModifiableQuote(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		if err := s.rr.DeleteByQuoteID(ctx, id); err != nil {
+			return err
+		}
+
+		if err := s.cr.DeleteByQuoteID(ctx, id); err != nil {
+			return err
+		}
+
+		return s.recordModeration(ctx, model.AuditDeleteQuote, q)
+	})
+}
+
+// recordModeration records an action in the audit log if the quote was modified by someone other than its submitter.
+func (s *Quote) recordModeration(ctx context.Context, action model.AuditAction, q model.Quote) error {
+	if ctxval.UserFromContext(ctx).ID == q.SubmitterID {
+		return nil
+	}
+
+	return s.audit.record(ctx, action, q.ID, q.Quotee+": "+q.Quote)
+}
+
+// verifyQuoteQuery checks that the user on the context may perform the provided QuoteQuery.
+func verifyQuoteQuery(ctx context.Context, q QuoteQuery) error {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return err
+	}
+
+	// the submitter of a quote is only visible to admins, and as such, other users may only filter for their own quotes
+	if q.SubmitterID != "" && q.SubmitterID != ctxval.UserFromContext(ctx).ID && !isAdmin(ctx) {
+		return ErrNotAuthorized
+	}
+
+	return nil
+}
+
+// QueryQuotes returns the Quotes matching the provided QuoteQuery, from newest to oldest. If q.Limit is set and
+// additional matching Quotes remain, a cursor is returned which can be provided as q.After to retrieve the next page.
+func (s *Quote) QueryQuotes(ctx context.Context, q QuoteQuery) (quotes []model.Quote, next *QuoteCursor, err error) {
+	if err := verifyQuoteQuery(ctx, q); err != nil {
+		return nil, nil, err
+	}
+
+	q.CommunityID = ctxval.CommunityFromContext(ctx).ID
+	// tags are stored normalized, so the filter must be too
+	q.Tag = normalizeName(q.Tag)
+
+	limit := q.Limit
+	if limit > 0 {
+		// request an additional quote to determine if there is another page
+		q.Limit++
+	}
+
+	quotes, err = s.repo.Query(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if limit > 0 && len(quotes) > limit {
+		quotes = quotes[:limit]
+		c := CursorOf(quotes[limit-1])
+		next = &c
+	}
+
+	return quotes, next, nil
+}
+
+// QueryLovedQuotes returns the Quotes matching the provided QuoteQuery, from most to least reactions, with ties
+// ordered from newest to oldest. Pagination works as with QueryQuotes.
+func (s *Quote) QueryLovedQuotes(ctx context.Context, q QuoteQuery) (quotes []model.Quote, next *QuoteCursor, err error) {
+	if err := verifyQuoteQuery(ctx, q); err != nil {
+		return nil, nil, err
+	}
+
+	q.CommunityID = ctxval.CommunityFromContext(ctx).ID
+	q.Tag = normalizeName(q.Tag)
+
+	// since reactions are stored separately from quotes, every matching quote is retrieved and ordered here
+	limit, after := q.Limit, q.After
+	q.Limit, q.After = 0, nil
+
+	quotes, err = s.repo.Query(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	counts, err := s.rr.CountByQuote(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// quotes are already ordered from newest to oldest, which is preserved amongst quotes with equal reactions
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return counts[quotes[i].ID] > counts[quotes[j].ID]
+	})
+
+	if after != nil {
+		quotes = quotes[sort.Search(len(quotes), func(i int) bool {
+			n, c := counts[quotes[i].ID], *after
+			if n != c.Reactions {
+				return n < c.Reactions
+			}
+			if !quotes[i].Created.Equal(c.Created) {
+				return quotes[i].Created.Before(c.Created)
+			}
+			return quotes[i].ID < c.ID
+		}):]
+	}
+
+	if limit > 0 && len(quotes) > limit {
+		quotes = quotes[:limit]
+		c := CursorOf(quotes[limit-1])
+		c.Reactions = counts[c.ID]
+		next = &c
 	}
 
-	quotes, err := s.repo.FindAll(ctx)
-	return quotes, err
+	return quotes, next, nil
 }
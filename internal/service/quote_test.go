@@ -0,0 +1,331 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+var (
+	submitter = model.User{ID: "submitter"}
+	otherUser = model.User{ID: "other"}
+	adminUser = model.User{ID: "admin", Admin: true}
+
+	// testCommunity is the community in which tests are performed, unless they require another.
+	testCommunity = model.Community{ID: model.DefaultCommunityID, Title: "Test Community"}
+
+	// discardLogger is provided to services which log failures that tests do not inspect.
+	discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+)
+
+// userContext returns a context in which the provided user is signed in to testCommunity, as a member who has passed
+// its entry quiz.
+func userContext(u model.User) context.Context {
+	return communityContext(u, testCommunity)
+}
+
+// communityContext returns a context in which the provided user is signed in to the provided community, as a member
+// who has passed its entry quiz.
+func communityContext(u model.User, c model.Community) context.Context {
+	ctx := ctxval.ContextWithCommunity(ctxval.ContextWithUser(context.Background(), u), c)
+	return ctxval.ContextWithMembership(ctx, model.Membership{
+		CommunityID: c.ID,
+		UserID:      u.ID,
+		QuizPassed:  true,
+	})
+}
+
+func TestQuote_EditQuote(t *testing.T) {
+	is := is.New(t)
+
+	repo := inmemory.NewQuoteRepository()
+	quoteService := service.NewQuoteService(repo, inmemory.NewReactionRepository(), inmemory.NewCommentRepository(),
+		inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), service.Webhook{}, discardLogger)
+
+	ctxSubmitter := userContext(submitter)
+	q := model.Quote{
+		Quotee: "Jaustin Ross",
+		Quote:  "Isn't every truck a hand truck?",
+	}
+	is.NoErr(quoteService.CreateQuote(ctxSubmitter, &q)) // creating quote should not fail
+
+	edit := model.Quote{
+		ID:     q.ID,
+		Quotee: "Jaustin Ross",
+		Quote:  "Isn't every truck a hand truck cuz of the steering wheel?",
+	}
+	is.NoErr(quoteService.EditQuote(ctxSubmitter, &edit)) // submitter should be able to edit their quote
+	is.Equal(edit.SubmitterID, submitter.ID)              // edit should preserve submitter
+	is.Equal(edit.Created, q.Created)                     // edit should preserve created time
+
+	got, err := repo.FindByID(context.Background(), q.ID)
+	is.NoErr(err)
+	is.Equal(got.Quote, edit.Quote) // stored quote should reflect edit
+
+	blank := model.Quote{ID: q.ID}
+	is.True(quoteService.EditQuote(ctxSubmitter, &blank) != nil) // edit with blank fields should fail
+
+	ctxOther := userContext(otherUser)
+	is.Equal(quoteService.EditQuote(ctxOther, &edit), service.ErrQuoteNotModifiable) // other users should not be able to edit
+
+	ctxAdmin := userContext(adminUser)
+	is.NoErr(quoteService.EditQuote(ctxAdmin, &edit)) // admins should be able to edit any quote
+
+	missing := model.Quote{ID: "missing", Quotee: "Nobody", Quote: "Nothing"}
+	is.Equal(quoteService.EditQuote(ctxAdmin, &missing), service.ErrQuoteNotFound) // editing missing quote should fail
+}
+
+func TestQuote_GetQuote(t *testing.T) {
+	is := is.New(t)
+
+	quoteService := service.NewQuoteService(inmemory.NewQuoteRepository(), inmemory.NewReactionRepository(),
+		inmemory.NewCommentRepository(), inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), service.Webhook{}, discardLogger)
+
+	ctxSubmitter := userContext(submitter)
+	q := model.Quote{
+		Quotee: "Jaustin Ross",
+		Quote:  "Isn't every truck a hand truck?",
+	}
+	is.NoErr(quoteService.CreateQuote(ctxSubmitter, &q)) // creating quote should not fail
+
+	ctxOther := userContext(otherUser)
+	got, err := quoteService.GetQuote(ctxOther, q.ID)
+	is.NoErr(err)          // other users should be able to get the quote
+	is.Equal(got.ID, q.ID) // returned quote should match
+
+	_, err = quoteService.GetQuote(ctxOther, "missing")
+	is.Equal(err, service.ErrQuoteNotFound) // getting missing quote should fail
+
+	_, err = quoteService.GetQuote(context.Background(), q.ID)
+	is.True(err != nil) // signed out users should not be able to get the quote
+}
+
+func TestQuote_CommunityIsolation(t *testing.T) {
+	is := is.New(t)
+
+	quoteService := service.NewQuoteService(inmemory.NewQuoteRepository(), inmemory.NewReactionRepository(),
+		inmemory.NewCommentRepository(), inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), service.Webhook{}, discardLogger)
+
+	ctxSubmitter := userContext(submitter)
+	q := model.Quote{Quotee: "AJBR", Quote: "Only for the default community"}
+	is.NoErr(quoteService.CreateQuote(ctxSubmitter, &q))
+	is.Equal(q.CommunityID, testCommunity.ID) // quote should belong to the current community
+
+	ctxElsewhere := communityContext(submitter, model.Community{ID: "other"})
+	_, err := quoteService.GetQuote(ctxElsewhere, q.ID)
+	is.Equal(err, service.ErrQuoteNotFound) // quotes should not be found from other communities
+
+	quotes, _, err := quoteService.QueryQuotes(ctxElsewhere, service.QuoteQuery{CommunityID: testCommunity.ID})
+	is.NoErr(err)
+	is.Equal(len(quotes), 0) // quotes of other communities should not be listed
+
+	is.Equal(quoteService.DeleteQuote(ctxElsewhere, q.ID), service.ErrQuoteNotFound) // quotes should not be deletable from other communities
+}
+
+func TestQuote_EditWindow(t *testing.T) {
+	is := is.New(t)
+
+	repo := inmemory.NewQuoteRepository()
+	quoteService := service.NewQuoteService(repo, inmemory.NewReactionRepository(), inmemory.NewCommentRepository(),
+		inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), service.Webhook{}, discardLogger)
+
+	old := model.Quote{
+		ID:          "old",
+		CommunityID: testCommunity.ID,
+		SubmitterID: submitter.ID,
+		Quotee:      "Charlene",
+		Quote:       "I'm an old quote",
+		Created:     time.Now().Add(-2 * time.Hour),
+	}
+	is.NoErr(repo.Create(context.Background(), old))
+
+	ctxSubmitter := userContext(submitter)
+	is.True(!quoteService.CanModifyQuote(ctxSubmitter, old))                                // submitter should not modify quotes outside window
+	is.Equal(quoteService.DeleteQuote(ctxSubmitter, old.ID), service.ErrQuoteNotModifiable) // submitter should not delete quotes outside window
+
+	ctxAdmin := userContext(adminUser)
+	is.True(quoteService.CanModifyQuote(ctxAdmin, old)) // admins should be able to modify quotes outside window
+}
+
+func TestQuote_DeleteQuote(t *testing.T) {
+	is := is.New(t)
+
+	repo := inmemory.NewQuoteRepository()
+	quoteService := service.NewQuoteService(repo, inmemory.NewReactionRepository(), inmemory.NewCommentRepository(),
+		inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), service.Webhook{}, discardLogger)
+
+	ctxSubmitter := userContext(submitter)
+	q := model.Quote{
+		Quotee: "AJBR",
+		Quote:  "I'm a typo'd quote",
+	}
+	is.NoErr(quoteService.CreateQuote(ctxSubmitter, &q))
+
+	ctxOther := userContext(otherUser)
+	is.Equal(quoteService.DeleteQuote(ctxOther, q.ID), service.ErrQuoteNotModifiable) // other users should not be able to delete
+
+	is.NoErr(quoteService.DeleteQuote(ctxSubmitter, q.ID)) // submitter should be able to delete their quote
+
+	_, err := repo.FindByID(context.Background(), q.ID)
+	is.True(err != nil) // deleted quote should not be found
+
+	ctxAdmin := userContext(adminUser)
+	is.Equal(quoteService.DeleteQuote(ctxAdmin, q.ID), service.ErrQuoteNotFound) // deleting missing quote should fail
+}
+
+func TestQuote_QueryQuotes(t *testing.T) {
+	is := is.New(t)
+
+	repo := inmemory.NewQuoteRepository()
+	quoteService := service.NewQuoteService(repo, inmemory.NewReactionRepository(), inmemory.NewCommentRepository(),
+		inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), service.Webhook{}, discardLogger)
+
+	ctxSubmitter := userContext(submitter)
+	for i := 0; i < 5; i++ {
+		q := model.Quote{
+			ID:          "q" + string(rune('a'+i)),
+			CommunityID: testCommunity.ID,
+			SubmitterID: submitter.ID,
+			Quotee:      "AJBR",
+			Quote:       "Quote",
+			Created:     time.Now().Add(time.Duration(-i) * time.Minute),
+		}
+		is.NoErr(repo.Create(context.Background(), q))
+	}
+
+	quotes, next, err := quoteService.QueryQuotes(ctxSubmitter, service.QuoteQuery{Limit: 3})
+	is.NoErr(err)
+	is.Equal(len(quotes), 3) // first page should be full
+	is.True(next != nil)     // first page should have a next page
+	is.Equal(next.ID, "qc")  // cursor should point to last quote of first page
+
+	quotes, next, err = quoteService.QueryQuotes(ctxSubmitter, service.QuoteQuery{Limit: 3, After: next})
+	is.NoErr(err)
+	is.Equal(len(quotes), 2) // second page should hold the remainder
+	is.Equal(next, nil)      // second page should be the last
+
+	c, err := service.ParseQuoteCursor(service.CursorOf(quotes[0]).String())
+	is.NoErr(err)                               // encoded cursor should parse
+	is.Equal(c.ID, quotes[0].ID)                // parsed cursor should retain ID
+	is.True(c.Created.Equal(quotes[0].Created)) // parsed cursor should retain created time
+
+	_, err = service.ParseQuoteCursor("not a cursor")
+	is.True(err != nil) // invalid cursor should not parse
+
+	_, _, err = quoteService.QueryQuotes(ctxSubmitter, service.QuoteQuery{SubmitterID: submitter.ID})
+	is.NoErr(err) // users should be able to filter for their own quotes
+
+	ctxOther := userContext(otherUser)
+	_, _, err = quoteService.QueryQuotes(ctxOther, service.QuoteQuery{SubmitterID: submitter.ID})
+	is.Equal(err, service.ErrNotAuthorized) // users should not be able to filter for others' quotes
+}
+
+func TestQuote_QueryLovedQuotes(t *testing.T) {
+	is := is.New(t)
+
+	repo := inmemory.NewQuoteRepository()
+	reactionRepo := inmemory.NewReactionRepository()
+	quoteService := service.NewQuoteService(repo, reactionRepo, inmemory.NewCommentRepository(),
+		inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), service.Webhook{}, discardLogger)
+
+	// qa is the newest quote, and qe the oldest
+	for i := 0; i < 5; i++ {
+		q := model.Quote{
+			ID:          "q" + string(rune('a'+i)),
+			CommunityID: testCommunity.ID,
+			SubmitterID: submitter.ID,
+			Quotee:      "AJBR",
+			Quote:       "Quote",
+			Created:     time.Now().Add(time.Duration(-i) * time.Minute),
+		}
+		is.NoErr(repo.Create(context.Background(), q))
+	}
+	for _, r := range []model.Reaction{
+		{QuoteID: "qd", UserID: submitter.ID, Emoji: "👍"},
+		{QuoteID: "qd", UserID: otherUser.ID, Emoji: "👍"},
+		{QuoteID: "qb", UserID: submitter.ID, Emoji: "😂"},
+		{QuoteID: "qe", UserID: otherUser.ID, Emoji: "😂"},
+	} {
+		is.NoErr(reactionRepo.Create(context.Background(), r))
+	}
+
+	ctxSubmitter := userContext(submitter)
+	var ids []string
+	var after *service.QuoteCursor
+	for {
+		quotes, next, err := quoteService.QueryLovedQuotes(ctxSubmitter, service.QuoteQuery{Limit: 2, After: after})
+		is.NoErr(err)
+		for _, q := range quotes {
+			ids = append(ids, q.ID)
+		}
+		if next == nil {
+			break
+		}
+
+		c, err := service.ParseQuoteCursor(next.String())
+		is.NoErr(err)                         // cursor should survive encoding
+		is.Equal(c.Reactions, next.Reactions) // encoded cursor should retain reactions
+		after = &c
+	}
+	is.Equal(ids, []string{"qd", "qb", "qe", "qa", "qc"}) // quotes should be ordered by reactions, then newest first
+
+	ctxOther := userContext(otherUser)
+	_, _, err := quoteService.QueryLovedQuotes(ctxOther, service.QuoteQuery{SubmitterID: submitter.ID})
+	is.Equal(err, service.ErrNotAuthorized) // users should not be able to filter for others' quotes
+}
+
+func TestQuote_ConversationQuotes(t *testing.T) {
+	is := is.New(t)
+	quotes, people, _ := newPersonTest(t)
+
+	ctxSubmitter := userContext(submitter)
+	q := model.Quote{
+		Lines: []model.QuoteLine{
+			{Speaker: "josh", Text: "Is that a hand truck?"},
+			{},
+			{Speaker: " AJBR ", Text: " Isn't every truck a hand truck? "},
+		},
+	}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &q))
+	is.Equal(len(q.Lines), 2)                  // blank lines should be removed
+	is.Equal(q.Quotee, "josh")                 // conversations are attributed to their first speaker
+	is.Equal(q.QuoteeID, q.Lines[0].SpeakerID) // conversations are attributed to their first speaker
+
+	// quote should hold the conversation as text
+	is.Equal(q.Quote, "josh: Is that a hand truck?\nAJBR: Isn't every truck a hand truck?")
+
+	ajbr, err := people.GetPerson(ctxSubmitter, q.Lines[1].SpeakerID)
+	is.NoErr(err)
+	is.Equal(ajbr.Name, "AJBR") // each speaker should be attributed to a person
+
+	single := model.Quote{Lines: []model.QuoteLine{{Speaker: "AJBR", Text: "Just me"}}}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &single))
+	is.True(!single.IsConversation())  // conversations of one line should become single quotes
+	is.Equal(single.QuoteeID, ajbr.ID) // single line should be attributed to its speaker
+	is.Equal(single.Quote, "Just me")  // single line should become the quote
+
+	unattributed := model.Quote{Lines: []model.QuoteLine{{Speaker: "josh", Text: "Hi"}, {Text: "Hello"}}}
+	is.True(isBadRequest(quotes.CreateQuote(ctxSubmitter, &unattributed))) // every line must have a speaker
+
+	q.Lines = nil
+	q.Quote = "Is that a hand truck?"
+	is.NoErr(quotes.EditQuote(ctxSubmitter, &q))
+	is.True(!q.IsConversation())               // conversations may be edited into single quotes
+	is.Equal(q.Quote, "Is that a hand truck?") // edited quote should be stored
+}
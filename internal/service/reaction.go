@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// _defaultReaction is the only reaction available when no reactions are configured.
+const _defaultReaction = "👍"
+
+// ErrInvalidReaction is returned when a user attempts to react to a quote with an emoji which is not available.
+var ErrInvalidReaction = Error{
+	Issues:     []string{"That reaction is not available."},
+	StatusCode: 400,
+}
+
+// ReactionRepository provides methods for storing and retrieving Reactions.
+type ReactionRepository interface {
+	Create(ctx context.Context, r model.Reaction) error
+	// Delete removes the Reaction with the same QuoteID, UserID, and Emoji as r.
+	Delete(ctx context.Context, r model.Reaction) error
+	DeleteByQuoteID(ctx context.Context, quoteID string) error
+	// FindByQuoteIDs returns all Reactions to any of the specified Quotes, in no particular order.
+	FindByQuoteIDs(ctx context.Context, quoteIDs []string) ([]model.Reaction, error)
+	// CountByQuote returns the total number of Reactions to each Quote which has at least one Reaction.
+	CountByQuote(ctx context.Context) (map[string]int, error)
+	// ReassignUser changes the UserID of every Reaction made by the user fromID to toID. Reactions which toID has
+	// already made to the same Quote with the same emoji are removed instead.
+	ReassignUser(ctx context.Context, fromID string, toID string) error
+}
+
+// ReactionSummary describes the reactions to a quote with a single emoji.
+type ReactionSummary struct {
+	Emoji string
+	Count int
+	// Reacted is true if the current user has reacted with this emoji.
+	Reacted bool
+}
+
+// Reaction provides a service for reacting to Quotes.
+type Reaction struct {
+	repo ReactionRepository
+	qr   QuoteRepository
+	// emoji are the reactions available to users.
+	emoji []string
+}
+
+// NewReactionService returns a new Reaction service with the provided ReactionRepository, and QuoteRepository used
+// to find the quotes being reacted to. Users may react with any of the provided emoji, or with a thumbs up if none
+// are provided.
+func NewReactionService(repo ReactionRepository, qr QuoteRepository, emoji []string) Reaction {
+	if len(emoji) == 0 {
+		emoji = []string{_defaultReaction}
+	}
+
+	return Reaction{
+		repo:  repo,
+		qr:    qr,
+		emoji: emoji,
+	}
+}
+
+// Emoji returns the emoji with which users may react to quotes.
+func (s Reaction) Emoji() []string {
+	return s.emoji
+}
+
+// ToggleReaction adds a reaction with the provided emoji to a Quote on behalf of the user on the context, or removes
+// it if they have already reacted with that emoji. Returns true if the reaction was added.
+func (s Reaction) ToggleReaction(ctx context.Context, quoteID string, emoji string) (bool, error) {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return false, err
+	}
+
+	if !slices.Contains(s.emoji, emoji) {
+		return false, ErrInvalidReaction
+	}
+
+	if _, err := findQuote(ctx, s.qr, quoteID); err != nil {
+		return false, err
+	}
+
+	r := model.Reaction{
+		QuoteID: quoteID,
+		UserID:  ctxval.UserFromContext(ctx).ID,
+		Emoji:   emoji,
+		Created: time.Now(),
+	}
+
+	err := s.repo.Create(ctx, r)
+	if err == storage.ErrAlreadyExists {
+		return false, s.repo.Delete(ctx, r)
+	}
+	return err == nil, err
+}
+
+// GetReactions returns a summary of the reactions to each of the specified Quotes, with an entry for each available
+// emoji in the order they are configured.
+func (s Reaction) GetReactions(ctx context.Context, quoteIDs []string) (map[string][]ReactionSummary, error) {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return nil, err
+	}
+
+	reactions, err := s.repo.FindByQuoteIDs(ctx, quoteIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string][]ReactionSummary, len(quoteIDs))
+	for _, id := range quoteIDs {
+		summaries[id] = make([]ReactionSummary, len(s.emoji))
+		for i, e := range s.emoji {
+			summaries[id][i].Emoji = e
+		}
+	}
+
+	userID := ctxval.UserFromContext(ctx).ID
+	for _, r := range reactions {
+		i := slices.Index(s.emoji, r.Emoji)
+		if i < 0 {
+			// the emoji is no longer available
+			continue
+		}
+
+		summaries[r.QuoteID][i].Count++
+		if r.UserID == userID {
+			summaries[r.QuoteID][i].Reacted = true
+		}
+	}
+
+	return summaries, nil
+}
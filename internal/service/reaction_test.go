@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+func TestReaction_ToggleReaction(t *testing.T) {
+	is := is.New(t)
+
+	quoteRepo := inmemory.NewQuoteRepository()
+	reactionRepo := inmemory.NewReactionRepository()
+	quoteService := service.NewQuoteService(quoteRepo, reactionRepo, inmemory.NewCommentRepository(),
+		inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), service.Webhook{}, discardLogger)
+	reactionService := service.NewReactionService(reactionRepo, quoteRepo, []string{"👍", "😂"})
+
+	ctxSubmitter := userContext(submitter)
+	q := model.Quote{
+		Quotee: "Jaustin Ross",
+		Quote:  "Isn't every truck a hand truck?",
+	}
+	is.NoErr(quoteService.CreateQuote(ctxSubmitter, &q))
+
+	added, err := reactionService.ToggleReaction(ctxSubmitter, q.ID, "👍")
+	is.NoErr(err)
+	is.True(added) // first reaction should be added
+
+	ctxOther := userContext(otherUser)
+	added, err = reactionService.ToggleReaction(ctxOther, q.ID, "👍")
+	is.NoErr(err)
+	is.True(added) // other users should be able to react with the same emoji
+
+	summaries, err := reactionService.GetReactions(ctxSubmitter, []string{q.ID})
+	is.NoErr(err)
+	is.Equal(summaries[q.ID], []service.ReactionSummary{
+		{Emoji: "👍", Count: 2, Reacted: true},
+		{Emoji: "😂", Count: 0},
+	}) // summary should include every available emoji in order
+
+	added, err = reactionService.ToggleReaction(ctxSubmitter, q.ID, "👍")
+	is.NoErr(err)
+	is.True(!added) // reacting again should remove the reaction
+
+	summaries, err = reactionService.GetReactions(ctxSubmitter, []string{q.ID})
+	is.NoErr(err)
+	is.Equal(summaries[q.ID][0], service.ReactionSummary{Emoji: "👍", Count: 1}) // removed reaction should not be counted
+
+	_, err = reactionService.ToggleReaction(ctxSubmitter, q.ID, "🍕")
+	is.Equal(err, service.ErrInvalidReaction) // unavailable emoji should be rejected
+
+	_, err = reactionService.ToggleReaction(ctxSubmitter, "missing", "👍")
+	is.Equal(err, service.ErrQuoteNotFound) // reacting to missing quote should fail
+
+	ctxVisitor := ctxval.ContextWithUser(context.Background(), model.User{ID: "visitor"})
+	_, err = reactionService.ToggleReaction(ctxVisitor, q.ID, "👍")
+	is.True(err != nil) // users who have not passed the quiz should not be able to react
+
+	is.NoErr(quoteService.DeleteQuote(ctxSubmitter, q.ID))
+	counts, err := reactionRepo.CountByQuote(context.Background())
+	is.NoErr(err)
+	is.Equal(counts[q.ID], 0) // deleting a quote should delete its reactions
+}
+
+func TestReaction_DefaultEmoji(t *testing.T) {
+	is := is.New(t)
+
+	reactionService := service.NewReactionService(inmemory.NewReactionRepository(), inmemory.NewQuoteRepository(), nil)
+	is.Equal(len(reactionService.Emoji()), 1) // a default reaction should be available when none are configured
+}
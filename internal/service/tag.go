@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+)
+
+const (
+	// _maxQuoteTags is the maximum number of tags on a quote.
+	_maxQuoteTags = 10
+	// _maxTagLength is the maximum length of a tag, in bytes.
+	_maxTagLength = 40
+)
+
+// ErrTagNotFound is returned when a requested tag is not on any quote.
+var ErrTagNotFound = Error{
+	Issues:     []string{"Tag not found."},
+	StatusCode: 404,
+}
+
+// TagSummary is a tag and the number of quotes tagged with it.
+type TagSummary struct {
+	Name   string
+	Quotes int
+}
+
+// cleanTags returns the provided tags normalized, deduplicated, and in alphabetical order, with blank tags removed.
+func cleanTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var cleaned []string
+	for _, t := range tags {
+		t = normalizeName(t)
+		if t != "" && !seen[t] {
+			seen[t] = true
+			cleaned = append(cleaned, t)
+		}
+	}
+
+	sort.Strings(cleaned)
+	return cleaned
+}
+
+// validateTags adds any issues with the provided cleaned tags to err.
+func validateTags(tags []string, err *Error) {
+	if len(tags) > _maxQuoteTags {
+		err.addIssue(fmt.Sprintf("Quotes must not have more than %d tags.", _maxQuoteTags))
+	}
+	for _, t := range tags {
+		if len(t) > _maxTagLength {
+			err.addIssue(fmt.Sprintf("Tags must not be longer than %d characters.", _maxTagLength))
+			return
+		}
+	}
+}
+
+// GetTags returns every tag which is on at least one quote of the current community, in alphabetical order.
+func (s *Quote) GetTags(ctx context.Context) ([]TagSummary, error) {
+	if err := verifyUserPrivilege(ctx); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.CountByTag(ctx, ctxval.CommunityFromContext(ctx).ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]TagSummary, 0, len(counts))
+	for name, n := range counts {
+		tags = append(tags, TagSummary{
+			Name:   name,
+			Quotes: n,
+		})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+
+	return tags, nil
+}
+
+// RenameTag renames the tag from to the tag to on every quote of the current community, and can only be performed by
+// admins. If to is already on some quotes, the tags are merged.
+func (s *Quote) RenameTag(ctx context.Context, from string, to string) error {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return err
+	}
+
+	from = normalizeName(from)
+	to = normalizeName(to)
+
+	verr := Error{
+		StatusCode: 400,
+	}
+	if to == "" {
+		verr.addIssue("Tag must not be blank.")
+	} else if to == from {
+		verr.addIssue("A tag cannot be renamed to itself.")
+	}
+	validateTags([]string{to}, &verr)
+	if verr.HasIssues() {
+		return verr
+	}
+
+	communityID := ctxval.CommunityFromContext(ctx).ID
+	counts, err := s.repo.CountByTag(ctx, communityID)
+	if err != nil {
+		return err
+	}
+	if counts[from] == 0 {
+		return ErrTagNotFound
+	}
+
+	if err := s.repo.RenameTag(ctx, communityID, from, to); err != nil {
+		return err
+	}
+
+	return s.audit.record(ctx, model.AuditRenameTag, from, from+" to "+to)
+}
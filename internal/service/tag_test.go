@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+
+	"github.com/matryer/is"
+)
+
+func TestQuote_CreateQuoteCleansTags(t *testing.T) {
+	is := is.New(t)
+	quotes, _, _ := newPersonTest(t)
+
+	ctxSubmitter := userContext(submitter)
+	q := model.Quote{Quotee: "Josh", Quote: "First", Tags: []string{" Road  Trip", "mail", "road trip", ""}}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &q))
+	is.Equal(q.Tags, []string{"mail", "road trip"}) // tags should be normalized, deduplicated, and sorted
+
+	long := model.Quote{Quotee: "Josh", Quote: "Long", Tags: []string{strings.Repeat("a", 41)}}
+	is.True(isBadRequest(quotes.CreateQuote(ctxSubmitter, &long))) // long tags should be rejected
+
+	many := model.Quote{Quotee: "Josh", Quote: "Many"}
+	for i := 0; i < 11; i++ {
+		many.Tags = append(many.Tags, string(rune('a'+i)))
+	}
+	is.True(isBadRequest(quotes.CreateQuote(ctxSubmitter, &many))) // too many tags should be rejected
+
+	q.Tags = []string{"Mail"}
+	is.NoErr(quotes.EditQuote(ctxSubmitter, &q))
+	got, err := quotes.GetQuote(ctxSubmitter, q.ID)
+	is.NoErr(err)
+	is.Equal(got.Tags, []string{"mail"}) // editing should replace the tags
+
+	tagged, _, err := quotes.QueryQuotes(ctxSubmitter, service.QuoteQuery{Tag: "MAIL"})
+	is.NoErr(err)
+	is.Equal(len(tagged), 1) // quotes should be filterable by tag, regardless of capitalization
+}
+
+func TestQuote_RenameTag(t *testing.T) {
+	is := is.New(t)
+	quotes, _, audit := newPersonTest(t)
+
+	ctxSubmitter := userContext(submitter)
+	q1 := model.Quote{Quotee: "Josh", Quote: "First", Tags: []string{"roadtrip"}}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &q1))
+	q2 := model.Quote{Quotee: "Josh", Quote: "Second", Tags: []string{"road trip", "roadtrip"}}
+	is.NoErr(quotes.CreateQuote(ctxSubmitter, &q2))
+
+	is.Equal(quotes.RenameTag(ctxSubmitter, "roadtrip", "road trip"), service.ErrNotAuthorized) // admin only
+
+	ctxAdmin := userContext(adminUser)
+	is.True(isBadRequest(quotes.RenameTag(ctxAdmin, "roadtrip", " "))) // tags can't be renamed to blank
+	is.Equal(quotes.RenameTag(ctxAdmin, "missing", "other"), service.ErrTagNotFound)
+	is.NoErr(quotes.RenameTag(ctxAdmin, "roadtrip", "Road Trip"))
+
+	tags, err := quotes.GetTags(ctxSubmitter)
+	is.NoErr(err)
+	is.Equal(tags, []service.TagSummary{{Name: "road trip", Quotes: 2}}) // tags should be merged
+
+	got, err := quotes.GetQuote(ctxSubmitter, q2.ID)
+	is.NoErr(err)
+	is.Equal(got.Tags, []string{"road trip"}) // merged tags should not be duplicated on a quote
+
+	entries, err := audit.QueryAuditLog(ctxAdmin, service.AuditLogQuery{Action: model.AuditRenameTag})
+	is.NoErr(err)
+	is.Equal(len(entries), 1) // renames should be audited
+}
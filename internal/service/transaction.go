@@ -0,0 +1,10 @@
+package service
+
+import "context"
+
+// Transactor provides a method for running multiple repository operations as a single unit of work.
+type Transactor interface {
+	// InTransaction calls fn with a context on which every repository call is made in a single transaction, which
+	// is committed if fn succeeds, and rolled back if it returns an error.
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -3,83 +3,103 @@ package service
 import (
 	"context"
 	"fmt"
-	"strings"
+	"math"
+	"sort"
 	"time"
 
+	"github.com/willbicks/epigram/internal/ctxval"
 	"github.com/willbicks/epigram/internal/model"
 	"github.com/willbicks/epigram/internal/storage"
 
 	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/rs/xid"
 )
 
 // UserRepository provides methods for storing, manipulating, and retrieving Users.
 type UserRepository interface {
 	Create(ctx context.Context, u model.User) error
 	Update(ctx context.Context, u model.User) error
+	Delete(ctx context.Context, id string) error
 	FindByID(ctx context.Context, id string) (model.User, error)
 	FindAll(ctx context.Context) ([]model.User, error)
 }
 
-// User provides a service for interacting with Users.
+// User provides a service for interacting with Users, and their memberships of communities.
 type User struct {
-	ur   UserRepository
-	sess UserSession
+	ur    UserRepository
+	ir    UserIdentityRepository
+	mr    MembershipRepository
+	ar    QuizAttemptRepository
+	qr    QuoteRepository
+	refs  UserReferences
+	tx    Transactor
+	sess  UserSession
+	audit AuditLog
+	quiz  QuizPolicy
 }
 
-// NewUserService returns a new UserService with the provided UserRepository.
-func NewUserService(ur UserRepository, sr UserSessionRepository) User {
-	return User{
-		ur:   ur,
-		sess: NewUserSessionService(sr),
-	}
+// Member is a user, and their membership of the current community.
+type Member struct {
+	model.User
+	Membership model.Membership
+	// LockedOut is true if the member has used all of their attempts at the entry quiz without passing it.
+	LockedOut bool
+	// AwaitingApproval is true if the member is waiting for the admins of the community to approve or reject them.
+	AwaitingApproval bool
 }
 
-// GetUserFromIDToken returns a user from the specified OIDC token (assumed to be already verified).
-// If a user already exists with the specified ID (derived from the issuer URL and subclass),
-// that user is returned. If no such user exists, a new user is created based on the token
-// details and returned.
-func (s User) GetUserFromIDToken(ctx context.Context, token oidc.IDToken) (model.User, error) {
-	var claims struct {
-		Issuer     string `json:"iss"`
-		Subject    string `json:"sub"`
-		Name       string `json:"name"`
-		Email      string `json:"email"`
-		PictureURL string `json:"picture"`
-	}
-	if err := token.Claims(&claims); err != nil {
-		return model.User{}, fmt.Errorf("unmarshalling token claims: %w", err)
-	}
-
-	domain := claims.Issuer
-	if strings.Contains(domain, "://") {
-		domain = strings.Split(domain, "://")[1]
+// newMember returns the provided user as a Member of the current community with the provided membership.
+func (s *User) newMember(ctx context.Context, u model.User, m model.Membership) Member {
+	return Member{
+		User:             u,
+		Membership:       m,
+		LockedOut:        s.quiz.status(m).LockedOut,
+		AwaitingApproval: m.AwaitingApproval(ctxval.CommunityFromContext(ctx).Gate),
 	}
-	if strings.Contains(domain, "/") {
-		domain = strings.Split(domain, "/")[0]
-	}
-	id := domain + "/" + claims.Subject
+}
 
-	// Check if the user exists, and if so, return them
-	u, err := s.ur.FindByID(ctx, id)
-	if err == nil {
-		return u, nil
-	} else if err != storage.ErrNotFound {
-		return model.User{}, fmt.Errorf("unable to find from user repo: %w", err)
-	}
+// UserReferences are the repositories of records which refer to users by ID, and are transferred along with their
+// identities, memberships, and quotes when accounts are merged.
+type UserReferences struct {
+	APITokens      APITokenRepository
+	ChatLinks      ChatLinkRepository
+	Reactions      ReactionRepository
+	Comments       CommentRepository
+	People         PersonRepository
+	AccessRequests AccessRequestRepository
+	Invites        InviteRepository
+	QuizSessions   QuizSessionRepository
+}
 
-	// User does not exist, create them
-	u = model.User{
-		ID:         id,
-		Name:       claims.Name,
-		Email:      claims.Email,
-		PictureURL: claims.PictureURL,
+// NewUserService returns a new UserService with the provided UserRepository, UserIdentityRepository,
+// MembershipRepository, UserSession service, and AuditLog service used to record privileged actions. The
+// QuoteRepository and UserReferences are used to transfer records between users when their accounts are merged, in a
+// transaction run by the Transactor, while attempts at entry quizzes are limited by the provided QuizPolicy, and
+// recorded in the provided QuizAttemptRepository.
+func NewUserService(ur UserRepository, ir UserIdentityRepository, mr MembershipRepository, ar QuizAttemptRepository,
+	qr QuoteRepository, refs UserReferences, tx Transactor, sess UserSession, audit AuditLog, quiz QuizPolicy) User {
+	return User{
+		ur:    ur,
+		ir:    ir,
+		mr:    mr,
+		ar:    ar,
+		qr:    qr,
+		refs:  refs,
+		tx:    tx,
+		sess:  sess,
+		audit: audit,
+		quiz:  quiz,
 	}
+}
 
-	if err := s.CreateUser(ctx, &u); err != nil {
-		return model.User{}, fmt.Errorf("creating user from id token: %w", err)
+// GetUserFromIDToken returns a user from the specified OIDC token (assumed to be already verified), as described by
+// GetUserFromIdentity.
+func (s User) GetUserFromIDToken(ctx context.Context, token oidc.IDToken) (model.User, error) {
+	ident, err := IdentityFromIDToken(token)
+	if err != nil {
+		return model.User{}, err
 	}
-
-	return u, nil
+	return s.GetUserFromIdentity(ctx, ident)
 }
 
 // CreateUser stores the provided User in the database, updating their Created time.
@@ -131,34 +151,485 @@ func (s User) GetUserFromSessionID(ctx context.Context, sessID string) (model.Us
 	return s.ur.FindByID(ctx, sess.UserID)
 }
 
-// RecordQuizAttempt records that the user attempted to complete a quiz, updates their information, and returns
-// either an empty string (pass), or the reason they failed (either got a question wrong or too many attempts),
-func (s *User) RecordQuizAttempt(ctx context.Context, u *model.User, passed bool) (failReason string, err error) {
-	u.QuizAttempts++
-	u.QuizPassed = passed
+// ResumeUserSession returns the user associated with the specified session ID, along with the session itself,
+// renewing it if permitted by the session policy. If renewed is true, the client should be issued the session again
+// with its new expiry.
+func (s User) ResumeUserSession(ctx context.Context, sessID string) (u model.User, sess model.UserSession, renewed bool, err error) {
+	sess, err = s.sess.FindSessionByID(ctx, sessID)
+	if err != nil {
+		return model.User{}, model.UserSession{}, false, err
+	}
+
+	u, err = s.ur.FindByID(ctx, sess.UserID)
+	if err != nil {
+		return model.User{}, model.UserSession{}, false, err
+	}
+
+	sess, renewed, err = s.sess.RenewSession(ctx, sess)
+	if err != nil {
+		return model.User{}, model.UserSession{}, false, err
+	}
+
+	return u, sess, renewed, nil
+}
+
+// ErrSessionNotFound is returned when a requested session does not exist, or does not belong to the current user.
+var ErrSessionNotFound = Error{
+	Issues:     []string{"Session not found."},
+	StatusCode: 404,
+}
+
+// EndUserSession deletes the session with the specified ID, such as when its user logs out. Sessions which do not
+// exist are ignored.
+func (s User) EndUserSession(ctx context.Context, sessID string) error {
+	if err := s.sess.DeleteSession(ctx, sessID); err != nil && err != storage.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// SessionSummary describes a session without its ID, which authenticates the session, and must therefore not be
+// presented. The session is instead identified by its Handle (see model.SessionHandle).
+type SessionSummary struct {
+	Handle  string
+	Created time.Time
+	Expires time.Time
+	IP      string
+}
+
+// GetUserSessions returns the active sessions of the user on the context, from newest to oldest.
+func (s User) GetUserSessions(ctx context.Context) ([]SessionSummary, error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sess.FindSessionsByUserID(ctx, ctxval.UserFromContext(ctx).ID)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]SessionSummary, len(sessions))
+	for i, sess := range sessions {
+		summaries[i] = SessionSummary{
+			Handle:  sess.Handle(),
+			Created: sess.Created,
+			Expires: sess.Expires,
+			IP:      sess.IP,
+		}
+	}
+	return summaries, nil
+}
+
+// RevokeUserSession deletes the session with the specified handle, provided that it belongs to the user on the
+// context.
+func (s User) RevokeUserSession(ctx context.Context, handle string) error {
+	if err := verifySignedIn(ctx); err != nil {
+		return err
+	}
+
+	sessions, err := s.sess.FindSessionsByUserID(ctx, ctxval.UserFromContext(ctx).ID)
+	if err != nil {
+		return err
+	}
+
+	for _, sess := range sessions {
+		if sess.Handle() == handle {
+			return s.sess.DeleteSession(ctx, sess.ID)
+		}
+	}
+	return ErrSessionNotFound
+}
+
+// RevokeAllUserSessions deletes every session belonging to the user with the specified ID, signing them out on all
+// devices. It can only be used by admins of the instance.
+func (s User) RevokeAllUserSessions(ctx context.Context, id string) error {
+	if err := verifyInstanceAdminPrivilege(ctx); err != nil {
+		return err
+	}
+
+	u, err := s.ur.FindByID(ctx, id)
+	if err == storage.ErrNotFound {
+		return ErrUserNotFound
+	} else if err != nil {
+		return fmt.Errorf("finding user to revoke sessions: %w", err)
+	}
+
+	if err := s.sess.DeleteSessionsByUserID(ctx, u.ID); err != nil {
+		return err
+	}
+
+	return s.audit.record(ctx, model.AuditRevokeSessions, u.ID, u.Name)
+}
+
+// GetQuizStatus returns whether the user on the context may attempt the entry quiz of the current community.
+func (s *User) GetQuizStatus(ctx context.Context) (QuizStatus, error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return QuizStatus{}, err
+	}
+
+	m, err := s.mr.Find(ctx, ctxval.CommunityFromContext(ctx).ID, ctxval.UserFromContext(ctx).ID)
+	if err == storage.ErrNotFound {
+		return QuizStatus{}, nil
+	} else if err != nil {
+		return QuizStatus{}, fmt.Errorf("finding membership: %w", err)
+	}
+
+	return s.quiz.status(m), nil
+}
+
+// RecordQuizAttempt records that the user on the context attempted to complete the entry quiz of the current
+// community with the provided result, making them a member of it if they were not already, and adding the attempt to
+// their quiz history. It returns their updated membership, along with either an empty string (pass), or the reason
+// they failed. If the user is locked out of the quiz, or must wait before attempting it again, the attempt is not
+// recorded, and ErrQuizLockedOut or ErrQuizCooldown is returned. If the community has no entry quiz,
+// ErrQuizNotRequired is returned.
+func (s *User) RecordQuizAttempt(ctx context.Context, result QuizResult) (m model.Membership, failReason string, err error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return model.Membership{}, "", err
+	}
+	if !ctxval.CommunityFromContext(ctx).Gate.RequiresQuiz() {
+		return model.Membership{}, "", ErrQuizNotRequired
+	}
+
+	communityID := ctxval.CommunityFromContext(ctx).ID
+	userID := ctxval.UserFromContext(ctx).ID
+	m, err = s.mr.Find(ctx, communityID, userID)
+	joined := err == storage.ErrNotFound
+	if joined {
+		m = model.Membership{
+			CommunityID: communityID,
+			UserID:      userID,
+			Joined:      time.Now(),
+		}
+	} else if err != nil {
+		return model.Membership{}, "Unable to find membership", err
+	}
+
+	if err := s.quiz.status(m).verify(); err != nil {
+		return m, "", err
+	}
+
+	if m.QuizAttempts < math.MaxInt8 {
+		m.QuizAttempts++
+	}
+	m.LastQuizAttempt = time.Now()
+	m.QuizPassed = result.Passed()
+
+	if joined {
+		err = s.mr.Create(ctx, m)
+	} else {
+		err = s.mr.Update(ctx, m)
+	}
+	if err != nil {
+		return m, "Unable to update membership", err
+	}
+
+	a := model.QuizAttempt{
+		ID:               xid.New().String(),
+		CommunityID:      communityID,
+		UserID:           userID,
+		IP:               ctxval.IPFromContext(ctx),
+		Submitted:        m.LastQuizAttempt,
+		QuestionIDs:      result.QuestionIDs,
+		WrongQuestionIDs: result.WrongQuestionIDs,
+		Passed:           m.QuizPassed,
+	}
+	if s.quiz.RecordAnswers {
+		a.Answers = result.Answers
+	}
+	if err := s.ar.Create(ctx, a); err != nil {
+		return m, "Unable to record attempt", err
+	}
+
+	if !m.QuizPassed {
+		return m, "Sorry, at least one answer was incorrect.", nil
+	}
+
+	return m, "", nil
+}
+
+// JoinCommunity makes the user on the context a member of the current community if they are not already, and it has
+// no entry quiz (communities with a quiz are joined by attempting it), then returns their membership. Members of
+// communities which require approval await it once they have joined.
+func (s *User) JoinCommunity(ctx context.Context) (model.Membership, error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return model.Membership{}, err
+	}
+
+	return s.joinCommunity(ctx, ctxval.CommunityFromContext(ctx), ctxval.UserFromContext(ctx).ID)
+}
 
-	if err := s.UpdateUser(ctx, *u); err != nil {
-		return "Unable to update user", err
+// joinCommunity makes the user with the specified ID a member of the provided community, as described by
+// JoinCommunity.
+func (s *User) joinCommunity(ctx context.Context, c model.Community, userID string) (model.Membership, error) {
+	m, err := s.mr.Find(ctx, c.ID, userID)
+	if err == nil {
+		return m, nil
+	} else if err != storage.ErrNotFound {
+		return model.Membership{}, fmt.Errorf("finding membership: %w", err)
 	}
 
-	if u.QuizAttempts > model.MaxQuizAttempts {
-		return "Too many failed quiz attempts, please contact an administrator.", nil
+	m = model.Membership{
+		CommunityID: c.ID,
+		UserID:      userID,
+	}
+	if c.Gate.RequiresQuiz() {
+		return m, nil
 	}
 
-	if !passed {
-		return "Sorry, at least one answer was incorrect.", nil
+	m.Joined = time.Now()
+	if err := s.mr.Create(ctx, m); err == storage.ErrAlreadyExists {
+		// the user joined concurrently, so their existing membership is used instead
+		return s.mr.Find(ctx, c.ID, userID)
+	} else if err != nil {
+		return model.Membership{}, fmt.Errorf("creating membership: %w", err)
 	}
+	return m, nil
+}
 
-	return "", nil
+// GetQuizAttempts returns the attempts of the member of the current community with the specified ID at its entry
+// quiz, from newest to oldest, and can only be accessed by admins.
+func (s *User) GetQuizAttempts(ctx context.Context, id string) ([]model.QuizAttempt, error) {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.ar.FindByUserID(ctx, ctxval.CommunityFromContext(ctx).ID, id)
 }
 
-// GetAllUsers returns a slice of all users, and can ony be accessed by admins.
+// GetAllUsers returns a slice of users, and can only be accessed by admins. Admins of the instance receive every
+// user, while admins of a community receive only its members.
 func (s *User) GetAllUsers(ctx context.Context) ([]model.User, error) {
 	if err := verifyAdminPrivilege(ctx); err != nil {
 		return nil, err
 	}
 
-	users, err := s.ur.FindAll(ctx)
+	if ctxval.UserFromContext(ctx).IsAdmin() {
+		return s.ur.FindAll(ctx)
+	}
+
+	members, err := s.GetMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]model.User, len(members))
+	for i, m := range members {
+		users[i] = m.User
+	}
+	return users, nil
+}
+
+// GetMembers returns the members of the current community, ordered by the time they joined, and can only be
+// accessed by admins.
+func (s *User) GetMembers(ctx context.Context) ([]Member, error) {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.mr.FindByCommunityID(ctx, ctxval.CommunityFromContext(ctx).ID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]Member, 0, len(memberships))
+	for _, m := range memberships {
+		u, err := s.ur.FindByID(ctx, m.UserID)
+		if err == storage.ErrNotFound {
+			// memberships of deleted users are ignored
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("finding member: %w", err)
+		}
+
+		members = append(members, s.newMember(ctx, u, m))
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Membership.Joined.Before(members[j].Membership.Joined)
+	})
+
+	return members, nil
+}
+
+// ErrUserNotFound is returned when a requested user does not exist.
+var ErrUserNotFound = Error{
+	Issues:     []string{"User not found."},
+	StatusCode: 404,
+}
+
+// GetMember returns the member of the current community with the specified ID, and can only be accessed by admins.
+func (s *User) GetMember(ctx context.Context, id string) (Member, error) {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return Member{}, err
+	}
+
+	u, err := s.ur.FindByID(ctx, id)
+	if err == storage.ErrNotFound {
+		return Member{}, ErrUserNotFound
+	} else if err != nil {
+		return Member{}, fmt.Errorf("finding member: %w", err)
+	}
+
+	m, err := s.mr.Find(ctx, ctxval.CommunityFromContext(ctx).ID, u.ID)
+	if err == storage.ErrNotFound {
+		return Member{}, ErrUserNotFound
+	} else if err != nil {
+		return Member{}, fmt.Errorf("finding membership: %w", err)
+	}
+
+	return s.newMember(ctx, u, m), nil
+}
+
+// modifyMember finds the member of the current community with the specified user ID, applies the provided
+// modification to their membership, stores the result, and records the action in the audit log. It can only be used
+// by admins.
+func (s *User) modifyMember(ctx context.Context, id string, action model.AuditAction, modify func(u model.User, m *model.Membership) error) error {
+	if err := verifyAdminPrivilege(ctx); err != nil {
+		return err
+	}
+
+	u, err := s.ur.FindByID(ctx, id)
+	if err == storage.ErrNotFound {
+		return ErrUserNotFound
+	} else if err != nil {
+		return fmt.Errorf("finding user to modify: %w", err)
+	}
+
+	c := ctxval.CommunityFromContext(ctx)
+	m, err := s.mr.Find(ctx, c.ID, u.ID)
+	if err == storage.ErrNotFound {
+		return ErrUserNotFound
+	} else if err != nil {
+		return fmt.Errorf("finding membership to modify: %w", err)
+	}
+
+	if err := modify(u, &m); err != nil {
+		return err
+	}
+
+	if err := s.mr.Update(ctx, m); err != nil {
+		return err
+	}
+
+	return s.audit.record(ctx, action, u.ID, u.Name+" in "+c.Title)
+}
+
+// SetUserBanned bans or unbans the member of the current community with the specified ID, and can only be used by
+// admins. Admins cannot be banned, and must first be demoted. Banned members are signed out of every session.
+func (s *User) SetUserBanned(ctx context.Context, id string, banned bool) error {
+	action := model.AuditUnbanUser
+	if banned {
+		action = model.AuditBanUser
+	}
+
+	err := s.modifyMember(ctx, id, action, func(u model.User, m *model.Membership) error {
+		if banned && (u.Admin || m.Admin) {
+			return Error{
+				Issues:     []string{"Admins cannot be banned, and must be demoted first."},
+				StatusCode: 400,
+			}
+		}
+		m.Banned = banned
+		return nil
+	})
+	if err != nil || !banned {
+		return err
+	}
+
+	if err := s.sess.DeleteSessionsByUserID(ctx, id); err != nil {
+		return fmt.Errorf("revoking sessions of banned user: %w", err)
+	}
+	return nil
+}
+
+// SetUserAdmin grants or revokes admin privileges in the current community for the member with the specified ID, and
+// can only be used by admins. The last remaining admin of a community cannot be demoted.
+func (s *User) SetUserAdmin(ctx context.Context, id string, admin bool) error {
+	action := model.AuditDemoteUser
+	if admin {
+		action = model.AuditPromoteUser
+	}
+
+	return s.modifyMember(ctx, id, action, func(u model.User, m *model.Membership) error {
+		if !admin && m.Admin {
+			memberships, err := s.mr.FindByCommunityID(ctx, m.CommunityID)
+			if err != nil {
+				return fmt.Errorf("counting admins: %w", err)
+			}
+
+			var admins int
+			for _, other := range memberships {
+				if other.Admin {
+					admins++
+				}
+			}
+			if admins <= 1 {
+				return Error{
+					Issues:     []string{"The last remaining admin of a community cannot be demoted."},
+					StatusCode: 400,
+				}
+			}
+		}
+		if admin && m.Banned {
+			return Error{
+				Issues:     []string{"Banned users cannot be promoted, and must be unbanned first."},
+				StatusCode: 400,
+			}
+		}
+		m.Admin = admin
+		return nil
+	})
+}
+
+// ErrApprovalNotRequired is returned when admins attempt to approve or reject a member of a community whose gate does
+// not require approval.
+var ErrApprovalNotRequired = Error{
+	Issues:     []string{"This community does not require members to be approved."},
+	StatusCode: 400,
+}
+
+// GetPendingMembers returns the members of the current community who are awaiting approval by its admins, ordered by
+// the time they joined, and can only be accessed by admins.
+func (s *User) GetPendingMembers(ctx context.Context) ([]Member, error) {
+	members, err := s.GetMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]Member, 0)
+	for _, m := range members {
+		if m.AwaitingApproval {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// SetUserApproved approves or rejects the member of the current community with the specified ID, granting or denying
+// them access to it, and can only be used by admins. Rejected members may later be approved.
+func (s *User) SetUserApproved(ctx context.Context, id string, approved bool) error {
+	action := model.AuditRejectUser
+	if approved {
+		action = model.AuditApproveUser
+	}
+
+	return s.modifyMember(ctx, id, action, func(u model.User, m *model.Membership) error {
+		if !ctxval.CommunityFromContext(ctx).Gate.RequiresApproval() {
+			return ErrApprovalNotRequired
+		}
+		m.Approved = approved
+		m.Rejected = !approved
+		return nil
+	})
+}
 
-	return users, err
+// ResetQuizAttempts resets the number of entry quiz attempts made by the member of the current community with the
+// specified ID, allowing them to attempt the quiz again immediately. It can only be used by admins.
+func (s *User) ResetQuizAttempts(ctx context.Context, id string) error {
+	return s.modifyMember(ctx, id, model.AuditResetQuiz, func(u model.User, m *model.Membership) error {
+		m.QuizAttempts = 0
+		m.LastQuizAttempt = time.Time{}
+		return nil
+	})
 }
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// UserIdentityRepository provides methods for storing and retrieving UserIdentities.
+type UserIdentityRepository interface {
+	Create(ctx context.Context, ui model.UserIdentity) error
+	FindByID(ctx context.Context, id string) (model.UserIdentity, error)
+	// FindByUserID returns all UserIdentities linked to the specified user, from oldest to newest.
+	FindByUserID(ctx context.Context, userID string) ([]model.UserIdentity, error)
+	// ReassignUser links every UserIdentity linked to the user fromID to the user toID instead.
+	ReassignUser(ctx context.Context, fromID string, toID string) error
+}
+
+// ErrIdentityLinked is returned when a user attempts to link an identity which already belongs to another account.
+var ErrIdentityLinked = Error{
+	Issues:     []string{"This login already belongs to another account. Ask an administrator to merge the accounts."},
+	StatusCode: 409,
+}
+
+// OIDCIdentity describes a user as asserted by the claims of an OIDC provider.
+type OIDCIdentity struct {
+	Issuer     string `json:"iss"`
+	Subject    string `json:"sub"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	PictureURL string `json:"picture"`
+}
+
+// IdentityFromIDToken returns the OIDCIdentity described by the claims of the specified OIDC token (assumed to be
+// already verified).
+func IdentityFromIDToken(token oidc.IDToken) (OIDCIdentity, error) {
+	var ident OIDCIdentity
+	if err := token.Claims(&ident); err != nil {
+		return OIDCIdentity{}, fmt.Errorf("unmarshalling token claims: %w", err)
+	}
+	return ident, nil
+}
+
+// Domain returns the domain of the identity's issuer.
+func (i OIDCIdentity) Domain() string {
+	domain := i.Issuer
+	if strings.Contains(domain, "://") {
+		domain = strings.Split(domain, "://")[1]
+	}
+	if strings.Contains(domain, "/") {
+		domain = strings.Split(domain, "/")[0]
+	}
+	return domain
+}
+
+// ID returns the unique ID of the identity, derived from the domain of its issuer and its subject.
+func (i OIDCIdentity) ID() string {
+	return i.Domain() + "/" + i.Subject
+}
+
+// userIdentity returns a UserIdentity linking the OIDCIdentity to the specified user.
+func (i OIDCIdentity) userIdentity(userID string) model.UserIdentity {
+	return model.UserIdentity{
+		ID:      i.ID(),
+		UserID:  userID,
+		Domain:  i.Domain(),
+		Email:   i.Email,
+		Created: time.Now(),
+	}
+}
+
+// GetUserFromIdentity returns the user linked to the specified OIDCIdentity. Users created before identities were
+// tracked are found by their ID (which matches the ID of the identity they first signed in with), and linked to
+// that identity. If no such user exists, a new user is created based on the identity details and returned, having
+// joined the current community (if any) as described by JoinCommunity.
+func (s User) GetUserFromIdentity(ctx context.Context, ident OIDCIdentity) (model.User, error) {
+	ui, err := s.ir.FindByID(ctx, ident.ID())
+	if err == nil {
+		return s.ur.FindByID(ctx, ui.UserID)
+	} else if err != storage.ErrNotFound {
+		return model.User{}, fmt.Errorf("unable to find from user identity repo: %w", err)
+	}
+
+	// Check if the user exists, and if so, link the identity to them and return them
+	u, err := s.ur.FindByID(ctx, ident.ID())
+	if err == nil {
+		if err := s.ir.Create(ctx, ident.userIdentity(u.ID)); err != nil && err != storage.ErrAlreadyExists {
+			return model.User{}, fmt.Errorf("linking identity to existing user: %w", err)
+		}
+		return u, nil
+	} else if err != storage.ErrNotFound {
+		return model.User{}, fmt.Errorf("unable to find from user repo: %w", err)
+	}
+
+	// User does not exist, create them
+	u = model.User{
+		ID:         ident.ID(),
+		Name:       ident.Name,
+		Email:      ident.Email,
+		PictureURL: ident.PictureURL,
+	}
+
+	if err := s.CreateUser(ctx, &u); err != nil {
+		return model.User{}, fmt.Errorf("creating user from identity: %w", err)
+	}
+
+	if err := s.ir.Create(ctx, ident.userIdentity(u.ID)); err != nil {
+		return model.User{}, fmt.Errorf("linking identity to new user: %w", err)
+	}
+
+	// new users join the community they signed up in, so that those which require approval list them as pending
+	if c := ctxval.CommunityFromContext(ctx); c.ID != "" {
+		if _, err := s.joinCommunity(ctx, c, u.ID); err != nil {
+			return model.User{}, fmt.Errorf("joining community: %w", err)
+		}
+	}
+
+	return u, nil
+}
+
+// LinkIDToken links the identity described by the specified OIDC token (assumed to be already verified) to the user
+// on the context, as described by LinkIdentity.
+func (s User) LinkIDToken(ctx context.Context, token oidc.IDToken) error {
+	ident, err := IdentityFromIDToken(token)
+	if err != nil {
+		return err
+	}
+	return s.LinkIdentity(ctx, ident)
+}
+
+// LinkIdentity links the specified OIDCIdentity to the user on the context, allowing them to sign in with it. If the
+// identity already belongs to another user, ErrIdentityLinked is returned.
+func (s User) LinkIdentity(ctx context.Context, ident OIDCIdentity) error {
+	if err := verifySignedIn(ctx); err != nil {
+		return err
+	}
+	u := ctxval.UserFromContext(ctx)
+
+	ui, err := s.ir.FindByID(ctx, ident.ID())
+	if err == nil {
+		if ui.UserID != u.ID {
+			return ErrIdentityLinked
+		}
+		return nil
+	} else if err != storage.ErrNotFound {
+		return fmt.Errorf("unable to find from user identity repo: %w", err)
+	}
+
+	// an account created with this identity before identities were tracked must be merged, rather than linked
+	if ident.ID() != u.ID {
+		if _, err := s.ur.FindByID(ctx, ident.ID()); err == nil {
+			return ErrIdentityLinked
+		} else if err != storage.ErrNotFound {
+			return fmt.Errorf("unable to find from user repo: %w", err)
+		}
+	}
+
+	return s.ir.Create(ctx, ident.userIdentity(u.ID))
+}
+
+// GetUserIdentities returns the identities linked to the user on the context, from oldest to newest.
+func (s User) GetUserIdentities(ctx context.Context) ([]model.UserIdentity, error) {
+	if err := verifySignedIn(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.ir.FindByUserID(ctx, ctxval.UserFromContext(ctx).ID)
+}
+
+// MergeUsers merges the user fromID into the user intoID, and can only be used by admins of the instance. The
+// identities, quotes, memberships, quiz attempts and sessions, access requests, invites, API tokens, chat links,
+// reactions, comments, and people of the user fromID are transferred, their sessions are revoked, and their account is
+// deleted, all in a single transaction. In communities of which both users are members,
+// the merged user has passed the entry quiz if either user had, but otherwise retains the privileges of the user
+// intoID.
+func (s *User) MergeUsers(ctx context.Context, fromID string, intoID string) error {
+	if err := verifyInstanceAdminPrivilege(ctx); err != nil {
+		return err
+	}
+
+	if fromID == intoID {
+		return Error{
+			Issues:     []string{"An account cannot be merged into itself."},
+			StatusCode: 400,
+		}
+	}
+
+	return s.tx.InTransaction(ctx, func(ctx context.Context) error {
+		return s.mergeUsers(ctx, fromID, intoID)
+	})
+}
+
+// userReassigner is implemented by repositories of records which refer to users by ID.
+type userReassigner interface {
+	ReassignUser(ctx context.Context, fromID string, toID string) error
+}
+
+// mergeUsers performs MergeUsers, and must be called in a transaction.
+func (s *User) mergeUsers(ctx context.Context, fromID string, intoID string) error {
+	from, err := s.ur.FindByID(ctx, fromID)
+	if err == storage.ErrNotFound {
+		return ErrUserNotFound
+	} else if err != nil {
+		return fmt.Errorf("finding user to merge: %w", err)
+	}
+
+	into, err := s.ur.FindByID(ctx, intoID)
+	if err == storage.ErrNotFound {
+		return ErrUserNotFound
+	} else if err != nil {
+		return fmt.Errorf("finding user to merge into: %w", err)
+	}
+
+	fromMemberships, err := s.mr.FindByUserID(ctx, from.ID)
+	if err != nil {
+		return fmt.Errorf("finding memberships to merge: %w", err)
+	}
+
+	fromAdmin := from.Admin
+	for _, m := range fromMemberships {
+		fromAdmin = fromAdmin || m.Admin
+	}
+	if fromAdmin {
+		return Error{
+			Issues:     []string{"Admins cannot be merged into other accounts, and must be demoted first."},
+			StatusCode: 400,
+		}
+	}
+
+	if err := s.ir.ReassignUser(ctx, from.ID, into.ID); err != nil {
+		return fmt.Errorf("reassigning identities: %w", err)
+	}
+
+	// accounts created before identities were tracked are identified by the ID of the identity they signed in with
+	domain, _, _ := strings.Cut(from.ID, "/")
+	legacy := model.UserIdentity{
+		ID:      from.ID,
+		UserID:  into.ID,
+		Domain:  domain,
+		Email:   from.Email,
+		Created: from.Created,
+	}
+	if err := s.ir.Create(ctx, legacy); err != nil && err != storage.ErrAlreadyExists {
+		return fmt.Errorf("linking identity of merged user: %w", err)
+	}
+
+	if err := s.qr.ReassignSubmitter(ctx, from.ID, into.ID); err != nil {
+		return fmt.Errorf("reassigning quotes: %w", err)
+	}
+
+	reassign := []struct {
+		name string
+		repo userReassigner
+	}{
+		{"api tokens", s.refs.APITokens},
+		{"chat links", s.refs.ChatLinks},
+		{"reactions", s.refs.Reactions},
+		{"comments", s.refs.Comments},
+		{"people", s.refs.People},
+		{"quiz attempts", s.ar},
+		{"quiz sessions", s.refs.QuizSessions},
+		{"access requests", s.refs.AccessRequests},
+		{"invites", s.refs.Invites},
+	}
+	for _, r := range reassign {
+		if err := r.repo.ReassignUser(ctx, from.ID, into.ID); err != nil {
+			return fmt.Errorf("reassigning %v: %w", r.name, err)
+		}
+	}
+
+	if err := s.sess.DeleteSessionsByUserID(ctx, from.ID); err != nil {
+		return fmt.Errorf("revoking sessions of merged user: %w", err)
+	}
+
+	for _, m := range fromMemberships {
+		if err := s.mergeMembership(ctx, m, into.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.ur.Delete(ctx, from.ID); err != nil {
+		return fmt.Errorf("deleting merged user: %w", err)
+	}
+
+	return s.audit.record(ctx, model.AuditMergeUsers, into.ID, from.Name+" ("+from.ID+") merged into "+into.Name)
+}
+
+// mergeMembership transfers the membership m to the user intoID, or if they are already a member of the same
+// community, combines their quiz progress, approval, and ban with it. The membership m is then deleted.
+func (s *User) mergeMembership(ctx context.Context, m model.Membership, intoID string) error {
+	fromID := m.UserID
+
+	existing, err := s.mr.Find(ctx, m.CommunityID, intoID)
+	if err == storage.ErrNotFound {
+		m.UserID = intoID
+		err = s.mr.Create(ctx, m)
+	} else if err == nil {
+		existing.QuizPassed = existing.QuizPassed || m.QuizPassed
+		existing.Approved = existing.Approved || m.Approved
+		existing.Rejected = existing.Rejected && m.Rejected
+		existing.Banned = existing.Banned || m.Banned
+		err = s.mr.Update(ctx, existing)
+	}
+	if err != nil {
+		return fmt.Errorf("merging membership of %v: %w", m.CommunityID, err)
+	}
+
+	return s.mr.Delete(ctx, m.CommunityID, fromID)
+}
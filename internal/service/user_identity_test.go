@@ -0,0 +1,259 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+var (
+	googleIdentity = service.OIDCIdentity{
+		Issuer:  "https://accounts.google.com",
+		Subject: "1234",
+		Name:    "Google User",
+		Email:   "user@gmail.com",
+	}
+	dexIdentity = service.OIDCIdentity{
+		Issuer:  "https://dex.example.com/dex",
+		Subject: "5678",
+		Name:    "Dex User",
+		Email:   "user@example.com",
+	}
+)
+
+// userIdentityFixture holds a User service, and the repositories backing it.
+type userIdentityFixture struct {
+	users       service.User
+	userRepo    service.UserRepository
+	quoteRepo   service.QuoteRepository
+	identities  service.UserIdentityRepository
+	memberships service.MembershipRepository
+	attempts    service.QuizAttemptRepository
+	refs        service.UserReferences
+}
+
+func newUserIdentityFixture(t *testing.T, users ...model.User) userIdentityFixture {
+	t.Helper()
+
+	f := userIdentityFixture{
+		userRepo:    inmemory.NewUserRepository(),
+		quoteRepo:   inmemory.NewQuoteRepository(),
+		identities:  inmemory.NewUserIdentityRepository(),
+		memberships: inmemory.NewMembershipRepository(),
+		attempts:    inmemory.NewQuizAttemptRepository(),
+		refs:        newUserReferences(),
+	}
+	for _, u := range users {
+		if err := f.userRepo.Create(context.Background(), u); err != nil {
+			t.Fatalf("creating user %v: %v", u.ID, err)
+		}
+	}
+
+	f.users = service.NewUserService(
+		f.userRepo,
+		f.identities,
+		f.memberships,
+		f.attempts,
+		f.quoteRepo,
+		f.refs,
+		inmemory.NewTransactor(),
+		service.NewUserSessionService(inmemory.NewUserSessionRepository(), service.SessionPolicy{}),
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()),
+		service.QuizPolicy{},
+	)
+	return f
+}
+
+func TestOIDCIdentity_ID(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(googleIdentity.ID(), "accounts.google.com/1234") // ID should combine issuer domain and subject
+	is.Equal(dexIdentity.ID(), "dex.example.com/5678")        // issuer path should be excluded from ID
+}
+
+func TestUser_GetUserFromIdentity(t *testing.T) {
+	is := is.New(t)
+
+	legacy := model.User{ID: dexIdentity.ID(), Name: "Legacy User", Email: "legacy@example.com"}
+	f := newUserIdentityFixture(t, legacy)
+
+	u, err := f.users.GetUserFromIdentity(context.Background(), googleIdentity)
+	is.NoErr(err)
+	is.Equal(u.ID, googleIdentity.ID())   // new user should be created with the identity's ID
+	is.Equal(u.Name, googleIdentity.Name) // new user should be named after the identity
+
+	again, err := f.users.GetUserFromIdentity(context.Background(), googleIdentity)
+	is.NoErr(err)
+	is.Equal(again.ID, u.ID) // signing in again should return the same user
+
+	u, err = f.users.GetUserFromIdentity(context.Background(), dexIdentity)
+	is.NoErr(err)
+	is.Equal(u.ID, legacy.ID) // existing users should be found by their ID
+
+	ui, err := f.identities.FindByID(context.Background(), dexIdentity.ID())
+	is.NoErr(err)
+	is.Equal(ui.UserID, legacy.ID) // existing users should be linked to the identity they signed in with
+}
+
+func TestUser_GetUserFromIdentity_JoinsCommunity(t *testing.T) {
+	is := is.New(t)
+
+	f := newUserIdentityFixture(t)
+
+	u, err := f.users.GetUserFromIdentity(ctxval.ContextWithCommunity(context.Background(), approvalCommunity), googleIdentity)
+	is.NoErr(err)
+
+	m, err := f.memberships.Find(context.Background(), approvalCommunity.ID, u.ID)
+	is.NoErr(err)                                       // new users should join the community they signed up in
+	is.True(m.AwaitingApproval(approvalCommunity.Gate)) // new members should await approval
+
+	u, err = f.users.GetUserFromIdentity(ctxval.ContextWithCommunity(context.Background(), testCommunity), dexIdentity)
+	is.NoErr(err)
+
+	_, err = f.memberships.Find(context.Background(), testCommunity.ID, u.ID)
+	is.Equal(err, storage.ErrNotFound) // new users should not join communities with an entry quiz until attempting it
+}
+
+func TestUser_LinkIdentity(t *testing.T) {
+	is := is.New(t)
+
+	f := newUserIdentityFixture(t)
+
+	google, err := f.users.GetUserFromIdentity(context.Background(), googleIdentity)
+	is.NoErr(err)
+	ctxGoogle := userContext(google)
+
+	is.Equal(f.users.LinkIdentity(context.Background(), dexIdentity), service.ErrNotAuthenticated) // linking requires a signed in user
+	is.NoErr(f.users.LinkIdentity(ctxGoogle, dexIdentity))                                         // signed in users should be able to link another identity
+	is.NoErr(f.users.LinkIdentity(ctxGoogle, dexIdentity))                                         // linking an already linked identity should succeed
+
+	u, err := f.users.GetUserFromIdentity(context.Background(), dexIdentity)
+	is.NoErr(err)
+	is.Equal(u.ID, google.ID) // linked identity should sign in to the same user
+
+	identities, err := f.users.GetUserIdentities(ctxGoogle)
+	is.NoErr(err)
+	is.Equal(len(identities), 2) // user should have both identities listed
+
+	other := model.User{ID: "other", Name: "Other", Email: "other@example.com"}
+	is.NoErr(f.userRepo.Create(context.Background(), other))
+	ctxOther := userContext(other)
+	is.Equal(f.users.LinkIdentity(ctxOther, googleIdentity), service.ErrIdentityLinked) // identities of other users should not be linkable
+}
+
+func TestUser_MergeUsers(t *testing.T) {
+	is := is.New(t)
+
+	from := model.User{ID: googleIdentity.ID(), Name: "Old Account", Email: "user@gmail.com", Created: time.Now()}
+	into := model.User{ID: dexIdentity.ID(), Name: "New Account", Email: "user@example.com"}
+	f := newUserIdentityFixture(t, from, into, adminUser)
+	is.NoErr(f.memberships.Create(context.Background(), model.Membership{
+		CommunityID: testCommunity.ID,
+		UserID:      from.ID,
+		QuizPassed:  true,
+	}))
+
+	quote := model.Quote{ID: "q1", SubmitterID: from.ID, Quotee: "Someone", Quote: "Something", Created: time.Now()}
+	is.NoErr(f.quoteRepo.Create(context.Background(), quote))
+
+	ctx := context.Background()
+	is.NoErr(f.refs.APITokens.Create(ctx, model.APIToken{ID: "t1", UserID: from.ID, Hash: "hash"}))
+	is.NoErr(f.refs.ChatLinks.Create(ctx, model.ChatLink{ID: "slack:T1:U1", UserID: from.ID}))
+	is.NoErr(f.refs.Reactions.Create(ctx, model.Reaction{QuoteID: quote.ID, UserID: from.ID, Emoji: "👍"}))
+	is.NoErr(f.refs.Reactions.Create(ctx, model.Reaction{QuoteID: quote.ID, UserID: into.ID, Emoji: "👍"}))
+	is.NoErr(f.refs.Comments.Create(ctx, model.Comment{ID: "c1", QuoteID: quote.ID, AuthorID: from.ID, Text: "Hi"}))
+	is.NoErr(f.refs.People.Create(ctx, model.Person{ID: "p1", CommunityID: testCommunity.ID, Name: "Old", UserID: from.ID}))
+	is.NoErr(f.attempts.Create(ctx, model.QuizAttempt{ID: "a1", CommunityID: testCommunity.ID, UserID: from.ID}))
+	is.NoErr(f.refs.AccessRequests.Create(ctx, model.AccessRequest{CommunityID: testCommunity.ID, UserID: from.ID}))
+	is.NoErr(f.refs.Invites.Create(ctx, model.Invite{ID: "i1", CommunityID: testCommunity.ID, Code: "code", CreatorID: from.ID}))
+	is.NoErr(f.refs.Invites.AddRedemption(ctx, model.InviteRedemption{InviteID: "i1", UserID: from.ID}))
+	is.NoErr(f.refs.QuizSessions.Create(ctx, model.QuizSession{CommunityID: testCommunity.ID, UserID: from.ID}))
+
+	ctxFrom := userContext(from)
+	is.Equal(f.users.MergeUsers(ctxFrom, from.ID, into.ID), service.ErrNotAuthorized) // non-admins should not be able to merge users
+
+	ctxAdmin := userContext(adminUser)
+	is.True(f.users.MergeUsers(ctxAdmin, into.ID, into.ID) != nil)                      // users should not be merged into themselves
+	is.True(f.users.MergeUsers(ctxAdmin, adminUser.ID, into.ID) != nil)                 // admins should not be merged away
+	is.Equal(f.users.MergeUsers(ctxAdmin, "missing", into.ID), service.ErrUserNotFound) // merging missing user should fail
+
+	is.NoErr(f.users.MergeUsers(ctxAdmin, from.ID, into.ID)) // admins should be able to merge users
+
+	_, err := f.userRepo.FindByID(context.Background(), from.ID)
+	is.True(err != nil) // merged user should be deleted
+
+	got, err := f.memberships.Find(context.Background(), testCommunity.ID, into.ID)
+	is.NoErr(err)           // merged user should keep their memberships
+	is.True(got.QuizPassed) // merged user should keep quiz progress
+
+	gotQuote, err := f.quoteRepo.FindByID(context.Background(), quote.ID)
+	is.NoErr(err)
+	is.Equal(gotQuote.SubmitterID, into.ID) // quotes should be transferred to the merged user
+
+	tokens, err := f.refs.APITokens.FindByUserID(ctx, into.ID)
+	is.NoErr(err)
+	is.Equal(len(tokens), 1) // api tokens should be transferred to the merged user
+
+	links, err := f.refs.ChatLinks.FindByUserID(ctx, into.ID)
+	is.NoErr(err)
+	is.Equal(len(links), 1) // chat links should be transferred to the merged user
+
+	reactions, err := f.refs.Reactions.FindByQuoteIDs(ctx, []string{quote.ID})
+	is.NoErr(err)
+	is.Equal(reactions, []model.Reaction{{QuoteID: quote.ID, UserID: into.ID, Emoji: "👍"}}) // duplicate reactions should be merged
+
+	comment, err := f.refs.Comments.FindByID(ctx, "c1")
+	is.NoErr(err)
+	is.Equal(comment.AuthorID, into.ID) // comments should be transferred to the merged user
+
+	person, err := f.refs.People.FindByID(ctx, "p1")
+	is.NoErr(err)
+	is.Equal(person.UserID, into.ID) // people should be linked to the merged user
+
+	attempts, err := f.attempts.FindByUserID(ctx, testCommunity.ID, into.ID)
+	is.NoErr(err)
+	is.Equal(len(attempts), 1) // quiz attempts should be transferred to the merged user
+
+	_, err = f.refs.AccessRequests.Find(ctx, testCommunity.ID, into.ID)
+	is.NoErr(err) // access requests should be transferred to the merged user
+
+	invite, err := f.refs.Invites.FindByID(ctx, "i1")
+	is.NoErr(err)
+	is.Equal(invite.CreatorID, into.ID)             // invites should be transferred to the merged user
+	is.Equal(invite.Redemptions[0].UserID, into.ID) // redemptions should be transferred to the merged user
+
+	_, err = f.refs.QuizSessions.Find(ctx, testCommunity.ID, into.ID)
+	is.NoErr(err) // quiz sessions should be transferred to the merged user
+
+	u, err := f.users.GetUserFromIdentity(context.Background(), googleIdentity)
+	is.NoErr(err)
+	is.Equal(u.ID, into.ID) // signing in with the old account's identity should return the merged user
+}
+
+func TestUser_MergeUsers_KeepsBan(t *testing.T) {
+	is := is.New(t)
+
+	from := model.User{ID: "banned", Name: "Banned Account"}
+	into := model.User{ID: "member", Name: "Member Account"}
+	f := newUserIdentityFixture(t, from, into, adminUser)
+	is.NoErr(f.memberships.Create(context.Background(), model.Membership{
+		CommunityID: testCommunity.ID, UserID: from.ID, QuizPassed: true, Banned: true,
+	}))
+	is.NoErr(f.memberships.Create(context.Background(), model.Membership{
+		CommunityID: testCommunity.ID, UserID: into.ID, QuizPassed: true,
+	}))
+
+	is.NoErr(f.users.MergeUsers(userContext(adminUser), from.ID, into.ID))
+
+	got, err := f.memberships.Find(context.Background(), testCommunity.ID, into.ID)
+	is.NoErr(err)
+	is.True(got.Banned) // merging a banned account should not lift its ban
+}
@@ -18,25 +18,70 @@ const (
 	// required.
 	_idRandBytes = 18
 
-	// DefaultExpiry represents the default amount of time after which a UserSession will expire.
+	// _defaultExpiry represents the default amount of time after which a UserSession will expire, used when the
+	// SessionPolicy does not specify a Lifetime.
 	_defaultExpiry = time.Hour * 24 * 14
 )
 
 // UserSessionRepository provides methods for storing and retrieving UserSessions.
 type UserSessionRepository interface {
 	Create(ctx context.Context, us model.UserSession) error
+	Update(ctx context.Context, us model.UserSession) error
+	Delete(ctx context.Context, id string) error
 	FindByID(ctx context.Context, id string) (model.UserSession, error)
+	// FindByUserID returns all UserSessions belonging to the specified user, from newest to oldest.
+	FindByUserID(ctx context.Context, userID string) ([]model.UserSession, error)
+	DeleteByUserID(ctx context.Context, userID string) error
+	// DeleteExpired removes all UserSessions which have expired relative to the provided time, and returns the number
+	// of sessions removed.
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// SessionPolicy dictates how long UserSessions remain valid.
+type SessionPolicy struct {
+	// Lifetime is the amount of time after creation (or renewal) at which a UserSession expires. If zero, sessions
+	// expire after 14 days.
+	Lifetime time.Duration
+	// Sliding enables renewal of UserSessions which are used after half of their Lifetime has elapsed, extending
+	// their expiry to a full Lifetime from the time of use.
+	Sliding bool
+	// MaxLifetime is the absolute amount of time after creation at which a UserSession expires, regardless of
+	// renewal. If zero, sessions may be renewed indefinitely.
+	MaxLifetime time.Duration
+}
+
+// lifetime returns the Lifetime of the policy, or the default expiry if none is specified.
+func (p SessionPolicy) lifetime() time.Duration {
+	if p.Lifetime <= 0 {
+		return _defaultExpiry
+	}
+	return p.Lifetime
+}
+
+// expiry returns the time at which a UserSession created at the specified time should expire if it is issued or
+// renewed at now, limited by MaxLifetime.
+func (p SessionPolicy) expiry(created time.Time, now time.Time) time.Time {
+	exp := now.Add(p.lifetime())
+	if p.MaxLifetime > 0 {
+		if limit := created.Add(p.MaxLifetime); limit.Before(exp) {
+			return limit
+		}
+	}
+	return exp
 }
 
 // UserSession is a service for managing UserSessions.
 type UserSession struct {
-	repo UserSessionRepository
+	repo   UserSessionRepository
+	policy SessionPolicy
 }
 
-// NewUserSessionService returns a new UserSession service with the provided UserSessionRepository.
-func NewUserSessionService(repo UserSessionRepository) UserSession {
+// NewUserSessionService returns a new UserSession service with the provided UserSessionRepository, which issues and
+// renews sessions according to the provided SessionPolicy.
+func NewUserSessionService(repo UserSessionRepository, policy SessionPolicy) UserSession {
 	return UserSession{
-		repo,
+		repo:   repo,
+		policy: policy,
 	}
 }
 
@@ -56,7 +101,7 @@ func (s UserSession) CreateUserSession(ctx context.Context, u model.User, IP str
 	session.ID = base64.URLEncoding.EncodeToString(randBytes)
 
 	session.Created = time.Now()
-	session.Expires = session.Created.Add(_defaultExpiry)
+	session.Expires = s.policy.expiry(session.Created, session.Created)
 	session.IP = IP
 
 	return session, s.repo.Create(ctx, session)
@@ -75,3 +120,89 @@ func (s UserSession) FindSessionByID(ctx context.Context, id string) (model.User
 
 	return session, nil
 }
+
+// RenewSession extends the expiry of the provided UserSession if sliding renewal is enabled and more than half of its
+// lifetime has elapsed, and returns the resulting UserSession, and whether or not it was renewed.
+func (s UserSession) RenewSession(ctx context.Context, session model.UserSession) (model.UserSession, bool, error) {
+	if !s.policy.Sliding {
+		return session, false, nil
+	}
+
+	now := time.Now()
+	if session.Expires.Sub(now) > s.policy.lifetime()/2 {
+		return session, false, nil
+	}
+
+	exp := s.policy.expiry(session.Created, now)
+	if !exp.After(session.Expires) {
+		// the session has reached its maximum lifetime, and cannot be extended further
+		return session, false, nil
+	}
+
+	session.Expires = exp
+	if err := s.repo.Update(ctx, session); err != nil {
+		return model.UserSession{}, false, fmt.Errorf("UserSession: %w", err)
+	}
+
+	return session, true, nil
+}
+
+// FindSessionsByUserID returns the unexpired UserSessions belonging to the specified user, from newest to oldest.
+func (s UserSession) FindSessionsByUserID(ctx context.Context, userID string) ([]model.UserSession, error) {
+	sessions, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("UserSession: %w", err)
+	}
+
+	now := time.Now()
+	active := make([]model.UserSession, 0, len(sessions))
+	for _, sess := range sessions {
+		if !sess.IsExpired(now) {
+			active = append(active, sess)
+		}
+	}
+
+	return active, nil
+}
+
+// DeleteSession deletes the UserSession with the specified ID.
+func (s UserSession) DeleteSession(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// DeleteSessionsByUserID deletes all UserSessions belonging to the specified user.
+func (s UserSession) DeleteSessionsByUserID(ctx context.Context, userID string) error {
+	return s.repo.DeleteByUserID(ctx, userID)
+}
+
+// DeleteExpiredSessions deletes all UserSessions which have expired, and returns the number of sessions deleted.
+func (s UserSession) DeleteExpiredSessions(ctx context.Context) (int, error) {
+	n, err := s.repo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return n, fmt.Errorf("UserSession: %w", err)
+	}
+	return n, nil
+}
+
+// RunJanitor deletes expired UserSessions once every interval until the provided context is cancelled. The result
+// of each purge is reported to the provided callback, which may be nil. A non-positive interval disables the janitor.
+func (s UserSession) RunJanitor(ctx context.Context, interval time.Duration, report func(deleted int, err error)) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.DeleteExpiredSessions(ctx)
+			if report != nil {
+				report(n, err)
+			}
+		}
+	}
+}
@@ -19,7 +19,7 @@ func TestUserSession_CreateUserSession(t *testing.T) {
 	userRepo := inmemory.NewUserRepository()
 	sessionRepo := inmemory.NewUserSessionRepository()
 
-	service := service.NewUserSessionService(sessionRepo)
+	service := service.NewUserSessionService(sessionRepo, service.SessionPolicy{})
 
 	user := model.User{
 		ID:   xid.New().String(),
@@ -42,7 +42,7 @@ func TestUserSession_FindSessionByID_Valid(t *testing.T) {
 
 	sessionRepo := inmemory.NewUserSessionRepository()
 
-	service := service.NewUserSessionService(sessionRepo)
+	service := service.NewUserSessionService(sessionRepo, service.SessionPolicy{})
 
 	user := model.User{
 		ID:   xid.New().String(),
@@ -61,7 +61,7 @@ func TestUserSession_FindSessionByID_Invalid(t *testing.T) {
 
 	sessionRepo := inmemory.NewUserSessionRepository()
 
-	service := service.NewUserSessionService(sessionRepo)
+	service := service.NewUserSessionService(sessionRepo, service.SessionPolicy{})
 
 	user := model.User{
 		ID:   xid.New().String(),
@@ -81,3 +81,100 @@ func TestUserSession_FindSessionByID_Invalid(t *testing.T) {
 	_, err = service.FindSessionByID(context.Background(), "ExPiReD000")
 	is.True(err != nil) // lookup of expired session id should return error
 }
+
+func TestUserSession_RunJanitor(t *testing.T) {
+	is := is.New(t)
+
+	sessionRepo := inmemory.NewUserSessionRepository()
+
+	service := service.NewUserSessionService(sessionRepo, service.SessionPolicy{})
+
+	sessionRepo.Create(context.Background(), model.UserSession{
+		ID:      "ExPiReD000",
+		UserID:  "user",
+		Created: time.Now().Add(-25 * time.Hour),
+		Expires: time.Now().Add(-1 * time.Hour),
+	})
+	valid, err := service.CreateUserSession(context.Background(), model.User{ID: "user"}, "")
+	is.NoErr(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		service.RunJanitor(ctx, time.Millisecond, func(deleted int, err error) {
+			if err != nil {
+				t.Errorf("purging expired sessions: %v", err)
+			}
+			if deleted > 0 {
+				cancel()
+			}
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("janitor did not purge expired session and stop when cancelled")
+	}
+
+	_, err = sessionRepo.FindByID(context.Background(), "ExPiReD000")
+	is.True(err != nil) // expired session should be purged
+	_, err = sessionRepo.FindByID(context.Background(), valid.ID)
+	is.NoErr(err) // valid session should remain
+}
+
+func TestUserSession_CreateUserSession_Policy(t *testing.T) {
+	is := is.New(t)
+
+	service := service.NewUserSessionService(inmemory.NewUserSessionRepository(), service.SessionPolicy{
+		Lifetime:    48 * time.Hour,
+		MaxLifetime: 24 * time.Hour,
+	})
+
+	sess, err := service.CreateUserSession(context.Background(), model.User{ID: "user"}, "")
+	is.NoErr(err)
+	is.Equal(sess.Expires, sess.Created.Add(24*time.Hour)) // session expiry should be limited by max lifetime
+}
+
+func TestUserSession_RenewSession(t *testing.T) {
+	is := is.New(t)
+
+	sessionRepo := inmemory.NewUserSessionRepository()
+	now := time.Now()
+
+	fresh := model.UserSession{ID: "fresh", UserID: "user", Created: now.Add(-time.Hour), Expires: now.Add(9 * time.Hour)}
+	stale := model.UserSession{ID: "stale", UserID: "user", Created: now.Add(-8 * time.Hour), Expires: now.Add(2 * time.Hour)}
+	old := model.UserSession{ID: "old", UserID: "user", Created: now.Add(-23 * time.Hour), Expires: now.Add(time.Hour)}
+	for _, sess := range []model.UserSession{fresh, stale, old} {
+		is.NoErr(sessionRepo.Create(context.Background(), sess))
+	}
+
+	fixed := service.NewUserSessionService(sessionRepo, service.SessionPolicy{Lifetime: 10 * time.Hour})
+	_, renewed, err := fixed.RenewSession(context.Background(), stale)
+	is.NoErr(err)
+	is.True(!renewed) // sessions should not be renewed without sliding renewal
+
+	sliding := service.NewUserSessionService(sessionRepo, service.SessionPolicy{
+		Lifetime:    10 * time.Hour,
+		Sliding:     true,
+		MaxLifetime: 24 * time.Hour,
+	})
+
+	_, renewed, err = sliding.RenewSession(context.Background(), fresh)
+	is.NoErr(err)
+	is.True(!renewed) // sessions should not be renewed before half their lifetime has elapsed
+
+	got, renewed, err := sliding.RenewSession(context.Background(), stale)
+	is.NoErr(err)
+	is.True(renewed)                               // sessions past half their lifetime should be renewed
+	is.True(time.Until(got.Expires) > 9*time.Hour) // renewed session should expire a full lifetime from now
+	stored, err := sessionRepo.FindByID(context.Background(), stale.ID)
+	is.NoErr(err)
+	is.Equal(stored.Expires, got.Expires) // renewed expiry should be stored
+
+	got, renewed, err = sliding.RenewSession(context.Background(), old)
+	is.NoErr(err)
+	is.True(!renewed)                  // sessions at their max lifetime should not be renewed
+	is.Equal(got.Expires, old.Expires) // session expiry should be unchanged
+}
@@ -0,0 +1,487 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/willbicks/epigram/internal/ctxval"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+// newUserServiceWithUsers returns a User service backed by in memory repositories containing the provided users.
+func newUserServiceWithUsers(t *testing.T, users ...model.User) (service.User, service.UserRepository) {
+	t.Helper()
+
+	members := make([]service.Member, len(users))
+	for i, u := range users {
+		members[i] = service.Member{User: u}
+	}
+	userService, userRepo, _ := newUserServiceWithMembers(t, members...)
+	return userService, userRepo
+}
+
+// testQuizPolicy locks users out of the entry quiz after three failed attempts.
+var testQuizPolicy = service.QuizPolicy{MaxAttempts: 3}
+
+// passedQuiz and failedQuiz are the results of attempts at a quiz of two questions, the second of which is answered
+// incorrectly in failedQuiz.
+var (
+	passedQuiz = service.QuizResult{
+		QuestionIDs:      []int{2, 0},
+		WrongQuestionIDs: []int{},
+		Answers:          map[int]string{2: "woods", 0: "panel"},
+	}
+	failedQuiz = service.QuizResult{
+		QuestionIDs:      []int{2, 0},
+		WrongQuestionIDs: []int{0},
+		Answers:          map[int]string{2: "woods", 0: "pane"},
+	}
+)
+
+// newUserServiceWithMembers returns a User service backed by in memory repositories containing the provided users,
+// and their memberships of testCommunity (if their membership is not empty), which applies testQuizPolicy.
+func newUserServiceWithMembers(t *testing.T, members ...service.Member) (service.User, service.UserRepository, service.MembershipRepository) {
+	t.Helper()
+
+	return newUserServiceWithPolicy(t, testQuizPolicy, members...)
+}
+
+// newUserServiceWithPolicy returns a User service like newUserServiceWithMembers, which applies the provided quiz
+// policy.
+func newUserServiceWithPolicy(t *testing.T, quiz service.QuizPolicy, members ...service.Member) (service.User, service.UserRepository, service.MembershipRepository) {
+	t.Helper()
+
+	userRepo := inmemory.NewUserRepository()
+	membershipRepo := inmemory.NewMembershipRepository()
+	for _, m := range members {
+		if err := userRepo.Create(context.Background(), m.User); err != nil {
+			t.Fatalf("creating user %v: %v", m.ID, err)
+		}
+		if m.Membership == (model.Membership{}) {
+			continue
+		}
+		m.Membership.CommunityID = testCommunity.ID
+		m.Membership.UserID = m.ID
+		if err := membershipRepo.Create(context.Background(), m.Membership); err != nil {
+			t.Fatalf("creating membership %v: %v", m.ID, err)
+		}
+	}
+
+	return service.NewUserService(
+		userRepo,
+		inmemory.NewUserIdentityRepository(),
+		membershipRepo,
+		inmemory.NewQuizAttemptRepository(),
+		inmemory.NewQuoteRepository(),
+		newUserReferences(),
+		inmemory.NewTransactor(),
+		service.NewUserSessionService(inmemory.NewUserSessionRepository(), service.SessionPolicy{}),
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()),
+		quiz,
+	), userRepo, membershipRepo
+}
+
+// newUserReferences returns UserReferences backed by empty in-memory repositories.
+func newUserReferences() service.UserReferences {
+	return service.UserReferences{
+		APITokens:      inmemory.NewAPITokenRepository(),
+		ChatLinks:      inmemory.NewChatLinkRepository(),
+		Reactions:      inmemory.NewReactionRepository(),
+		Comments:       inmemory.NewCommentRepository(),
+		People:         inmemory.NewPersonRepository(),
+		AccessRequests: inmemory.NewAccessRequestRepository(),
+		Invites:        inmemory.NewInviteRepository(),
+		QuizSessions:   inmemory.NewQuizSessionRepository(),
+	}
+}
+
+func TestUser_SetUserBanned(t *testing.T) {
+	is := is.New(t)
+
+	member := service.Member{User: model.User{ID: "member"}, Membership: model.Membership{QuizPassed: true}}
+	communityAdmin := service.Member{User: model.User{ID: "cadmin"}, Membership: model.Membership{QuizPassed: true, Admin: true}}
+	userService, _, membershipRepo := newUserServiceWithMembers(t, member, communityAdmin, service.Member{User: adminUser})
+
+	ctxMember := userContext(member.User)
+	is.Equal(userService.SetUserBanned(ctxMember, member.ID, true), service.ErrNotAuthorized) // non-admins should not be able to ban
+
+	sess, err := userService.CreateUserSession(context.Background(), member.User, "10.0.0.1")
+	is.NoErr(err)
+
+	ctxAdmin := userContext(adminUser)
+	is.NoErr(userService.SetUserBanned(ctxAdmin, member.ID, true)) // admins should be able to ban users
+
+	got, err := membershipRepo.Find(context.Background(), testCommunity.ID, member.ID)
+	is.NoErr(err)
+	is.True(got.Banned)                            // member should be banned
+	is.True(!got.IsAuthorized(testCommunity.Gate)) // banned member should not be authorized
+
+	_, err = userService.GetUserFromSessionID(context.Background(), sess.ID)
+	is.True(err != nil) // banning a user should revoke their sessions
+
+	is.NoErr(userService.SetUserBanned(ctxAdmin, member.ID, false)) // admins should be able to unban users
+
+	got, err = membershipRepo.Find(context.Background(), testCommunity.ID, member.ID)
+	is.NoErr(err)
+	is.True(!got.Banned) // member should be unbanned
+
+	is.True(userService.SetUserBanned(ctxAdmin, communityAdmin.ID, true) != nil)               // admins should not be bannable
+	is.Equal(userService.SetUserBanned(ctxAdmin, "missing", true), service.ErrUserNotFound)    // banning missing user should fail
+	is.Equal(userService.SetUserBanned(ctxAdmin, adminUser.ID, true), service.ErrUserNotFound) // banning non-members should fail
+}
+
+func TestUser_SetUserAdmin(t *testing.T) {
+	is := is.New(t)
+
+	member := service.Member{User: model.User{ID: "member"}, Membership: model.Membership{QuizPassed: true}}
+	communityAdmin := service.Member{User: model.User{ID: "cadmin"}, Membership: model.Membership{QuizPassed: true, Admin: true}}
+	userService, _, membershipRepo := newUserServiceWithMembers(t, member, communityAdmin)
+
+	ctxAdmin := ctxval.ContextWithMembership(userContext(communityAdmin.User), communityAdmin.Membership)
+	is.True(userService.SetUserAdmin(ctxAdmin, communityAdmin.ID, false) != nil) // last admin should not be demotable
+
+	is.NoErr(userService.SetUserAdmin(ctxAdmin, member.ID, true)) // community admins should be able to promote members
+
+	got, err := membershipRepo.Find(context.Background(), testCommunity.ID, member.ID)
+	is.NoErr(err)
+	is.True(got.Admin) // member should be promoted
+
+	is.NoErr(userService.SetUserAdmin(ctxAdmin, communityAdmin.ID, false)) // admin should be demotable once another exists
+
+	got, err = membershipRepo.Find(context.Background(), testCommunity.ID, communityAdmin.ID)
+	is.NoErr(err)
+	is.True(!got.Admin) // admin should be demoted
+}
+
+func TestUser_ResetQuizAttempts(t *testing.T) {
+	is := is.New(t)
+
+	lockedOut := service.Member{User: model.User{ID: "locked"}, Membership: model.Membership{QuizAttempts: 3}}
+	userService, _, membershipRepo := newUserServiceWithMembers(t, lockedOut, service.Member{User: adminUser})
+
+	ctxLockedOut := ctxval.ContextWithMembership(userContext(lockedOut.User), lockedOut.Membership)
+	is.Equal(userService.ResetQuizAttempts(ctxLockedOut, lockedOut.ID), service.ErrNotAuthorized) // users should not be able to reset their own attempts
+
+	ctxAdmin := userContext(adminUser)
+	is.NoErr(userService.ResetQuizAttempts(ctxAdmin, lockedOut.ID)) // admins should be able to reset attempts
+
+	got, err := membershipRepo.Find(context.Background(), testCommunity.ID, lockedOut.ID)
+	is.NoErr(err)
+	is.Equal(got.QuizAttempts, int8(0))   // quiz attempts should be reset
+	is.True(got.LastQuizAttempt.IsZero()) // last attempt should be forgotten
+}
+
+func TestUser_RecordQuizAttempt(t *testing.T) {
+	is := is.New(t)
+
+	newcomer := model.User{ID: "newcomer"}
+	userService, _, membershipRepo := newUserServiceWithMembers(t, service.Member{User: newcomer})
+	ctx := ctxval.ContextWithCommunity(ctxval.ContextWithUser(context.Background(), newcomer), testCommunity)
+
+	_, _, err := userService.RecordQuizAttempt(ctxval.ContextWithCommunity(context.Background(), testCommunity), passedQuiz)
+	is.Equal(err, service.ErrNotAuthenticated) // anonymous users should not be able to attempt the quiz
+
+	m, failReason, err := userService.RecordQuizAttempt(ctx, failedQuiz)
+	is.NoErr(err)
+	is.True(failReason != "")                 // failed attempt should give a reason
+	is.Equal(m.QuizAttempts, int8(1))         // attempt should be counted
+	is.Equal(m.CommunityID, testCommunity.ID) // attempt should be recorded in the current community
+
+	m, failReason, err = userService.RecordQuizAttempt(ctx, passedQuiz)
+	is.NoErr(err)
+	is.Equal(failReason, "")          // passing attempt should not give a reason
+	is.Equal(m.QuizAttempts, int8(2)) // attempts should accumulate
+
+	got, err := membershipRepo.Find(context.Background(), testCommunity.ID, newcomer.ID)
+	is.NoErr(err)
+	is.True(got.QuizPassed)                // attempting the quiz should make the user a member
+	is.True(!got.LastQuizAttempt.IsZero()) // time of the attempt should be recorded
+}
+
+func TestUser_RecordQuizAttempt_LockedOut(t *testing.T) {
+	is := is.New(t)
+
+	newcomer := model.User{ID: "newcomer"}
+	userService, _, membershipRepo := newUserServiceWithMembers(t, service.Member{User: newcomer})
+	ctx := signedInContext(newcomer)
+
+	for i := 0; i < testQuizPolicy.MaxAttempts; i++ {
+		_, _, err := userService.RecordQuizAttempt(ctx, failedQuiz)
+		is.NoErr(err) // attempts within the limit should be recorded
+	}
+
+	st, err := userService.GetQuizStatus(ctx)
+	is.NoErr(err)
+	is.True(st.LockedOut) // user should be locked out after using all attempts
+
+	_, _, err = userService.RecordQuizAttempt(ctx, passedQuiz)
+	is.Equal(err, service.ErrQuizLockedOut) // locked out users should not be able to pass the quiz
+
+	got, err := membershipRepo.Find(context.Background(), testCommunity.ID, newcomer.ID)
+	is.NoErr(err)
+	is.True(!got.QuizPassed)                                    // rejected attempt should not pass the quiz
+	is.Equal(int(got.QuizAttempts), testQuizPolicy.MaxAttempts) // rejected attempt should not be counted
+
+	members, err := userService.GetMembers(userContext(adminUser))
+	is.NoErr(err)
+	is.True(members[0].LockedOut) // admins should see that the member is locked out
+
+	is.NoErr(userService.ResetQuizAttempts(userContext(adminUser), newcomer.ID))
+	_, _, err = userService.RecordQuizAttempt(ctx, passedQuiz)
+	is.NoErr(err) // user should be able to attempt the quiz once reset
+}
+
+func TestUser_RecordQuizAttempt_Cooldown(t *testing.T) {
+	is := is.New(t)
+
+	newcomer := model.User{ID: "newcomer"}
+	userService, _, membershipRepo := newUserServiceWithPolicy(t, service.QuizPolicy{Cooldown: time.Hour},
+		service.Member{User: newcomer})
+	ctx := signedInContext(newcomer)
+
+	st, err := userService.GetQuizStatus(ctx)
+	is.NoErr(err)
+	is.Equal(st.Wait(), time.Duration(0)) // users should not wait before their first attempt
+
+	_, _, err = userService.RecordQuizAttempt(ctx, failedQuiz)
+	is.NoErr(err)
+
+	st, err = userService.GetQuizStatus(ctx)
+	is.NoErr(err)
+	is.True(!st.LockedOut)              // unlimited attempts should never lock users out
+	is.True(st.Wait() > 59*time.Minute) // users should wait the cooldown after an attempt
+	is.True(st.Wait()%time.Second == 0) // wait should be rounded to whole seconds
+
+	_, _, err = userService.RecordQuizAttempt(ctx, passedQuiz)
+	is.Equal(err, service.ErrQuizCooldown) // attempts during the cooldown should be rejected
+
+	m, err := membershipRepo.Find(context.Background(), testCommunity.ID, newcomer.ID)
+	is.NoErr(err)
+	m.LastQuizAttempt = time.Now().Add(-2 * time.Hour)
+	is.NoErr(membershipRepo.Update(context.Background(), m))
+
+	_, _, err = userService.RecordQuizAttempt(ctx, passedQuiz)
+	is.NoErr(err) // attempts after the cooldown should be recorded
+}
+
+func TestUser_GetQuizAttempts(t *testing.T) {
+	is := is.New(t)
+
+	newcomer := model.User{ID: "newcomer"}
+	for _, record := range []bool{false, true} {
+		policy := service.QuizPolicy{RecordAnswers: record}
+		userService, _, _ := newUserServiceWithPolicy(t, policy, service.Member{User: newcomer}, service.Member{User: adminUser})
+		ctx := ctxval.ContextWithIP(signedInContext(newcomer), "192.168.0.1")
+
+		_, _, err := userService.RecordQuizAttempt(ctx, failedQuiz)
+		is.NoErr(err)
+		_, _, err = userService.RecordQuizAttempt(ctx, passedQuiz)
+		is.NoErr(err)
+
+		_, err = userService.GetQuizAttempts(ctx, newcomer.ID)
+		is.Equal(err, service.ErrNotAuthorized) // non-admins should not be able to view quiz history
+
+		attempts, err := userService.GetQuizAttempts(userContext(adminUser), newcomer.ID)
+		is.NoErr(err)
+		is.Equal(len(attempts), 2)                                          // each attempt should be recorded
+		is.True(attempts[0].Passed)                                         // newest attempt should be listed first
+		is.True(!attempts[1].Passed)                                        // failed attempt should be recorded as such
+		is.Equal(attempts[1].WrongQuestionIDs, failedQuiz.WrongQuestionIDs) // incorrectly answered questions should be recorded
+		is.Equal(attempts[1].QuestionIDs, failedQuiz.QuestionIDs)           // presented questions should be recorded
+		is.Equal(attempts[1].IP, "192.168.0.1")                             // IP of the attempt should be recorded
+		is.Equal(attempts[1].CommunityID, testCommunity.ID)                 // attempt should be recorded in the current community
+		if record {
+			is.Equal(attempts[1].Answers, failedQuiz.Answers) // answers should be recorded when enabled
+		} else {
+			is.Equal(len(attempts[1].Answers), 0) // answers should not be recorded unless enabled
+		}
+	}
+}
+
+func TestUser_GetMembers(t *testing.T) {
+	is := is.New(t)
+
+	member := service.Member{User: model.User{ID: "member"}, Membership: model.Membership{QuizPassed: true}}
+	userService, _, _ := newUserServiceWithMembers(t, member, service.Member{User: adminUser}, service.Member{User: otherUser})
+
+	ctxAdmin := userContext(adminUser)
+	members, err := userService.GetMembers(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(members), 1)          // only members of the current community should be listed
+	is.Equal(members[0].ID, member.ID) // member should be listed
+
+	got, err := userService.GetMember(ctxAdmin, member.ID)
+	is.NoErr(err)
+	is.Equal(got.Membership.QuizPassed, true) // admins should be able to view a member
+
+	_, err = userService.GetMember(ctxAdmin, otherUser.ID)
+	is.Equal(err, service.ErrUserNotFound) // users who are not members should not be found
+
+	users, err := userService.GetAllUsers(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(users), 3) // instance admins should see every user
+
+	_, err = userService.GetMembers(userContext(member.User))
+	is.Equal(err, service.ErrNotAuthorized) // non-admins should not be able to list members
+}
+
+func TestUser_RevokeUserSession(t *testing.T) {
+	is := is.New(t)
+
+	member := model.User{ID: "member"}
+	userService, _ := newUserServiceWithUsers(t, member, otherUser)
+
+	own, err := userService.CreateUserSession(context.Background(), member, "10.0.0.1")
+	is.NoErr(err)
+	other, err := userService.CreateUserSession(context.Background(), otherUser, "10.0.0.2")
+	is.NoErr(err)
+
+	ctxMember := userContext(member)
+	sessions, err := userService.GetUserSessions(ctxMember)
+	is.NoErr(err)
+	is.Equal(len(sessions), 1)                 // users should only see their own sessions
+	is.Equal(sessions[0].Handle, own.Handle()) // session should be identified by its handle
+	is.True(sessions[0].Handle != own.ID)      // session ID should not be revealed
+	is.Equal(sessions[0].IP, own.IP)           // session details should be listed
+
+	is.Equal(userService.RevokeUserSession(ctxMember, other.Handle()), service.ErrSessionNotFound) // users should not revoke others' sessions
+	_, err = userService.GetUserFromSessionID(context.Background(), other.ID)
+	is.NoErr(err) // other user's session should remain valid
+
+	is.Equal(userService.RevokeUserSession(ctxMember, own.ID), service.ErrSessionNotFound) // sessions should not be revoked by ID
+
+	is.NoErr(userService.RevokeUserSession(ctxMember, own.Handle())) // users should be able to revoke their own sessions
+	_, err = userService.GetUserFromSessionID(context.Background(), own.ID)
+	is.True(err != nil) // revoked session should no longer be valid
+
+	_, err = userService.GetUserSessions(context.Background())
+	is.Equal(err, service.ErrNotAuthenticated) // anonymous users have no sessions to list
+}
+
+func TestUser_EndUserSession(t *testing.T) {
+	is := is.New(t)
+
+	member := model.User{ID: "member"}
+	userService, _ := newUserServiceWithUsers(t, member)
+
+	sess, err := userService.CreateUserSession(context.Background(), member, "10.0.0.1")
+	is.NoErr(err)
+
+	is.NoErr(userService.EndUserSession(context.Background(), sess.ID)) // ending a session should not fail
+	_, err = userService.GetUserFromSessionID(context.Background(), sess.ID)
+	is.True(err != nil) // ended session should no longer be valid
+
+	is.NoErr(userService.EndUserSession(context.Background(), sess.ID)) // ending a missing session should be ignored
+}
+
+func TestUser_RevokeAllUserSessions(t *testing.T) {
+	is := is.New(t)
+
+	member := model.User{ID: "member"}
+	userService, _ := newUserServiceWithUsers(t, member, adminUser)
+
+	sess, err := userService.CreateUserSession(context.Background(), member, "10.0.0.1")
+	is.NoErr(err)
+
+	ctxMember := userContext(member)
+	is.Equal(userService.RevokeAllUserSessions(ctxMember, member.ID), service.ErrNotAuthorized) // non-admins should not revoke all sessions
+
+	ctxAdmin := userContext(adminUser)
+	is.NoErr(userService.RevokeAllUserSessions(ctxAdmin, member.ID)) // admins should be able to revoke all sessions
+	_, err = userService.GetUserFromSessionID(context.Background(), sess.ID)
+	is.True(err != nil) // revoked session should no longer be valid
+
+	is.Equal(userService.RevokeAllUserSessions(ctxAdmin, "missing"), service.ErrUserNotFound) // revoking sessions of missing user should fail
+}
+
+// approvalCommunity is testCommunity, with a gate which requires members to be approved by its admins.
+var approvalCommunity = model.Community{ID: testCommunity.ID, Title: testCommunity.Title, Gate: model.GateApproval}
+
+func TestUser_JoinCommunity(t *testing.T) {
+	is := is.New(t)
+
+	newcomer := model.User{ID: "newcomer"}
+	userService, _, membershipRepo := newUserServiceWithMembers(t, service.Member{User: newcomer})
+	ctxFor := func(c model.Community) context.Context {
+		return ctxval.ContextWithCommunity(ctxval.ContextWithUser(context.Background(), newcomer), c)
+	}
+
+	_, err := userService.JoinCommunity(ctxval.ContextWithCommunity(context.Background(), approvalCommunity))
+	is.Equal(err, service.ErrNotAuthenticated) // anonymous users should not be able to join
+
+	m, err := userService.JoinCommunity(ctxFor(testCommunity))
+	is.NoErr(err)
+	is.True(m.Joined.IsZero()) // communities with an entry quiz should not be joined without attempting it
+	_, err = membershipRepo.Find(context.Background(), testCommunity.ID, newcomer.ID)
+	is.Equal(err, storage.ErrNotFound) // membership should not be stored
+
+	m, err = userService.JoinCommunity(ctxFor(approvalCommunity))
+	is.NoErr(err)
+	is.True(!m.Joined.IsZero())                         // communities which require approval should be joined
+	is.True(m.AwaitingApproval(approvalCommunity.Gate)) // new member should await approval
+	is.True(!m.IsAuthorized(approvalCommunity.Gate))    // new member should not be authorized
+
+	again, err := userService.JoinCommunity(ctxFor(approvalCommunity))
+	is.NoErr(err)
+	is.True(again.Joined.Equal(m.Joined)) // joining again should return the existing membership
+
+	openCommunity := model.Community{ID: "open", Gate: model.GateOpen}
+	m, err = userService.JoinCommunity(ctxFor(openCommunity))
+	is.NoErr(err)
+	is.True(m.IsAuthorized(openCommunity.Gate)) // members of open communities should be authorized immediately
+}
+
+func TestUser_SetUserApproved(t *testing.T) {
+	is := is.New(t)
+
+	pending := service.Member{User: model.User{ID: "pending"}, Membership: model.Membership{Joined: time.Now()}}
+	member := service.Member{User: model.User{ID: "member"}, Membership: model.Membership{Joined: time.Now(), Approved: true}}
+	userService, _, membershipRepo := newUserServiceWithMembers(t, pending, member, service.Member{User: adminUser})
+	ctxAdmin := communityContext(adminUser, approvalCommunity)
+
+	is.Equal(userService.SetUserApproved(communityContext(member.User, approvalCommunity), pending.ID, true),
+		service.ErrNotAuthorized) // non-admins should not be able to approve users
+
+	members, err := userService.GetPendingMembers(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(members), 1)           // only members awaiting approval should be pending
+	is.Equal(members[0].ID, pending.ID) // pending member should be listed
+
+	is.NoErr(userService.SetUserApproved(ctxAdmin, pending.ID, false)) // admins should be able to reject users
+
+	got, err := membershipRepo.Find(context.Background(), testCommunity.ID, pending.ID)
+	is.NoErr(err)
+	is.True(got.Rejected)                              // member should be rejected
+	is.True(!got.IsAuthorized(approvalCommunity.Gate)) // rejected member should not be authorized
+
+	members, err = userService.GetPendingMembers(ctxAdmin)
+	is.NoErr(err)
+	is.Equal(len(members), 0) // rejected members should no longer be pending
+
+	is.NoErr(userService.SetUserApproved(ctxAdmin, pending.ID, true)) // admins should be able to approve rejected users
+
+	got, err = membershipRepo.Find(context.Background(), testCommunity.ID, pending.ID)
+	is.NoErr(err)
+	is.True(got.Approved && !got.Rejected)            // member should be approved
+	is.True(got.IsAuthorized(approvalCommunity.Gate)) // approved member should be authorized
+
+	is.Equal(userService.SetUserApproved(userContext(adminUser), pending.ID, true),
+		service.ErrApprovalNotRequired) // communities which do not require approval should not approve users
+}
+
+func TestUser_RecordQuizAttempt_NotRequired(t *testing.T) {
+	is := is.New(t)
+
+	newcomer := model.User{ID: "newcomer"}
+	userService, _, _ := newUserServiceWithMembers(t, service.Member{User: newcomer})
+	ctx := ctxval.ContextWithCommunity(ctxval.ContextWithUser(context.Background(), newcomer), approvalCommunity)
+
+	_, _, err := userService.RecordQuizAttempt(ctx, passedQuiz)
+	is.Equal(err, service.ErrQuizNotRequired) // communities without an entry quiz should not record attempts
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/willbicks/epigram/internal/model"
+
+	"github.com/rs/xid"
+)
+
+const (
+	// _webhookTimeout is the maximum amount of time to wait for a webhook to respond to a delivery.
+	_webhookTimeout = 10 * time.Second
+
+	// _webhookBatchSize is the maximum number of WebhookDeliveries attempted each time pending deliveries are sent.
+	_webhookBatchSize = 50
+
+	// _webhookMaxAttempts is the number of unsuccessful attempts after which a WebhookDelivery is discarded.
+	_webhookMaxAttempts = 8
+
+	// _webhookRetryBackoff is the delay before the first retry of a WebhookDelivery, which doubles after each
+	// subsequent unsuccessful attempt.
+	_webhookRetryBackoff = 30 * time.Second
+
+	// _defaultWebhookTemplate is the message text of slack and discord payloads, when not overridden by the webhook.
+	_defaultWebhookTemplate = `New quote from {{.Quotee}}: "{{.Quote}}"{{if .Context}} ({{.Context}}){{end}} {{.URL}}`
+)
+
+// WebhookEventQuoteCreated is the event sent to webhooks when a new Quote is created.
+const WebhookEventQuoteCreated = "quote.created"
+
+// Headers included in each webhook delivery. The signature header is only included if the webhook has a secret.
+const (
+	WebhookEventHeader     = "X-Epigram-Event"
+	WebhookDeliveryHeader  = "X-Epigram-Delivery"
+	WebhookTimestampHeader = "X-Epigram-Timestamp"
+	WebhookSignatureHeader = "X-Epigram-Signature"
+)
+
+// WebhookFormat dictates the structure of the payload sent to a webhook.
+type WebhookFormat string
+
+const (
+	// WebhookGeneric payloads describe the event and quote as structured JSON.
+	WebhookGeneric WebhookFormat = "generic"
+	// WebhookSlack payloads are messages compatible with Slack incoming webhooks.
+	WebhookSlack WebhookFormat = "slack"
+	// WebhookDiscord payloads are messages compatible with Discord webhooks.
+	WebhookDiscord WebhookFormat = "discord"
+)
+
+// WebhookDeliveryRepository provides methods for storing and retrieving queued WebhookDeliveries.
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, d model.WebhookDelivery) error
+	Update(ctx context.Context, d model.WebhookDelivery) error
+	Delete(ctx context.Context, id string) error
+	// FindDue returns up to limit WebhookDeliveries whose NextAttempt is at or before the provided time, ordered by
+	// NextAttempt, with ties broken by ID.
+	FindDue(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error)
+}
+
+// WebhookTarget describes an outgoing webhook which is notified when a new Quote is created.
+type WebhookTarget struct {
+	// Name uniquely identifies the webhook.
+	Name string
+	URL  string
+	// Format of the payload sent to the webhook. If blank, WebhookGeneric is used.
+	Format WebhookFormat
+	// Secret is used to sign payloads, if set.
+	Secret string
+	// Template overrides the message text of WebhookSlack and WebhookDiscord payloads, and is executed with a
+	// WebhookQuote. The text of the quote is escaped for Slack, and Discord payloads never mention users or roles.
+	Template string
+	// Community is the ID of the community whose quotes are announced. If blank, model.DefaultCommunityID is used.
+	Community string
+}
+
+// webhookTarget is a WebhookTarget with its message template parsed.
+type webhookTarget struct {
+	WebhookTarget
+	tmpl *template.Template
+}
+
+// WebhookQuote describes a newly created Quote in generic webhook payloads, and is provided to message templates.
+type WebhookQuote struct {
+	ID      string    `json:"id"`
+	Quote   string    `json:"quote"`
+	Quotee  string    `json:"quotee"`
+	Context string    `json:"context"`
+	Created time.Time `json:"created"`
+	// URL links to the quotes page.
+	URL string `json:"url"`
+}
+
+// webhookGenericPayload is the payload sent to WebhookGeneric webhooks.
+type webhookGenericPayload struct {
+	Event string       `json:"event"`
+	Quote WebhookQuote `json:"quote"`
+}
+
+// Webhook is a service which notifies outgoing webhooks of new Quotes. Payloads are queued in a
+// WebhookDeliveryRepository, and delivered in the background by RunDispatcher.
+type Webhook struct {
+	repo    WebhookDeliveryRepository
+	targets []webhookTarget
+	// quotesURL is the absolute URL of the quotes page, linked to in payloads.
+	quotesURL string
+	client    *http.Client
+	// wake signals the dispatcher that new deliveries have been queued.
+	wake chan struct{}
+}
+
+// NewWebhookService returns a new Webhook service which queues deliveries to the provided targets in repo, linking to
+// the quotes page at quotesURL. An error is returned if any of the targets are invalid.
+func NewWebhookService(repo WebhookDeliveryRepository, targets []WebhookTarget, quotesURL string) (Webhook, error) {
+	s := Webhook{
+		repo:      repo,
+		targets:   make([]webhookTarget, 0, len(targets)),
+		quotesURL: quotesURL,
+		client:    &http.Client{Timeout: _webhookTimeout},
+		wake:      make(chan struct{}, 1),
+	}
+
+	names := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		if t.Name == "" {
+			return Webhook{}, errors.New("webhook name must not be blank")
+		}
+		if names[t.Name] {
+			return Webhook{}, fmt.Errorf("webhook name %q is used more than once", t.Name)
+		}
+		names[t.Name] = true
+
+		if u, err := url.Parse(t.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Webhook{}, fmt.Errorf("webhook %q must have an absolute http or https URL", t.Name)
+		}
+
+		switch t.Format {
+		case "":
+			t.Format = WebhookGeneric
+		case WebhookGeneric, WebhookSlack, WebhookDiscord:
+		default:
+			return Webhook{}, fmt.Errorf("webhook %q has unknown format %q", t.Name, t.Format)
+		}
+
+		if t.Community == "" {
+			t.Community = model.DefaultCommunityID
+		}
+
+		text := t.Template
+		if text == "" {
+			text = _defaultWebhookTemplate
+		}
+		tmpl, err := template.New(t.Name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return Webhook{}, fmt.Errorf("parsing template of webhook %q: %w", t.Name, err)
+		}
+
+		s.targets = append(s.targets, webhookTarget{WebhookTarget: t, tmpl: tmpl})
+	}
+
+	return s, nil
+}
+
+// SignWebhookPayload returns the signature of a webhook payload sent at the provided unix timestamp, as included in
+// the WebhookSignatureHeader. The signature is the hex encoded HMAC-SHA256 of the timestamp, a period, and the payload,
+// prefixed with "sha256=".
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// payload renders the payload sent to the target describing the provided quote.
+func (t webhookTarget) payload(q WebhookQuote) ([]byte, error) {
+	if t.Format == WebhookGeneric {
+		return json.Marshal(webhookGenericPayload{
+			Event: WebhookEventQuoteCreated,
+			Quote: q,
+		})
+	}
+
+	if t.Format == WebhookSlack {
+		// quotes must not be able to form links or mentions, which slack denotes with angle brackets
+		r := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+		q.Quote, q.Quotee, q.Context = r.Replace(q.Quote), r.Replace(q.Quotee), r.Replace(q.Context)
+	}
+
+	var text strings.Builder
+	if err := t.tmpl.Execute(&text, q); err != nil {
+		return nil, fmt.Errorf("executing template of webhook %q: %w", t.Name, err)
+	}
+
+	if t.Format == WebhookSlack {
+		return json.Marshal(struct {
+			Text string `json:"text"`
+		}{text.String()})
+	}
+	return json.Marshal(struct {
+		Content         string              `json:"content"`
+		AllowedMentions map[string][]string `json:"allowed_mentions"`
+	}{
+		Content: text.String(),
+		// quotes must not be able to mention anyone, including @everyone
+		AllowedMentions: map[string][]string{"parse": {}},
+	})
+}
+
+// target returns the target with the provided name.
+func (s Webhook) target(name string) (webhookTarget, bool) {
+	for _, t := range s.targets {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return webhookTarget{}, false
+}
+
+// QuoteCreated queues a delivery to each webhook of the Quote's community announcing it, and signals the dispatcher to
+// deliver them.
+func (s Webhook) QuoteCreated(ctx context.Context, q model.Quote) error {
+	if len(s.targets) == 0 {
+		return nil
+	}
+
+	wq := WebhookQuote{
+		ID:      q.ID,
+		Quote:   q.Quote,
+		Quotee:  q.Quotee,
+		Context: q.Context,
+		Created: q.Created,
+		URL:     s.quotesURL,
+	}
+
+	now := time.Now()
+	for _, t := range s.targets {
+		if t.Community != q.CommunityID {
+			continue
+		}
+
+		payload, err := t.payload(wq)
+		if err != nil {
+			return err
+		}
+
+		err = s.repo.Create(ctx, model.WebhookDelivery{
+			ID:          xid.New().String(),
+			Webhook:     t.Name,
+			Payload:     string(payload),
+			NextAttempt: now,
+			Created:     now,
+		})
+		if err != nil {
+			return fmt.Errorf("queueing delivery to webhook %q: %w", t.Name, err)
+		}
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// deliver sends a single WebhookDelivery to its target, returning an error if it was not accepted.
+func (s Webhook) deliver(ctx context.Context, t webhookTarget, d model.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, strings.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, WebhookEventQuoteCreated)
+	req.Header.Set(WebhookDeliveryHeader, d.ID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(ts, 10))
+	if t.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(t.Secret, ts, []byte(d.Payload)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("webhook responded %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// DeliverPending attempts each WebhookDelivery which is due, and returns the number which were delivered and which
+// failed. Failed deliveries are retried with exponential backoff, until they are discarded after too many attempts.
+// The returned error describes any failures.
+func (s Webhook) DeliverPending(ctx context.Context) (delivered int, failed int, err error) {
+	due, err := s.repo.FindDue(ctx, time.Now(), _webhookBatchSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("finding due webhook deliveries: %w", err)
+	}
+
+	var errs []error
+	for _, d := range due {
+		t, ok := s.target(d.Webhook)
+		if !ok {
+			// the webhook has been removed from the configuration since the delivery was queued
+			if err := s.repo.Delete(ctx, d.ID); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		deliverErr := s.deliver(ctx, t, d)
+		if deliverErr == nil {
+			delivered++
+			if err := s.repo.Delete(ctx, d.ID); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		failed++
+		d.Attempts++
+		d.LastError = deliverErr.Error()
+		if d.Attempts >= _webhookMaxAttempts {
+			errs = append(errs, fmt.Errorf("discarding delivery %s to webhook %q after %d attempts: %w",
+				d.ID, d.Webhook, d.Attempts, deliverErr))
+			if err := s.repo.Delete(ctx, d.ID); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("delivery %s to webhook %q: %w", d.ID, d.Webhook, deliverErr))
+		d.NextAttempt = time.Now().Add(_webhookRetryBackoff << (d.Attempts - 1))
+		if err := s.repo.Update(ctx, d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return delivered, failed, errors.Join(errs...)
+}
+
+// RunDispatcher delivers pending WebhookDeliveries when new deliveries are queued, and once every interval to retry
+// failed deliveries, until the provided context is cancelled. The result of each run is reported to the provided
+// callback, which may be nil. If no webhooks are configured, RunDispatcher returns immediately.
+func (s Webhook) RunDispatcher(ctx context.Context, interval time.Duration, report func(delivered, failed int, err error)) {
+	if len(s.targets) == 0 || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		delivered, failed, err := s.DeliverPending(ctx)
+		if report != nil && (delivered > 0 || failed > 0 || err != nil) {
+			report(delivered, failed, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
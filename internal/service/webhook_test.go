@@ -0,0 +1,322 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage/inmemory"
+
+	"github.com/matryer/is"
+)
+
+// webhookReceiver is a local webhook endpoint which records the requests it receives, and responds with status.
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   []string
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	t.Helper()
+
+	wr := &webhookReceiver{status: http.StatusNoContent}
+	wr.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		wr.mu.Lock()
+		defer wr.mu.Unlock()
+		wr.requests = append(wr.requests, r)
+		wr.bodies = append(wr.bodies, string(body))
+		w.WriteHeader(wr.status)
+	}))
+	t.Cleanup(wr.Close)
+
+	return wr
+}
+
+func (wr *webhookReceiver) setStatus(status int) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	wr.status = status
+}
+
+func (wr *webhookReceiver) received() ([]*http.Request, []string) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	return append([]*http.Request(nil), wr.requests...), append([]string(nil), wr.bodies...)
+}
+
+func TestNewWebhookService(t *testing.T) {
+	repo := inmemory.NewWebhookDeliveryRepository()
+
+	tests := []struct {
+		name    string
+		targets []service.WebhookTarget
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			targets: []service.WebhookTarget{{Name: "a", URL: "https://example.com"}, {Name: "b", URL: "http://example.com", Format: service.WebhookSlack}},
+		},
+		{
+			name:    "blank name",
+			targets: []service.WebhookTarget{{URL: "https://example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate name",
+			targets: []service.WebhookTarget{{Name: "a", URL: "https://example.com"}, {Name: "a", URL: "https://example.org"}},
+			wantErr: true,
+		},
+		{
+			name:    "relative URL",
+			targets: []service.WebhookTarget{{Name: "a", URL: "/hook"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			targets: []service.WebhookTarget{{Name: "a", URL: "https://example.com", Format: "teams"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid template",
+			targets: []service.WebhookTarget{{Name: "a", URL: "https://example.com", Template: "{{.Quote"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.NewWebhookService(repo, tt.targets, "https://epigram.example.com/quotes")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewWebhookService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhook_DeliverPending(t *testing.T) {
+	is := is.New(t)
+
+	generic := newWebhookReceiver(t)
+	slack := newWebhookReceiver(t)
+	discord := newWebhookReceiver(t)
+
+	webhooks, err := service.NewWebhookService(inmemory.NewWebhookDeliveryRepository(), []service.WebhookTarget{
+		{Name: "generic", URL: generic.URL, Secret: "shh"},
+		{Name: "slack", URL: slack.URL, Format: service.WebhookSlack},
+		{Name: "discord", URL: discord.URL, Format: service.WebhookDiscord, Template: "{{.Quotee}}: {{.Quote}}"},
+	}, "https://epigram.example.com/quotes")
+	is.NoErr(err)
+
+	quoteService := service.NewQuoteService(inmemory.NewQuoteRepository(), inmemory.NewReactionRepository(),
+		inmemory.NewCommentRepository(), inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), webhooks, discardLogger)
+	q := model.Quote{
+		Quotee: "Jaustin Ross",
+		Quote:  "Isn't every truck a hand truck? <@everyone>",
+	}
+	is.NoErr(quoteService.CreateQuote(userContext(submitter), &q))
+
+	requests, _ := generic.received()
+	is.Equal(len(requests), 0) // creating a quote should not deliver webhooks synchronously
+
+	delivered, failed, err := webhooks.DeliverPending(context.Background())
+	is.NoErr(err)
+	is.Equal(delivered, 3) // each webhook should be delivered
+	is.Equal(failed, 0)
+
+	requests, bodies := generic.received()
+	is.Equal(len(requests), 1) // generic webhook should receive one request
+	ts, err := strconv.ParseInt(requests[0].Header.Get(service.WebhookTimestampHeader), 10, 64)
+	is.NoErr(err) // timestamp header should be set
+	is.Equal(requests[0].Header.Get(service.WebhookSignatureHeader),
+		service.SignWebhookPayload("shh", ts, []byte(bodies[0]))) // payload should be signed
+	is.Equal(requests[0].Header.Get(service.WebhookEventHeader), service.WebhookEventQuoteCreated)
+
+	var payload struct {
+		Event string               `json:"event"`
+		Quote service.WebhookQuote `json:"quote"`
+	}
+	is.NoErr(json.Unmarshal([]byte(bodies[0]), &payload))
+	is.Equal(payload.Event, service.WebhookEventQuoteCreated)         // generic payload should include event
+	is.Equal(payload.Quote.ID, q.ID)                                  // generic payload should include quote
+	is.Equal(payload.Quote.URL, "https://epigram.example.com/quotes") // generic payload should link to quotes
+
+	requests, bodies = slack.received()
+	is.Equal(len(requests), 1)
+	is.Equal(requests[0].Header.Get(service.WebhookSignatureHeader), "") // webhook without secret should not be signed
+	var slackPayload struct {
+		Text string `json:"text"`
+	}
+	is.NoErr(json.Unmarshal([]byte(bodies[0]), &slackPayload))
+	is.Equal(slackPayload.Text,
+		`New quote from Jaustin Ross: "Isn't every truck a hand truck? &lt;@everyone&gt;" https://epigram.example.com/quotes`) // slack payload should use default template, and escape the quote
+
+	_, bodies = discord.received()
+	is.Equal(len(bodies), 1)
+	var discordPayload struct {
+		Content         string              `json:"content"`
+		AllowedMentions map[string][]string `json:"allowed_mentions"`
+	}
+	is.NoErr(json.Unmarshal([]byte(bodies[0]), &discordPayload))
+	is.Equal(discordPayload.Content, "Jaustin Ross: Isn't every truck a hand truck? <@everyone>") // discord payload should use custom template
+	is.Equal(discordPayload.AllowedMentions, map[string][]string{"parse": {}})                    // discord payload should not mention anyone
+
+	delivered, failed, err = webhooks.DeliverPending(context.Background())
+	is.NoErr(err)
+	is.Equal(delivered+failed, 0) // delivered webhooks should not be sent again
+}
+
+func TestWebhook_Retry(t *testing.T) {
+	is := is.New(t)
+
+	receiver := newWebhookReceiver(t)
+	receiver.setStatus(http.StatusInternalServerError)
+
+	repo := inmemory.NewWebhookDeliveryRepository()
+	webhooks, err := service.NewWebhookService(repo, []service.WebhookTarget{
+		{Name: "flaky", URL: receiver.URL},
+	}, "https://epigram.example.com/quotes")
+	is.NoErr(err)
+
+	is.NoErr(webhooks.QuoteCreated(context.Background(), model.Quote{ID: "q1", CommunityID: model.DefaultCommunityID, Quotee: "Charlene", Quote: "Hello"}))
+
+	delivered, failed, err := webhooks.DeliverPending(context.Background())
+	is.True(err != nil) // failed delivery should be reported
+	is.Equal(delivered, 0)
+	is.Equal(failed, 1)
+
+	pending, err := repo.FindDue(context.Background(), time.Now().Add(time.Hour), 0)
+	is.NoErr(err)
+	is.Equal(len(pending), 1)                         // failed delivery should remain queued
+	is.Equal(pending[0].Attempts, 1)                  // failed attempt should be counted
+	is.True(pending[0].NextAttempt.After(time.Now())) // retry should be delayed
+	is.True(pending[0].LastError != "")               // failure reason should be recorded
+
+	delivered, failed, err = webhooks.DeliverPending(context.Background())
+	is.NoErr(err)
+	is.Equal(delivered+failed, 0) // delivery should not be retried before its next attempt
+
+	receiver.setStatus(http.StatusOK)
+	pending[0].NextAttempt = time.Now().Add(-time.Second)
+	is.NoErr(repo.Update(context.Background(), pending[0]))
+
+	delivered, failed, err = webhooks.DeliverPending(context.Background())
+	is.NoErr(err)
+	is.Equal(delivered, 1) // delivery should succeed once the receiver recovers
+	is.Equal(failed, 0)
+
+	requests, bodies := receiver.received()
+	is.Equal(len(requests), 2)                                                                                             // receiver should have been sent the delivery twice
+	is.Equal(requests[0].Header.Get(service.WebhookDeliveryHeader), requests[1].Header.Get(service.WebhookDeliveryHeader)) // retry should reuse the delivery ID
+	is.Equal(bodies[0], bodies[1])                                                                                         // retry should resend the same payload
+}
+
+func TestWebhook_RunDispatcher(t *testing.T) {
+	is := is.New(t)
+
+	receiver := newWebhookReceiver(t)
+	webhooks, err := service.NewWebhookService(inmemory.NewWebhookDeliveryRepository(), []service.WebhookTarget{
+		{Name: "hook", URL: receiver.URL},
+	}, "https://epigram.example.com/quotes")
+	is.NoErr(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reports := make(chan int, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// the long interval ensures delivery is triggered by the new quote, rather than the ticker
+		webhooks.RunDispatcher(ctx, time.Hour, func(delivered, failed int, err error) {
+			if err != nil {
+				t.Errorf("dispatcher reported error: %v", err)
+			}
+			reports <- delivered
+		})
+	}()
+
+	is.NoErr(webhooks.QuoteCreated(context.Background(), model.Quote{ID: "q1", CommunityID: model.DefaultCommunityID, Quotee: "Charlene", Quote: "Hello"}))
+
+	select {
+	case delivered := <-reports:
+		is.Equal(delivered, 1) // dispatcher should deliver the new quote
+	case <-time.After(5 * time.Second):
+		t.Fatal("dispatcher did not deliver queued webhook")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestWebhook_QuoteCreated_Community(t *testing.T) {
+	is := is.New(t)
+
+	defaultReceiver := newWebhookReceiver(t)
+	otherReceiver := newWebhookReceiver(t)
+
+	webhooks, err := service.NewWebhookService(inmemory.NewWebhookDeliveryRepository(), []service.WebhookTarget{
+		{Name: "default", URL: defaultReceiver.URL},
+		{Name: "other", URL: otherReceiver.URL, Community: "other"},
+	}, "https://epigram.example.com/quotes")
+	is.NoErr(err)
+
+	is.NoErr(webhooks.QuoteCreated(context.Background(), model.Quote{ID: "q1", CommunityID: model.DefaultCommunityID, Quotee: "Charlene", Quote: "Hello"}))
+
+	delivered, failed, err := webhooks.DeliverPending(context.Background())
+	is.NoErr(err)
+	is.Equal(delivered, 1) // quote should only be delivered to targets of its community
+	is.Equal(failed, 0)
+
+	requests, _ := defaultReceiver.received()
+	is.Equal(len(requests), 1) // targets without a community should receive quotes of the default community
+	requests, _ = otherReceiver.received()
+	is.Equal(len(requests), 0) // targets of other communities should not receive the quote
+}
+
+// failingDeliveryRepository is a WebhookDeliveryRepository which is unable to store deliveries.
+type failingDeliveryRepository struct {
+	service.WebhookDeliveryRepository
+}
+
+func (failingDeliveryRepository) Create(ctx context.Context, d model.WebhookDelivery) error {
+	return errors.New("database is locked")
+}
+
+func TestQuote_CreateQuote_WebhookFailure(t *testing.T) {
+	is := is.New(t)
+
+	webhooks, err := service.NewWebhookService(failingDeliveryRepository{}, []service.WebhookTarget{
+		{Name: "generic", URL: "https://hooks.example.com"},
+	}, "https://epigram.example.com/quotes")
+	is.NoErr(err)
+
+	var logs bytes.Buffer
+	repo := inmemory.NewQuoteRepository()
+	quoteService := service.NewQuoteService(repo, inmemory.NewReactionRepository(), inmemory.NewCommentRepository(),
+		inmemory.NewPersonRepository(), inmemory.NewTransactor(), time.Hour,
+		service.NewAuditLogService(inmemory.NewAuditLogRepository()), webhooks,
+		slog.New(slog.NewTextHandler(&logs, nil)))
+
+	q := model.Quote{Quotee: "Jaustin Ross", Quote: "Isn't every truck a hand truck?"}
+	is.NoErr(quoteService.CreateQuote(userContext(submitter), &q)) // failing to queue webhooks should not fail creation
+
+	_, err = repo.FindByID(context.Background(), q.ID)
+	is.NoErr(err)                                                        // quote should be saved
+	is.True(strings.Contains(logs.String(), "unable to queue webhooks")) // failure to queue webhooks should be logged
+}
@@ -7,4 +7,7 @@ var (
 	ErrNotFound = errors.New("not found")
 	// ErrAlreadyExists is returned when a requested item already exists
 	ErrAlreadyExists = errors.New("already exists")
+	// ErrUnavailable is returned when a requested item exists, but can no longer be used, such as an invite which
+	// has expired or been used up
+	ErrUnavailable = errors.New("unavailable")
 )
@@ -0,0 +1,132 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// AccessRequestRepository is an in-memory implementation of the service.AccessRequestRepository interface.
+type AccessRequestRepository struct {
+	mu sync.RWMutex
+	m  map[membershipKey]model.AccessRequest
+}
+
+// NewAccessRequestRepository returns a new AccessRequestRepository which stores AccessRequests in memory.
+func NewAccessRequestRepository() service.AccessRequestRepository {
+	return &AccessRequestRepository{
+		m: make(map[membershipKey]model.AccessRequest, 0),
+	}
+}
+
+// Create adds a new AccessRequest to the repository.
+func (r *AccessRequestRepository) Create(ctx context.Context, req model.AccessRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := membershipKey{req.CommunityID, req.UserID}
+	if _, ok := r.m[k]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	r.m[k] = req
+	return nil
+}
+
+// Update updates an existing AccessRequest in the repository.
+func (r *AccessRequestRepository) Update(ctx context.Context, req model.AccessRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := membershipKey{req.CommunityID, req.UserID}
+	if _, ok := r.m[k]; !ok {
+		return storage.ErrNotFound
+	}
+
+	r.m[k] = req
+	return nil
+}
+
+// Delete removes the AccessRequest of the specified user to the specified community.
+func (r *AccessRequestRepository) Delete(ctx context.Context, communityID string, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := membershipKey{communityID, userID}
+	if _, ok := r.m[k]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, k)
+	return nil
+}
+
+// Find returns the AccessRequest of the specified user to the specified community.
+func (r *AccessRequestRepository) Find(ctx context.Context, communityID string, userID string) (model.AccessRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	req, ok := r.m[membershipKey{communityID, userID}]
+	if !ok {
+		return model.AccessRequest{}, storage.ErrNotFound
+	}
+
+	return req, nil
+}
+
+// FindByCommunityID returns the AccessRequests to the specified community with the specified status, from oldest to
+// newest.
+func (r *AccessRequestRepository) FindByCommunityID(ctx context.Context, communityID string, status model.AccessRequestStatus) ([]model.AccessRequest, error) {
+	v := make([]model.AccessRequest, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, req := range r.m {
+		if req.CommunityID == communityID && req.Status == status {
+			v = append(v, req)
+		}
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].Created.Equal(v[j].Created) {
+			return v[i].UserID < v[j].UserID
+		}
+		return v[i].Created.Before(v[j].Created)
+	})
+
+	return v, nil
+}
+
+// ReassignUser changes the UserID of every AccessRequest made by the user fromID, and the ResolverID of every
+// AccessRequest resolved by them, to toID. Requests to communities which toID has already requested access to are
+// removed instead.
+func (r *AccessRequestRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, req := range r.m {
+		if req.ResolverID == fromID {
+			req.ResolverID = toID
+			r.m[k] = req
+		}
+	}
+
+	for k, req := range r.m {
+		if req.UserID != fromID {
+			continue
+		}
+
+		delete(r.m, k)
+		req.UserID = toID
+		if _, ok := r.m[membershipKey{req.CommunityID, toID}]; !ok {
+			r.m[membershipKey{req.CommunityID, toID}] = req
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// APITokenRepository is an in-memory implementation of the service.APITokenRepository interface.
+type APITokenRepository struct {
+	mu sync.RWMutex
+	m  map[string]model.APIToken
+}
+
+// NewAPITokenRepository returns a new APITokenRepository which stores APITokens in memory.
+func NewAPITokenRepository() service.APITokenRepository {
+	return &APITokenRepository{
+		m: make(map[string]model.APIToken, 0),
+	}
+}
+
+// Create adds a new APIToken to the repository.
+func (r *APITokenRepository) Create(ctx context.Context, t model.APIToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[t.ID]; ok {
+		return storage.ErrAlreadyExists
+	}
+	for _, existing := range r.m {
+		if existing.Hash == t.Hash {
+			return storage.ErrAlreadyExists
+		}
+	}
+
+	r.m[t.ID] = t
+	return nil
+}
+
+// Delete removes the APIToken with the provided ID.
+func (r *APITokenRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, id)
+	return nil
+}
+
+// FindByID returns the APIToken with the provided ID.
+func (r *APITokenRepository) FindByID(ctx context.Context, id string) (model.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.m[id]
+	if !ok {
+		return model.APIToken{}, storage.ErrNotFound
+	}
+
+	return t, nil
+}
+
+// FindByHash returns the APIToken with the provided hash.
+func (r *APITokenRepository) FindByHash(ctx context.Context, hash string) (model.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.m {
+		if t.Hash == hash {
+			return t, nil
+		}
+	}
+
+	return model.APIToken{}, storage.ErrNotFound
+}
+
+// FindByUserID returns all APITokens belonging to the user with the provided ID, from newest to oldest.
+func (r *APITokenRepository) FindByUserID(ctx context.Context, userID string) ([]model.APIToken, error) {
+	v := make([]model.APIToken, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.m {
+		if t.UserID == userID {
+			v = append(v, t)
+		}
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].Created.Equal(v[j].Created) {
+			return v[i].ID > v[j].ID
+		}
+		return v[i].Created.After(v[j].Created)
+	})
+
+	return v, nil
+}
+
+// ReassignUser changes the UserID of every APIToken belonging to the user fromID to toID.
+func (r *APITokenRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, t := range r.m {
+		if t.UserID == fromID {
+			t.UserID = toID
+			r.m[id] = t
+		}
+	}
+
+	return nil
+}
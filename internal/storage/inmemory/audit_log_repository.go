@@ -0,0 +1,71 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// AuditLogRepository is an in-memory implementation of the service.AuditLogRepository interface.
+type AuditLogRepository struct {
+	mu sync.RWMutex
+	m  map[string]model.AuditLogEntry
+}
+
+// NewAuditLogRepository returns a new AuditLogRepository which stores AuditLogEntries in memory.
+func NewAuditLogRepository() service.AuditLogRepository {
+	return &AuditLogRepository{
+		m: make(map[string]model.AuditLogEntry, 0),
+	}
+}
+
+// Create adds a new AuditLogEntry to the repository.
+func (r *AuditLogRepository) Create(ctx context.Context, e model.AuditLogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[e.ID]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	r.m[e.ID] = e
+	return nil
+}
+
+// Query returns the AuditLogEntries in the repository matching the provided AuditLogQuery, from newest to oldest.
+func (r *AuditLogRepository) Query(ctx context.Context, q service.AuditLogQuery) ([]model.AuditLogEntry, error) {
+	v := make([]model.AuditLogEntry, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, e := range r.m {
+		if q.ActorID != "" && e.ActorID != q.ActorID {
+			continue
+		}
+		if q.Action != "" && e.Action != q.Action {
+			continue
+		}
+		if q.TargetID != "" && e.TargetID != q.TargetID {
+			continue
+		}
+		v = append(v, e)
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].Created.Equal(v[j].Created) {
+			return v[i].ID > v[j].ID
+		}
+		return v[i].Created.After(v[j].Created)
+	})
+
+	if q.Limit > 0 && len(v) > q.Limit {
+		v = v[:q.Limit]
+	}
+
+	return v, nil
+}
@@ -0,0 +1,101 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// ChatLinkRepository is an in-memory implementation of the service.ChatLinkRepository interface.
+type ChatLinkRepository struct {
+	mu sync.RWMutex
+	m  map[string]model.ChatLink
+}
+
+// NewChatLinkRepository returns a new ChatLinkRepository which stores ChatLinks in memory.
+func NewChatLinkRepository() service.ChatLinkRepository {
+	return &ChatLinkRepository{
+		m: make(map[string]model.ChatLink, 0),
+	}
+}
+
+// Create adds a new ChatLink to the repository.
+func (r *ChatLinkRepository) Create(ctx context.Context, l model.ChatLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[l.ID]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	r.m[l.ID] = l
+	return nil
+}
+
+// Delete removes the ChatLink with the provided ID.
+func (r *ChatLinkRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, id)
+	return nil
+}
+
+// FindByID returns the ChatLink with the provided ID.
+func (r *ChatLinkRepository) FindByID(ctx context.Context, id string) (model.ChatLink, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	l, ok := r.m[id]
+	if !ok {
+		return model.ChatLink{}, storage.ErrNotFound
+	}
+
+	return l, nil
+}
+
+// FindByUserID returns all ChatLinks belonging to the user with the provided ID, from oldest to newest.
+func (r *ChatLinkRepository) FindByUserID(ctx context.Context, userID string) ([]model.ChatLink, error) {
+	v := make([]model.ChatLink, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, l := range r.m {
+		if l.UserID == userID {
+			v = append(v, l)
+		}
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].Created.Equal(v[j].Created) {
+			return v[i].ID < v[j].ID
+		}
+		return v[i].Created.Before(v[j].Created)
+	})
+
+	return v, nil
+}
+
+// ReassignUser links every ChatLink linked to the user fromID to the user toID instead.
+func (r *ChatLinkRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, l := range r.m {
+		if l.UserID == fromID {
+			l.UserID = toID
+			r.m[id] = l
+		}
+	}
+
+	return nil
+}
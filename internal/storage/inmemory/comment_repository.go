@@ -0,0 +1,127 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// CommentRepository is an in-memory implementation of the service.CommentRepository interface.
+type CommentRepository struct {
+	mu sync.RWMutex
+	m  map[string]model.Comment
+}
+
+// NewCommentRepository returns a new CommentRepository which stores Comments in memory.
+func NewCommentRepository() service.CommentRepository {
+	return &CommentRepository{
+		m: make(map[string]model.Comment, 0),
+	}
+}
+
+// Create adds a new Comment to the repository.
+func (r *CommentRepository) Create(ctx context.Context, c model.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[c.ID]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	r.m[c.ID] = c
+	return nil
+}
+
+// Update replaces the Comment with the same ID as c.
+func (r *CommentRepository) Update(ctx context.Context, c model.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[c.ID]; !ok {
+		return storage.ErrNotFound
+	}
+
+	r.m[c.ID] = c
+	return nil
+}
+
+// Delete removes the Comment with the provided ID.
+func (r *CommentRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, id)
+	return nil
+}
+
+// DeleteByQuoteID removes all Comments on the Quote with the provided ID.
+func (r *CommentRepository) DeleteByQuoteID(ctx context.Context, quoteID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, c := range r.m {
+		if c.QuoteID == quoteID {
+			delete(r.m, id)
+		}
+	}
+	return nil
+}
+
+// FindByID returns the Comment with the provided ID.
+func (r *CommentRepository) FindByID(ctx context.Context, id string) (model.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.m[id]
+	if !ok {
+		return model.Comment{}, storage.ErrNotFound
+	}
+
+	return c, nil
+}
+
+// FindByQuoteID returns all Comments on the Quote with the provided ID, from oldest to newest.
+func (r *CommentRepository) FindByQuoteID(ctx context.Context, quoteID string) ([]model.Comment, error) {
+	v := make([]model.Comment, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.m {
+		if c.QuoteID == quoteID {
+			v = append(v, c)
+		}
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].Created.Equal(v[j].Created) {
+			return v[i].ID < v[j].ID
+		}
+		return v[i].Created.Before(v[j].Created)
+	})
+
+	return v, nil
+}
+
+// ReassignUser changes the AuthorID of every Comment written by the user fromID to toID.
+func (r *CommentRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, c := range r.m {
+		if c.AuthorID == fromID {
+			c.AuthorID = toID
+			r.m[id] = c
+		}
+	}
+
+	return nil
+}
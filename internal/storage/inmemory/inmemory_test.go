@@ -24,3 +24,87 @@ func TestUserSessionRepository(t *testing.T) {
 		return NewUserSessionRepository(), func() {}
 	})
 }
+
+func TestAuditLogRepository(t *testing.T) {
+	validate.AuditLogRepository(t, func() (repo service.AuditLogRepository, closer func()) {
+		return NewAuditLogRepository(), func() {}
+	})
+}
+
+func TestUserIdentityRepository(t *testing.T) {
+	validate.UserIdentityRepository(t, func() (repo service.UserIdentityRepository, closer func()) {
+		return NewUserIdentityRepository(), func() {}
+	})
+}
+
+func TestAPITokenRepository(t *testing.T) {
+	validate.APITokenRepository(t, func() (repo service.APITokenRepository, closer func()) {
+		return NewAPITokenRepository(), func() {}
+	})
+}
+
+func TestWebhookDeliveryRepository(t *testing.T) {
+	validate.WebhookDeliveryRepository(t, func() (repo service.WebhookDeliveryRepository, closer func()) {
+		return NewWebhookDeliveryRepository(), func() {}
+	})
+}
+
+func TestChatLinkRepository(t *testing.T) {
+	validate.ChatLinkRepository(t, func() (repo service.ChatLinkRepository, closer func()) {
+		return NewChatLinkRepository(), func() {}
+	})
+}
+
+func TestReactionRepository(t *testing.T) {
+	validate.ReactionRepository(t, func() (repo service.ReactionRepository, closer func()) {
+		return NewReactionRepository(), func() {}
+	})
+}
+
+func TestCommentRepository(t *testing.T) {
+	validate.CommentRepository(t, func() (repo service.CommentRepository, closer func()) {
+		return NewCommentRepository(), func() {}
+	})
+}
+
+func TestPersonRepository(t *testing.T) {
+	validate.PersonRepository(t, func() (repo service.PersonRepository, closer func()) {
+		return NewPersonRepository(), func() {}
+	})
+}
+
+func TestMembershipRepository(t *testing.T) {
+	validate.MembershipRepository(t, func() (repo service.MembershipRepository, closer func()) {
+		return NewMembershipRepository(), func() {}
+	})
+}
+
+func TestInviteRepository(t *testing.T) {
+	validate.InviteRepository(t, func() (repo service.InviteRepository, closer func()) {
+		return NewInviteRepository(), func() {}
+	})
+}
+
+func TestQuizSessionRepository(t *testing.T) {
+	validate.QuizSessionRepository(t, func() (repo service.QuizSessionRepository, closer func()) {
+		return NewQuizSessionRepository(), func() {}
+	})
+}
+
+func TestAccessRequestRepository(t *testing.T) {
+	validate.AccessRequestRepository(t, func() (repo service.AccessRequestRepository, closer func()) {
+		return NewAccessRequestRepository(), func() {}
+	})
+}
+
+func TestQuizAttemptRepository(t *testing.T) {
+	validate.QuizAttemptRepository(t, func() (repo service.QuizAttemptRepository, closer func()) {
+		return NewQuizAttemptRepository(), func() {}
+	})
+}
+
+func TestQuizQuestionRepository(t *testing.T) {
+	validate.QuizQuestionRepository(t, func() (repo service.QuizQuestionRepository, closer func()) {
+		return NewQuizQuestionRepository(), func() {}
+	})
+}
@@ -0,0 +1,171 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// InviteRepository is an in-memory implementation of the service.InviteRepository interface.
+type InviteRepository struct {
+	mu sync.RWMutex
+	m  map[string]model.Invite
+}
+
+// NewInviteRepository returns a new InviteRepository which stores Invites in memory.
+func NewInviteRepository() service.InviteRepository {
+	return &InviteRepository{
+		m: make(map[string]model.Invite, 0),
+	}
+}
+
+// copyInvite returns a copy of the provided invite which does not share its Redemptions.
+func copyInvite(i model.Invite) model.Invite {
+	i.Redemptions = append([]model.InviteRedemption{}, i.Redemptions...)
+	return i
+}
+
+// Create adds a new Invite to the repository, ignoring its Redemptions.
+func (r *InviteRepository) Create(ctx context.Context, i model.Invite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[i.ID]; ok {
+		return storage.ErrAlreadyExists
+	}
+	for _, existing := range r.m {
+		if existing.Code == i.Code {
+			return storage.ErrAlreadyExists
+		}
+	}
+
+	i.Redemptions = []model.InviteRedemption{}
+	r.m[i.ID] = i
+	return nil
+}
+
+// Delete removes the Invite with the provided ID, along with its Redemptions.
+func (r *InviteRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, id)
+	return nil
+}
+
+// FindByID returns the Invite with the provided ID.
+func (r *InviteRepository) FindByID(ctx context.Context, id string) (model.Invite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, ok := r.m[id]
+	if !ok {
+		return model.Invite{}, storage.ErrNotFound
+	}
+
+	return copyInvite(i), nil
+}
+
+// FindByCode returns the Invite with the provided code.
+func (r *InviteRepository) FindByCode(ctx context.Context, code string) (model.Invite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, i := range r.m {
+		if i.Code == code {
+			return copyInvite(i), nil
+		}
+	}
+
+	return model.Invite{}, storage.ErrNotFound
+}
+
+// FindByCommunityID returns the Invites of the specified community, from newest to oldest.
+func (r *InviteRepository) FindByCommunityID(ctx context.Context, communityID string) ([]model.Invite, error) {
+	v := make([]model.Invite, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, i := range r.m {
+		if i.CommunityID == communityID {
+			v = append(v, copyInvite(i))
+		}
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].Created.Equal(v[j].Created) {
+			return v[i].ID > v[j].ID
+		}
+		return v[i].Created.After(v[j].Created)
+	})
+
+	return v, nil
+}
+
+// AddRedemption records that an Invite was redeemed, provided that it had not expired or been used up at the time.
+func (r *InviteRepository) AddRedemption(ctx context.Context, redemption model.InviteRedemption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.m[redemption.InviteID]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	for _, existing := range i.Redemptions {
+		if existing.UserID == redemption.UserID {
+			return storage.ErrAlreadyExists
+		}
+	}
+	if !i.IsRedeemable(redemption.Redeemed) {
+		return storage.ErrUnavailable
+	}
+
+	i.Redemptions = append(i.Redemptions, redemption)
+	sort.SliceStable(i.Redemptions, func(a, b int) bool {
+		return i.Redemptions[a].Redeemed.Before(i.Redemptions[b].Redeemed)
+	})
+	r.m[i.ID] = i
+	return nil
+}
+
+// ReassignUser changes the CreatorID of every Invite created by the user fromID, and the UserID of every redemption
+// made by them, to toID. Redemptions of invites which toID has already redeemed are removed instead.
+func (r *InviteRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, i := range r.m {
+		if i.CreatorID == fromID {
+			i.CreatorID = toID
+		}
+
+		redemptions := make([]model.InviteRedemption, 0, len(i.Redemptions))
+		redeemed := slices.ContainsFunc(i.Redemptions, func(ir model.InviteRedemption) bool {
+			return ir.UserID == toID
+		})
+		for _, ir := range i.Redemptions {
+			if ir.UserID == fromID {
+				if redeemed {
+					continue
+				}
+				ir.UserID = toID
+			}
+			redemptions = append(redemptions, ir)
+		}
+		i.Redemptions = redemptions
+
+		r.m[id] = i
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// membershipKey identifies a Membership by the community and user it belongs to.
+type membershipKey struct {
+	communityID string
+	userID      string
+}
+
+// MembershipRepository is an in-memory implementation of the service.MembershipRepository interface.
+type MembershipRepository struct {
+	mu sync.RWMutex
+	m  map[membershipKey]model.Membership
+}
+
+// NewMembershipRepository returns a new MembershipRepository which stores Memberships in memory.
+func NewMembershipRepository() service.MembershipRepository {
+	return &MembershipRepository{
+		m: make(map[membershipKey]model.Membership, 0),
+	}
+}
+
+// Create adds a new Membership to the repository.
+func (r *MembershipRepository) Create(ctx context.Context, m model.Membership) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := membershipKey{m.CommunityID, m.UserID}
+	if _, ok := r.m[k]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	r.m[k] = m
+	return nil
+}
+
+// Update updates an existing Membership in the repository.
+func (r *MembershipRepository) Update(ctx context.Context, m model.Membership) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := membershipKey{m.CommunityID, m.UserID}
+	if _, ok := r.m[k]; !ok {
+		return storage.ErrNotFound
+	}
+
+	r.m[k] = m
+	return nil
+}
+
+// Delete removes the Membership of the specified user in the specified community.
+func (r *MembershipRepository) Delete(ctx context.Context, communityID string, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := membershipKey{communityID, userID}
+	if _, ok := r.m[k]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, k)
+	return nil
+}
+
+// Find returns the Membership of the specified user in the specified community.
+func (r *MembershipRepository) Find(ctx context.Context, communityID string, userID string) (model.Membership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.m[membershipKey{communityID, userID}]
+	if !ok {
+		return model.Membership{}, storage.ErrNotFound
+	}
+
+	return m, nil
+}
+
+// FindByUserID returns the Memberships of the specified user.
+func (r *MembershipRepository) FindByUserID(ctx context.Context, userID string) ([]model.Membership, error) {
+	v := make([]model.Membership, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, m := range r.m {
+		if m.UserID == userID {
+			v = append(v, m)
+		}
+	}
+
+	return v, nil
+}
+
+// FindByCommunityID returns the Memberships of the specified community.
+func (r *MembershipRepository) FindByCommunityID(ctx context.Context, communityID string) ([]model.Membership, error) {
+	v := make([]model.Membership, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, m := range r.m {
+		if m.CommunityID == communityID {
+			v = append(v, m)
+		}
+	}
+
+	return v, nil
+}
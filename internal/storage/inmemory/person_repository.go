@@ -0,0 +1,116 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// PersonRepository is an in-memory implementation of the service.PersonRepository interface.
+type PersonRepository struct {
+	mu sync.RWMutex
+	m  map[string]model.Person
+}
+
+// NewPersonRepository returns a new PersonRepository which stores People in memory.
+func NewPersonRepository() service.PersonRepository {
+	return &PersonRepository{
+		m: make(map[string]model.Person, 0),
+	}
+}
+
+// Create adds a new Person to the repository.
+func (r *PersonRepository) Create(ctx context.Context, p model.Person) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[p.ID]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	r.m[p.ID] = p
+	return nil
+}
+
+// Update replaces the Person with the same ID as p.
+func (r *PersonRepository) Update(ctx context.Context, p model.Person) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[p.ID]; !ok {
+		return storage.ErrNotFound
+	}
+
+	r.m[p.ID] = p
+	return nil
+}
+
+// Delete removes the Person with the provided ID.
+func (r *PersonRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, id)
+	return nil
+}
+
+// FindByID returns the Person with the provided ID.
+func (r *PersonRepository) FindByID(ctx context.Context, id string) (model.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.m[id]
+	if !ok {
+		return model.Person{}, storage.ErrNotFound
+	}
+
+	return p, nil
+}
+
+// FindByCommunityID returns all People of the specified community, ordered by name regardless of capitalization.
+func (r *PersonRepository) FindByCommunityID(ctx context.Context, communityID string) ([]model.Person, error) {
+	v := make([]model.Person, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range r.m {
+		if p.CommunityID == communityID {
+			v = append(v, p)
+		}
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		a, b := strings.ToLower(v[i].Name), strings.ToLower(v[j].Name)
+		if a == b {
+			return v[i].ID < v[j].ID
+		}
+		return a < b
+	})
+
+	return v, nil
+}
+
+// ReassignUser links every Person linked to the user fromID to the user toID instead.
+func (r *PersonRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, p := range r.m {
+		if p.UserID == fromID {
+			p.UserID = toID
+			r.m[id] = p
+		}
+	}
+
+	return nil
+}
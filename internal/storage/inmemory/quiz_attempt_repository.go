@@ -0,0 +1,90 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// QuizAttemptRepository is an in-memory implementation of the service.QuizAttemptRepository interface.
+type QuizAttemptRepository struct {
+	mu sync.RWMutex
+	m  map[string]model.QuizAttempt
+}
+
+// NewQuizAttemptRepository returns a new QuizAttemptRepository which stores QuizAttempts in memory.
+func NewQuizAttemptRepository() service.QuizAttemptRepository {
+	return &QuizAttemptRepository{
+		m: make(map[string]model.QuizAttempt, 0),
+	}
+}
+
+// Create adds a new QuizAttempt to the repository.
+func (r *QuizAttemptRepository) Create(ctx context.Context, a model.QuizAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[a.ID]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	if a.QuestionIDs != nil {
+		a.QuestionIDs = append([]int{}, a.QuestionIDs...)
+	}
+	if a.WrongQuestionIDs != nil {
+		a.WrongQuestionIDs = append([]int{}, a.WrongQuestionIDs...)
+	}
+	if a.Answers != nil {
+		answers := make(map[int]string, len(a.Answers))
+		for id, answer := range a.Answers {
+			answers[id] = answer
+		}
+		a.Answers = answers
+	}
+
+	r.m[a.ID] = a
+	return nil
+}
+
+// FindByUserID returns the QuizAttempts of the specified user at the entry quiz of the specified community, from
+// newest to oldest.
+func (r *QuizAttemptRepository) FindByUserID(ctx context.Context, communityID string, userID string) ([]model.QuizAttempt, error) {
+	v := make([]model.QuizAttempt, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, a := range r.m {
+		if a.CommunityID == communityID && a.UserID == userID {
+			v = append(v, a)
+		}
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].Submitted.Equal(v[j].Submitted) {
+			return v[i].ID > v[j].ID
+		}
+		return v[i].Submitted.After(v[j].Submitted)
+	})
+
+	return v, nil
+}
+
+// ReassignUser changes the UserID of every QuizAttempt made by the user fromID to toID.
+func (r *QuizAttemptRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, a := range r.m {
+		if a.UserID == fromID {
+			a.UserID = toID
+			r.m[id] = a
+		}
+	}
+
+	return nil
+}
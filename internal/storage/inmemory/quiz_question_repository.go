@@ -0,0 +1,105 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// quizQuestionKey identifies a QuizQuestion by its community and ID.
+type quizQuestionKey struct {
+	communityID string
+	id          int
+}
+
+// QuizQuestionRepository is an in-memory implementation of the service.QuizQuestionRepository interface.
+type QuizQuestionRepository struct {
+	mu sync.RWMutex
+	m  map[quizQuestionKey]model.QuizQuestion
+}
+
+// NewQuizQuestionRepository returns a new QuizQuestionRepository which stores QuizQuestions in memory.
+func NewQuizQuestionRepository() service.QuizQuestionRepository {
+	return &QuizQuestionRepository{
+		m: make(map[quizQuestionKey]model.QuizQuestion, 0),
+	}
+}
+
+// copyQuizQuestion returns a copy of the provided question which shares no memory with it.
+func copyQuizQuestion(q model.QuizQuestion) model.QuizQuestion {
+	if q.Answers != nil {
+		q.Answers = append([]string{}, q.Answers...)
+	}
+	if q.Normalize != nil {
+		q.Normalize = append([]string{}, q.Normalize...)
+	}
+	return q
+}
+
+// Create adds a new QuizQuestion to the repository.
+func (r *QuizQuestionRepository) Create(ctx context.Context, q model.QuizQuestion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := quizQuestionKey{q.CommunityID, q.ID}
+	if _, ok := r.m[k]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	r.m[k] = copyQuizQuestion(q)
+	return nil
+}
+
+// Update updates an existing QuizQuestion in the repository.
+func (r *QuizQuestionRepository) Update(ctx context.Context, q model.QuizQuestion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := quizQuestionKey{q.CommunityID, q.ID}
+	if _, ok := r.m[k]; !ok {
+		return storage.ErrNotFound
+	}
+
+	r.m[k] = copyQuizQuestion(q)
+	return nil
+}
+
+// Find returns the QuizQuestion of the specified community with the specified ID.
+func (r *QuizQuestionRepository) Find(ctx context.Context, communityID string, id int) (model.QuizQuestion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	q, ok := r.m[quizQuestionKey{communityID, id}]
+	if !ok {
+		return model.QuizQuestion{}, storage.ErrNotFound
+	}
+
+	return copyQuizQuestion(q), nil
+}
+
+// FindByCommunityID returns the QuizQuestions of the specified community, ordered by their position.
+func (r *QuizQuestionRepository) FindByCommunityID(ctx context.Context, communityID string) ([]model.QuizQuestion, error) {
+	v := make([]model.QuizQuestion, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, q := range r.m {
+		if q.CommunityID == communityID {
+			v = append(v, copyQuizQuestion(q))
+		}
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].Position == v[j].Position {
+			return v[i].ID < v[j].ID
+		}
+		return v[i].Position < v[j].Position
+	})
+
+	return v, nil
+}
@@ -0,0 +1,87 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// QuizSessionRepository is an in-memory implementation of the service.QuizSessionRepository interface.
+type QuizSessionRepository struct {
+	mu sync.RWMutex
+	m  map[membershipKey]model.QuizSession
+}
+
+// NewQuizSessionRepository returns a new QuizSessionRepository which stores QuizSessions in memory.
+func NewQuizSessionRepository() service.QuizSessionRepository {
+	return &QuizSessionRepository{
+		m: make(map[membershipKey]model.QuizSession, 0),
+	}
+}
+
+// Create adds a new QuizSession to the repository.
+func (r *QuizSessionRepository) Create(ctx context.Context, s model.QuizSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := membershipKey{s.CommunityID, s.UserID}
+	if _, ok := r.m[k]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	s.QuestionIDs = append([]int{}, s.QuestionIDs...)
+	r.m[k] = s
+	return nil
+}
+
+// Delete removes the QuizSession of the specified user in the specified community.
+func (r *QuizSessionRepository) Delete(ctx context.Context, communityID string, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := membershipKey{communityID, userID}
+	if _, ok := r.m[k]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, k)
+	return nil
+}
+
+// Find returns the QuizSession of the specified user in the specified community.
+func (r *QuizSessionRepository) Find(ctx context.Context, communityID string, userID string) (model.QuizSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.m[membershipKey{communityID, userID}]
+	if !ok {
+		return model.QuizSession{}, storage.ErrNotFound
+	}
+
+	s.QuestionIDs = append([]int{}, s.QuestionIDs...)
+	return s, nil
+}
+
+// ReassignUser changes the UserID of every QuizSession of the user fromID to toID. Sessions in communities in which
+// toID already has a QuizSession are removed instead.
+func (r *QuizSessionRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, s := range r.m {
+		if s.UserID != fromID {
+			continue
+		}
+
+		delete(r.m, k)
+		s.UserID = toID
+		if _, ok := r.m[membershipKey{s.CommunityID, toID}]; !ok {
+			r.m[membershipKey{s.CommunityID, toID}] = s
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,8 @@ package inmemory
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/willbicks/epigram/internal/model"
@@ -50,6 +52,142 @@ func (r *QuoteRepository) Update(ctx context.Context, q model.Quote) error {
 	return nil
 }
 
+// Delete removes the Quote with the provided ID from the repository.
+func (r *QuoteRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, id)
+	return nil
+}
+
+// ReassignSubmitter changes the SubmitterID of every Quote submitted by the user fromID to toID.
+func (r *QuoteRepository) ReassignSubmitter(ctx context.Context, fromID string, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, q := range r.m {
+		if q.SubmitterID == fromID {
+			q.SubmitterID = toID
+			r.m[id] = q
+		}
+	}
+
+	return nil
+}
+
+// ReassignQuotee changes the QuoteeID of every Quote attributed to the person fromID, and the SpeakerID of every
+// line they said, to toID, and the corresponding Quotee and Speaker to the provided name. The Quote of each
+// conversation they spoke in is rewritten as its ConversationText.
+func (r *QuoteRepository) ReassignQuotee(ctx context.Context, fromID string, toID string, quotee string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, q := range r.m {
+		if !q.HasSpeaker(fromID) {
+			continue
+		}
+
+		if q.QuoteeID == fromID {
+			q.QuoteeID = toID
+			q.Quotee = quotee
+		}
+
+		// lines are copied, as they may share memory with the Quote provided to Create or Update
+		lines := make([]model.QuoteLine, len(q.Lines))
+		copy(lines, q.Lines)
+		for i, l := range lines {
+			if l.SpeakerID == fromID {
+				lines[i].SpeakerID = toID
+				lines[i].Speaker = quotee
+			}
+		}
+		if len(lines) > 0 {
+			q.Lines = lines
+			q.Quote = q.ConversationText()
+		}
+
+		r.m[id] = q
+	}
+
+	return nil
+}
+
+// CountByQuoteeID returns the number of Quotes said by each person who has said at least one Quote, either as its
+// quotee or a speaker in it.
+func (r *QuoteRepository) CountByQuoteeID(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, q := range r.m {
+		speakers := map[string]bool{q.QuoteeID: true}
+		for _, l := range q.Lines {
+			speakers[l.SpeakerID] = true
+		}
+
+		for id := range speakers {
+			if id != "" {
+				counts[id]++
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// RenameTag replaces the tag from with the tag to on every Quote of the specified community tagged with it, merging
+// the tags on Quotes which are already tagged with both.
+func (r *QuoteRepository) RenameTag(ctx context.Context, communityID string, from string, to string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, q := range r.m {
+		if q.CommunityID != communityID || !q.HasTag(from) {
+			continue
+		}
+
+		// tags are copied, as they may share memory with the Quote provided to Create or Update
+		tags := []string{to}
+		for _, t := range q.Tags {
+			if t != from && t != to {
+				tags = append(tags, t)
+			}
+		}
+		sort.Strings(tags)
+
+		q.Tags = tags
+		r.m[id] = q
+	}
+
+	return nil
+}
+
+// CountByTag returns the number of Quotes of the specified community tagged with each tag which is on at least one of
+// them.
+func (r *QuoteRepository) CountByTag(ctx context.Context, communityID string) (map[string]int, error) {
+	counts := make(map[string]int)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, q := range r.m {
+		if q.CommunityID != communityID {
+			continue
+		}
+		for _, t := range q.Tags {
+			counts[t]++
+		}
+	}
+
+	return counts, nil
+}
+
 // FindByID returns a Quote with the provided ID.
 func (r *QuoteRepository) FindByID(ctx context.Context, id string) (model.Quote, error) {
 	r.mu.RLock()
@@ -63,15 +201,80 @@ func (r *QuoteRepository) FindByID(ctx context.Context, id string) (model.Quote,
 	return q, nil
 }
 
-// FindAll returns all Quotes in the repository.
-func (r *QuoteRepository) FindAll(ctx context.Context) ([]model.Quote, error) {
-	v := make([]model.Quote, 0, len(r.m))
+// Query returns the Quotes in the repository matching the provided QuoteQuery, from newest to oldest.
+func (r *QuoteRepository) Query(ctx context.Context, query service.QuoteQuery) ([]model.Quote, error) {
+	terms := storage.SearchTerms(query.Search)
+	v := make([]model.Quote, 0)
 
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	for _, value := range r.m {
-		v = append(v, value)
+
+	for _, q := range r.m {
+		if query.CommunityID != "" && q.CommunityID != query.CommunityID {
+			continue
+		}
+		if query.After != nil && !isOrderedAfter(q, *query.After) {
+			continue
+		}
+		if !query.CreatedFrom.IsZero() && q.Created.Before(query.CreatedFrom) {
+			continue
+		}
+		if !query.CreatedBefore.IsZero() && !q.Created.Before(query.CreatedBefore) {
+			continue
+		}
+		if query.Quotee != "" && !strings.EqualFold(q.Quotee, query.Quotee) {
+			continue
+		}
+		if query.QuoteeID != "" && !q.HasSpeaker(query.QuoteeID) {
+			continue
+		}
+		if query.SubmitterID != "" && q.SubmitterID != query.SubmitterID {
+			continue
+		}
+		if query.Tag != "" && !q.HasTag(query.Tag) {
+			continue
+		}
+		if !matchesTerms(terms, q.Quote, q.Quotee, q.Context) {
+			continue
+		}
+		v = append(v, q)
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		return isOrderedAfter(v[j], service.CursorOf(v[i]))
+	})
+
+	if query.Limit > 0 && len(v) > query.Limit {
+		v = v[:query.Limit]
 	}
 
 	return v, nil
 }
+
+// isOrderedAfter returns true if the Quote is positioned after the cursor when ordered from newest to oldest.
+func isOrderedAfter(q model.Quote, c service.QuoteCursor) bool {
+	if q.Created.Equal(c.Created) {
+		return q.ID < c.ID
+	}
+	return q.Created.Before(c.Created)
+}
+
+// matchesTerms returns true if every term is the prefix of at least one word in the provided fields.
+func matchesTerms(terms []string, fields ...string) bool {
+	words := storage.SearchTerms(strings.Join(fields, " "))
+
+	for _, term := range terms {
+		found := false
+		for _, w := range words {
+			if strings.HasPrefix(w, term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,129 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// reactionKey uniquely identifies a Reaction.
+type reactionKey struct {
+	quoteID string
+	userID  string
+	emoji   string
+}
+
+func keyOf(r model.Reaction) reactionKey {
+	return reactionKey{
+		quoteID: r.QuoteID,
+		userID:  r.UserID,
+		emoji:   r.Emoji,
+	}
+}
+
+// ReactionRepository is an in-memory implementation of the service.ReactionRepository interface.
+type ReactionRepository struct {
+	mu sync.RWMutex
+	m  map[reactionKey]model.Reaction
+}
+
+// NewReactionRepository returns a new ReactionRepository which stores Reactions in memory.
+func NewReactionRepository() service.ReactionRepository {
+	return &ReactionRepository{
+		m: make(map[reactionKey]model.Reaction, 0),
+	}
+}
+
+// Create adds a new Reaction to the repository.
+func (r *ReactionRepository) Create(ctx context.Context, re model.Reaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[keyOf(re)]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	r.m[keyOf(re)] = re
+	return nil
+}
+
+// Delete removes the Reaction with the same QuoteID, UserID, and Emoji as re.
+func (r *ReactionRepository) Delete(ctx context.Context, re model.Reaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[keyOf(re)]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, keyOf(re))
+	return nil
+}
+
+// DeleteByQuoteID removes all Reactions to the Quote with the provided ID.
+func (r *ReactionRepository) DeleteByQuoteID(ctx context.Context, quoteID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k := range r.m {
+		if k.quoteID == quoteID {
+			delete(r.m, k)
+		}
+	}
+	return nil
+}
+
+// FindByQuoteIDs returns all Reactions to any of the Quotes with the provided IDs.
+func (r *ReactionRepository) FindByQuoteIDs(ctx context.Context, quoteIDs []string) ([]model.Reaction, error) {
+	v := make([]model.Reaction, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, re := range r.m {
+		if slices.Contains(quoteIDs, re.QuoteID) {
+			v = append(v, re)
+		}
+	}
+
+	return v, nil
+}
+
+// CountByQuote returns the number of Reactions to each Quote which has at least one Reaction.
+func (r *ReactionRepository) CountByQuote(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for k := range r.m {
+		counts[k.quoteID]++
+	}
+
+	return counts, nil
+}
+
+// ReassignUser changes the UserID of every Reaction made by the user fromID to toID. Reactions which toID has
+// already made to the same Quote with the same emoji are removed instead.
+func (r *ReactionRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, re := range r.m {
+		if re.UserID != fromID {
+			continue
+		}
+
+		delete(r.m, k)
+		re.UserID = toID
+		if _, ok := r.m[keyOf(re)]; !ok {
+			r.m[keyOf(re)] = re
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/willbicks/epigram/internal/service"
+)
+
+// Transactor is an in-memory implementation of the service.Transactor interface.
+type Transactor struct{}
+
+// NewTransactor returns a new Transactor for repositories stored in memory.
+func NewTransactor() service.Transactor {
+	return Transactor{}
+}
+
+// InTransaction calls fn with the provided context. Changes made by fn before it fails are not rolled back, as
+// in-memory repositories are not transactional.
+func (Transactor) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
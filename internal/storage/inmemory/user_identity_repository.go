@@ -0,0 +1,88 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// UserIdentityRepository is an in-memory implementation of the service.UserIdentityRepository interface.
+type UserIdentityRepository struct {
+	mu sync.RWMutex
+	m  map[string]model.UserIdentity
+}
+
+// NewUserIdentityRepository returns a new UserIdentityRepository which stores UserIdentities in memory.
+func NewUserIdentityRepository() service.UserIdentityRepository {
+	return &UserIdentityRepository{
+		m: make(map[string]model.UserIdentity, 0),
+	}
+}
+
+// Create adds a new UserIdentity to the repository.
+func (r *UserIdentityRepository) Create(ctx context.Context, ui model.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[ui.ID]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	r.m[ui.ID] = ui
+	return nil
+}
+
+// FindByID returns the UserIdentity with the provided ID.
+func (r *UserIdentityRepository) FindByID(ctx context.Context, id string) (model.UserIdentity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ui, ok := r.m[id]
+	if !ok {
+		return model.UserIdentity{}, storage.ErrNotFound
+	}
+
+	return ui, nil
+}
+
+// FindByUserID returns all UserIdentities linked to the user with the provided ID, from oldest to newest.
+func (r *UserIdentityRepository) FindByUserID(ctx context.Context, userID string) ([]model.UserIdentity, error) {
+	v := make([]model.UserIdentity, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, ui := range r.m {
+		if ui.UserID == userID {
+			v = append(v, ui)
+		}
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].Created.Equal(v[j].Created) {
+			return v[i].ID < v[j].ID
+		}
+		return v[i].Created.Before(v[j].Created)
+	})
+
+	return v, nil
+}
+
+// ReassignUser links every UserIdentity linked to the user fromID to the user toID instead.
+func (r *UserIdentityRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, ui := range r.m {
+		if ui.UserID == fromID {
+			ui.UserID = toID
+			r.m[id] = ui
+		}
+	}
+
+	return nil
+}
@@ -50,6 +50,19 @@ func (r *UserRepository) Update(ctx context.Context, u model.User) error {
 	return nil
 }
 
+// Delete removes the User with the provided ID from the repository.
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, id)
+	return nil
+}
+
 // FindByID returns a User with the provided ID.
 func (r *UserRepository) FindByID(ctx context.Context, id string) (model.User, error) {
 	r.mu.RLock()
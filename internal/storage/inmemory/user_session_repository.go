@@ -2,7 +2,9 @@ package inmemory
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/willbicks/epigram/internal/model"
 	"github.com/willbicks/epigram/internal/service"
@@ -35,6 +37,19 @@ func (r *UserSessionRepository) Create(ctx context.Context, us model.UserSession
 	return nil
 }
 
+// Update updates an existing UserSession in the repository.
+func (r *UserSessionRepository) Update(ctx context.Context, us model.UserSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[us.ID]; !ok {
+		return storage.ErrNotFound
+	}
+
+	r.m[us.ID] = us
+	return nil
+}
+
 // FindByID returns the UserSession with the provided ID.
 func (r *UserSessionRepository) FindByID(ctx context.Context, id string) (model.UserSession, error) {
 	r.mu.RLock()
@@ -47,3 +62,70 @@ func (r *UserSessionRepository) FindByID(ctx context.Context, id string) (model.
 
 	return session, nil
 }
+
+// Delete removes the UserSession with the provided ID.
+func (r *UserSessionRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, id)
+	return nil
+}
+
+// FindByUserID returns all UserSessions belonging to the user with the provided ID, from newest to oldest.
+func (r *UserSessionRepository) FindByUserID(ctx context.Context, userID string) ([]model.UserSession, error) {
+	v := make([]model.UserSession, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, us := range r.m {
+		if us.UserID == userID {
+			v = append(v, us)
+		}
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].Created.Equal(v[j].Created) {
+			return v[i].ID > v[j].ID
+		}
+		return v[i].Created.After(v[j].Created)
+	})
+
+	return v, nil
+}
+
+// DeleteByUserID removes all UserSessions belonging to the user with the provided ID.
+func (r *UserSessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, us := range r.m {
+		if us.UserID == userID {
+			delete(r.m, id)
+		}
+	}
+
+	return nil
+}
+
+// DeleteExpired removes all UserSessions which have expired relative to the provided time, and returns the number of
+// sessions removed.
+func (r *UserSessionRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int
+	for id, us := range r.m {
+		if us.IsExpired(now) {
+			delete(r.m, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
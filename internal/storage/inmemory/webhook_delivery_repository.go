@@ -0,0 +1,92 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// WebhookDeliveryRepository is an in-memory implementation of the service.WebhookDeliveryRepository interface.
+type WebhookDeliveryRepository struct {
+	mu sync.RWMutex
+	m  map[string]model.WebhookDelivery
+}
+
+// NewWebhookDeliveryRepository returns a new WebhookDeliveryRepository which stores WebhookDeliveries in memory.
+func NewWebhookDeliveryRepository() service.WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		m: make(map[string]model.WebhookDelivery, 0),
+	}
+}
+
+// Create adds a new WebhookDelivery to the repository.
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, d model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[d.ID]; ok {
+		return storage.ErrAlreadyExists
+	}
+
+	r.m[d.ID] = d
+	return nil
+}
+
+// Update replaces an existing WebhookDelivery with the same ID.
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, d model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[d.ID]; !ok {
+		return storage.ErrNotFound
+	}
+
+	r.m[d.ID] = d
+	return nil
+}
+
+// Delete removes the WebhookDelivery with the provided ID.
+func (r *WebhookDeliveryRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.m, id)
+	return nil
+}
+
+// FindDue returns up to limit WebhookDeliveries whose NextAttempt is at or before now, ordered by NextAttempt, with
+// ties broken by ID.
+func (r *WebhookDeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	v := make([]model.WebhookDelivery, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, d := range r.m {
+		if !d.NextAttempt.After(now) {
+			v = append(v, d)
+		}
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].NextAttempt.Equal(v[j].NextAttempt) {
+			return v[i].ID < v[j].ID
+		}
+		return v[i].NextAttempt.Before(v[j].NextAttempt)
+	})
+
+	if limit > 0 && len(v) > limit {
+		v = v[:limit]
+	}
+
+	return v, nil
+}
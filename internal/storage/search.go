@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SearchTerms splits a free text search query into lower case terms, using any character which is not a letter
+// or number as a separator. Repositories implementing search should return items in which every term is the
+// prefix of at least one word.
+func SearchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// AccessRequestRepository implements the service.AccessRequestRepository interface and stores AccessRequests in a
+// SQLite database.
+type AccessRequestRepository struct {
+	db *sql.DB
+}
+
+// NewAccessRequestRepository returns a new AccessRequestRepository which stores AccessRequests in the provided SQLite
+// database.
+func NewAccessRequestRepository(db *sql.DB, c *MigrationController) (*AccessRequestRepository, error) {
+	err := c.migrateRepository(db, "access_request", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				`CREATE TABLE IF NOT EXISTS access_requests (
+					CommunityID text NOT NULL,
+					UserID text NOT NULL,
+					Message text NOT NULL,
+					Status text NOT NULL,
+					Created timestamp NOT NULL,
+					Resolved timestamp NOT NULL,
+					ResolverID text NOT NULL,
+					PRIMARY KEY (CommunityID, UserID)
+				);`,
+			},
+		},
+	})
+
+	return &AccessRequestRepository{db}, err
+}
+
+// accessRequestColumns selects every column of an access request, in the order read by scanAccessRequest.
+const accessRequestColumns = "CommunityID, UserID, Message, Status, Created, Resolved, ResolverID"
+
+// Create adds a new AccessRequest to the repository.
+func (r *AccessRequestRepository) Create(ctx context.Context, req model.AccessRequest) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO access_requests ("+accessRequestColumns+") VALUES (?, ?, ?, ?, ?, ?, ?);",
+		req.CommunityID, req.UserID, req.Message, req.Status, req.Created, req.Resolved, req.ResolverID)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Update updates an existing AccessRequest in the repository.
+func (r *AccessRequestRepository) Update(ctx context.Context, req model.AccessRequest) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE access_requests SET Message = ?, Status = ?, Created = ?, Resolved = ?,
+		ResolverID = ? WHERE CommunityID = ? AND UserID = ?;`,
+		req.Message, req.Status, req.Created, req.Resolved, req.ResolverID, req.CommunityID, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the AccessRequest of the specified user to the specified community.
+func (r *AccessRequestRepository) Delete(ctx context.Context, communityID string, userID string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM access_requests WHERE CommunityID = ? AND UserID = ?;",
+		communityID, userID)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// scanAccessRequest reads an AccessRequest from the provided row, which must contain accessRequestColumns.
+func scanAccessRequest(row interface{ Scan(...any) error }) (model.AccessRequest, error) {
+	var req model.AccessRequest
+	err := row.Scan(&req.CommunityID, &req.UserID, &req.Message, &req.Status, &req.Created, &req.Resolved,
+		&req.ResolverID)
+	return req, err
+}
+
+// Find returns the AccessRequest of the specified user to the specified community.
+func (r *AccessRequestRepository) Find(ctx context.Context, communityID string, userID string) (model.AccessRequest, error) {
+	req, err := scanAccessRequest(conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+accessRequestColumns+
+		" FROM access_requests WHERE CommunityID = ? AND UserID = ?;", communityID, userID))
+
+	if err == sql.ErrNoRows {
+		return model.AccessRequest{}, storage.ErrNotFound
+	}
+	return req, err
+}
+
+// FindByCommunityID returns the AccessRequests to the specified community with the specified status, from oldest to
+// newest.
+func (r *AccessRequestRepository) FindByCommunityID(ctx context.Context, communityID string, status model.AccessRequestStatus) ([]model.AccessRequest, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT "+accessRequestColumns+` FROM access_requests
+		WHERE CommunityID = ? AND Status = ? ORDER BY julianday(Created), UserID;`, communityID, status)
+	if err != nil {
+		return []model.AccessRequest{}, err
+	}
+	defer rows.Close()
+
+	requests := []model.AccessRequest{}
+	for rows.Next() {
+		req, err := scanAccessRequest(rows)
+		if err != nil {
+			return requests, err
+		}
+
+		requests = append(requests, req)
+	}
+
+	return requests, rows.Err()
+}
+
+// ReassignUser changes the UserID of every AccessRequest made by the user fromID, and the ResolverID of every
+// AccessRequest resolved by them, to toID. Requests to communities which toID has already requested access to are
+// removed instead.
+func (r *AccessRequestRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE OR IGNORE access_requests SET UserID = ? WHERE UserID = ?;", toID, fromID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM access_requests WHERE UserID = ?;", fromID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "UPDATE access_requests SET ResolverID = ? WHERE ResolverID = ?;", toID, fromID)
+		return err
+	})
+}
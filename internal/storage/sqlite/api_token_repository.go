@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// APITokenRepository implements the service.APITokenRepository interface and stores APITokens in a SQLite database
+type APITokenRepository struct {
+	db *sql.DB
+}
+
+// NewAPITokenRepository returns a new APITokenRepository which stores APITokens in the provided SQLite database
+func NewAPITokenRepository(db *sql.DB, c *MigrationController) (*APITokenRepository, error) {
+	err := c.migrateRepository(db, "apitoken", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				`CREATE TABLE apitokens (
+					ID text PRIMARY KEY,
+					UserID text NOT NULL,
+					Name text NOT NULL,
+					Hash text NOT NULL UNIQUE,
+					Created timestamp NOT NULL
+				);`,
+				`CREATE INDEX apitokens_userid ON apitokens (UserID);`,
+			},
+		},
+		{
+			version: 2,
+			stmts: []string{
+				`ALTER TABLE apitokens ADD COLUMN CommunityID text NOT NULL DEFAULT '` + model.DefaultCommunityID + `';`,
+			},
+		},
+	})
+
+	return &APITokenRepository{db}, err
+}
+
+// Create adds a new APIToken to the repository.
+func (r *APITokenRepository) Create(ctx context.Context, t model.APIToken) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO apitokens (ID, UserID, CommunityID, Name, Hash, Created) VALUES (?, ?, ?, ?, ?, ?);",
+		t.ID, t.UserID, t.CommunityID, t.Name, t.Hash, t.Created)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey ||
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique) {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Delete removes the APIToken with the provided ID.
+func (r *APITokenRepository) Delete(ctx context.Context, id string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM apitokens WHERE ID = ?;", id)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// FindByID returns the APIToken with the provided ID.
+func (r *APITokenRepository) FindByID(ctx context.Context, id string) (model.APIToken, error) {
+	return r.findOne(ctx, "SELECT ID, UserID, CommunityID, Name, Hash, Created FROM apitokens WHERE ID = ?;", id)
+}
+
+// FindByHash returns the APIToken with the provided hash.
+func (r *APITokenRepository) FindByHash(ctx context.Context, hash string) (model.APIToken, error) {
+	return r.findOne(ctx, "SELECT ID, UserID, CommunityID, Name, Hash, Created FROM apitokens WHERE Hash = ?;", hash)
+}
+
+// findOne returns the APIToken selected by the provided query.
+func (r *APITokenRepository) findOne(ctx context.Context, query string, args ...any) (model.APIToken, error) {
+	var t model.APIToken
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&t.ID, &t.UserID, &t.CommunityID, &t.Name, &t.Hash, &t.Created)
+
+	if err == sql.ErrNoRows {
+		return model.APIToken{}, storage.ErrNotFound
+	}
+	return t, err
+}
+
+// FindByUserID returns all APITokens belonging to the user with the provided ID, from newest to oldest.
+func (r *APITokenRepository) FindByUserID(ctx context.Context, userID string) ([]model.APIToken, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT ID, UserID, CommunityID, Name, Hash, Created FROM apitokens WHERE UserID = ?
+		ORDER BY julianday(Created) DESC, ID DESC;`, userID)
+	if err != nil {
+		return []model.APIToken{}, err
+	}
+	defer rows.Close()
+
+	tokens := []model.APIToken{}
+	for rows.Next() {
+		var t model.APIToken
+
+		err := rows.Scan(&t.ID, &t.UserID, &t.CommunityID, &t.Name, &t.Hash, &t.Created)
+		if err != nil {
+			return tokens, err
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// ReassignUser changes the UserID of every APIToken belonging to the user fromID to toID.
+func (r *APITokenRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE apitokens SET UserID = ? WHERE UserID = ?;", toID, fromID)
+	return err
+}
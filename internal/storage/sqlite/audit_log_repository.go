@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// AuditLogRepository implements the service.AuditLogRepository interface and stores AuditLogEntries in a SQLite
+// database.
+type AuditLogRepository struct {
+	db *sql.DB
+}
+
+// NewAuditLogRepository returns a new AuditLogRepository which stores AuditLogEntries in the provided SQLite database.
+func NewAuditLogRepository(db *sql.DB, c *MigrationController) (*AuditLogRepository, error) {
+	err := c.migrateRepository(db, "auditlog", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				`CREATE TABLE auditlog (
+					ID text PRIMARY KEY,
+					ActorID text NOT NULL,
+					Action text NOT NULL,
+					TargetID text NOT NULL,
+					Details text NOT NULL,
+					IP text NOT NULL,
+					Created timestamp NOT NULL
+				);`,
+			},
+		},
+	})
+
+	return &AuditLogRepository{db}, err
+}
+
+// Create adds a new AuditLogEntry to the repository.
+func (r *AuditLogRepository) Create(ctx context.Context, e model.AuditLogEntry) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO auditlog (ID, ActorID, Action, TargetID, Details, IP, Created) VALUES (?, ?, ?, ?, ?, ?, ?);",
+		e.ID, e.ActorID, e.Action, e.TargetID, e.Details, e.IP, e.Created)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Query returns the AuditLogEntries in the repository matching the provided AuditLogQuery, from newest to oldest.
+func (r *AuditLogRepository) Query(ctx context.Context, q service.AuditLogQuery) ([]model.AuditLogEntry, error) {
+	var conds []string
+	var args []any
+
+	if q.ActorID != "" {
+		conds = append(conds, "ActorID = ?")
+		args = append(args, q.ActorID)
+	}
+	if q.Action != "" {
+		conds = append(conds, "Action = ?")
+		args = append(args, q.Action)
+	}
+	if q.TargetID != "" {
+		conds = append(conds, "TargetID = ?")
+		args = append(args, q.TargetID)
+	}
+
+	stmt := "SELECT ID, ActorID, Action, TargetID, Details, IP, Created FROM auditlog"
+	if len(conds) > 0 {
+		stmt += " WHERE " + strings.Join(conds, " AND ")
+	}
+	stmt += " ORDER BY julianday(Created) DESC, ID DESC"
+	if q.Limit > 0 {
+		stmt += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, stmt+";", args...)
+	if err != nil {
+		return []model.AuditLogEntry{}, err
+	}
+	defer rows.Close()
+
+	entries := []model.AuditLogEntry{}
+	for rows.Next() {
+		var e model.AuditLogEntry
+
+		err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetID, &e.Details, &e.IP, &e.Created)
+		if err != nil {
+			return entries, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
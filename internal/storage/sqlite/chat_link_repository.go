@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// ChatLinkRepository implements the service.ChatLinkRepository interface and stores ChatLinks in a SQLite database
+type ChatLinkRepository struct {
+	db *sql.DB
+}
+
+// NewChatLinkRepository returns a new ChatLinkRepository which stores ChatLinks in the provided SQLite database
+func NewChatLinkRepository(db *sql.DB, c *MigrationController) (*ChatLinkRepository, error) {
+	err := c.migrateRepository(db, "chatlink", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				`CREATE TABLE chatlinks (
+					ID text PRIMARY KEY,
+					Platform text NOT NULL,
+					TeamID text NOT NULL,
+					ChatUserID text NOT NULL,
+					ChatUserName text NOT NULL,
+					UserID text NOT NULL,
+					Created timestamp NOT NULL
+				);`,
+				`CREATE INDEX chatlinks_userid ON chatlinks (UserID);`,
+			},
+		},
+	})
+
+	return &ChatLinkRepository{db}, err
+}
+
+// Create adds a new ChatLink to the repository.
+func (r *ChatLinkRepository) Create(ctx context.Context, l model.ChatLink) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO chatlinks (ID, Platform, TeamID, ChatUserID, ChatUserName, UserID,
+		Created) VALUES (?, ?, ?, ?, ?, ?, ?);`,
+		l.ID, l.Platform, l.TeamID, l.ChatUserID, l.ChatUserName, l.UserID, l.Created)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Delete removes the ChatLink with the provided ID.
+func (r *ChatLinkRepository) Delete(ctx context.Context, id string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM chatlinks WHERE ID = ?;", id)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// FindByID returns the ChatLink with the provided ID.
+func (r *ChatLinkRepository) FindByID(ctx context.Context, id string) (model.ChatLink, error) {
+	var l model.ChatLink
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT ID, Platform, TeamID, ChatUserID, ChatUserName, UserID, Created
+		FROM chatlinks WHERE ID = ?;`, id).Scan(
+		&l.ID, &l.Platform, &l.TeamID, &l.ChatUserID, &l.ChatUserName, &l.UserID, &l.Created)
+
+	if err == sql.ErrNoRows {
+		return model.ChatLink{}, storage.ErrNotFound
+	}
+	return l, err
+}
+
+// FindByUserID returns all ChatLinks belonging to the user with the provided ID, from oldest to newest.
+func (r *ChatLinkRepository) FindByUserID(ctx context.Context, userID string) ([]model.ChatLink, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT ID, Platform, TeamID, ChatUserID, ChatUserName, UserID, Created
+		FROM chatlinks WHERE UserID = ? ORDER BY julianday(Created), ID;`, userID)
+	if err != nil {
+		return []model.ChatLink{}, err
+	}
+	defer rows.Close()
+
+	links := []model.ChatLink{}
+	for rows.Next() {
+		var l model.ChatLink
+
+		err := rows.Scan(&l.ID, &l.Platform, &l.TeamID, &l.ChatUserID, &l.ChatUserName, &l.UserID, &l.Created)
+		if err != nil {
+			return links, err
+		}
+
+		links = append(links, l)
+	}
+
+	return links, rows.Err()
+}
+
+// ReassignUser links every ChatLink linked to the user fromID to the user toID instead.
+func (r *ChatLinkRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE chatlinks SET UserID = ? WHERE UserID = ?;", toID, fromID)
+	return err
+}
@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// CommentRepository implements the service.CommentRepository interface and stores Comments in a SQLite database
+type CommentRepository struct {
+	db *sql.DB
+}
+
+// NewCommentRepository returns a new CommentRepository which stores Comments in the provided SQLite database
+func NewCommentRepository(db *sql.DB, c *MigrationController) (*CommentRepository, error) {
+	err := c.migrateRepository(db, "comment", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				`CREATE TABLE comments (
+					ID text PRIMARY KEY,
+					QuoteID text NOT NULL,
+					ParentID text NOT NULL,
+					AuthorID text NOT NULL,
+					Text text NOT NULL,
+					Created timestamp NOT NULL,
+					Edited timestamp NOT NULL,
+					Deleted boolean NOT NULL
+				);`,
+				`CREATE INDEX comments_quoteid ON comments (QuoteID);`,
+			},
+		},
+	})
+
+	return &CommentRepository{db}, err
+}
+
+// Create adds a new Comment to the repository.
+func (r *CommentRepository) Create(ctx context.Context, c model.Comment) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO comments (ID, QuoteID, ParentID, AuthorID, Text, Created, Edited,
+		Deleted) VALUES (?, ?, ?, ?, ?, ?, ?, ?);`,
+		c.ID, c.QuoteID, c.ParentID, c.AuthorID, c.Text, c.Created, c.Edited, c.Deleted)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Update replaces the Comment with the same ID as c.
+func (r *CommentRepository) Update(ctx context.Context, c model.Comment) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE comments SET QuoteID = ?, ParentID = ?, AuthorID = ?, Text = ?,
+		Created = ?, Edited = ?, Deleted = ? WHERE ID = ?;`,
+		c.QuoteID, c.ParentID, c.AuthorID, c.Text, c.Created, c.Edited, c.Deleted, c.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the Comment with the provided ID.
+func (r *CommentRepository) Delete(ctx context.Context, id string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM comments WHERE ID = ?;", id)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteByQuoteID removes all Comments on the Quote with the provided ID.
+func (r *CommentRepository) DeleteByQuoteID(ctx context.Context, quoteID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM comments WHERE QuoteID = ?;", quoteID)
+	return err
+}
+
+// FindByID returns the Comment with the provided ID.
+func (r *CommentRepository) FindByID(ctx context.Context, id string) (model.Comment, error) {
+	var c model.Comment
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT ID, QuoteID, ParentID, AuthorID, Text, Created, Edited, Deleted
+		FROM comments WHERE ID = ?;`, id).Scan(
+		&c.ID, &c.QuoteID, &c.ParentID, &c.AuthorID, &c.Text, &c.Created, &c.Edited, &c.Deleted)
+
+	if err == sql.ErrNoRows {
+		return model.Comment{}, storage.ErrNotFound
+	}
+	return c, err
+}
+
+// FindByQuoteID returns all Comments on the Quote with the provided ID, from oldest to newest.
+func (r *CommentRepository) FindByQuoteID(ctx context.Context, quoteID string) ([]model.Comment, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT ID, QuoteID, ParentID, AuthorID, Text, Created, Edited, Deleted
+		FROM comments WHERE QuoteID = ? ORDER BY julianday(Created), ID;`, quoteID)
+	if err != nil {
+		return []model.Comment{}, err
+	}
+	defer rows.Close()
+
+	comments := []model.Comment{}
+	for rows.Next() {
+		var c model.Comment
+
+		err := rows.Scan(&c.ID, &c.QuoteID, &c.ParentID, &c.AuthorID, &c.Text, &c.Created, &c.Edited, &c.Deleted)
+		if err != nil {
+			return comments, err
+		}
+
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
+
+// ReassignUser changes the AuthorID of every Comment written by the user fromID to toID.
+func (r *CommentRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE comments SET AuthorID = ? WHERE AuthorID = ?;", toID, fromID)
+	return err
+}
@@ -0,0 +1,224 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// InviteRepository implements the service.InviteRepository interface and stores Invites in a SQLite database
+type InviteRepository struct {
+	db *sql.DB
+}
+
+// NewInviteRepository returns a new InviteRepository which stores Invites in the provided SQLite database
+func NewInviteRepository(db *sql.DB, c *MigrationController) (*InviteRepository, error) {
+	err := c.migrateRepository(db, "invite", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				// Expires is the zero time for invites which never expire
+				`CREATE TABLE invites (
+					ID text PRIMARY KEY,
+					CommunityID text NOT NULL,
+					Code text NOT NULL UNIQUE,
+					CreatorID text NOT NULL,
+					MaxUses integer NOT NULL,
+					Expires timestamp NOT NULL,
+					Created timestamp NOT NULL
+				);`,
+				`CREATE INDEX invites_communityid ON invites (CommunityID);`,
+				`CREATE TABLE invite_redemptions (
+					InviteID text NOT NULL,
+					UserID text NOT NULL,
+					Redeemed timestamp NOT NULL,
+					PRIMARY KEY (InviteID, UserID)
+				);`,
+			},
+		},
+	})
+
+	return &InviteRepository{db}, err
+}
+
+// inviteColumns selects every column of an invite, in the order read by scanInvite.
+const inviteColumns = "ID, CommunityID, Code, CreatorID, MaxUses, Expires, Created"
+
+// Create adds a new Invite to the repository, ignoring its Redemptions.
+func (r *InviteRepository) Create(ctx context.Context, i model.Invite) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO invites ("+inviteColumns+") VALUES (?, ?, ?, ?, ?, ?, ?);",
+		i.ID, i.CommunityID, i.Code, i.CreatorID, i.MaxUses, i.Expires, i.Created)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey ||
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique) {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Delete removes the Invite with the provided ID, along with its Redemptions.
+func (r *InviteRepository) Delete(ctx context.Context, id string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM invites WHERE ID = ?;", id)
+		if err != nil {
+			return err
+		}
+
+		if i, _ := result.RowsAffected(); i == 0 {
+			return storage.ErrNotFound
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM invite_redemptions WHERE InviteID = ?;", id)
+		return err
+	})
+}
+
+// scanInvite reads an Invite, without its Redemptions, from the provided row, which must contain inviteColumns.
+func scanInvite(row interface{ Scan(...any) error }) (model.Invite, error) {
+	i := model.Invite{Redemptions: []model.InviteRedemption{}}
+	err := row.Scan(&i.ID, &i.CommunityID, &i.Code, &i.CreatorID, &i.MaxUses, &i.Expires, &i.Created)
+	return i, err
+}
+
+// FindByID returns the Invite with the provided ID.
+func (r *InviteRepository) FindByID(ctx context.Context, id string) (model.Invite, error) {
+	return r.findOne(ctx, "SELECT "+inviteColumns+" FROM invites WHERE ID = ?;", id)
+}
+
+// FindByCode returns the Invite with the provided code.
+func (r *InviteRepository) FindByCode(ctx context.Context, code string) (model.Invite, error) {
+	return r.findOne(ctx, "SELECT "+inviteColumns+" FROM invites WHERE Code = ?;", code)
+}
+
+// findOne returns the Invite selected by the provided query, which must select inviteColumns, along with its
+// Redemptions.
+func (r *InviteRepository) findOne(ctx context.Context, query string, args ...any) (model.Invite, error) {
+	i, err := scanInvite(conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return model.Invite{}, storage.ErrNotFound
+	} else if err != nil {
+		return model.Invite{}, err
+	}
+
+	err = r.findRedemptions(ctx, func(ir model.InviteRedemption) {
+		i.Redemptions = append(i.Redemptions, ir)
+	}, "InviteID = ?", i.ID)
+	return i, err
+}
+
+// FindByCommunityID returns the Invites of the specified community, from newest to oldest.
+func (r *InviteRepository) FindByCommunityID(ctx context.Context, communityID string) ([]model.Invite, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT "+inviteColumns+` FROM invites WHERE CommunityID = ?
+		ORDER BY julianday(Created) DESC, ID DESC;`, communityID)
+	if err != nil {
+		return []model.Invite{}, err
+	}
+	defer rows.Close()
+
+	invites := []model.Invite{}
+	index := make(map[string]int)
+	for rows.Next() {
+		i, err := scanInvite(rows)
+		if err != nil {
+			return invites, err
+		}
+
+		index[i.ID] = len(invites)
+		invites = append(invites, i)
+	}
+	if err := rows.Err(); err != nil {
+		return invites, err
+	}
+	rows.Close()
+
+	err = r.findRedemptions(ctx, func(ir model.InviteRedemption) {
+		i := index[ir.InviteID]
+		invites[i].Redemptions = append(invites[i].Redemptions, ir)
+	}, "InviteID IN (SELECT ID FROM invites WHERE CommunityID = ?)", communityID)
+	return invites, err
+}
+
+// findRedemptions passes each InviteRedemption matching the provided condition to add, from oldest to newest.
+func (r *InviteRepository) findRedemptions(ctx context.Context, add func(model.InviteRedemption), cond string, args ...any) error {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT InviteID, UserID, Redeemed FROM invite_redemptions WHERE `+cond+`
+		ORDER BY julianday(Redeemed), UserID;`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ir model.InviteRedemption
+		if err := rows.Scan(&ir.InviteID, &ir.UserID, &ir.Redeemed); err != nil {
+			return err
+		}
+		add(ir)
+	}
+
+	return rows.Err()
+}
+
+// AddRedemption records that an Invite was redeemed, provided that it had not expired or been used up at the time.
+// The limits are checked by the insert itself, such that concurrent redemptions cannot exceed them.
+func (r *InviteRepository) AddRedemption(ctx context.Context, ir model.InviteRedemption) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO invite_redemptions (InviteID, UserID, Redeemed)
+		SELECT ?, ?, ? FROM invites i WHERE i.ID = ?
+			AND (i.MaxUses = 0 OR (SELECT COUNT(*) FROM invite_redemptions WHERE InviteID = i.ID) < i.MaxUses)
+			AND (julianday(i.Expires) = julianday(?) OR julianday(i.Expires) > julianday(?));`,
+		ir.InviteID, ir.UserID, ir.Redeemed, ir.InviteID, time.Time{}, ir.Redeemed)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	} else if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i > 0 {
+		return nil
+	}
+
+	// nothing was inserted, so determine why
+	var redeemed bool
+	err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM invite_redemptions WHERE InviteID = ? AND UserID = ?);",
+		ir.InviteID, ir.UserID).Scan(&redeemed)
+	if err != nil {
+		return err
+	}
+	if redeemed {
+		return storage.ErrAlreadyExists
+	}
+
+	var exists bool
+	err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM invites WHERE ID = ?);", ir.InviteID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return storage.ErrNotFound
+	}
+	return storage.ErrUnavailable
+}
+
+// ReassignUser changes the CreatorID of every Invite created by the user fromID, and the UserID of every redemption
+// made by them, to toID. Redemptions of invites which toID has already redeemed are removed instead.
+func (r *InviteRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE invites SET CreatorID = ? WHERE CreatorID = ?;", toID, fromID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE OR IGNORE invite_redemptions SET UserID = ? WHERE UserID = ?;", toID, fromID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "DELETE FROM invite_redemptions WHERE UserID = ?;", fromID)
+		return err
+	})
+}
@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// createMembershipsTable creates the memberships table. It may be run by either the user or membership repository
+// migrations, whichever runs first, as the user migration moves existing users' quiz state into it.
+var createMembershipsTable = []string{
+	`CREATE TABLE IF NOT EXISTS memberships (
+		CommunityID text NOT NULL,
+		UserID text NOT NULL,
+		QuizPassed boolean NOT NULL,
+		QuizAttempts smallint NOT NULL,
+		Banned boolean NOT NULL,
+		Admin boolean NOT NULL,
+		Joined timestamp NOT NULL,
+		PRIMARY KEY (CommunityID, UserID)
+	);`,
+	`CREATE INDEX IF NOT EXISTS memberships_userid ON memberships (UserID);`,
+}
+
+// MembershipRepository implements the service.MembershipRepository interface and stores Memberships in a SQLite
+// database.
+type MembershipRepository struct {
+	db *sql.DB
+}
+
+// NewMembershipRepository returns a new MembershipRepository which stores Memberships in the provided SQLite database.
+func NewMembershipRepository(db *sql.DB, c *MigrationController) (*MembershipRepository, error) {
+	err := c.migrateRepository(db, "membership", []migration{
+		{
+			version: 1,
+			stmts:   createMembershipsTable,
+		},
+		{
+			version: 2,
+			stmts: []string{
+				`ALTER TABLE memberships ADD COLUMN LastQuizAttempt timestamp NOT NULL DEFAULT '0001-01-01 00:00:00+00:00';`,
+			},
+		},
+		{
+			version: 3,
+			stmts: []string{
+				`ALTER TABLE memberships ADD COLUMN Approved boolean NOT NULL DEFAULT false;`,
+				`ALTER TABLE memberships ADD COLUMN Rejected boolean NOT NULL DEFAULT false;`,
+			},
+		},
+	})
+
+	return &MembershipRepository{db}, err
+}
+
+// membershipColumns selects every column of a membership, in the order read by scanMembership.
+const membershipColumns = "CommunityID, UserID, QuizPassed, QuizAttempts, LastQuizAttempt, Approved, Rejected, Banned, Admin, Joined"
+
+// Create adds a new Membership to the repository.
+func (r *MembershipRepository) Create(ctx context.Context, m model.Membership) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO memberships ("+membershipColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
+		m.CommunityID, m.UserID, m.QuizPassed, m.QuizAttempts, m.LastQuizAttempt, m.Approved, m.Rejected, m.Banned, m.Admin,
+		m.Joined)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Update updates an existing Membership in the repository.
+func (r *MembershipRepository) Update(ctx context.Context, m model.Membership) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE memberships SET QuizPassed = ?, QuizAttempts = ?, LastQuizAttempt = ?,
+		Approved = ?, Rejected = ?, Banned = ?, Admin = ?, Joined = ? WHERE CommunityID = ? AND UserID = ?;`,
+		m.QuizPassed, m.QuizAttempts, m.LastQuizAttempt, m.Approved, m.Rejected, m.Banned, m.Admin, m.Joined,
+		m.CommunityID, m.UserID)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the Membership of the specified user in the specified community.
+func (r *MembershipRepository) Delete(ctx context.Context, communityID string, userID string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM memberships WHERE CommunityID = ? AND UserID = ?;",
+		communityID, userID)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// scanMembership reads a Membership from the provided row, which must contain membershipColumns.
+func scanMembership(row interface{ Scan(...any) error }) (model.Membership, error) {
+	var m model.Membership
+	err := row.Scan(&m.CommunityID, &m.UserID, &m.QuizPassed, &m.QuizAttempts, &m.LastQuizAttempt, &m.Approved,
+		&m.Rejected, &m.Banned, &m.Admin, &m.Joined)
+	return m, err
+}
+
+// Find returns the Membership of the specified user in the specified community.
+func (r *MembershipRepository) Find(ctx context.Context, communityID string, userID string) (model.Membership, error) {
+	m, err := scanMembership(conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+membershipColumns+
+		" FROM memberships WHERE CommunityID = ? AND UserID = ?;", communityID, userID))
+
+	if err == sql.ErrNoRows {
+		return model.Membership{}, storage.ErrNotFound
+	}
+	return m, err
+}
+
+// FindByUserID returns the Memberships of the specified user.
+func (r *MembershipRepository) FindByUserID(ctx context.Context, userID string) ([]model.Membership, error) {
+	return r.findMany(ctx, "SELECT "+membershipColumns+" FROM memberships WHERE UserID = ?;", userID)
+}
+
+// FindByCommunityID returns the Memberships of the specified community.
+func (r *MembershipRepository) FindByCommunityID(ctx context.Context, communityID string) ([]model.Membership, error) {
+	return r.findMany(ctx, "SELECT "+membershipColumns+" FROM memberships WHERE CommunityID = ?;", communityID)
+}
+
+// findMany returns the Memberships selected by the provided query, which must select membershipColumns.
+func (r *MembershipRepository) findMany(ctx context.Context, query string, args ...any) ([]model.Membership, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return []model.Membership{}, err
+	}
+	defer rows.Close()
+
+	memberships := []model.Membership{}
+	for rows.Next() {
+		m, err := scanMembership(rows)
+		if err != nil {
+			return memberships, err
+		}
+
+		memberships = append(memberships, m)
+	}
+
+	return memberships, rows.Err()
+}
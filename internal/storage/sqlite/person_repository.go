@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// PersonRepository implements the service.PersonRepository interface and stores People in a SQLite database
+type PersonRepository struct {
+	db *sql.DB
+}
+
+// NewPersonRepository returns a new PersonRepository which stores People in the provided SQLite database
+func NewPersonRepository(db *sql.DB, c *MigrationController) (*PersonRepository, error) {
+	err := c.migrateRepository(db, "person", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				// Aliases are stored as a JSON array of strings
+				`CREATE TABLE people (
+					ID text PRIMARY KEY,
+					Name text NOT NULL,
+					Aliases text NOT NULL,
+					UserID text NOT NULL,
+					Created timestamp NOT NULL
+				);`,
+			},
+		},
+		{
+			version: 2,
+			stmts: []string{
+				`ALTER TABLE people ADD COLUMN CommunityID text NOT NULL DEFAULT '` + model.DefaultCommunityID + `';`,
+				`CREATE INDEX people_communityid ON people (CommunityID);`,
+			},
+		},
+	})
+
+	return &PersonRepository{db}, err
+}
+
+// Create adds a new Person to the repository.
+func (r *PersonRepository) Create(ctx context.Context, p model.Person) error {
+	aliases, err := json.Marshal(p.Aliases)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, `INSERT INTO people (ID, CommunityID, Name, Aliases, UserID, Created)
+		VALUES (?, ?, ?, ?, ?, ?);`,
+		p.ID, p.CommunityID, p.Name, string(aliases), p.UserID, p.Created)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Update replaces the Person with the same ID as p.
+func (r *PersonRepository) Update(ctx context.Context, p model.Person) error {
+	aliases, err := json.Marshal(p.Aliases)
+	if err != nil {
+		return err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE people SET Name = ?, Aliases = ?, UserID = ?, Created = ?
+		WHERE ID = ?;`,
+		p.Name, string(aliases), p.UserID, p.Created, p.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the Person with the provided ID.
+func (r *PersonRepository) Delete(ctx context.Context, id string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM people WHERE ID = ?;", id)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// scanPerson reads a Person from the provided row, decoding its aliases.
+func scanPerson(row interface{ Scan(...any) error }) (model.Person, error) {
+	var p model.Person
+	var aliases string
+
+	if err := row.Scan(&p.ID, &p.CommunityID, &p.Name, &aliases, &p.UserID, &p.Created); err != nil {
+		return model.Person{}, err
+	}
+
+	err := json.Unmarshal([]byte(aliases), &p.Aliases)
+	return p, err
+}
+
+// FindByID returns the Person with the provided ID.
+func (r *PersonRepository) FindByID(ctx context.Context, id string) (model.Person, error) {
+	p, err := scanPerson(conn(ctx, r.db).QueryRowContext(ctx, `SELECT ID, CommunityID, Name, Aliases, UserID, Created
+		FROM people WHERE ID = ?;`, id))
+
+	if err == sql.ErrNoRows {
+		return model.Person{}, storage.ErrNotFound
+	}
+	return p, err
+}
+
+// FindByCommunityID returns all People of the specified community, ordered by name regardless of capitalization.
+func (r *PersonRepository) FindByCommunityID(ctx context.Context, communityID string) ([]model.Person, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT ID, CommunityID, Name, Aliases, UserID, Created
+		FROM people WHERE CommunityID = ? ORDER BY lower(Name), ID;`, communityID)
+	if err != nil {
+		return []model.Person{}, err
+	}
+	defer rows.Close()
+
+	people := []model.Person{}
+	for rows.Next() {
+		p, err := scanPerson(rows)
+		if err != nil {
+			return people, err
+		}
+
+		people = append(people, p)
+	}
+
+	return people, rows.Err()
+}
+
+// ReassignUser links every Person linked to the user fromID to the user toID instead.
+func (r *PersonRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE people SET UserID = ? WHERE UserID = ?;", toID, fromID)
+	return err
+}
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// QuizAttemptRepository implements the service.QuizAttemptRepository interface and stores QuizAttempts in a SQLite
+// database.
+type QuizAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewQuizAttemptRepository returns a new QuizAttemptRepository which stores QuizAttempts in the provided SQLite
+// database.
+func NewQuizAttemptRepository(db *sql.DB, c *MigrationController) (*QuizAttemptRepository, error) {
+	err := c.migrateRepository(db, "quiz_attempt", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				`CREATE TABLE IF NOT EXISTS quiz_attempts (
+					ID text PRIMARY KEY,
+					CommunityID text NOT NULL,
+					UserID text NOT NULL,
+					IP text NOT NULL,
+					Submitted timestamp NOT NULL,
+					QuestionIDs text NOT NULL,
+					WrongQuestionIDs text NOT NULL,
+					Passed boolean NOT NULL,
+					Answers text NOT NULL
+				);`,
+				`CREATE INDEX IF NOT EXISTS quiz_attempts_member ON quiz_attempts (CommunityID, UserID);`,
+			},
+		},
+	})
+
+	return &QuizAttemptRepository{db}, err
+}
+
+// Create adds a new QuizAttempt to the repository.
+func (r *QuizAttemptRepository) Create(ctx context.Context, a model.QuizAttempt) error {
+	ids, err := json.Marshal(a.QuestionIDs)
+	if err != nil {
+		return fmt.Errorf("marshaling question ids: %w", err)
+	}
+	wrong, err := json.Marshal(a.WrongQuestionIDs)
+	if err != nil {
+		return fmt.Errorf("marshaling wrong question ids: %w", err)
+	}
+	answers, err := json.Marshal(a.Answers)
+	if err != nil {
+		return fmt.Errorf("marshaling answers: %w", err)
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, `INSERT INTO quiz_attempts (ID, CommunityID, UserID, IP, Submitted, QuestionIDs,
+		WrongQuestionIDs, Passed, Answers) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		a.ID, a.CommunityID, a.UserID, a.IP, a.Submitted, string(ids), string(wrong), a.Passed, string(answers))
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// FindByUserID returns the QuizAttempts of the specified user at the entry quiz of the specified community, from
+// newest to oldest.
+func (r *QuizAttemptRepository) FindByUserID(ctx context.Context, communityID string, userID string) ([]model.QuizAttempt, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT ID, CommunityID, UserID, IP, Submitted, QuestionIDs, WrongQuestionIDs,
+		Passed, Answers FROM quiz_attempts WHERE CommunityID = ? AND UserID = ?
+		ORDER BY julianday(Submitted) DESC, ID DESC;`, communityID, userID)
+	if err != nil {
+		return []model.QuizAttempt{}, err
+	}
+	defer rows.Close()
+
+	attempts := []model.QuizAttempt{}
+	for rows.Next() {
+		var a model.QuizAttempt
+		var ids, wrong, answers string
+		err := rows.Scan(&a.ID, &a.CommunityID, &a.UserID, &a.IP, &a.Submitted, &ids, &wrong, &a.Passed, &answers)
+		if err != nil {
+			return attempts, err
+		}
+
+		if err := json.Unmarshal([]byte(ids), &a.QuestionIDs); err != nil {
+			return attempts, fmt.Errorf("unmarshaling question ids: %w", err)
+		}
+		if err := json.Unmarshal([]byte(wrong), &a.WrongQuestionIDs); err != nil {
+			return attempts, fmt.Errorf("unmarshaling wrong question ids: %w", err)
+		}
+		if err := json.Unmarshal([]byte(answers), &a.Answers); err != nil {
+			return attempts, fmt.Errorf("unmarshaling answers: %w", err)
+		}
+
+		attempts = append(attempts, a)
+	}
+
+	return attempts, rows.Err()
+}
+
+// ReassignUser changes the UserID of every QuizAttempt made by the user fromID to toID.
+func (r *QuizAttemptRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE quiz_attempts SET UserID = ? WHERE UserID = ?;", toID, fromID)
+	return err
+}
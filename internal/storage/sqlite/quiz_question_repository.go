@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// QuizQuestionRepository implements the service.QuizQuestionRepository interface and stores QuizQuestions in a
+// SQLite database.
+type QuizQuestionRepository struct {
+	db *sql.DB
+}
+
+// NewQuizQuestionRepository returns a new QuizQuestionRepository which stores QuizQuestions in the provided SQLite
+// database.
+func NewQuizQuestionRepository(db *sql.DB, c *MigrationController) (*QuizQuestionRepository, error) {
+	err := c.migrateRepository(db, "quiz_question", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				`CREATE TABLE IF NOT EXISTS quiz_questions (
+					CommunityID text NOT NULL,
+					ID integer NOT NULL,
+					Question text NOT NULL,
+					Answers text NOT NULL,
+					Normalize text NOT NULL,
+					Position integer NOT NULL,
+					Enabled boolean NOT NULL,
+					PRIMARY KEY (CommunityID, ID)
+				);`,
+			},
+		},
+	})
+
+	return &QuizQuestionRepository{db}, err
+}
+
+// quizQuestionColumns selects every column of a quiz question, in the order read by scanQuizQuestion.
+const quizQuestionColumns = "CommunityID, ID, Question, Answers, Normalize, Position, Enabled"
+
+// marshalQuizQuestion returns the answers and normalization options of the provided question as JSON.
+func marshalQuizQuestion(q model.QuizQuestion) (answers string, normalize string, err error) {
+	a, err := json.Marshal(q.Answers)
+	if err != nil {
+		return "", "", fmt.Errorf("marshaling answers: %w", err)
+	}
+	n, err := json.Marshal(q.Normalize)
+	if err != nil {
+		return "", "", fmt.Errorf("marshaling normalization: %w", err)
+	}
+	return string(a), string(n), nil
+}
+
+// Create adds a new QuizQuestion to the repository.
+func (r *QuizQuestionRepository) Create(ctx context.Context, q model.QuizQuestion) error {
+	answers, normalize, err := marshalQuizQuestion(q)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, "INSERT INTO quiz_questions ("+quizQuestionColumns+") VALUES (?, ?, ?, ?, ?, ?, ?);",
+		q.CommunityID, q.ID, q.Question, answers, normalize, q.Position, q.Enabled)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Update updates an existing QuizQuestion in the repository.
+func (r *QuizQuestionRepository) Update(ctx context.Context, q model.QuizQuestion) error {
+	answers, normalize, err := marshalQuizQuestion(q)
+	if err != nil {
+		return err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE quiz_questions SET Question = ?, Answers = ?, Normalize = ?,
+		Position = ?, Enabled = ? WHERE CommunityID = ? AND ID = ?;`,
+		q.Question, answers, normalize, q.Position, q.Enabled, q.CommunityID, q.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// scanQuizQuestion reads a QuizQuestion from the provided row, which must contain quizQuestionColumns.
+func scanQuizQuestion(row interface{ Scan(...any) error }) (model.QuizQuestion, error) {
+	var q model.QuizQuestion
+	var answers, normalize string
+	if err := row.Scan(&q.CommunityID, &q.ID, &q.Question, &answers, &normalize, &q.Position, &q.Enabled); err != nil {
+		return q, err
+	}
+
+	if err := json.Unmarshal([]byte(answers), &q.Answers); err != nil {
+		return q, fmt.Errorf("unmarshaling answers: %w", err)
+	}
+	if err := json.Unmarshal([]byte(normalize), &q.Normalize); err != nil {
+		return q, fmt.Errorf("unmarshaling normalization: %w", err)
+	}
+	return q, nil
+}
+
+// Find returns the QuizQuestion of the specified community with the specified ID.
+func (r *QuizQuestionRepository) Find(ctx context.Context, communityID string, id int) (model.QuizQuestion, error) {
+	q, err := scanQuizQuestion(conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+quizQuestionColumns+
+		" FROM quiz_questions WHERE CommunityID = ? AND ID = ?;", communityID, id))
+
+	if err == sql.ErrNoRows {
+		return model.QuizQuestion{}, storage.ErrNotFound
+	}
+	return q, err
+}
+
+// FindByCommunityID returns the QuizQuestions of the specified community, ordered by their position.
+func (r *QuizQuestionRepository) FindByCommunityID(ctx context.Context, communityID string) ([]model.QuizQuestion, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT "+quizQuestionColumns+
+		" FROM quiz_questions WHERE CommunityID = ? ORDER BY Position, ID;", communityID)
+	if err != nil {
+		return []model.QuizQuestion{}, err
+	}
+	defer rows.Close()
+
+	questions := []model.QuizQuestion{}
+	for rows.Next() {
+		q, err := scanQuizQuestion(rows)
+		if err != nil {
+			return questions, err
+		}
+
+		questions = append(questions, q)
+	}
+
+	return questions, rows.Err()
+}
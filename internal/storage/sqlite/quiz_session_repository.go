@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// QuizSessionRepository implements the service.QuizSessionRepository interface and stores QuizSessions in a SQLite
+// database.
+type QuizSessionRepository struct {
+	db *sql.DB
+}
+
+// NewQuizSessionRepository returns a new QuizSessionRepository which stores QuizSessions in the provided SQLite
+// database.
+func NewQuizSessionRepository(db *sql.DB, c *MigrationController) (*QuizSessionRepository, error) {
+	err := c.migrateRepository(db, "quiz_session", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				`CREATE TABLE IF NOT EXISTS quiz_sessions (
+					CommunityID text NOT NULL,
+					UserID text NOT NULL,
+					QuestionIDs text NOT NULL,
+					Started timestamp NOT NULL,
+					PRIMARY KEY (CommunityID, UserID)
+				);`,
+			},
+		},
+	})
+
+	return &QuizSessionRepository{db}, err
+}
+
+// Create adds a new QuizSession to the repository.
+func (r *QuizSessionRepository) Create(ctx context.Context, s model.QuizSession) error {
+	ids, err := json.Marshal(s.QuestionIDs)
+	if err != nil {
+		return fmt.Errorf("marshaling question ids: %w", err)
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, "INSERT INTO quiz_sessions (CommunityID, UserID, QuestionIDs, Started) VALUES (?, ?, ?, ?);",
+		s.CommunityID, s.UserID, string(ids), s.Started)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Delete removes the QuizSession of the specified user in the specified community.
+func (r *QuizSessionRepository) Delete(ctx context.Context, communityID string, userID string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM quiz_sessions WHERE CommunityID = ? AND UserID = ?;",
+		communityID, userID)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// Find returns the QuizSession of the specified user in the specified community.
+func (r *QuizSessionRepository) Find(ctx context.Context, communityID string, userID string) (model.QuizSession, error) {
+	var s model.QuizSession
+	var ids string
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT CommunityID, UserID, QuestionIDs, Started FROM quiz_sessions
+		WHERE CommunityID = ? AND UserID = ?;`, communityID, userID).Scan(&s.CommunityID, &s.UserID, &ids, &s.Started)
+
+	if err == sql.ErrNoRows {
+		return model.QuizSession{}, storage.ErrNotFound
+	} else if err != nil {
+		return model.QuizSession{}, err
+	}
+
+	if err := json.Unmarshal([]byte(ids), &s.QuestionIDs); err != nil {
+		return model.QuizSession{}, fmt.Errorf("unmarshaling question ids: %w", err)
+	}
+	return s, nil
+}
+
+// ReassignUser changes the UserID of every QuizSession of the user fromID to toID. Sessions in communities in which
+// toID already has a QuizSession are removed instead.
+func (r *QuizSessionRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE OR IGNORE quiz_sessions SET UserID = ? WHERE UserID = ?;", toID, fromID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "DELETE FROM quiz_sessions WHERE UserID = ?;", fromID)
+		return err
+	})
+}
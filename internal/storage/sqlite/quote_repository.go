@@ -3,16 +3,23 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/mattn/go-sqlite3"
 	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
 	"github.com/willbicks/epigram/internal/storage"
 )
 
 // QuoteRepository is an implementation of the storage.QuoteRepository interface which stores Quotes in a SQLite database.
 type QuoteRepository struct {
 	db *sql.DB
+	// fts is true if the SQLite library was compiled with FTS5 (using the sqlite_fts5 build tag), in which case
+	// quotes are searched using the quotes_fts full-text index. Otherwise, search falls back to LIKE comparisons.
+	fts bool
 }
 
 // NewQuoteRepository returns a new QuoteRepository which stores Quotes in the specified SQLite database.
@@ -31,39 +38,325 @@ func NewQuoteRepository(db *sql.DB, c *MigrationController) (*QuoteRepository, e
 				);`,
 			},
 		},
+		{
+			version: 2,
+			stmts: []string{
+				`ALTER TABLE quotes ADD COLUMN QuoteeID text NOT NULL DEFAULT '';`,
+				`CREATE INDEX quotes_quoteeid ON quotes (QuoteeID);`,
+			},
+		},
+		{
+			version: 3,
+			stmts: []string{
+				// Lines are stored as a JSON array of model.QuoteLine objects, which is empty for existing quotes
+				`ALTER TABLE quotes ADD COLUMN Lines text NOT NULL DEFAULT '[]';`,
+			},
+		},
+		{
+			version: 4,
+			stmts: []string{
+				`CREATE TABLE quote_tags (
+					QuoteID text NOT NULL,
+					Tag text NOT NULL,
+					PRIMARY KEY (QuoteID, Tag)
+				);`,
+				`CREATE INDEX quote_tags_tag ON quote_tags (Tag);`,
+			},
+		},
+		{
+			version: 5,
+			stmts: []string{
+				`ALTER TABLE quotes ADD COLUMN CommunityID text NOT NULL DEFAULT '` + model.DefaultCommunityID + `';`,
+				`CREATE INDEX quotes_communityid ON quotes (CommunityID);`,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &QuoteRepository{db: db}
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5');").Scan(&r.fts); err != nil {
+		return nil, fmt.Errorf("checking for fts5 support: %w", err)
+	}
+	if !r.fts {
+		return r, dropSearchTriggers(db)
+	}
+
+	// The full-text index is migrated separately from the quotes table, so that it can be created whenever a
+	// database is first opened by a build with FTS5 support.
+	err = c.migrateRepository(db, "quotesearch", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				// an index left behind by an earlier FTS5 build is stale, as it was not updated by builds without FTS5
+				`DROP TABLE IF EXISTS quotes_fts;`,
+				`CREATE VIRTUAL TABLE quotes_fts USING fts5(
+					ID UNINDEXED,
+					Quote,
+					Quotee,
+					Context
+				);`,
+				`CREATE TRIGGER quotes_fts_insert AFTER INSERT ON quotes BEGIN
+					INSERT INTO quotes_fts (ID, Quote, Quotee, Context) VALUES (new.ID, new.Quote, new.Quotee, new.Context);
+				END;`,
+				`CREATE TRIGGER quotes_fts_update AFTER UPDATE ON quotes BEGIN
+					UPDATE quotes_fts SET ID = new.ID, Quote = new.Quote, Quotee = new.Quotee, Context = new.Context WHERE ID = old.ID;
+				END;`,
+				`CREATE TRIGGER quotes_fts_delete AFTER DELETE ON quotes BEGIN
+					DELETE FROM quotes_fts WHERE ID = old.ID;
+				END;`,
+				`INSERT INTO quotes_fts (ID, Quote, Quotee, Context) SELECT ID, Quote, Quotee, Context FROM quotes;`,
+			},
+		},
 	})
 
-	return &QuoteRepository{db}, err
+	return r, err
+}
+
+// dropSearchTriggers removes the triggers which keep the quotes_fts index up to date, if the database was previously
+// opened by a build with FTS5 support, since they would otherwise cause every change to quotes to fail. The quotes_fts
+// table itself cannot be dropped without FTS5, so the quotesearch migrations are forgotten instead, such that the
+// index is rebuilt if the database is opened by a build with FTS5 support again.
+func dropSearchTriggers(db *sql.DB) error {
+	return withTx(context.Background(), db, func(tx *sql.Tx) error {
+		for _, stmt := range []string{
+			"DROP TRIGGER IF EXISTS quotes_fts_insert;",
+			"DROP TRIGGER IF EXISTS quotes_fts_update;",
+			"DROP TRIGGER IF EXISTS quotes_fts_delete;",
+			"DELETE FROM migrations WHERE repo = 'quotesearch';",
+		} {
+			if _, err := tx.Exec(stmt); err != nil {
+				return fmt.Errorf("dropping full-text search triggers: %w", err)
+			}
+		}
+		return nil
+	})
 }
 
 // Create adds a new Quote to the repository.
 func (r *QuoteRepository) Create(ctx context.Context, q model.Quote) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO quotes (ID, SubmitterID, Quotee, Context, Quote, Created) VALUES (?, ?, ?, ?, ?, ?);",
-		q.ID, q.SubmitterID, q.Quotee, q.Context, q.Quote, q.Created)
-
-	var sqliteErr sqlite3.Error
-	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
-		return storage.ErrAlreadyExists
+	lines, err := encodeLines(q.Lines)
+	if err != nil {
+		return err
 	}
-	return err
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO quotes (ID, CommunityID, SubmitterID, QuoteeID, Quotee, Context, Quote, Lines, Created) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);",
+			q.ID, q.CommunityID, q.SubmitterID, q.QuoteeID, q.Quotee, q.Context, q.Quote, lines, q.Created)
+
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+			return storage.ErrAlreadyExists
+		} else if err != nil {
+			return err
+		}
+
+		return setTags(ctx, tx, q.ID, q.Tags)
+	})
 }
 
 // Update updates an existing Quote in the repository.
 func (r *QuoteRepository) Update(ctx context.Context, q model.Quote) error {
-	result, err := r.db.ExecContext(ctx, "UPDATE quotes SET SubmitterID = ?, Quotee = ?, Context = ?, Quote = ?, Created = ? WHERE ID = ?;",
-		q.SubmitterID, q.Quotee, q.Context, q.Quote, q.Created, q.ID)
+	lines, err := encodeLines(q.Lines)
+	if err != nil {
+		return err
+	}
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "UPDATE quotes SET CommunityID = ?, SubmitterID = ?, QuoteeID = ?, Quotee = ?, Context = ?, Quote = ?, Lines = ?, Created = ? WHERE ID = ?;",
+			q.CommunityID, q.SubmitterID, q.QuoteeID, q.Quotee, q.Context, q.Quote, lines, q.Created, q.ID)
+		if err != nil {
+			return err
+		}
+
+		if i, _ := result.RowsAffected(); i == 0 {
+			return storage.ErrNotFound
+		}
+
+		return setTags(ctx, tx, q.ID, q.Tags)
+	})
+}
 
-	if i, _ := result.RowsAffected(); i == 0 {
-		return storage.ErrNotFound
+// setTags replaces the tags of the Quote with the provided ID.
+func setTags(ctx context.Context, tx *sql.Tx, quoteID string, tags []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM quote_tags WHERE QuoteID = ?;", quoteID); err != nil {
+		return err
+	}
+
+	for _, t := range tags {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO quote_tags (QuoteID, Tag) VALUES (?, ?);", quoteID, t); err != nil {
+			return fmt.Errorf("tagging quote: %w", err)
+		}
 	}
+
+	return nil
+}
+
+// Delete removes the Quote with the provided ID from the repository.
+func (r *QuoteRepository) Delete(ctx context.Context, id string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM quotes WHERE ID = ?;", id)
+		if err != nil {
+			return err
+		}
+
+		if i, _ := result.RowsAffected(); i == 0 {
+			return storage.ErrNotFound
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM quote_tags WHERE QuoteID = ?;", id)
+		return err
+	})
+}
+
+// ReassignSubmitter changes the SubmitterID of every Quote submitted by the user fromID to toID.
+func (r *QuoteRepository) ReassignSubmitter(ctx context.Context, fromID string, toID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE quotes SET SubmitterID = ? WHERE SubmitterID = ?;", toID, fromID)
 	return err
 }
 
+// speaksCond is a condition matching quotes in which the person whose ID is its argument speaks a line.
+const speaksCond = "EXISTS (SELECT 1 FROM json_each(q.Lines) WHERE json_extract(value, '$.SpeakerID') = ?)"
+
+// ReassignQuotee changes the QuoteeID of every Quote attributed to the person fromID, and the SpeakerID of every
+// line they said, to toID, and the corresponding Quotee and Speaker to the provided name. The Quote of each
+// conversation they spoke in is rewritten as its ConversationText.
+func (r *QuoteRepository) ReassignQuotee(ctx context.Context, fromID string, toID string, quotee string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE quotes SET QuoteeID = ?, Quotee = ? WHERE QuoteeID = ?;",
+			toID, quotee, fromID); err != nil {
+			return err
+		}
+
+		// lines are rewritten individually, as they are stored as JSON, along with the text of the conversation
+		rows, err := tx.QueryContext(ctx, "SELECT q.ID, q.Lines FROM quotes q WHERE "+speaksCond+";", fromID)
+		if err != nil {
+			return err
+		}
+		updated := make(map[string]model.Quote)
+		for rows.Next() {
+			var id, encoded string
+			if err := rows.Scan(&id, &encoded); err != nil {
+				rows.Close()
+				return err
+			}
+
+			lines, err := decodeLines(encoded)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			for i, l := range lines {
+				if l.SpeakerID == fromID {
+					lines[i].SpeakerID = toID
+					lines[i].Speaker = quotee
+				}
+			}
+
+			updated[id] = model.Quote{Lines: lines}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for id, q := range updated {
+			lines, err := encodeLines(q.Lines)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, "UPDATE quotes SET Lines = ?, Quote = ? WHERE ID = ?;",
+				lines, q.ConversationText(), id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// CountByQuoteeID returns the number of Quotes said by each person who has said at least one Quote, either as its
+// quotee or a speaker in it.
+func (r *QuoteRepository) CountByQuoteeID(ctx context.Context) (map[string]int, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT PersonID, COUNT(DISTINCT QuoteID) FROM (
+			SELECT ID AS QuoteID, QuoteeID AS PersonID FROM quotes
+			UNION ALL
+			SELECT q.ID, json_extract(l.value, '$.SpeakerID') FROM quotes q, json_each(q.Lines) l
+		) WHERE PersonID != '' GROUP BY PersonID;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var n int
+
+		if err := rows.Scan(&id, &n); err != nil {
+			return counts, err
+		}
+
+		counts[id] = n
+	}
+
+	return counts, rows.Err()
+}
+
+// inCommunityCond is a condition matching quote tags whose quote belongs to the community whose ID is its argument.
+const inCommunityCond = "QuoteID IN (SELECT ID FROM quotes WHERE CommunityID = ?)"
+
+// RenameTag replaces the tag from with the tag to on every Quote of the specified community tagged with it, merging
+// the tags on Quotes which are already tagged with both.
+func (r *QuoteRepository) RenameTag(ctx context.Context, communityID string, from string, to string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO quote_tags (QuoteID, Tag) SELECT QuoteID, ? FROM quote_tags WHERE Tag = ? AND "+inCommunityCond+";",
+			to, from, communityID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM quote_tags WHERE Tag = ? AND Tag != ? AND "+inCommunityCond+";",
+			from, to, communityID)
+		return err
+	})
+}
+
+// CountByTag returns the number of Quotes of the specified community tagged with each tag which is on at least one of
+// them.
+func (r *QuoteRepository) CountByTag(ctx context.Context, communityID string) (map[string]int, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT Tag, COUNT(*) FROM quote_tags WHERE "+inCommunityCond+" GROUP BY Tag;",
+		communityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var tag string
+		var n int
+
+		if err := rows.Scan(&tag, &n); err != nil {
+			return counts, err
+		}
+
+		counts[tag] = n
+	}
+
+	return counts, rows.Err()
+}
+
+// quoteColumns selects every column of a quote aliased as q, in the order read by scanQuote. Tags are selected as a
+// JSON array in alphabetical order.
+const quoteColumns = "q.ID, q.CommunityID, q.SubmitterID, q.QuoteeID, q.Quotee, q.Context, q.Quote, q.Lines, q.Created, " +
+	"(SELECT json_group_array(Tag) FROM (SELECT Tag FROM quote_tags WHERE QuoteID = q.ID ORDER BY Tag))"
+
 // FindByID returns a Quote with the provided ID.
 func (r *QuoteRepository) FindByID(ctx context.Context, id string) (model.Quote, error) {
-	var q model.Quote
-	err := r.db.QueryRowContext(ctx, "SELECT ID, SubmitterID, Quotee, Context, Quote, Created FROM quotes WHERE ID = ?;", id).Scan(
-		&q.ID, &q.SubmitterID, &q.Quotee, &q.Context, &q.Quote, &q.Created)
+	q, err := scanQuote(conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+quoteColumns+" FROM quotes q WHERE q.ID = ?;", id))
 
 	if err == sql.ErrNoRows {
 		return model.Quote{}, storage.ErrNotFound
@@ -72,9 +365,93 @@ func (r *QuoteRepository) FindByID(ctx context.Context, id string) (model.Quote,
 	return q, err
 }
 
-// FindAll returns all Quotes in the repository.
-func (r *QuoteRepository) FindAll(ctx context.Context) ([]model.Quote, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT ID, SubmitterID, Quotee, Context, Quote, Created FROM quotes;")
+// searchText is the text of a quote searched when FTS5 is unavailable, with a leading space, and the separators
+// which most commonly precede words replaced by spaces.
+var searchText = func() string {
+	text := "(' ' || q.Quote || ' ' || q.Quotee || ' ' || q.Context)"
+	for _, sep := range []string{"char(9)", "char(10)", "char(13)", "'-'", "'/'", "'('", `'"'`, "''''"} {
+		text = "replace(" + text + ", " + sep + ", ' ')"
+	}
+	return text
+}()
+
+// Query returns the Quotes in the repository matching the provided QuoteQuery, from newest to oldest.
+//
+// Timestamps are compared using julianday() so that ordering is independent of the time zone each Quote was stored in.
+func (r *QuoteRepository) Query(ctx context.Context, query service.QuoteQuery) ([]model.Quote, error) {
+	var conds []string
+	var args []any
+
+	if query.CommunityID != "" {
+		conds = append(conds, "q.CommunityID = ?")
+		args = append(args, query.CommunityID)
+	}
+	if query.After != nil {
+		conds = append(conds, "(julianday(q.Created) < julianday(?) OR (julianday(q.Created) = julianday(?) AND q.ID < ?))")
+		args = append(args, query.After.Created, query.After.Created, query.After.ID)
+	}
+	if !query.CreatedFrom.IsZero() {
+		conds = append(conds, "julianday(q.Created) >= julianday(?)")
+		args = append(args, query.CreatedFrom)
+	}
+	if !query.CreatedBefore.IsZero() {
+		conds = append(conds, "julianday(q.Created) < julianday(?)")
+		args = append(args, query.CreatedBefore)
+	}
+	if query.Quotee != "" {
+		conds = append(conds, "q.Quotee = ? COLLATE NOCASE")
+		args = append(args, query.Quotee)
+	}
+	if query.QuoteeID != "" {
+		conds = append(conds, "(q.QuoteeID = ? OR "+speaksCond+")")
+		args = append(args, query.QuoteeID, query.QuoteeID)
+	}
+	if query.SubmitterID != "" {
+		conds = append(conds, "q.SubmitterID = ?")
+		args = append(args, query.SubmitterID)
+	}
+	if query.Tag != "" {
+		conds = append(conds, "EXISTS (SELECT 1 FROM quote_tags t WHERE t.QuoteID = q.ID AND t.Tag = ?)")
+		args = append(args, query.Tag)
+	}
+
+	from := "quotes q"
+	if terms := storage.SearchTerms(query.Search); len(terms) > 0 {
+		if r.fts {
+			// each term is quoted to escape FTS5 syntax, and suffixed with * to perform a prefix query
+			match := make([]string, len(terms))
+			for i, t := range terms {
+				match[i] = `"` + t + `"*`
+			}
+
+			from = "quotes q JOIN quotes_fts f ON f.ID = q.ID"
+			conds = append(conds, "quotes_fts MATCH ?")
+			args = append(args, strings.Join(match, " "))
+		} else {
+			// terms only match the start of words, each of which is preceded by a space in searchText
+			for _, t := range terms {
+				conds = append(conds, searchText+" LIKE ?")
+				args = append(args, "% "+t+"%")
+			}
+		}
+	}
+
+	stmt := "SELECT " + quoteColumns + " FROM " + from
+	if len(conds) > 0 {
+		stmt += " WHERE " + strings.Join(conds, " AND ")
+	}
+	stmt += " ORDER BY julianday(q.Created) DESC, q.ID DESC"
+	if query.Limit > 0 {
+		stmt += " LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	return r.scanQuotes(ctx, stmt+";", args...)
+}
+
+// scanQuotes executes the provided query, which must select quoteColumns, and returns the resulting Quotes.
+func (r *QuoteRepository) scanQuotes(ctx context.Context, query string, args ...any) ([]model.Quote, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return []model.Quote{}, err
 	}
@@ -82,9 +459,7 @@ func (r *QuoteRepository) FindAll(ctx context.Context) ([]model.Quote, error) {
 
 	quotes := []model.Quote{}
 	for rows.Next() {
-		var q model.Quote
-
-		err := rows.Scan(&q.ID, &q.SubmitterID, &q.Quotee, &q.Context, &q.Quote, &q.Created)
+		q, err := scanQuote(rows)
 		if err != nil {
 			return quotes, err
 		}
@@ -92,5 +467,52 @@ func (r *QuoteRepository) FindAll(ctx context.Context) ([]model.Quote, error) {
 		quotes = append(quotes, q)
 	}
 
-	return quotes, err
+	return quotes, rows.Err()
+}
+
+// scanQuote reads a Quote from the provided row, which must contain quoteColumns, decoding its lines and tags.
+func scanQuote(row interface{ Scan(...any) error }) (model.Quote, error) {
+	var q model.Quote
+	var lines, tags string
+
+	err := row.Scan(&q.ID, &q.CommunityID, &q.SubmitterID, &q.QuoteeID, &q.Quotee, &q.Context, &q.Quote, &lines, &q.Created, &tags)
+	if err != nil {
+		return model.Quote{}, err
+	}
+
+	if q.Lines, err = decodeLines(lines); err != nil {
+		return model.Quote{}, err
+	}
+
+	if err := json.Unmarshal([]byte(tags), &q.Tags); err != nil {
+		return model.Quote{}, fmt.Errorf("decoding quote tags: %w", err)
+	}
+	if len(q.Tags) == 0 {
+		q.Tags = nil
+	}
+
+	return q, nil
+}
+
+// encodeLines returns the JSON representation of the provided lines of a quote.
+func encodeLines(lines []model.QuoteLine) (string, error) {
+	if lines == nil {
+		lines = []model.QuoteLine{}
+	}
+
+	b, err := json.Marshal(lines)
+	return string(b), err
+}
+
+// decodeLines returns the lines of a quote from their JSON representation, or nil if there are none.
+func decodeLines(encoded string) ([]model.QuoteLine, error) {
+	var lines []model.QuoteLine
+	if err := json.Unmarshal([]byte(encoded), &lines); err != nil {
+		return nil, fmt.Errorf("decoding quote lines: %w", err)
+	}
+
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	return lines, nil
 }
@@ -0,0 +1,137 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// ReactionRepository implements the service.ReactionRepository interface and stores Reactions in a SQLite database
+type ReactionRepository struct {
+	db *sql.DB
+}
+
+// NewReactionRepository returns a new ReactionRepository which stores Reactions in the provided SQLite database
+func NewReactionRepository(db *sql.DB, c *MigrationController) (*ReactionRepository, error) {
+	err := c.migrateRepository(db, "reaction", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				`CREATE TABLE reactions (
+					QuoteID text NOT NULL,
+					UserID text NOT NULL,
+					Emoji text NOT NULL,
+					Created timestamp NOT NULL,
+					PRIMARY KEY (QuoteID, UserID, Emoji)
+				);`,
+			},
+		},
+	})
+
+	return &ReactionRepository{db}, err
+}
+
+// Create adds a new Reaction to the repository.
+func (r *ReactionRepository) Create(ctx context.Context, re model.Reaction) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO reactions (QuoteID, UserID, Emoji, Created) VALUES (?, ?, ?, ?);",
+		re.QuoteID, re.UserID, re.Emoji, re.Created)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Delete removes the Reaction with the same QuoteID, UserID, and Emoji as re.
+func (r *ReactionRepository) Delete(ctx context.Context, re model.Reaction) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM reactions WHERE QuoteID = ? AND UserID = ? AND Emoji = ?;",
+		re.QuoteID, re.UserID, re.Emoji)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteByQuoteID removes all Reactions to the Quote with the provided ID.
+func (r *ReactionRepository) DeleteByQuoteID(ctx context.Context, quoteID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM reactions WHERE QuoteID = ?;", quoteID)
+	return err
+}
+
+// FindByQuoteIDs returns all Reactions to any of the Quotes with the provided IDs.
+func (r *ReactionRepository) FindByQuoteIDs(ctx context.Context, quoteIDs []string) ([]model.Reaction, error) {
+	reactions := []model.Reaction{}
+	if len(quoteIDs) == 0 {
+		return reactions, nil
+	}
+
+	args := make([]any, len(quoteIDs))
+	for i, id := range quoteIDs {
+		args[i] = id
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT QuoteID, UserID, Emoji, Created FROM reactions
+		WHERE QuoteID IN (?`+strings.Repeat(", ?", len(quoteIDs)-1)+`);`, args...)
+	if err != nil {
+		return reactions, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var re model.Reaction
+
+		if err := rows.Scan(&re.QuoteID, &re.UserID, &re.Emoji, &re.Created); err != nil {
+			return reactions, err
+		}
+
+		reactions = append(reactions, re)
+	}
+
+	return reactions, rows.Err()
+}
+
+// CountByQuote returns the number of Reactions to each Quote which has at least one Reaction.
+func (r *ReactionRepository) CountByQuote(ctx context.Context) (map[string]int, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT QuoteID, COUNT(*) FROM reactions GROUP BY QuoteID;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var n int
+
+		if err := rows.Scan(&id, &n); err != nil {
+			return counts, err
+		}
+
+		counts[id] = n
+	}
+
+	return counts, rows.Err()
+}
+
+// ReassignUser changes the UserID of every Reaction made by the user fromID to toID. Reactions which toID has
+// already made to the same Quote with the same emoji are removed instead.
+func (r *ReactionRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE OR IGNORE reactions SET UserID = ? WHERE UserID = ?;", toID, fromID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "DELETE FROM reactions WHERE UserID = ?;", fromID)
+		return err
+	})
+}
@@ -1,10 +1,15 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/willbicks/epigram/internal/model"
 	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
 	"github.com/willbicks/epigram/internal/storage/validate"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -77,3 +82,434 @@ func TestUserSessionRepository(t *testing.T) {
 		}
 	})
 }
+
+func TestAuditLogRepository(t *testing.T) {
+	validate.AuditLogRepository(t, func() (repo service.AuditLogRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewAuditLogRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create audit log repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestUserIdentityRepository(t *testing.T) {
+	validate.UserIdentityRepository(t, func() (repo service.UserIdentityRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewUserIdentityRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create user identity repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestAPITokenRepository(t *testing.T) {
+	validate.APITokenRepository(t, func() (repo service.APITokenRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewAPITokenRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create API token repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestWebhookDeliveryRepository(t *testing.T) {
+	validate.WebhookDeliveryRepository(t, func() (repo service.WebhookDeliveryRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewWebhookDeliveryRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create webhook delivery repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestChatLinkRepository(t *testing.T) {
+	validate.ChatLinkRepository(t, func() (repo service.ChatLinkRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewChatLinkRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create chat link repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestReactionRepository(t *testing.T) {
+	validate.ReactionRepository(t, func() (repo service.ReactionRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewReactionRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create reaction repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestCommentRepository(t *testing.T) {
+	validate.CommentRepository(t, func() (repo service.CommentRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewCommentRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create comment repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestPersonRepository(t *testing.T) {
+	validate.PersonRepository(t, func() (repo service.PersonRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewPersonRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create person repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestMembershipRepository(t *testing.T) {
+	validate.MembershipRepository(t, func() (repo service.MembershipRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewMembershipRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create membership repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestInviteRepository(t *testing.T) {
+	validate.InviteRepository(t, func() (repo service.InviteRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewInviteRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create invite repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestQuizSessionRepository(t *testing.T) {
+	validate.QuizSessionRepository(t, func() (repo service.QuizSessionRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewQuizSessionRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create quiz session repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestAccessRequestRepository(t *testing.T) {
+	validate.AccessRequestRepository(t, func() (repo service.AccessRequestRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewAccessRequestRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create access request repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestQuizAttemptRepository(t *testing.T) {
+	validate.QuizAttemptRepository(t, func() (repo service.QuizAttemptRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewQuizAttemptRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create quiz attempt repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestQuizQuestionRepository(t *testing.T) {
+	validate.QuizQuestionRepository(t, func() (repo service.QuizQuestionRepository, closer func()) {
+		mc := &MigrationController{}
+		db := makeSqliteTestDB(t)
+
+		repo, err := NewQuizQuestionRepository(db, mc)
+		if err != nil {
+			t.Fatalf("unable to create quiz question repository: %v", err)
+		}
+
+		return repo, func() {
+			err = db.Close()
+			if err != nil {
+				t.Fatalf("unable to close database: %v", err)
+			}
+		}
+	})
+}
+
+func TestUserRepository_MigrateMemberships(t *testing.T) {
+	db := makeSqliteTestDB(t)
+	defer db.Close()
+	// the database is only shared between statements made using the same connection
+	db.SetMaxOpenConns(1)
+
+	// create a users table as it was before memberships were introduced
+	stmts := []string{
+		"CREATE TABLE migrations (id INTEGER PRIMARY KEY AUTOINCREMENT, repo TEXT,  version INT);",
+		"INSERT INTO migrations (repo, version) VALUES ('user', 1);",
+		`CREATE TABLE users (ID text PRIMARY KEY, Name text NOT NULL, Email text NOT NULL, PictureURL text NOT NULL,
+			Created timestamp NOT NULL, QuizAttempts smallint NOT NULL, QuizPassed boolean NOT NULL,
+			Banned boolean NOT NULL, Admin boolean NOT NULL);`,
+		`INSERT INTO users VALUES ('user_id', 'Ficky Neldo', 'fn@example.com', '', '2022-03-11 12:00:00+00:00', 2, 1, 1, 1);`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("unable to create legacy users table: %v", err)
+		}
+	}
+
+	mc := &MigrationController{}
+	users, err := NewUserRepository(db, mc)
+	if err != nil {
+		t.Fatalf("unable to migrate user repository: %v", err)
+	}
+	memberships, err := NewMembershipRepository(db, mc)
+	if err != nil {
+		t.Fatalf("unable to create membership repository: %v", err)
+	}
+
+	u, err := users.FindByID(context.Background(), "user_id")
+	if err != nil {
+		t.Fatalf("find migrated user: %v", err)
+	}
+	if !u.Admin {
+		t.Errorf("migrated user should remain an admin")
+	}
+
+	m, err := memberships.Find(context.Background(), model.DefaultCommunityID, "user_id")
+	if err != nil {
+		t.Fatalf("find membership of migrated user: %v", err)
+	}
+	want := model.Membership{
+		CommunityID:  model.DefaultCommunityID,
+		UserID:       "user_id",
+		QuizPassed:   true,
+		QuizAttempts: 2,
+		Banned:       true,
+		Joined:       u.Created,
+	}
+	if !cmp.Equal(m, want) {
+		t.Errorf("got membership %v, want %v", m, want)
+	}
+}
+
+func TestQuoteRepository_DropSearchTriggers(t *testing.T) {
+	db := makeSqliteTestDB(t)
+	defer db.Close()
+	// the database is only shared between statements made using the same connection
+	db.SetMaxOpenConns(1)
+
+	mc := &MigrationController{}
+	repo, err := NewQuoteRepository(db, mc)
+	if err != nil {
+		t.Fatalf("unable to create quote repository: %v", err)
+	}
+	if repo.fts {
+		t.Skip("full-text index is kept by builds with FTS5")
+	}
+
+	// leave the triggers and migration of a full-text index as a build with FTS5 would, using a plain table in
+	// place of the virtual table so that they can be created without FTS5
+	stmts := []string{
+		"CREATE TABLE quotes_fts (ID text, Quote text, Quotee text, Context text);",
+		"CREATE TRIGGER quotes_fts_insert AFTER INSERT ON quotes BEGIN INSERT INTO quotes_fts (ID) VALUES (new.ID); END;",
+		"CREATE TRIGGER quotes_fts_update AFTER UPDATE ON quotes BEGIN UPDATE quotes_fts SET ID = new.ID WHERE ID = old.ID; END;",
+		"CREATE TRIGGER quotes_fts_delete AFTER DELETE ON quotes BEGIN DELETE FROM quotes_fts WHERE ID = old.ID; END;",
+		"INSERT INTO migrations (repo, version) VALUES ('quotesearch', 1);",
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("unable to create full-text index: %v", err)
+		}
+	}
+
+	if _, err := NewQuoteRepository(db, mc); err != nil {
+		t.Fatalf("unable to reopen quote repository: %v", err)
+	}
+
+	var triggers int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger';").Scan(&triggers); err != nil {
+		t.Fatalf("count triggers: %v", err)
+	}
+	if triggers != 0 {
+		t.Errorf("got %v triggers, want 0", triggers)
+	}
+
+	var version sql.NullInt32
+	if err := db.QueryRow("SELECT MAX(version) FROM migrations WHERE repo = 'quotesearch';").Scan(&version); err != nil {
+		t.Fatalf("select quotesearch version: %v", err)
+	}
+	if version.Valid {
+		t.Errorf("got quotesearch version %v, want none", version.Int32)
+	}
+}
+
+func TestTransactor(t *testing.T) {
+	db := makeSqliteTestDB(t)
+	defer db.Close()
+	// the database is only shared between statements made using the same connection
+	db.SetMaxOpenConns(1)
+
+	mc := &MigrationController{}
+	tokens, err := NewAPITokenRepository(db, mc)
+	if err != nil {
+		t.Fatalf("unable to create api token repository: %v", err)
+	}
+	quotes, err := NewQuoteRepository(db, mc)
+	if err != nil {
+		t.Fatalf("unable to create quote repository: %v", err)
+	}
+	tr := NewTransactor(db)
+
+	tok := model.APIToken{ID: "token_id", UserID: "user_id", Hash: "hash"}
+	q := model.Quote{ID: "quote_id", SubmitterID: "user_id", Tags: []string{"tag"}}
+	errFailed := errors.New("failed")
+
+	err = tr.InTransaction(context.Background(), func(ctx context.Context) error {
+		if err := tokens.Create(ctx, tok); err != nil {
+			return err
+		}
+		// quotes are created in their own transaction unless they are already in one
+		if err := quotes.Create(ctx, q); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if err != errFailed {
+		t.Errorf("failed transaction: got error %v, want %v", err, errFailed)
+	}
+	if _, err := tokens.FindByID(context.Background(), tok.ID); err != storage.ErrNotFound {
+		t.Errorf("find token after rollback: got error %v, want %v", err, storage.ErrNotFound)
+	}
+	if _, err := quotes.FindByID(context.Background(), q.ID); err != storage.ErrNotFound {
+		t.Errorf("find quote after rollback: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	err = tr.InTransaction(context.Background(), func(ctx context.Context) error {
+		if err := tokens.Create(ctx, tok); err != nil {
+			return err
+		}
+		return quotes.Create(ctx, q)
+	})
+	if err != nil {
+		t.Errorf("successful transaction: %v", err)
+	}
+	if _, err := tokens.FindByID(context.Background(), tok.ID); err != nil {
+		t.Errorf("find token after commit: %v", err)
+	}
+	if _, err := quotes.FindByID(context.Background(), q.ID); err != nil {
+		t.Errorf("find quote after commit: %v", err)
+	}
+}
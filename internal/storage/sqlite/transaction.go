@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+)
+
+// txKey is the context key of the transaction in which repositories should execute their statements.
+type txKey struct{}
+
+// querier executes statements against either a database or a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn returns the transaction on the context if there is one, or otherwise the provided database.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// withTx calls fn with the transaction on the context if there is one, leaving it to be committed by its owner.
+// Otherwise, fn is called with a new transaction, which is committed if fn succeeds, and rolled back if it fails.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(tx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Transactor implements the service.Transactor interface, running functions in transactions of a SQLite database
+// which are used by every repository stored in the same database.
+type Transactor struct {
+	db *sql.DB
+}
+
+// NewTransactor returns a new Transactor which runs functions in transactions of the provided SQLite database.
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{
+		db: db,
+	}
+}
+
+// InTransaction calls fn with a context on which every repository call is executed in a single transaction, which is
+// committed if fn succeeds, and rolled back if it fails. If ctx is already in a transaction, fn joins it.
+func (t *Transactor) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, t.db, func(tx *sql.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// UserIdentityRepository implements the service.UserIdentityRepository interface and stores UserIdentities in a
+// SQLite database
+type UserIdentityRepository struct {
+	db *sql.DB
+}
+
+// NewUserIdentityRepository returns a new UserIdentityRepository which stores UserIdentities in the provided SQLite
+// database
+func NewUserIdentityRepository(db *sql.DB, c *MigrationController) (*UserIdentityRepository, error) {
+	err := c.migrateRepository(db, "useridentity", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				`CREATE TABLE useridentities (
+					ID text PRIMARY KEY,
+					UserID text NOT NULL,
+					Domain text NOT NULL,
+					Email text NOT NULL,
+					Created timestamp NOT NULL
+				);`,
+				`CREATE INDEX useridentities_userid ON useridentities (UserID);`,
+			},
+		},
+	})
+
+	return &UserIdentityRepository{db}, err
+}
+
+// Create adds a new UserIdentity to the repository.
+func (r *UserIdentityRepository) Create(ctx context.Context, ui model.UserIdentity) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO useridentities (ID, UserID, Domain, Email, Created) VALUES (?, ?, ?, ?, ?);",
+		ui.ID, ui.UserID, ui.Domain, ui.Email, ui.Created)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// FindByID returns the UserIdentity with the provided ID.
+func (r *UserIdentityRepository) FindByID(ctx context.Context, id string) (model.UserIdentity, error) {
+	var ui model.UserIdentity
+	err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT ID, UserID, Domain, Email, Created FROM useridentities WHERE ID = ?;", id).Scan(
+		&ui.ID, &ui.UserID, &ui.Domain, &ui.Email, &ui.Created)
+
+	if err == sql.ErrNoRows {
+		return model.UserIdentity{}, storage.ErrNotFound
+	}
+	return ui, err
+}
+
+// FindByUserID returns all UserIdentities linked to the user with the provided ID, from oldest to newest.
+func (r *UserIdentityRepository) FindByUserID(ctx context.Context, userID string) ([]model.UserIdentity, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT ID, UserID, Domain, Email, Created FROM useridentities WHERE UserID = ?
+		ORDER BY julianday(Created), ID;`, userID)
+	if err != nil {
+		return []model.UserIdentity{}, err
+	}
+	defer rows.Close()
+
+	identities := []model.UserIdentity{}
+	for rows.Next() {
+		var ui model.UserIdentity
+
+		err := rows.Scan(&ui.ID, &ui.UserID, &ui.Domain, &ui.Email, &ui.Created)
+		if err != nil {
+			return identities, err
+		}
+
+		identities = append(identities, ui)
+	}
+
+	return identities, rows.Err()
+}
+
+// ReassignUser links every UserIdentity linked to the user fromID to the user toID instead.
+func (r *UserIdentityRepository) ReassignUser(ctx context.Context, fromID string, toID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE useridentities SET UserID = ? WHERE UserID = ?;", toID, fromID)
+	return err
+}
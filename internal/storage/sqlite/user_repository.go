@@ -34,6 +34,17 @@ func NewUserRepository(db *sql.DB, c *MigrationController) (*UserRepository, err
 				);`,
 			},
 		},
+		{
+			version: 2,
+			// quiz state and bans are moved to the user's membership of the default community
+			stmts: append(append([]string{}, createMembershipsTable...),
+				`INSERT OR IGNORE INTO memberships (CommunityID, UserID, QuizPassed, QuizAttempts, Banned, Admin, Joined)
+					SELECT '`+model.DefaultCommunityID+`', ID, QuizPassed, QuizAttempts, Banned, 0, Created FROM users;`,
+				`ALTER TABLE users DROP COLUMN QuizAttempts;`,
+				`ALTER TABLE users DROP COLUMN QuizPassed;`,
+				`ALTER TABLE users DROP COLUMN Banned;`,
+			),
+		},
 	})
 
 	return &UserRepository{db}, err
@@ -41,8 +52,8 @@ func NewUserRepository(db *sql.DB, c *MigrationController) (*UserRepository, err
 
 // Create adds a new User to the repository.
 func (r *UserRepository) Create(ctx context.Context, u model.User) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO users (ID, Name, Email, PictureURL, Created, QuizAttempts, QuizPassed, Banned, Admin) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);",
-		u.ID, u.Name, u.Email, u.PictureURL, u.Created, u.QuizAttempts, u.QuizPassed, u.Banned, u.Admin)
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO users (ID, Name, Email, PictureURL, Created, Admin) VALUES (?, ?, ?, ?, ?, ?);",
+		u.ID, u.Name, u.Email, u.PictureURL, u.Created, u.Admin)
 
 	var sqliteErr sqlite3.Error
 	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
@@ -53,8 +64,8 @@ func (r *UserRepository) Create(ctx context.Context, u model.User) error {
 
 // Update updates an existing User in the repository.
 func (r *UserRepository) Update(ctx context.Context, u model.User) error {
-	result, err := r.db.ExecContext(ctx, "UPDATE users SET Name = ?, Email = ?, PictureURL = ?, Created = ?, QuizAttempts = ?, QuizPassed = ?, Banned = ?, Admin = ? WHERE ID = ?;",
-		u.Name, u.Email, u.PictureURL, u.Created, u.QuizAttempts, u.QuizPassed, u.Banned, u.Admin, u.ID)
+	result, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE users SET Name = ?, Email = ?, PictureURL = ?, Created = ?, Admin = ? WHERE ID = ?;",
+		u.Name, u.Email, u.PictureURL, u.Created, u.Admin, u.ID)
 
 	if i, _ := result.RowsAffected(); i == 0 {
 		return storage.ErrNotFound
@@ -62,11 +73,24 @@ func (r *UserRepository) Update(ctx context.Context, u model.User) error {
 	return err
 }
 
+// Delete removes the User with the provided ID from the repository.
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM users WHERE ID = ?;", id)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
 // FindByID returns the User with the provided ID.
 func (r *UserRepository) FindByID(ctx context.Context, id string) (model.User, error) {
 	var u model.User
-	err := r.db.QueryRowContext(ctx, "SELECT ID, Name, Email, PictureURL, Created, QuizAttempts, QuizPassed, Banned, Admin FROM users WHERE ID = ?;", id).Scan(
-		&u.ID, &u.Name, &u.Email, &u.PictureURL, &u.Created, &u.QuizAttempts, &u.QuizPassed, &u.Banned, &u.Admin)
+	err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT ID, Name, Email, PictureURL, Created, Admin FROM users WHERE ID = ?;", id).Scan(
+		&u.ID, &u.Name, &u.Email, &u.PictureURL, &u.Created, &u.Admin)
 
 	if err == sql.ErrNoRows {
 		return model.User{}, storage.ErrNotFound
@@ -77,7 +101,7 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (model.User, e
 
 // FindAll returns all Users in the repository.
 func (r *UserRepository) FindAll(ctx context.Context) ([]model.User, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT ID, Name, Email, PictureURL, Created, QuizAttempts, QuizPassed, Banned, Admin FROM users;")
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT ID, Name, Email, PictureURL, Created, Admin FROM users;")
 	if err != nil {
 		return []model.User{}, err
 	}
@@ -87,7 +111,7 @@ func (r *UserRepository) FindAll(ctx context.Context) ([]model.User, error) {
 	for rows.Next() {
 		var u model.User
 
-		err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.PictureURL, &u.Created, &u.QuizAttempts, &u.QuizPassed, &u.Banned, &u.Admin)
+		err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.PictureURL, &u.Created, &u.Admin)
 		if err != nil {
 			return users, err
 		}
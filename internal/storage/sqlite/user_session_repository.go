@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/mattn/go-sqlite3"
 	"github.com/willbicks/epigram/internal/model"
@@ -30,6 +31,12 @@ func NewUserSessionRepository(db *sql.DB, c *MigrationController) (*UserSessionR
 				);`,
 			},
 		},
+		{
+			version: 2,
+			stmts: []string{
+				`CREATE INDEX usersessions_userid ON usersessions (UserID);`,
+			},
+		},
 	})
 
 	return &UserSessionRepository{db}, err
@@ -37,7 +44,7 @@ func NewUserSessionRepository(db *sql.DB, c *MigrationController) (*UserSessionR
 
 // Create adds a new UserSession to the repository.
 func (r *UserSessionRepository) Create(ctx context.Context, us model.UserSession) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO usersessions (ID, UserID, Created, Expires, IP) VALUES (?, ?, ?, ?, ?);",
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO usersessions (ID, UserID, Created, Expires, IP) VALUES (?, ?, ?, ?, ?);",
 		us.ID, us.UserID, us.Created, us.Expires, us.IP)
 
 	var sqliteErr sqlite3.Error
@@ -47,10 +54,24 @@ func (r *UserSessionRepository) Create(ctx context.Context, us model.UserSession
 	return err
 }
 
+// Update updates an existing UserSession in the repository.
+func (r *UserSessionRepository) Update(ctx context.Context, us model.UserSession) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE usersessions SET UserID = ?, Created = ?, Expires = ?, IP = ? WHERE ID = ?;",
+		us.UserID, us.Created, us.Expires, us.IP, us.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
 // FindByID returns the UserSession with the provided ID
 func (r *UserSessionRepository) FindByID(ctx context.Context, id string) (model.UserSession, error) {
 	var us model.UserSession
-	err := r.db.QueryRowContext(ctx, "SELECT ID, UserID, Created, Expires, IP FROM usersessions WHERE ID = ?;", id).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT ID, UserID, Created, Expires, IP FROM usersessions WHERE ID = ?;", id).Scan(
 		&us.ID, &us.UserID, &us.Created, &us.Expires, &us.IP)
 
 	if err == sql.ErrNoRows {
@@ -58,3 +79,58 @@ func (r *UserSessionRepository) FindByID(ctx context.Context, id string) (model.
 	}
 	return us, err
 }
+
+// Delete removes the UserSession with the provided ID.
+func (r *UserSessionRepository) Delete(ctx context.Context, id string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM usersessions WHERE ID = ?;", id)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// FindByUserID returns all UserSessions belonging to the user with the provided ID, from newest to oldest.
+func (r *UserSessionRepository) FindByUserID(ctx context.Context, userID string) ([]model.UserSession, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT ID, UserID, Created, Expires, IP FROM usersessions WHERE UserID = ?
+		ORDER BY julianday(Created) DESC, ID DESC;`, userID)
+	if err != nil {
+		return []model.UserSession{}, err
+	}
+	defer rows.Close()
+
+	sessions := []model.UserSession{}
+	for rows.Next() {
+		var us model.UserSession
+
+		err := rows.Scan(&us.ID, &us.UserID, &us.Created, &us.Expires, &us.IP)
+		if err != nil {
+			return sessions, err
+		}
+
+		sessions = append(sessions, us)
+	}
+
+	return sessions, rows.Err()
+}
+
+// DeleteByUserID removes all UserSessions belonging to the user with the provided ID.
+func (r *UserSessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM usersessions WHERE UserID = ?;", userID)
+	return err
+}
+
+// DeleteExpired removes all UserSessions which have expired relative to the provided time, and returns the number of
+// sessions removed.
+func (r *UserSessionRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM usersessions WHERE julianday(Expires) < julianday(?);", now)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// WebhookDeliveryRepository implements the service.WebhookDeliveryRepository interface and stores WebhookDeliveries
+// in a SQLite database
+type WebhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookDeliveryRepository returns a new WebhookDeliveryRepository which stores WebhookDeliveries in the provided
+// SQLite database
+func NewWebhookDeliveryRepository(db *sql.DB, c *MigrationController) (*WebhookDeliveryRepository, error) {
+	err := c.migrateRepository(db, "webhookdelivery", []migration{
+		{
+			version: 1,
+			stmts: []string{
+				`CREATE TABLE webhookdeliveries (
+					ID text PRIMARY KEY,
+					Webhook text NOT NULL,
+					Payload text NOT NULL,
+					Attempts integer NOT NULL,
+					NextAttempt timestamp NOT NULL,
+					LastError text NOT NULL,
+					Created timestamp NOT NULL
+				);`,
+				`CREATE INDEX webhookdeliveries_nextattempt ON webhookdeliveries (NextAttempt);`,
+			},
+		},
+	})
+
+	return &WebhookDeliveryRepository{db}, err
+}
+
+// Create adds a new WebhookDelivery to the repository.
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, d model.WebhookDelivery) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO webhookdeliveries (ID, Webhook, Payload, Attempts, NextAttempt,
+		LastError, Created) VALUES (?, ?, ?, ?, ?, ?, ?);`,
+		d.ID, d.Webhook, d.Payload, d.Attempts, d.NextAttempt, d.LastError, d.Created)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// Update replaces an existing WebhookDelivery with the same ID.
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, d model.WebhookDelivery) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE webhookdeliveries SET Webhook = ?, Payload = ?, Attempts = ?,
+		NextAttempt = ?, LastError = ?, Created = ? WHERE ID = ?;`,
+		d.Webhook, d.Payload, d.Attempts, d.NextAttempt, d.LastError, d.Created, d.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the WebhookDelivery with the provided ID.
+func (r *WebhookDeliveryRepository) Delete(ctx context.Context, id string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM webhookdeliveries WHERE ID = ?;", id)
+	if err != nil {
+		return err
+	}
+
+	if i, _ := result.RowsAffected(); i == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// FindDue returns up to limit WebhookDeliveries whose NextAttempt is at or before now, ordered by NextAttempt, with
+// ties broken by ID.
+func (r *WebhookDeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT ID, Webhook, Payload, Attempts, NextAttempt, LastError, Created
+		FROM webhookdeliveries WHERE julianday(NextAttempt) <= julianday(?)
+		ORDER BY julianday(NextAttempt), ID LIMIT ?;`, now, limit)
+	if err != nil {
+		return []model.WebhookDelivery{}, err
+	}
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+	for rows.Next() {
+		var d model.WebhookDelivery
+
+		err := rows.Scan(&d.ID, &d.Webhook, &d.Payload, &d.Attempts, &d.NextAttempt, &d.LastError, &d.Created)
+		if err != nil {
+			return deliveries, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
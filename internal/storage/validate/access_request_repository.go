@@ -0,0 +1,171 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// AccessRequestRepository validates a type implementing the AccessRequestRepository interface
+func AccessRequestRepository(t *testing.T, repoFactory func() (repo service.AccessRequestRepository, close func())) {
+	t.Run("Create_Update_Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		accessRequestRepository_Create_Update_Delete(t, repo)
+	})
+
+	t.Run("FindByCommunityID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		accessRequestRepository_FindByCommunityID(t, repo)
+	})
+
+	t.Run("ReassignUser", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		accessRequestRepository_ReassignUser(t, repo)
+	})
+}
+
+func accessRequestRepository_Create_Update_Delete(t *testing.T, repo service.AccessRequestRepository) {
+	r := model.AccessRequest{
+		CommunityID: "c1",
+		UserID:      "user_id",
+		Message:     "I'm Ficky, from the third floor.",
+		Status:      model.AccessRequestPending,
+		Created:     time.Now(),
+	}
+
+	if _, err := repo.Find(context.Background(), r.CommunityID, r.UserID); err != storage.ErrNotFound {
+		t.Errorf("find request before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Update(context.Background(), r); err != storage.ErrNotFound {
+		t.Errorf("update request before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), r); err != nil {
+		t.Errorf("create request: %v", err)
+	}
+
+	got, err := repo.Find(context.Background(), r.CommunityID, r.UserID)
+	if err != nil {
+		t.Errorf("find request: %v", err)
+	}
+	if !cmp.Equal(got, r) {
+		t.Errorf("got request %v, want %v", got, r)
+	}
+
+	if err := repo.Create(context.Background(), r); err != storage.ErrAlreadyExists {
+		t.Errorf("create request again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	r.Status = model.AccessRequestDenied
+	r.Resolved = time.Now()
+	r.ResolverID = "admin_id"
+	if err := repo.Update(context.Background(), r); err != nil {
+		t.Errorf("update request: %v", err)
+	}
+
+	got, err = repo.Find(context.Background(), r.CommunityID, r.UserID)
+	if err != nil {
+		t.Errorf("find updated request: %v", err)
+	}
+	if !cmp.Equal(got, r) {
+		t.Errorf("got updated request %v, want %v", got, r)
+	}
+
+	if err := repo.Delete(context.Background(), r.CommunityID, r.UserID); err != nil {
+		t.Errorf("delete request: %v", err)
+	}
+
+	if _, err := repo.Find(context.Background(), r.CommunityID, r.UserID); err != storage.ErrNotFound {
+		t.Errorf("find request after delete: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Delete(context.Background(), r.CommunityID, r.UserID); err != storage.ErrNotFound {
+		t.Errorf("delete request again: got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func accessRequestRepository_FindByCommunityID(t *testing.T, repo service.AccessRequestRepository) {
+	now := time.Now()
+	requests := []model.AccessRequest{
+		{CommunityID: "c1", UserID: "newer", Status: model.AccessRequestPending, Created: now},
+		{CommunityID: "c1", UserID: "older", Status: model.AccessRequestPending, Created: now.Add(-time.Hour)},
+		{CommunityID: "c1", UserID: "denied", Status: model.AccessRequestDenied, Created: now.Add(-2 * time.Hour)},
+		{CommunityID: "c2", UserID: "older", Status: model.AccessRequestPending, Created: now.Add(-3 * time.Hour)},
+	}
+	for _, r := range requests {
+		if err := repo.Create(context.Background(), r); err != nil {
+			t.Fatalf("create request: %v", err)
+		}
+	}
+
+	got, err := repo.FindByCommunityID(context.Background(), "c1", model.AccessRequestPending)
+	if err != nil {
+		t.Errorf("find pending requests: %v", err)
+	}
+	want := []model.AccessRequest{requests[1], requests[0]}
+	if !cmp.Equal(got, want) {
+		t.Errorf("got pending requests %v, want %v", got, want)
+	}
+
+	got, err = repo.FindByCommunityID(context.Background(), "c3", model.AccessRequestPending)
+	if err != nil {
+		t.Errorf("find requests of community without any: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d requests of community without any, want 0", len(got))
+	}
+}
+
+func accessRequestRepository_ReassignUser(t *testing.T, repo service.AccessRequestRepository) {
+	now := time.Now()
+	requests := []model.AccessRequest{
+		{CommunityID: "c1", UserID: "user_a", Status: model.AccessRequestPending, Created: now},
+		{CommunityID: "c1", UserID: "user_b", Status: model.AccessRequestPending, Created: now.Add(-time.Hour)},
+		{CommunityID: "c2", UserID: "user_a", Status: model.AccessRequestPending, Created: now},
+		{CommunityID: "c2", UserID: "user_c", Status: model.AccessRequestDenied, Created: now, Resolved: now, ResolverID: "user_a"},
+	}
+	for _, r := range requests {
+		if err := repo.Create(context.Background(), r); err != nil {
+			t.Fatalf("create request: %v", err)
+		}
+	}
+
+	if err := repo.ReassignUser(context.Background(), "user_a", "user_b"); err != nil {
+		t.Errorf("reassign requests of user_a: %v", err)
+	}
+
+	// the request user_b already made to c1 is kept, rather than replaced
+	if got, err := repo.Find(context.Background(), "c1", "user_b"); err != nil || !cmp.Equal(got, requests[1]) {
+		t.Errorf("got request of user_b to c1 %v (error %v), want %v", got, err, requests[1])
+	}
+
+	want := requests[2]
+	want.UserID = "user_b"
+	if got, err := repo.Find(context.Background(), "c2", "user_b"); err != nil || !cmp.Equal(got, want) {
+		t.Errorf("got request of user_b to c2 %v (error %v), want %v", got, err, want)
+	}
+
+	for _, c := range []string{"c1", "c2"} {
+		if _, err := repo.Find(context.Background(), c, "user_a"); err != storage.ErrNotFound {
+			t.Errorf("find request of user_a to %v after reassign: got error %v, want %v", c, err, storage.ErrNotFound)
+		}
+	}
+
+	want = requests[3]
+	want.ResolverID = "user_b"
+	if got, err := repo.Find(context.Background(), "c2", "user_c"); err != nil || !cmp.Equal(got, want) {
+		t.Errorf("got request resolved by user_a %v (error %v), want %v", got, err, want)
+	}
+}
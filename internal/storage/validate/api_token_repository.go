@@ -0,0 +1,152 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// APITokenRepository validates a type implementing the APITokenRepository interface
+func APITokenRepository(t *testing.T, repoFactory func() (repo service.APITokenRepository, close func())) {
+	t.Run("Create_Find_Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		apiTokenRepository_Create_Find_Delete(t, repo)
+	})
+
+	t.Run("FindByUserID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		apiTokenRepository_FindByUserID(t, repo)
+	})
+
+	t.Run("ReassignUser", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		apiTokenRepository_ReassignUser(t, repo)
+	})
+}
+
+func apiTokenRepository_Create_Find_Delete(t *testing.T, repo service.APITokenRepository) {
+	tok := model.APIToken{
+		ID:          "token_id",
+		UserID:      "user_id",
+		CommunityID: "community_id",
+		Name:        "CI",
+		Hash:        "abcdef0123456789",
+		Created:     time.Now(),
+	}
+
+	if _, err := repo.FindByHash(context.Background(), tok.Hash); err != storage.ErrNotFound {
+		t.Errorf("find token before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), tok); err != nil {
+		t.Errorf("create token: %v", err)
+	}
+
+	got, err := repo.FindByID(context.Background(), tok.ID)
+	if err != nil {
+		t.Errorf("find token by id: %v", err)
+	}
+	if !cmp.Equal(got, tok) {
+		t.Errorf("got token %v, want %v", got, tok)
+	}
+
+	got, err = repo.FindByHash(context.Background(), tok.Hash)
+	if err != nil {
+		t.Errorf("find token by hash: %v", err)
+	}
+	if !cmp.Equal(got, tok) {
+		t.Errorf("got token %v, want %v", got, tok)
+	}
+
+	if err := repo.Create(context.Background(), tok); err != storage.ErrAlreadyExists {
+		t.Errorf("create token again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	if err := repo.Delete(context.Background(), tok.ID); err != nil {
+		t.Errorf("delete token: %v", err)
+	}
+
+	if _, err := repo.FindByHash(context.Background(), tok.Hash); err != storage.ErrNotFound {
+		t.Errorf("find token after delete: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Delete(context.Background(), tok.ID); err != storage.ErrNotFound {
+		t.Errorf("delete token again: got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func apiTokenRepository_FindByUserID(t *testing.T, repo service.APITokenRepository) {
+	now := time.Now()
+	tokens := []model.APIToken{
+		{ID: "token_1", UserID: "user_a", Name: "old", Hash: "hash_1", Created: now.Add(-time.Hour)},
+		{ID: "token_2", UserID: "user_a", Name: "new", Hash: "hash_2", Created: now},
+		{ID: "token_3", UserID: "user_b", Name: "other", Hash: "hash_3", Created: now},
+	}
+	for _, tok := range tokens {
+		if err := repo.Create(context.Background(), tok); err != nil {
+			t.Errorf("create token %v: %v", tok.ID, err)
+		}
+	}
+
+	got, err := repo.FindByUserID(context.Background(), "user_a")
+	if err != nil {
+		t.Errorf("find tokens of user_a: %v", err)
+	}
+	if want := []model.APIToken{tokens[1], tokens[0]}; !cmp.Equal(got, want) {
+		t.Errorf("got tokens %v, want %v", got, want)
+	}
+
+	got, err = repo.FindByUserID(context.Background(), "user_c")
+	if err != nil {
+		t.Errorf("find tokens of user_c: %v", err)
+	}
+	if !cmp.Equal(got, []model.APIToken{}) {
+		t.Errorf("user_c should have no tokens, got %v", got)
+	}
+}
+
+func apiTokenRepository_ReassignUser(t *testing.T, repo service.APITokenRepository) {
+	now := time.Now()
+	tokens := []model.APIToken{
+		{ID: "token_1", UserID: "user_a", Name: "old", Hash: "hash_1", Created: now.Add(-time.Hour)},
+		{ID: "token_2", UserID: "user_b", Name: "new", Hash: "hash_2", Created: now},
+	}
+	for _, tok := range tokens {
+		if err := repo.Create(context.Background(), tok); err != nil {
+			t.Errorf("create token %v: %v", tok.ID, err)
+		}
+	}
+
+	if err := repo.ReassignUser(context.Background(), "user_a", "user_b"); err != nil {
+		t.Errorf("reassign tokens of user_a: %v", err)
+	}
+
+	got, err := repo.FindByUserID(context.Background(), "user_a")
+	if err != nil {
+		t.Errorf("find tokens of user_a after reassign: %v", err)
+	}
+	if !cmp.Equal(got, []model.APIToken{}) {
+		t.Errorf("user_a should have no tokens after reassign, got %v", got)
+	}
+
+	got, err = repo.FindByUserID(context.Background(), "user_b")
+	if err != nil {
+		t.Errorf("find tokens of user_b: %v", err)
+	}
+	tokens[0].UserID = "user_b"
+	if want := []model.APIToken{tokens[1], tokens[0]}; !cmp.Equal(got, want) {
+		t.Errorf("got tokens %v, want %v", got, want)
+	}
+}
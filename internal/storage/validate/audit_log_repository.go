@@ -0,0 +1,95 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// AuditLogRepository validates a type implementing the AuditLogRepository interface
+func AuditLogRepository(t *testing.T, repoFactory func() (repo service.AuditLogRepository, close func())) {
+	t.Run("Create_Query", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		auditLogRepository_Create_Query(t, repo)
+	})
+}
+
+func auditLogRepository_Create_Query(t *testing.T, repo service.AuditLogRepository) {
+	got, err := repo.Query(context.Background(), service.AuditLogQuery{})
+	if err != nil {
+		t.Errorf("query empty repo: %v", err)
+	}
+	if !cmp.Equal(got, []model.AuditLogEntry{}) {
+		t.Errorf("query empty repo, got %v, want empty", got)
+	}
+
+	now := time.Now()
+	ban := model.AuditLogEntry{
+		ID:       "a001",
+		ActorID:  "admin_id",
+		Action:   model.AuditBanUser,
+		TargetID: "user_id",
+		Details:  "Ficky Neldo",
+		IP:       "192.168.0.1",
+		Created:  now.Add(-2 * time.Hour),
+	}
+	unban := model.AuditLogEntry{
+		ID:       "a002",
+		ActorID:  "admin_id2",
+		Action:   model.AuditUnbanUser,
+		TargetID: "user_id",
+		Details:  "Ficky Neldo",
+		IP:       "24.197.123.1",
+		Created:  now.Add(-1 * time.Hour),
+	}
+	deleteQuote := model.AuditLogEntry{
+		ID:       "a003",
+		ActorID:  "admin_id",
+		Action:   model.AuditDeleteQuote,
+		TargetID: "quote_id",
+		Details:  "AJBR: I'm a quote",
+		IP:       "192.168.0.1",
+		Created:  now,
+	}
+	for _, e := range []model.AuditLogEntry{ban, deleteQuote, unban} {
+		if err := repo.Create(context.Background(), e); err != nil {
+			t.Errorf("create entry %v: %v", e.ID, err)
+		}
+	}
+
+	if err := repo.Create(context.Background(), ban); err != storage.ErrAlreadyExists {
+		t.Errorf("create duplicate entry should return ErrAlreadyExists, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query service.AuditLogQuery
+		want  []model.AuditLogEntry
+	}{
+		{"all newest first", service.AuditLogQuery{}, []model.AuditLogEntry{deleteQuote, unban, ban}},
+		{"actor", service.AuditLogQuery{ActorID: "admin_id"}, []model.AuditLogEntry{deleteQuote, ban}},
+		{"action", service.AuditLogQuery{Action: model.AuditUnbanUser}, []model.AuditLogEntry{unban}},
+		{"target", service.AuditLogQuery{TargetID: "user_id"}, []model.AuditLogEntry{unban, ban}},
+		{"combined", service.AuditLogQuery{ActorID: "admin_id", TargetID: "user_id"}, []model.AuditLogEntry{ban}},
+		{"limit", service.AuditLogQuery{Limit: 1}, []model.AuditLogEntry{deleteQuote}},
+		{"no matches", service.AuditLogQuery{Action: model.AuditPromoteUser}, []model.AuditLogEntry{}},
+	}
+
+	for _, tt := range tests {
+		got, err := repo.Query(context.Background(), tt.query)
+		if err != nil {
+			t.Errorf("query %v: %v", tt.name, err)
+		}
+		if !cmp.Equal(got, tt.want) {
+			t.Errorf("query %v, got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// ChatLinkRepository validates a type implementing the ChatLinkRepository interface
+func ChatLinkRepository(t *testing.T, repoFactory func() (repo service.ChatLinkRepository, close func())) {
+	t.Run("Create_FindByID_Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		chatLinkRepository_Create_FindByID_Delete(t, repo)
+	})
+
+	t.Run("FindByUserID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		chatLinkRepository_FindByUserID(t, repo)
+	})
+
+	t.Run("ReassignUser", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		chatLinkRepository_ReassignUser(t, repo)
+	})
+}
+
+func chatLinkRepository_Create_FindByID_Delete(t *testing.T, repo service.ChatLinkRepository) {
+	l := model.ChatLink{
+		ID:           "slack:T123:U456",
+		Platform:     "slack",
+		TeamID:       "T123",
+		ChatUserID:   "U456",
+		ChatUserName: "jross",
+		UserID:       "user_id",
+		Created:      time.Now(),
+	}
+
+	if _, err := repo.FindByID(context.Background(), l.ID); err != storage.ErrNotFound {
+		t.Errorf("find link before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), l); err != nil {
+		t.Errorf("create link: %v", err)
+	}
+
+	got, err := repo.FindByID(context.Background(), l.ID)
+	if err != nil {
+		t.Errorf("find link: %v", err)
+	}
+	if !cmp.Equal(got, l) {
+		t.Errorf("got link %v, want %v", got, l)
+	}
+
+	if err := repo.Create(context.Background(), l); err != storage.ErrAlreadyExists {
+		t.Errorf("create link again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	if err := repo.Delete(context.Background(), l.ID); err != nil {
+		t.Errorf("delete link: %v", err)
+	}
+
+	if _, err := repo.FindByID(context.Background(), l.ID); err != storage.ErrNotFound {
+		t.Errorf("find link after delete: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Delete(context.Background(), l.ID); err != storage.ErrNotFound {
+		t.Errorf("delete link again: got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func chatLinkRepository_FindByUserID(t *testing.T, repo service.ChatLinkRepository) {
+	now := time.Now()
+	links := []model.ChatLink{
+		{ID: "slack:T1:U1", Platform: "slack", TeamID: "T1", ChatUserID: "U1", UserID: "user_a", Created: now},
+		{ID: "mattermost:T2:U2", Platform: "mattermost", TeamID: "T2", ChatUserID: "U2", UserID: "user_a", Created: now.Add(-time.Hour)},
+		{ID: "slack:T1:U3", Platform: "slack", TeamID: "T1", ChatUserID: "U3", UserID: "user_b", Created: now},
+	}
+	for _, l := range links {
+		if err := repo.Create(context.Background(), l); err != nil {
+			t.Errorf("create link %v: %v", l.ID, err)
+		}
+	}
+
+	got, err := repo.FindByUserID(context.Background(), "user_a")
+	if err != nil {
+		t.Errorf("find links of user_a: %v", err)
+	}
+	if want := []model.ChatLink{links[1], links[0]}; !cmp.Equal(got, want) {
+		t.Errorf("got links %v, want %v", got, want)
+	}
+
+	got, err = repo.FindByUserID(context.Background(), "user_c")
+	if err != nil {
+		t.Errorf("find links of user_c: %v", err)
+	}
+	if !cmp.Equal(got, []model.ChatLink{}) {
+		t.Errorf("user_c should have no links, got %v", got)
+	}
+}
+
+func chatLinkRepository_ReassignUser(t *testing.T, repo service.ChatLinkRepository) {
+	now := time.Now()
+	links := []model.ChatLink{
+		{ID: "slack:T1:U1", Platform: "slack", TeamID: "T1", ChatUserID: "U1", UserID: "user_a", Created: now.Add(-time.Hour)},
+		{ID: "slack:T1:U2", Platform: "slack", TeamID: "T1", ChatUserID: "U2", UserID: "user_b", Created: now},
+	}
+	for _, l := range links {
+		if err := repo.Create(context.Background(), l); err != nil {
+			t.Errorf("create link %v: %v", l.ID, err)
+		}
+	}
+
+	if err := repo.ReassignUser(context.Background(), "user_a", "user_b"); err != nil {
+		t.Errorf("reassign links of user_a: %v", err)
+	}
+
+	got, err := repo.FindByUserID(context.Background(), "user_a")
+	if err != nil {
+		t.Errorf("find links of user_a after reassign: %v", err)
+	}
+	if !cmp.Equal(got, []model.ChatLink{}) {
+		t.Errorf("user_a should have no links after reassign, got %v", got)
+	}
+
+	got, err = repo.FindByUserID(context.Background(), "user_b")
+	if err != nil {
+		t.Errorf("find links of user_b: %v", err)
+	}
+	links[0].UserID = "user_b"
+	if !cmp.Equal(got, links) {
+		t.Errorf("got links %v, want %v", got, links)
+	}
+}
@@ -0,0 +1,167 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// CommentRepository validates a type implementing the CommentRepository interface
+func CommentRepository(t *testing.T, repoFactory func() (repo service.CommentRepository, close func())) {
+	t.Run("Create_FindByID_Update_Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		commentRepository_Create_FindByID_Update_Delete(t, repo)
+	})
+
+	t.Run("FindByQuoteID_DeleteByQuoteID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		commentRepository_FindByQuoteID_DeleteByQuoteID(t, repo)
+	})
+
+	t.Run("ReassignUser", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		commentRepository_ReassignUser(t, repo)
+	})
+}
+
+func commentRepository_Create_FindByID_Update_Delete(t *testing.T, repo service.CommentRepository) {
+	c := model.Comment{
+		ID:       "comment_id",
+		QuoteID:  "quote_id",
+		AuthorID: "user_id",
+		Text:     "I was there when this happened.",
+		Created:  time.Now(),
+	}
+
+	if _, err := repo.FindByID(context.Background(), c.ID); err != storage.ErrNotFound {
+		t.Errorf("find comment before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Update(context.Background(), c); err != storage.ErrNotFound {
+		t.Errorf("update comment before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), c); err != nil {
+		t.Errorf("create comment: %v", err)
+	}
+
+	got, err := repo.FindByID(context.Background(), c.ID)
+	if err != nil {
+		t.Errorf("find comment: %v", err)
+	}
+	if !cmp.Equal(got, c) {
+		t.Errorf("got comment %v, want %v", got, c)
+	}
+
+	if err := repo.Create(context.Background(), c); err != storage.ErrAlreadyExists {
+		t.Errorf("create comment again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	c.Text = ""
+	c.Edited = time.Now()
+	c.Deleted = true
+	if err := repo.Update(context.Background(), c); err != nil {
+		t.Errorf("update comment: %v", err)
+	}
+
+	got, err = repo.FindByID(context.Background(), c.ID)
+	if err != nil {
+		t.Errorf("find updated comment: %v", err)
+	}
+	if !cmp.Equal(got, c) {
+		t.Errorf("got updated comment %v, want %v", got, c)
+	}
+
+	if err := repo.Delete(context.Background(), c.ID); err != nil {
+		t.Errorf("delete comment: %v", err)
+	}
+
+	if _, err := repo.FindByID(context.Background(), c.ID); err != storage.ErrNotFound {
+		t.Errorf("find comment after delete: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Delete(context.Background(), c.ID); err != storage.ErrNotFound {
+		t.Errorf("delete comment again: got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func commentRepository_FindByQuoteID_DeleteByQuoteID(t *testing.T, repo service.CommentRepository) {
+	now := time.Now()
+	comments := []model.Comment{
+		{ID: "c1", QuoteID: "quote_a", AuthorID: "user_a", Text: "First", Created: now.Add(-time.Hour)},
+		{ID: "c2", QuoteID: "quote_b", AuthorID: "user_a", Text: "Elsewhere", Created: now},
+		{ID: "c3", QuoteID: "quote_a", ParentID: "c1", AuthorID: "user_b", Text: "Reply", Created: now},
+		{ID: "c4", QuoteID: "quote_a", AuthorID: "user_b", Text: "Second", Created: now.Add(-time.Minute)},
+	}
+	for _, c := range comments {
+		if err := repo.Create(context.Background(), c); err != nil {
+			t.Errorf("create comment %v: %v", c.ID, err)
+		}
+	}
+
+	got, err := repo.FindByQuoteID(context.Background(), "quote_a")
+	if err != nil {
+		t.Errorf("find comments on quote_a: %v", err)
+	}
+	if want := []model.Comment{comments[0], comments[3], comments[2]}; !cmp.Equal(got, want) {
+		t.Errorf("got comments %v, want %v", got, want)
+	}
+
+	if err := repo.DeleteByQuoteID(context.Background(), "quote_a"); err != nil {
+		t.Errorf("delete comments on quote_a: %v", err)
+	}
+
+	got, err = repo.FindByQuoteID(context.Background(), "quote_a")
+	if err != nil {
+		t.Errorf("find comments on quote_a after delete: %v", err)
+	}
+	if !cmp.Equal(got, []model.Comment{}) {
+		t.Errorf("quote_a should have no comments, got %v", got)
+	}
+
+	got, err = repo.FindByQuoteID(context.Background(), "quote_b")
+	if err != nil {
+		t.Errorf("find comments on quote_b: %v", err)
+	}
+	if want := []model.Comment{comments[1]}; !cmp.Equal(got, want) {
+		t.Errorf("comments on other quotes should remain, got %v, want %v", got, want)
+	}
+}
+
+func commentRepository_ReassignUser(t *testing.T, repo service.CommentRepository) {
+	now := time.Now()
+	comments := []model.Comment{
+		{ID: "c1", QuoteID: "quote_a", AuthorID: "user_a", Text: "First", Created: now.Add(-time.Hour)},
+		{ID: "c2", QuoteID: "quote_a", ParentID: "c1", AuthorID: "user_b", Text: "Reply", Created: now},
+	}
+	for _, c := range comments {
+		if err := repo.Create(context.Background(), c); err != nil {
+			t.Errorf("create comment %v: %v", c.ID, err)
+		}
+	}
+
+	if err := repo.ReassignUser(context.Background(), "user_a", "user_b"); err != nil {
+		t.Errorf("reassign comments of user_a: %v", err)
+	}
+
+	got, err := repo.FindByQuoteID(context.Background(), "quote_a")
+	if err != nil {
+		t.Errorf("find comments on quote_a: %v", err)
+	}
+	comments[0].AuthorID = "user_b"
+	if !cmp.Equal(got, comments) {
+		t.Errorf("got comments %v, want %v", got, comments)
+	}
+}
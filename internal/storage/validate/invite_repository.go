@@ -0,0 +1,289 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// InviteRepository validates a type implementing the InviteRepository interface
+func InviteRepository(t *testing.T, repoFactory func() (repo service.InviteRepository, close func())) {
+	t.Run("Create_Find_Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		inviteRepository_Create_Find_Delete(t, repo)
+	})
+
+	t.Run("AddRedemption", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		inviteRepository_AddRedemption(t, repo)
+	})
+
+	t.Run("AddRedemption_Limits", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		inviteRepository_AddRedemption_Limits(t, repo)
+	})
+
+	t.Run("FindByCommunityID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		inviteRepository_FindByCommunityID(t, repo)
+	})
+
+	t.Run("ReassignUser", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		inviteRepository_ReassignUser(t, repo)
+	})
+}
+
+func inviteRepository_Create_Find_Delete(t *testing.T, repo service.InviteRepository) {
+	i := model.Invite{
+		ID:          "invite_id",
+		CommunityID: "community_id",
+		Code:        "invite_code",
+		CreatorID:   "user_id",
+		MaxUses:     5,
+		Expires:     time.Now().Add(time.Hour),
+		Created:     time.Now(),
+		Redemptions: []model.InviteRedemption{},
+	}
+
+	if _, err := repo.FindByCode(context.Background(), i.Code); err != storage.ErrNotFound {
+		t.Errorf("find invite before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), i); err != nil {
+		t.Errorf("create invite: %v", err)
+	}
+
+	got, err := repo.FindByID(context.Background(), i.ID)
+	if err != nil {
+		t.Errorf("find invite by id: %v", err)
+	}
+	if !cmp.Equal(got, i) {
+		t.Errorf("got invite %v, want %v", got, i)
+	}
+
+	got, err = repo.FindByCode(context.Background(), i.Code)
+	if err != nil {
+		t.Errorf("find invite by code: %v", err)
+	}
+	if !cmp.Equal(got, i) {
+		t.Errorf("got invite %v, want %v", got, i)
+	}
+
+	if err := repo.Create(context.Background(), i); err != storage.ErrAlreadyExists {
+		t.Errorf("create invite again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	other := i
+	other.ID = "other_id"
+	if err := repo.Create(context.Background(), other); err != storage.ErrAlreadyExists {
+		t.Errorf("create invite with same code: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	if err := repo.Delete(context.Background(), i.ID); err != nil {
+		t.Errorf("delete invite: %v", err)
+	}
+
+	if _, err := repo.FindByID(context.Background(), i.ID); err != storage.ErrNotFound {
+		t.Errorf("find invite after delete: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Delete(context.Background(), i.ID); err != storage.ErrNotFound {
+		t.Errorf("delete invite again: got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func inviteRepository_AddRedemption(t *testing.T, repo service.InviteRepository) {
+	now := time.Now()
+	i := model.Invite{ID: "invite_id", CommunityID: "community_id", Code: "invite_code", Created: now}
+	redemptions := []model.InviteRedemption{
+		{InviteID: i.ID, UserID: "user_b", Redeemed: now.Add(-time.Minute)},
+		{InviteID: i.ID, UserID: "user_a", Redeemed: now},
+	}
+
+	if err := repo.AddRedemption(context.Background(), redemptions[0]); err != storage.ErrNotFound {
+		t.Errorf("redeem invite before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), i); err != nil {
+		t.Errorf("create invite: %v", err)
+	}
+
+	// redemptions are added out of order, to ensure they are returned from oldest to newest
+	for _, r := range []model.InviteRedemption{redemptions[1], redemptions[0]} {
+		if err := repo.AddRedemption(context.Background(), r); err != nil {
+			t.Errorf("redeem invite for %v: %v", r.UserID, err)
+		}
+	}
+
+	if err := repo.AddRedemption(context.Background(), redemptions[0]); err != storage.ErrAlreadyExists {
+		t.Errorf("redeem invite again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	got, err := repo.FindByCode(context.Background(), i.Code)
+	if err != nil {
+		t.Errorf("find redeemed invite: %v", err)
+	}
+	if !cmp.Equal(got.Redemptions, redemptions) {
+		t.Errorf("got redemptions %v, want %v", got.Redemptions, redemptions)
+	}
+
+	if err := repo.Delete(context.Background(), i.ID); err != nil {
+		t.Errorf("delete invite: %v", err)
+	}
+
+	if err := repo.Create(context.Background(), i); err != nil {
+		t.Errorf("create invite after delete: %v", err)
+	}
+
+	got, err = repo.FindByID(context.Background(), i.ID)
+	if err != nil {
+		t.Errorf("find recreated invite: %v", err)
+	}
+	if !cmp.Equal(got.Redemptions, []model.InviteRedemption{}) {
+		t.Errorf("redemptions should be deleted with their invite, got %v", got.Redemptions)
+	}
+}
+
+func inviteRepository_AddRedemption_Limits(t *testing.T, repo service.InviteRepository) {
+	now := time.Now()
+	limited := model.Invite{ID: "limited", CommunityID: "c1", Code: "code_1", MaxUses: 1, Created: now}
+	expiring := model.Invite{ID: "expiring", CommunityID: "c1", Code: "code_2", Expires: now.Add(time.Hour), Created: now}
+	for _, i := range []model.Invite{limited, expiring} {
+		if err := repo.Create(context.Background(), i); err != nil {
+			t.Errorf("create invite %v: %v", i.ID, err)
+		}
+	}
+
+	r := model.InviteRedemption{InviteID: limited.ID, UserID: "user_a", Redeemed: now}
+	if err := repo.AddRedemption(context.Background(), r); err != nil {
+		t.Errorf("redeem limited invite: %v", err)
+	}
+	if err := repo.AddRedemption(context.Background(), r); err != storage.ErrAlreadyExists {
+		t.Errorf("redeem used up invite again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	r.UserID = "user_b"
+	if err := repo.AddRedemption(context.Background(), r); err != storage.ErrUnavailable {
+		t.Errorf("redeem used up invite: got error %v, want %v", err, storage.ErrUnavailable)
+	}
+
+	r = model.InviteRedemption{InviteID: expiring.ID, UserID: "user_a", Redeemed: now.Add(2 * time.Hour)}
+	if err := repo.AddRedemption(context.Background(), r); err != storage.ErrUnavailable {
+		t.Errorf("redeem expired invite: got error %v, want %v", err, storage.ErrUnavailable)
+	}
+
+	r.Redeemed = now.Add(time.Minute)
+	if err := repo.AddRedemption(context.Background(), r); err != nil {
+		t.Errorf("redeem invite before it expires: %v", err)
+	}
+
+	got, err := repo.FindByID(context.Background(), limited.ID)
+	if err != nil {
+		t.Errorf("find limited invite: %v", err)
+	}
+	if len(got.Redemptions) != 1 {
+		t.Errorf("limited invite should have 1 redemption, got %v", got.Redemptions)
+	}
+}
+
+func inviteRepository_FindByCommunityID(t *testing.T, repo service.InviteRepository) {
+	now := time.Now()
+	invites := []model.Invite{
+		{ID: "invite_1", CommunityID: "c1", Code: "code_1", Created: now.Add(-time.Hour), Redemptions: []model.InviteRedemption{}},
+		{ID: "invite_2", CommunityID: "c1", Code: "code_2", MaxUses: 1, Created: now, Redemptions: []model.InviteRedemption{}},
+		{ID: "invite_3", CommunityID: "c2", Code: "code_3", Created: now, Redemptions: []model.InviteRedemption{}},
+	}
+	for _, i := range invites {
+		if err := repo.Create(context.Background(), i); err != nil {
+			t.Errorf("create invite %v: %v", i.ID, err)
+		}
+	}
+
+	r := model.InviteRedemption{InviteID: "invite_2", UserID: "user_id", Redeemed: now}
+	if err := repo.AddRedemption(context.Background(), r); err != nil {
+		t.Errorf("redeem invite: %v", err)
+	}
+	invites[1].Redemptions = []model.InviteRedemption{r}
+
+	got, err := repo.FindByCommunityID(context.Background(), "c1")
+	if err != nil {
+		t.Errorf("find invites of c1: %v", err)
+	}
+	if want := []model.Invite{invites[1], invites[0]}; !cmp.Equal(got, want) {
+		t.Errorf("got invites %v, want %v", got, want)
+	}
+
+	got, err = repo.FindByCommunityID(context.Background(), "c3")
+	if err != nil {
+		t.Errorf("find invites of c3: %v", err)
+	}
+	if !cmp.Equal(got, []model.Invite{}) {
+		t.Errorf("c3 should have no invites, got %v", got)
+	}
+}
+
+func inviteRepository_ReassignUser(t *testing.T, repo service.InviteRepository) {
+	now := time.Now()
+	invites := []model.Invite{
+		{ID: "invite_1", CommunityID: "c1", Code: "code_1", CreatorID: "user_a", Created: now},
+		{ID: "invite_2", CommunityID: "c1", Code: "code_2", CreatorID: "user_c", Created: now},
+	}
+	for _, i := range invites {
+		if err := repo.Create(context.Background(), i); err != nil {
+			t.Errorf("create invite %v: %v", i.ID, err)
+		}
+	}
+	redemptions := []model.InviteRedemption{
+		{InviteID: "invite_1", UserID: "user_a", Redeemed: now.Add(-time.Hour)},
+		{InviteID: "invite_2", UserID: "user_a", Redeemed: now.Add(-time.Hour)},
+		{InviteID: "invite_2", UserID: "user_b", Redeemed: now},
+	}
+	for _, r := range redemptions {
+		if err := repo.AddRedemption(context.Background(), r); err != nil {
+			t.Errorf("redeem invite %v for %v: %v", r.InviteID, r.UserID, err)
+		}
+	}
+
+	if err := repo.ReassignUser(context.Background(), "user_a", "user_b"); err != nil {
+		t.Errorf("reassign invites of user_a: %v", err)
+	}
+
+	got, err := repo.FindByID(context.Background(), "invite_1")
+	if err != nil {
+		t.Errorf("find invite created by user_a: %v", err)
+	}
+	want := invites[0]
+	want.CreatorID = "user_b"
+	want.Redemptions = []model.InviteRedemption{{InviteID: "invite_1", UserID: "user_b", Redeemed: redemptions[0].Redeemed}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("got invite %v, want %v", got, want)
+	}
+
+	// the redemption user_b already made is kept, rather than duplicated
+	got, err = repo.FindByID(context.Background(), "invite_2")
+	if err != nil {
+		t.Errorf("find invite redeemed by both users: %v", err)
+	}
+	want = invites[1]
+	want.Redemptions = []model.InviteRedemption{redemptions[2]}
+	if !cmp.Equal(got, want) {
+		t.Errorf("got invite %v, want %v", got, want)
+	}
+}
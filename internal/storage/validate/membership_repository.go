@@ -0,0 +1,152 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// MembershipRepository validates a type implementing the MembershipRepository interface
+func MembershipRepository(t *testing.T, repoFactory func() (repo service.MembershipRepository, close func())) {
+	t.Run("Create_Find_Update_Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		membershipRepository_Create_Find_Update_Delete(t, repo)
+	})
+
+	t.Run("FindByUserID_FindByCommunityID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		membershipRepository_FindByUserID_FindByCommunityID(t, repo)
+	})
+}
+
+func membershipRepository_Create_Find_Update_Delete(t *testing.T, repo service.MembershipRepository) {
+	m := model.Membership{
+		CommunityID:  "c1",
+		UserID:       "user_id",
+		QuizPassed:   false,
+		QuizAttempts: 1,
+		Joined:       time.Now(),
+	}
+
+	if _, err := repo.Find(context.Background(), m.CommunityID, m.UserID); err != storage.ErrNotFound {
+		t.Errorf("find membership before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Update(context.Background(), m); err != storage.ErrNotFound {
+		t.Errorf("update membership before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), m); err != nil {
+		t.Errorf("create membership: %v", err)
+	}
+
+	got, err := repo.Find(context.Background(), m.CommunityID, m.UserID)
+	if err != nil {
+		t.Errorf("find membership: %v", err)
+	}
+	if !cmp.Equal(got, m) {
+		t.Errorf("got membership %v, want %v", got, m)
+	}
+
+	if err := repo.Create(context.Background(), m); err != storage.ErrAlreadyExists {
+		t.Errorf("create membership again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	// the same user may be a member of another community
+	other := m
+	other.CommunityID = "c2"
+	if err := repo.Create(context.Background(), other); err != nil {
+		t.Errorf("create membership of other community: %v", err)
+	}
+
+	m.QuizPassed = true
+	m.QuizAttempts = 2
+	m.LastQuizAttempt = time.Now()
+	m.Approved = true
+	m.Rejected = true
+	m.Banned = true
+	m.Admin = true
+	if err := repo.Update(context.Background(), m); err != nil {
+		t.Errorf("update membership: %v", err)
+	}
+
+	got, err = repo.Find(context.Background(), m.CommunityID, m.UserID)
+	if err != nil {
+		t.Errorf("find updated membership: %v", err)
+	}
+	if !cmp.Equal(got, m) {
+		t.Errorf("got updated membership %v, want %v", got, m)
+	}
+
+	got, err = repo.Find(context.Background(), other.CommunityID, other.UserID)
+	if err != nil {
+		t.Errorf("find membership of other community: %v", err)
+	}
+	if !cmp.Equal(got, other) {
+		t.Errorf("membership of other community should be unchanged, got %v, want %v", got, other)
+	}
+
+	if err := repo.Delete(context.Background(), m.CommunityID, m.UserID); err != nil {
+		t.Errorf("delete membership: %v", err)
+	}
+
+	if _, err := repo.Find(context.Background(), m.CommunityID, m.UserID); err != storage.ErrNotFound {
+		t.Errorf("find membership after delete: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Delete(context.Background(), m.CommunityID, m.UserID); err != storage.ErrNotFound {
+		t.Errorf("delete membership again: got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func membershipRepository_FindByUserID_FindByCommunityID(t *testing.T, repo service.MembershipRepository) {
+	got, err := repo.FindByUserID(context.Background(), "user_id")
+	if err != nil {
+		t.Errorf("find memberships before created: %v", err)
+	}
+	if !cmp.Equal(got, []model.Membership{}) {
+		t.Errorf("there should be no memberships, got %v", got)
+	}
+
+	now := time.Now()
+	memberships := []model.Membership{
+		{CommunityID: "c1", UserID: "user_id", QuizPassed: true, Joined: now},
+		{CommunityID: "c2", UserID: "user_id", QuizAttempts: 3, Joined: now},
+		{CommunityID: "c1", UserID: "user_id2", Admin: true, Joined: now},
+	}
+	for _, m := range memberships {
+		if err := repo.Create(context.Background(), m); err != nil {
+			t.Errorf("create membership %v/%v: %v", m.CommunityID, m.UserID, err)
+		}
+	}
+
+	sortMemberships := cmpopts.SortSlices(func(a, b model.Membership) bool {
+		return a.CommunityID+"/"+a.UserID < b.CommunityID+"/"+b.UserID
+	})
+
+	got, err = repo.FindByUserID(context.Background(), "user_id")
+	if err != nil {
+		t.Errorf("find memberships of user: %v", err)
+	}
+	if want := []model.Membership{memberships[0], memberships[1]}; !cmp.Equal(got, want, sortMemberships) {
+		t.Errorf("got memberships of user %v, want %v", got, want)
+	}
+
+	got, err = repo.FindByCommunityID(context.Background(), "c1")
+	if err != nil {
+		t.Errorf("find memberships of community: %v", err)
+	}
+	if want := []model.Membership{memberships[0], memberships[2]}; !cmp.Equal(got, want, sortMemberships) {
+		t.Errorf("got memberships of community %v, want %v", got, want)
+	}
+}
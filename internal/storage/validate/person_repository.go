@@ -0,0 +1,164 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// PersonRepository validates a type implementing the PersonRepository interface
+func PersonRepository(t *testing.T, repoFactory func() (repo service.PersonRepository, close func())) {
+	t.Run("Create_FindByID_Update_Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		personRepository_Create_FindByID_Update_Delete(t, repo)
+	})
+
+	t.Run("FindByCommunityID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		personRepository_FindByCommunityID(t, repo)
+	})
+
+	t.Run("ReassignUser", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		personRepository_ReassignUser(t, repo)
+	})
+}
+
+func personRepository_Create_FindByID_Update_Delete(t *testing.T, repo service.PersonRepository) {
+	p := model.Person{
+		ID:          "person_id",
+		CommunityID: "community_id",
+		Name:        "Joshua Smith",
+		Aliases:     []string{},
+		Created:     time.Now(),
+	}
+
+	if _, err := repo.FindByID(context.Background(), p.ID); err != storage.ErrNotFound {
+		t.Errorf("find person before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Update(context.Background(), p); err != storage.ErrNotFound {
+		t.Errorf("update person before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), p); err != nil {
+		t.Errorf("create person: %v", err)
+	}
+
+	got, err := repo.FindByID(context.Background(), p.ID)
+	if err != nil {
+		t.Errorf("find person: %v", err)
+	}
+	if !cmp.Equal(got, p) {
+		t.Errorf("got person %v, want %v", got, p)
+	}
+
+	if err := repo.Create(context.Background(), p); err != storage.ErrAlreadyExists {
+		t.Errorf("create person again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	p.Name = "Josh Smith"
+	p.Aliases = []string{"Joshua Smith", "JS"}
+	p.UserID = "user_id"
+	if err := repo.Update(context.Background(), p); err != nil {
+		t.Errorf("update person: %v", err)
+	}
+
+	got, err = repo.FindByID(context.Background(), p.ID)
+	if err != nil {
+		t.Errorf("find updated person: %v", err)
+	}
+	if !cmp.Equal(got, p) {
+		t.Errorf("got updated person %v, want %v", got, p)
+	}
+
+	if err := repo.Delete(context.Background(), p.ID); err != nil {
+		t.Errorf("delete person: %v", err)
+	}
+
+	if _, err := repo.FindByID(context.Background(), p.ID); err != storage.ErrNotFound {
+		t.Errorf("find person after delete: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Delete(context.Background(), p.ID); err != storage.ErrNotFound {
+		t.Errorf("delete person again: got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func personRepository_FindByCommunityID(t *testing.T, repo service.PersonRepository) {
+	got, err := repo.FindByCommunityID(context.Background(), "c1")
+	if err != nil {
+		t.Errorf("find people before created: %v", err)
+	}
+	if !cmp.Equal(got, []model.Person{}) {
+		t.Errorf("there should be no people, got %v", got)
+	}
+
+	now := time.Now()
+	people := []model.Person{
+		{ID: "p1", CommunityID: "c1", Name: "zoe", Aliases: []string{}, Created: now},
+		{ID: "p2", CommunityID: "c1", Name: "Adam", Aliases: []string{"Ad"}, Created: now},
+		{ID: "p3", CommunityID: "c1", Name: "Mia", Aliases: []string{}, UserID: "user_id", Created: now},
+		{ID: "p4", CommunityID: "c2", Name: "Bea", Aliases: []string{}, Created: now},
+	}
+	for _, p := range people {
+		if err := repo.Create(context.Background(), p); err != nil {
+			t.Errorf("create person %v: %v", p.ID, err)
+		}
+	}
+
+	got, err = repo.FindByCommunityID(context.Background(), "c1")
+	if err != nil {
+		t.Errorf("find people: %v", err)
+	}
+	if want := []model.Person{people[1], people[2], people[0]}; !cmp.Equal(got, want) {
+		t.Errorf("got people %v, want %v", got, want)
+	}
+
+	got, err = repo.FindByCommunityID(context.Background(), "c2")
+	if err != nil {
+		t.Errorf("find people of other community: %v", err)
+	}
+	if want := []model.Person{people[3]}; !cmp.Equal(got, want) {
+		t.Errorf("got people of other community %v, want %v", got, want)
+	}
+}
+
+func personRepository_ReassignUser(t *testing.T, repo service.PersonRepository) {
+	now := time.Now()
+	people := []model.Person{
+		{ID: "p1", CommunityID: "c1", Name: "Adam", Aliases: []string{}, UserID: "user_a", Created: now},
+		{ID: "p2", CommunityID: "c1", Name: "Bea", Aliases: []string{}, UserID: "user_b", Created: now},
+		{ID: "p3", CommunityID: "c1", Name: "Mia", Aliases: []string{}, Created: now},
+	}
+	for _, p := range people {
+		if err := repo.Create(context.Background(), p); err != nil {
+			t.Errorf("create person %v: %v", p.ID, err)
+		}
+	}
+
+	if err := repo.ReassignUser(context.Background(), "user_a", "user_b"); err != nil {
+		t.Errorf("reassign people of user_a: %v", err)
+	}
+
+	got, err := repo.FindByCommunityID(context.Background(), "c1")
+	if err != nil {
+		t.Errorf("find people: %v", err)
+	}
+	people[0].UserID = "user_b"
+	if !cmp.Equal(got, people) {
+		t.Errorf("got people %v, want %v", got, people)
+	}
+}
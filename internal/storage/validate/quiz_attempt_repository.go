@@ -0,0 +1,132 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// QuizAttemptRepository validates a type implementing the QuizAttemptRepository interface
+func QuizAttemptRepository(t *testing.T, repoFactory func() (repo service.QuizAttemptRepository, close func())) {
+	t.Run("Create_FindByUserID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quizAttemptRepository_Create_FindByUserID(t, repo)
+	})
+
+	t.Run("ReassignUser", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quizAttemptRepository_ReassignUser(t, repo)
+	})
+}
+
+func quizAttemptRepository_Create_FindByUserID(t *testing.T, repo service.QuizAttemptRepository) {
+	now := time.Now()
+	attempts := []model.QuizAttempt{
+		{
+			ID:               "a1",
+			CommunityID:      "c1",
+			UserID:           "user_id",
+			IP:               "192.168.0.1",
+			Submitted:        now.Add(-time.Hour),
+			QuestionIDs:      []int{4, 0, 2},
+			WrongQuestionIDs: []int{0, 2},
+			Answers:          map[int]string{4: "purple", 0: "", 2: "ALIGATOR"},
+		},
+		{
+			ID:               "a2",
+			CommunityID:      "c1",
+			UserID:           "user_id",
+			IP:               "192.168.0.1",
+			Submitted:        now,
+			QuestionIDs:      []int{1, 3},
+			WrongQuestionIDs: []int{},
+			Passed:           true,
+		},
+		{
+			ID:               "a3",
+			CommunityID:      "c2",
+			UserID:           "user_id",
+			Submitted:        now,
+			QuestionIDs:      []int{0},
+			WrongQuestionIDs: []int{0},
+		},
+		{
+			ID:               "a4",
+			CommunityID:      "c1",
+			UserID:           "user_id2",
+			Submitted:        now,
+			QuestionIDs:      []int{0},
+			WrongQuestionIDs: []int{},
+			Passed:           true,
+		},
+	}
+	for _, a := range attempts {
+		if err := repo.Create(context.Background(), a); err != nil {
+			t.Fatalf("create attempt %v: %v", a.ID, err)
+		}
+	}
+
+	if err := repo.Create(context.Background(), attempts[0]); err != storage.ErrAlreadyExists {
+		t.Errorf("create attempt again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	got, err := repo.FindByUserID(context.Background(), "c1", "user_id")
+	if err != nil {
+		t.Errorf("find attempts: %v", err)
+	}
+	want := []model.QuizAttempt{attempts[1], attempts[0]}
+	if !cmp.Equal(got, want) {
+		t.Errorf("got attempts %v, want %v", got, want)
+	}
+
+	got, err = repo.FindByUserID(context.Background(), "c3", "user_id")
+	if err != nil {
+		t.Errorf("find attempts in community without any: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d attempts in community without any, want 0", len(got))
+	}
+}
+
+func quizAttemptRepository_ReassignUser(t *testing.T, repo service.QuizAttemptRepository) {
+	now := time.Now()
+	attempts := []model.QuizAttempt{
+		{ID: "a1", CommunityID: "c1", UserID: "user_a", Submitted: now.Add(-time.Hour), QuestionIDs: []int{0}, WrongQuestionIDs: []int{0}},
+		{ID: "a2", CommunityID: "c1", UserID: "user_b", Submitted: now, QuestionIDs: []int{1}, WrongQuestionIDs: []int{}, Passed: true},
+	}
+	for _, a := range attempts {
+		if err := repo.Create(context.Background(), a); err != nil {
+			t.Errorf("create attempt %v: %v", a.ID, err)
+		}
+	}
+
+	if err := repo.ReassignUser(context.Background(), "user_a", "user_b"); err != nil {
+		t.Errorf("reassign attempts of user_a: %v", err)
+	}
+
+	got, err := repo.FindByUserID(context.Background(), "c1", "user_a")
+	if err != nil {
+		t.Errorf("find attempts of user_a after reassign: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("user_a should have no attempts after reassign, got %v", got)
+	}
+
+	got, err = repo.FindByUserID(context.Background(), "c1", "user_b")
+	if err != nil {
+		t.Errorf("find attempts of user_b: %v", err)
+	}
+	attempts[0].UserID = "user_b"
+	if want := []model.QuizAttempt{attempts[1], attempts[0]}; !cmp.Equal(got, want) {
+		t.Errorf("got attempts %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package validate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// QuizQuestionRepository validates a type implementing the QuizQuestionRepository interface
+func QuizQuestionRepository(t *testing.T, repoFactory func() (repo service.QuizQuestionRepository, close func())) {
+	t.Run("Create_Find_Update", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quizQuestionRepository_Create_Find_Update(t, repo)
+	})
+
+	t.Run("FindByCommunityID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quizQuestionRepository_FindByCommunityID(t, repo)
+	})
+}
+
+func quizQuestionRepository_Create_Find_Update(t *testing.T, repo service.QuizQuestionRepository) {
+	q := model.QuizQuestion{
+		CommunityID: "c1",
+		ID:          3,
+		Question:    "What is the best color?",
+		Answers:     []string{"purple", "violet"},
+		Normalize:   []string{"whitespace"},
+		Position:    2,
+		Enabled:     true,
+	}
+
+	if _, err := repo.Find(context.Background(), q.CommunityID, q.ID); err != storage.ErrNotFound {
+		t.Errorf("find question before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Update(context.Background(), q); err != storage.ErrNotFound {
+		t.Errorf("update question before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), q); err != nil {
+		t.Errorf("create question: %v", err)
+	}
+
+	got, err := repo.Find(context.Background(), q.CommunityID, q.ID)
+	if err != nil {
+		t.Errorf("find question: %v", err)
+	}
+	if !cmp.Equal(got, q) {
+		t.Errorf("got question %v, want %v", got, q)
+	}
+
+	if err := repo.Create(context.Background(), q); err != storage.ErrAlreadyExists {
+		t.Errorf("create question again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	other := q
+	other.CommunityID = "c2"
+	if err := repo.Create(context.Background(), other); err != nil {
+		t.Errorf("create question with same ID in other community: %v", err)
+	}
+
+	q.Question = "What is the worst color?"
+	q.Answers = []string{"beige"}
+	q.Normalize = []string{}
+	q.Position = 0
+	q.Enabled = false
+	if err := repo.Update(context.Background(), q); err != nil {
+		t.Errorf("update question: %v", err)
+	}
+
+	got, err = repo.Find(context.Background(), q.CommunityID, q.ID)
+	if err != nil {
+		t.Errorf("find updated question: %v", err)
+	}
+	if !cmp.Equal(got, q) {
+		t.Errorf("got updated question %v, want %v", got, q)
+	}
+
+	got, err = repo.Find(context.Background(), other.CommunityID, other.ID)
+	if err != nil {
+		t.Errorf("find question in other community: %v", err)
+	}
+	if !cmp.Equal(got, other) {
+		t.Errorf("question in other community should be unchanged, got %v, want %v", got, other)
+	}
+}
+
+func quizQuestionRepository_FindByCommunityID(t *testing.T, repo service.QuizQuestionRepository) {
+	questions := []model.QuizQuestion{
+		{CommunityID: "c1", ID: 0, Question: "Q0", Answers: []string{"a0"}, Normalize: []string{}, Position: 2, Enabled: true},
+		{CommunityID: "c1", ID: 1, Question: "Q1", Answers: []string{"a1"}, Normalize: []string{}, Position: 0},
+		{CommunityID: "c1", ID: 2, Question: "Q2", Answers: []string{"a2"}, Normalize: []string{}, Position: 1, Enabled: true},
+		{CommunityID: "c2", ID: 0, Question: "Q0", Answers: []string{"a0"}, Normalize: []string{}, Position: 0, Enabled: true},
+	}
+	for _, q := range questions {
+		if err := repo.Create(context.Background(), q); err != nil {
+			t.Fatalf("create question: %v", err)
+		}
+	}
+
+	got, err := repo.FindByCommunityID(context.Background(), "c1")
+	if err != nil {
+		t.Errorf("find questions: %v", err)
+	}
+	want := []model.QuizQuestion{questions[1], questions[2], questions[0]}
+	if !cmp.Equal(got, want) {
+		t.Errorf("got questions %v, want %v", got, want)
+	}
+
+	got, err = repo.FindByCommunityID(context.Background(), "c3")
+	if err != nil {
+		t.Errorf("find questions of community without any: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d questions of community without any, want 0", len(got))
+	}
+}
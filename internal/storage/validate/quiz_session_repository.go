@@ -0,0 +1,120 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// QuizSessionRepository validates a type implementing the QuizSessionRepository interface
+func QuizSessionRepository(t *testing.T, repoFactory func() (repo service.QuizSessionRepository, close func())) {
+	t.Run("Create_Find_Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quizSessionRepository_Create_Find_Delete(t, repo)
+	})
+
+	t.Run("ReassignUser", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quizSessionRepository_ReassignUser(t, repo)
+	})
+}
+
+func quizSessionRepository_Create_Find_Delete(t *testing.T, repo service.QuizSessionRepository) {
+	s := model.QuizSession{
+		CommunityID: "c1",
+		UserID:      "user_id",
+		QuestionIDs: []int{4, 0, 2},
+		Started:     time.Now(),
+	}
+
+	if _, err := repo.Find(context.Background(), s.CommunityID, s.UserID); err != storage.ErrNotFound {
+		t.Errorf("find session before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), s); err != nil {
+		t.Errorf("create session: %v", err)
+	}
+
+	got, err := repo.Find(context.Background(), s.CommunityID, s.UserID)
+	if err != nil {
+		t.Errorf("find session: %v", err)
+	}
+	if !cmp.Equal(got, s) {
+		t.Errorf("got session %v, want %v", got, s)
+	}
+
+	if err := repo.Create(context.Background(), s); err != storage.ErrAlreadyExists {
+		t.Errorf("create session again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	other := s
+	other.CommunityID = "c2"
+	other.QuestionIDs = []int{1}
+	if err := repo.Create(context.Background(), other); err != nil {
+		t.Errorf("create session in other community: %v", err)
+	}
+
+	if err := repo.Delete(context.Background(), s.CommunityID, s.UserID); err != nil {
+		t.Errorf("delete session: %v", err)
+	}
+
+	if _, err := repo.Find(context.Background(), s.CommunityID, s.UserID); err != storage.ErrNotFound {
+		t.Errorf("find session after delete: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Delete(context.Background(), s.CommunityID, s.UserID); err != storage.ErrNotFound {
+		t.Errorf("delete session again: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	got, err = repo.Find(context.Background(), other.CommunityID, other.UserID)
+	if err != nil {
+		t.Errorf("find session in other community: %v", err)
+	}
+	if !cmp.Equal(got, other) {
+		t.Errorf("got session %v, want %v", got, other)
+	}
+}
+
+func quizSessionRepository_ReassignUser(t *testing.T, repo service.QuizSessionRepository) {
+	now := time.Now()
+	sessions := []model.QuizSession{
+		{CommunityID: "c1", UserID: "user_a", QuestionIDs: []int{1, 2}, Started: now},
+		{CommunityID: "c1", UserID: "user_b", QuestionIDs: []int{3}, Started: now.Add(-time.Minute)},
+		{CommunityID: "c2", UserID: "user_a", QuestionIDs: []int{0}, Started: now},
+	}
+	for _, s := range sessions {
+		if err := repo.Create(context.Background(), s); err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+	}
+
+	if err := repo.ReassignUser(context.Background(), "user_a", "user_b"); err != nil {
+		t.Errorf("reassign sessions of user_a: %v", err)
+	}
+
+	// the session user_b already began in c1 is kept, rather than replaced
+	if got, err := repo.Find(context.Background(), "c1", "user_b"); err != nil || !cmp.Equal(got, sessions[1]) {
+		t.Errorf("got session of user_b in c1 %v (error %v), want %v", got, err, sessions[1])
+	}
+
+	want := sessions[2]
+	want.UserID = "user_b"
+	if got, err := repo.Find(context.Background(), "c2", "user_b"); err != nil || !cmp.Equal(got, want) {
+		t.Errorf("got session of user_b in c2 %v (error %v), want %v", got, err, want)
+	}
+
+	for _, c := range []string{"c1", "c2"} {
+		if _, err := repo.Find(context.Background(), c, "user_a"); err != storage.ErrNotFound {
+			t.Errorf("find session of user_a in %v after reassign: got error %v, want %v", c, err, storage.ErrNotFound)
+		}
+	}
+}
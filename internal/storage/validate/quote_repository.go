@@ -3,6 +3,7 @@ package validate
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -27,11 +28,60 @@ func QuoteRepository(t *testing.T, repoFactory func() (repo service.QuoteReposit
 		quoteRepository_Update(t, repo)
 	})
 
-	t.Run("FindAll", func(t *testing.T) {
+	t.Run("Delete", func(t *testing.T) {
 		repo, close := repoFactory()
 		defer close()
 		t.Parallel()
-		quoteRepository_FindAll(t, repo)
+		quoteRepository_Delete(t, repo)
+	})
+
+	t.Run("ReassignSubmitter", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quoteRepository_ReassignSubmitter(t, repo)
+	})
+
+	t.Run("ReassignQuotee_CountByQuoteeID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quoteRepository_ReassignQuotee_CountByQuoteeID(t, repo)
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quoteRepository_Tags(t, repo)
+	})
+
+	t.Run("Query_All", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quoteRepository_Query_All(t, repo)
+	})
+
+	t.Run("Query_Filters", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quoteRepository_Query_Filters(t, repo)
+	})
+
+	t.Run("Query_Pagination", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quoteRepository_Query_Pagination(t, repo)
+	})
+
+	t.Run("Query_Search", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		quoteRepository_Query_Search(t, repo)
 	})
 }
 
@@ -39,9 +89,11 @@ func quoteRepository_Create_FindByID(t *testing.T, repo service.QuoteRepository)
 	q1 := model.Quote{
 		ID:          "quote_id",
 		SubmitterID: "user_id",
+		QuoteeID:    "person_id",
 		Quotee:      "AJBR",
 		Quote:       "I'm a quote",
 		Context:     "mail trucks",
+		Tags:        []string{"mail", "road trip 2023"},
 	}
 	if err := repo.Create(context.Background(), q1); err != nil {
 		t.Errorf("create quote q1: %v", err)
@@ -66,7 +118,7 @@ func quoteRepository_Create_FindByID(t *testing.T, repo service.QuoteRepository)
 	if err != storage.ErrNotFound {
 		t.Errorf("non-existent quote should return ErrNotFound, got %v", err)
 	}
-	if gotQ2 != (model.Quote{}) {
+	if !cmp.Equal(gotQ2, model.Quote{}) {
 		t.Errorf("non-existent quote should return empty Quote, got %v", gotQ2)
 	}
 
@@ -88,6 +140,44 @@ func quoteRepository_Create_FindByID(t *testing.T, repo service.QuoteRepository)
 	if err != storage.ErrAlreadyExists {
 		t.Errorf("create duplicate quote should return ErrAlreadyExists, got %v", err)
 	}
+
+	conversation := model.Quote{
+		ID:          "quote_id3",
+		SubmitterID: "user_id",
+		QuoteeID:    "person_a",
+		Quotee:      "Josh",
+		Quote:       "Josh: Is that a hand truck?\nAJBR: Isn't every truck a hand truck?",
+		Lines: []model.QuoteLine{
+			{SpeakerID: "person_a", Speaker: "Josh", Text: "Is that a hand truck?"},
+			{SpeakerID: "person_b", Speaker: "AJBR", Text: "Isn't every truck a hand truck?"},
+		},
+	}
+	if err := repo.Create(context.Background(), conversation); err != nil {
+		t.Errorf("create conversation quote: %v", err)
+	}
+
+	got, err := repo.FindByID(context.Background(), conversation.ID)
+	if err != nil {
+		t.Errorf("find conversation quote: %v", err)
+	}
+	if !cmp.Equal(got, conversation) {
+		t.Errorf("got conversation quote %v, want %v", got, conversation)
+	}
+
+	// conversations may be edited into single line quotes
+	conversation.Lines = nil
+	conversation.Quote = "Is that a hand truck?"
+	if err := repo.Update(context.Background(), conversation); err != nil {
+		t.Errorf("update conversation quote: %v", err)
+	}
+
+	got, err = repo.FindByID(context.Background(), conversation.ID)
+	if err != nil {
+		t.Errorf("find updated conversation quote: %v", err)
+	}
+	if !cmp.Equal(got, conversation) {
+		t.Errorf("got updated conversation quote %v, want %v", got, conversation)
+	}
 }
 
 func quoteRepository_Update(t *testing.T, repo service.QuoteRepository) {
@@ -155,8 +245,241 @@ func quoteRepository_Update(t *testing.T, repo service.QuoteRepository) {
 	}
 }
 
-func quoteRepository_FindAll(t *testing.T, repo service.QuoteRepository) {
-	got, err := repo.FindAll(context.Background())
+func quoteRepository_Delete(t *testing.T, repo service.QuoteRepository) {
+	kept := model.Quote{
+		ID:          "k001",
+		SubmitterID: "user_id",
+		Quotee:      "Charlene",
+		Quote:       "Don't delete me",
+	}
+	if err := repo.Create(context.Background(), kept); err != nil {
+		t.Errorf("create quote kept: %v", err)
+	}
+
+	q1 := model.Quote{
+		ID:          "d001",
+		SubmitterID: "user_id",
+		Quotee:      "AJBR",
+		Quote:       "I'm a typo'd quote",
+	}
+	if err := repo.Create(context.Background(), q1); err != nil {
+		t.Errorf("create quote q1: %v", err)
+	}
+
+	if err := repo.Delete(context.Background(), q1.ID); err != nil {
+		t.Errorf("delete quote q1: %v", err)
+	}
+
+	if _, err := repo.FindByID(context.Background(), q1.ID); err != storage.ErrNotFound {
+		t.Errorf("find deleted quote should return ErrNotFound, got %v", err)
+	}
+
+	if err := repo.Delete(context.Background(), q1.ID); err != storage.ErrNotFound {
+		t.Errorf("delete already deleted quote should return ErrNotFound, got %v", err)
+	}
+
+	gotKept, err := repo.FindByID(context.Background(), kept.ID)
+	if err != nil {
+		t.Errorf("find kept: %v", err)
+	}
+	if !cmp.Equal(gotKept, kept) {
+		t.Errorf("quote was unexpectedly changed, got %v, want %v", gotKept, kept)
+	}
+}
+
+func quoteRepository_ReassignSubmitter(t *testing.T, repo service.QuoteRepository) {
+	quotes := []model.Quote{
+		{ID: "r001", SubmitterID: "old_user", Quotee: "Ada", Quote: "First", Created: time.Now()},
+		{ID: "r002", SubmitterID: "old_user", Quotee: "Ada", Quote: "Second", Created: time.Now()},
+		{ID: "r003", SubmitterID: "other_user", Quotee: "Grace", Quote: "Third", Created: time.Now()},
+	}
+	for _, q := range quotes {
+		if err := repo.Create(context.Background(), q); err != nil {
+			t.Errorf("create quote %v: %v", q.ID, err)
+		}
+	}
+
+	if err := repo.ReassignSubmitter(context.Background(), "old_user", "new_user"); err != nil {
+		t.Errorf("reassign submitter: %v", err)
+	}
+
+	wantSubmitters := map[string]string{
+		"r001": "new_user",
+		"r002": "new_user",
+		"r003": "other_user",
+	}
+	for id, want := range wantSubmitters {
+		got, err := repo.FindByID(context.Background(), id)
+		if err != nil {
+			t.Errorf("find quote %v: %v", id, err)
+		}
+		if got.SubmitterID != want {
+			t.Errorf("quote %v: got submitter %v, want %v", id, got.SubmitterID, want)
+		}
+	}
+
+	if err := repo.ReassignSubmitter(context.Background(), "missing_user", "new_user"); err != nil {
+		t.Errorf("reassigning quotes of user without quotes should not fail, got %v", err)
+	}
+}
+
+func quoteRepository_ReassignQuotee_CountByQuoteeID(t *testing.T, repo service.QuoteRepository) {
+	quotes := []model.Quote{
+		{ID: "p001", SubmitterID: "user", QuoteeID: "josh", Quotee: "Josh", Quote: "First", Created: time.Now()},
+		{ID: "p002", SubmitterID: "user", QuoteeID: "josh_r", Quote: "Second", Quotee: "Josh R.", Created: time.Now()},
+		{ID: "p003", SubmitterID: "user", QuoteeID: "josh_r", Quote: "Third", Quotee: "Josh R.", Created: time.Now()},
+		{ID: "p004", SubmitterID: "user", QuoteeID: "grace", Quotee: "Grace", Quote: "Fourth", Created: time.Now()},
+		{ID: "p005", SubmitterID: "user", Quotee: "Nobody", Quote: "Fifth", Created: time.Now()},
+		{ID: "p006", SubmitterID: "user", QuoteeID: "grace", Quotee: "Grace", Quote: "Grace: Hello\nJosh R.: Hi\nGrace: Goodbye", Created: time.Now(),
+			Lines: []model.QuoteLine{
+				{SpeakerID: "grace", Speaker: "Grace", Text: "Hello"},
+				{SpeakerID: "josh_r", Speaker: "Josh R.", Text: "Hi"},
+				{SpeakerID: "grace", Speaker: "Grace", Text: "Goodbye"},
+			},
+		},
+	}
+	for _, q := range quotes {
+		if err := repo.Create(context.Background(), q); err != nil {
+			t.Errorf("create quote %v: %v", q.ID, err)
+		}
+	}
+
+	counts, err := repo.CountByQuoteeID(context.Background())
+	if err != nil {
+		t.Errorf("count quotes by quotee: %v", err)
+	}
+	if want := map[string]int{"josh": 1, "josh_r": 3, "grace": 2}; !cmp.Equal(counts, want) {
+		t.Errorf("got counts %v, want %v", counts, want)
+	}
+
+	if err := repo.ReassignQuotee(context.Background(), "josh_r", "josh", "Joshua"); err != nil {
+		t.Errorf("reassign quotee: %v", err)
+	}
+
+	want := map[string][2]string{
+		"p001": {"josh", "Josh"},
+		"p002": {"josh", "Joshua"},
+		"p003": {"josh", "Joshua"},
+		"p004": {"grace", "Grace"},
+	}
+	for id, w := range want {
+		got, err := repo.FindByID(context.Background(), id)
+		if err != nil {
+			t.Errorf("find quote %v: %v", id, err)
+		}
+		if got.QuoteeID != w[0] || got.Quotee != w[1] {
+			t.Errorf("quote %v: got quotee %v (%v), want %v (%v)", id, got.Quotee, got.QuoteeID, w[1], w[0])
+		}
+	}
+
+	conversation, err := repo.FindByID(context.Background(), "p006")
+	if err != nil {
+		t.Errorf("find conversation quote: %v", err)
+	}
+	if l := conversation.Lines[1]; l.SpeakerID != "josh" || l.Speaker != "Joshua" {
+		t.Errorf("conversation line: got speaker %v (%v), want Joshua (josh)", l.Speaker, l.SpeakerID)
+	}
+	if l := conversation.Lines[0]; l.SpeakerID != "grace" || l.Speaker != "Grace" {
+		t.Errorf("conversation line of other speaker should be unchanged, got %v (%v)", l.Speaker, l.SpeakerID)
+	}
+	if want := "Grace: Hello\nJoshua: Hi\nGrace: Goodbye"; conversation.Quote != want {
+		t.Errorf("conversation text: got %q, want %q", conversation.Quote, want)
+	}
+
+	// quotes in which a person speaks are included when querying their quotes
+	got, err := repo.Query(context.Background(), service.QuoteQuery{QuoteeID: "josh"})
+	if err != nil {
+		t.Errorf("query quotes of josh: %v", err)
+	}
+	if len(got) != 4 {
+		t.Errorf("got %v quotes of josh, want 4", len(got))
+	}
+}
+
+func quoteRepository_Tags(t *testing.T, repo service.QuoteRepository) {
+	now := time.Now()
+	quotes := []model.Quote{
+		{ID: "t001", CommunityID: "c1", Quotee: "Josh", Quote: "First", Tags: []string{"road trip", "work"}, Created: now},
+		{ID: "t002", CommunityID: "c1", Quotee: "Josh", Quote: "Second", Tags: []string{"work"}, Created: now.Add(-time.Minute)},
+		{ID: "t003", CommunityID: "c1", Quotee: "Josh", Quote: "Third", Tags: []string{"office"}, Created: now.Add(-time.Hour)},
+		{ID: "t004", CommunityID: "c1", Quotee: "Josh", Quote: "Fourth", Created: now.Add(-2 * time.Hour)},
+		// tags of other communities should be neither counted nor renamed
+		{ID: "t005", CommunityID: "c2", Quotee: "Josh", Quote: "Fifth", Tags: []string{"work"}, Created: now},
+	}
+	for _, q := range quotes {
+		if err := repo.Create(context.Background(), q); err != nil {
+			t.Errorf("create quote %v: %v", q.ID, err)
+		}
+	}
+
+	assertTagged := func(tag string, wantIDs ...string) {
+		t.Helper()
+		got, err := repo.Query(context.Background(), service.QuoteQuery{CommunityID: "c1", Tag: tag})
+		if err != nil {
+			t.Errorf("query quotes tagged %v: %v", tag, err)
+		}
+		var gotIDs []string
+		for _, q := range got {
+			gotIDs = append(gotIDs, q.ID)
+		}
+		if !cmp.Equal(gotIDs, wantIDs) {
+			t.Errorf("quotes tagged %v: got %v, want %v", tag, gotIDs, wantIDs)
+		}
+	}
+
+	assertTagged("work", "t001", "t002")
+	assertTagged("road trip", "t001")
+
+	counts, err := repo.CountByTag(context.Background(), "c1")
+	if err != nil {
+		t.Errorf("count quotes by tag: %v", err)
+	}
+	if want := map[string]int{"road trip": 1, "work": 2, "office": 1}; !cmp.Equal(counts, want) {
+		t.Errorf("got counts %v, want %v", counts, want)
+	}
+
+	// merging work into office should not duplicate the tag of quotes with both
+	quotes[2].Tags = []string{"office", "work"}
+	if err := repo.Update(context.Background(), quotes[2]); err != nil {
+		t.Errorf("update tags of quote: %v", err)
+	}
+	if err := repo.RenameTag(context.Background(), "c1", "work", "office"); err != nil {
+		t.Errorf("rename tag: %v", err)
+	}
+
+	assertTagged("work")
+	assertTagged("office", "t001", "t002", "t003")
+
+	got, err := repo.FindByID(context.Background(), "t001")
+	if err != nil {
+		t.Errorf("find renamed quote: %v", err)
+	}
+	if want := []string{"office", "road trip"}; !cmp.Equal(got.Tags, want) {
+		t.Errorf("got tags %v, want %v", got.Tags, want)
+	}
+
+	got, err = repo.FindByID(context.Background(), "t005")
+	if err != nil {
+		t.Errorf("find quote of other community: %v", err)
+	}
+	if want := []string{"work"}; !cmp.Equal(got.Tags, want) {
+		t.Errorf("tags of other communities should not be renamed, got tags %v, want %v", got.Tags, want)
+	}
+
+	if err := repo.Delete(context.Background(), "t003"); err != nil {
+		t.Errorf("delete quote: %v", err)
+	}
+	counts, err = repo.CountByTag(context.Background(), "c1")
+	if err != nil {
+		t.Errorf("count quotes by tag after delete: %v", err)
+	}
+	if want := map[string]int{"road trip": 1, "office": 2}; !cmp.Equal(counts, want) {
+		t.Errorf("tags of deleted quotes should not be counted, got counts %v, want %v", counts, want)
+	}
+}
+
+func quoteRepository_Query_All(t *testing.T, repo service.QuoteRepository) {
+	got, err := repo.Query(context.Background(), service.QuoteQuery{})
 	if err != nil {
 		t.Errorf("finding all from empty repo: %v", err)
 	}
@@ -176,7 +499,7 @@ func quoteRepository_FindAll(t *testing.T, repo service.QuoteRepository) {
 		t.Errorf("create quote q1: %v", err)
 	}
 
-	got, err = repo.FindAll(context.Background())
+	got, err = repo.Query(context.Background(), service.QuoteQuery{})
 	if err != nil {
 		t.Errorf("finding all from repo with one quote: %v", err)
 	}
@@ -196,7 +519,7 @@ func quoteRepository_FindAll(t *testing.T, repo service.QuoteRepository) {
 		t.Errorf("create quote q2: %v", err)
 	}
 
-	got, err = repo.FindAll(context.Background())
+	got, err = repo.Query(context.Background(), service.QuoteQuery{})
 	if err != nil {
 		t.Errorf("finding all from repo with two quotes: %v", err)
 	}
@@ -207,3 +530,239 @@ func quoteRepository_FindAll(t *testing.T, repo service.QuoteRepository) {
 		t.Errorf("finding all from repo with two quotes, got %v, want %v", got, want)
 	}
 }
+
+func quoteRepository_Query_Search(t *testing.T, repo service.QuoteRepository) {
+	now := time.Now()
+	trucks := model.Quote{
+		ID:          "s001",
+		SubmitterID: "user_id",
+		Quotee:      "DJ JD",
+		Quote:       "Isn't every truck a hand truck cuz of the steering wheel?",
+		Context:     "mail trucks",
+		Created:     now.Add(-3 * time.Hour),
+	}
+	chickens := model.Quote{
+		ID:          "s002",
+		SubmitterID: "user_id",
+		Quotee:      "Charlene",
+		Quote:       "How many chickens can lay an egg?",
+		Context:     "",
+		Created:     now.Add(-2 * time.Hour),
+	}
+	steering := model.Quote{
+		ID:          "s003",
+		SubmitterID: "user_id2",
+		Quotee:      "AJBR",
+		Quote:       "Who is steering this thing?",
+		Context:     "road trip",
+		Created:     now.Add(-1 * time.Hour),
+	}
+	for _, q := range []model.Quote{trucks, chickens, steering} {
+		if err := repo.Create(context.Background(), q); err != nil {
+			t.Errorf("create quote %v: %v", q.ID, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []model.Quote
+	}{
+		{"empty query", "", []model.Quote{steering, chickens, trucks}},
+		{"no matches", "penguin", []model.Quote{}},
+		{"quote text", "chickens", []model.Quote{chickens}},
+		{"case insensitive", "CHICKENS", []model.Quote{chickens}},
+		{"prefix", "chick", []model.Quote{chickens}},
+		{"quotee", "charlene", []model.Quote{chickens}},
+		{"context", "mail", []model.Quote{trucks}},
+		{"multiple matches newest first", "steering", []model.Quote{steering, trucks}},
+		{"all terms required", "steering wheel", []model.Quote{trucks}},
+		{"terms across fields", "road AJBR", []model.Quote{steering}},
+		{"punctuation ignored", "  \"egg?\" ", []model.Quote{chickens}},
+		{"middle of word", "ruck", []model.Quote{}},
+		{"end of word", "eering", []model.Quote{}},
+	}
+
+	for _, tt := range tests {
+		got, err := repo.Query(context.Background(), service.QuoteQuery{Search: tt.query})
+		if err != nil {
+			t.Errorf("search %v: %v", tt.name, err)
+		}
+		if !cmp.Equal(got, tt.want) {
+			t.Errorf("search %v, got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func quoteRepository_Query_Filters(t *testing.T, repo service.QuoteRepository) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	q2021 := model.Quote{
+		ID:          "f001",
+		SubmitterID: "user_id",
+		Quotee:      "Charlene",
+		Quote:       "Twenty twenty one",
+		Created:     day(2021, time.June, 1),
+	}
+	q2022a := model.Quote{
+		ID:          "f002",
+		SubmitterID: "user_id2",
+		Quotee:      "AJBR",
+		Quote:       "Twenty twenty two",
+		Created:     day(2022, time.January, 1),
+	}
+	q2022b := model.Quote{
+		ID:          "f003",
+		SubmitterID: "user_id",
+		Quotee:      "charlene",
+		Quote:       "Twenty twenty two, again",
+		Created:     day(2022, time.December, 31),
+	}
+	q2023 := model.Quote{
+		ID:          "f004",
+		CommunityID: "other",
+		SubmitterID: "user_id2",
+		Quotee:      "DJ JD",
+		Quote:       "Twenty twenty three",
+		Created:     time.Date(2023, time.January, 1, 0, 0, 0, 0, time.FixedZone("EST", -5*60*60)),
+	}
+	for _, q := range []model.Quote{q2022a, q2021, q2023, q2022b} {
+		if err := repo.Create(context.Background(), q); err != nil {
+			t.Errorf("create quote %v: %v", q.ID, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query service.QuoteQuery
+		want  []model.Quote
+	}{
+		{
+			name:  "newest first",
+			query: service.QuoteQuery{},
+			want:  []model.Quote{q2023, q2022b, q2022a, q2021},
+		},
+		{
+			name: "date range",
+			query: service.QuoteQuery{
+				CreatedFrom:   time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+				CreatedBefore: time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+			want: []model.Quote{q2022b, q2022a},
+		},
+		{
+			name: "date range inclusive start",
+			query: service.QuoteQuery{
+				CreatedFrom: q2022a.Created,
+			},
+			want: []model.Quote{q2023, q2022b, q2022a},
+		},
+		{
+			name: "date range exclusive end",
+			query: service.QuoteQuery{
+				CreatedBefore: q2022b.Created,
+			},
+			want: []model.Quote{q2022a, q2021},
+		},
+		{
+			name: "quotee ignores case",
+			query: service.QuoteQuery{
+				Quotee: "CHARLENE",
+			},
+			want: []model.Quote{q2022b, q2021},
+		},
+		{
+			name: "community",
+			query: service.QuoteQuery{
+				CommunityID: "other",
+			},
+			want: []model.Quote{q2023},
+		},
+		{
+			name: "submitter",
+			query: service.QuoteQuery{
+				SubmitterID: "user_id2",
+			},
+			want: []model.Quote{q2023, q2022a},
+		},
+		{
+			name: "combined",
+			query: service.QuoteQuery{
+				SubmitterID: "user_id",
+				CreatedFrom: q2022a.Created,
+				Search:      "again",
+			},
+			want: []model.Quote{q2022b},
+		},
+		{
+			name: "limit",
+			query: service.QuoteQuery{
+				Limit: 2,
+			},
+			want: []model.Quote{q2023, q2022b},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := repo.Query(context.Background(), tt.query)
+		if err != nil {
+			t.Errorf("query %v: %v", tt.name, err)
+		}
+		if !cmp.Equal(got, tt.want) {
+			t.Errorf("query %v, got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func quoteRepository_Query_Pagination(t *testing.T, repo service.QuoteRepository) {
+	created := time.Date(2022, time.March, 11, 12, 0, 0, 0, time.UTC)
+
+	// quotes sharing a timestamp should be ordered by descending ID
+	var want []model.Quote
+	for _, id := range []string{"p009", "p008", "p007", "p006", "p005"} {
+		want = append(want, model.Quote{
+			ID:          id,
+			SubmitterID: "user_id",
+			Quotee:      "AJBR",
+			Quote:       "Quote " + id,
+			Created:     created,
+		})
+	}
+	want = append(want, model.Quote{
+		ID:          "p010",
+		SubmitterID: "user_id",
+		Quotee:      "AJBR",
+		Quote:       "An older quote",
+		Created:     created.Add(-time.Hour),
+	})
+	for _, q := range want {
+		if err := repo.Create(context.Background(), q); err != nil {
+			t.Errorf("create quote %v: %v", q.ID, err)
+		}
+	}
+
+	var got []model.Quote
+	query := service.QuoteQuery{Limit: 4}
+	for pages := 0; pages < 10; pages++ {
+		page, err := repo.Query(context.Background(), query)
+		if err != nil {
+			t.Errorf("query page %v: %v", pages, err)
+		}
+		if len(page) > query.Limit {
+			t.Errorf("query page %v returned %v quotes, want at most %v", pages, len(page), query.Limit)
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		got = append(got, page...)
+		c := service.CursorOf(page[len(page)-1])
+		query.After = &c
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Errorf("paging through quotes, got %v, want %v", got, want)
+	}
+}
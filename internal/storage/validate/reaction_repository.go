@@ -0,0 +1,213 @@
+package validate
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// ReactionRepository validates a type implementing the ReactionRepository interface
+func ReactionRepository(t *testing.T, repoFactory func() (repo service.ReactionRepository, close func())) {
+	t.Run("Create_Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		reactionRepository_Create_Delete(t, repo)
+	})
+
+	t.Run("FindByQuoteIDs_CountByQuote", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		reactionRepository_FindByQuoteIDs_CountByQuote(t, repo)
+	})
+
+	t.Run("DeleteByQuoteID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		reactionRepository_DeleteByQuoteID(t, repo)
+	})
+
+	t.Run("ReassignUser", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		reactionRepository_ReassignUser(t, repo)
+	})
+}
+
+// sortReactions sorts reactions by QuoteID, UserID, and Emoji, so they can be compared regardless of order.
+func sortReactions(reactions []model.Reaction) {
+	sort.Slice(reactions, func(i, j int) bool {
+		a, b := reactions[i], reactions[j]
+		if a.QuoteID != b.QuoteID {
+			return a.QuoteID < b.QuoteID
+		}
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.Emoji < b.Emoji
+	})
+}
+
+func reactionRepository_Create_Delete(t *testing.T, repo service.ReactionRepository) {
+	r := model.Reaction{
+		QuoteID: "quote_id",
+		UserID:  "user_id",
+		Emoji:   "👍",
+		Created: time.Now(),
+	}
+
+	if err := repo.Delete(context.Background(), r); err != storage.ErrNotFound {
+		t.Errorf("delete reaction before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), r); err != nil {
+		t.Errorf("create reaction: %v", err)
+	}
+
+	got, err := repo.FindByQuoteIDs(context.Background(), []string{r.QuoteID})
+	if err != nil {
+		t.Errorf("find reactions: %v", err)
+	}
+	if want := []model.Reaction{r}; !cmp.Equal(got, want) {
+		t.Errorf("got reactions %v, want %v", got, want)
+	}
+
+	if err := repo.Create(context.Background(), r); err != storage.ErrAlreadyExists {
+		t.Errorf("create reaction again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	other := r
+	other.Emoji = "😂"
+	if err := repo.Create(context.Background(), other); err != nil {
+		t.Errorf("create reaction with other emoji: %v", err)
+	}
+
+	if err := repo.Delete(context.Background(), r); err != nil {
+		t.Errorf("delete reaction: %v", err)
+	}
+
+	got, err = repo.FindByQuoteIDs(context.Background(), []string{r.QuoteID})
+	if err != nil {
+		t.Errorf("find reactions after delete: %v", err)
+	}
+	if want := []model.Reaction{other}; !cmp.Equal(got, want) {
+		t.Errorf("got reactions after delete %v, want %v", got, want)
+	}
+
+	if err := repo.Delete(context.Background(), r); err != storage.ErrNotFound {
+		t.Errorf("delete reaction again: got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func reactionRepository_FindByQuoteIDs_CountByQuote(t *testing.T, repo service.ReactionRepository) {
+	now := time.Now()
+	reactions := []model.Reaction{
+		{QuoteID: "quote_a", UserID: "user_a", Emoji: "👍", Created: now},
+		{QuoteID: "quote_a", UserID: "user_b", Emoji: "👍", Created: now},
+		{QuoteID: "quote_a", UserID: "user_b", Emoji: "😂", Created: now},
+		{QuoteID: "quote_b", UserID: "user_a", Emoji: "❤️", Created: now},
+		{QuoteID: "quote_c", UserID: "user_a", Emoji: "👍", Created: now},
+	}
+	for _, r := range reactions {
+		if err := repo.Create(context.Background(), r); err != nil {
+			t.Errorf("create reaction %v: %v", r, err)
+		}
+	}
+
+	got, err := repo.FindByQuoteIDs(context.Background(), []string{"quote_a", "quote_b", "quote_d"})
+	if err != nil {
+		t.Errorf("find reactions: %v", err)
+	}
+	sortReactions(got)
+	if want := reactions[:4]; !cmp.Equal(got, want) {
+		t.Errorf("got reactions %v, want %v", got, want)
+	}
+
+	got, err = repo.FindByQuoteIDs(context.Background(), []string{})
+	if err != nil {
+		t.Errorf("find reactions of no quotes: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got reactions of no quotes %v, want none", got)
+	}
+
+	counts, err := repo.CountByQuote(context.Background())
+	if err != nil {
+		t.Errorf("count reactions: %v", err)
+	}
+	if want := map[string]int{"quote_a": 3, "quote_b": 1, "quote_c": 1}; !cmp.Equal(counts, want) {
+		t.Errorf("got counts %v, want %v", counts, want)
+	}
+}
+
+func reactionRepository_DeleteByQuoteID(t *testing.T, repo service.ReactionRepository) {
+	now := time.Now()
+	reactions := []model.Reaction{
+		{QuoteID: "quote_a", UserID: "user_a", Emoji: "👍", Created: now},
+		{QuoteID: "quote_a", UserID: "user_b", Emoji: "😂", Created: now},
+		{QuoteID: "quote_b", UserID: "user_a", Emoji: "👍", Created: now},
+	}
+	for _, r := range reactions {
+		if err := repo.Create(context.Background(), r); err != nil {
+			t.Errorf("create reaction %v: %v", r, err)
+		}
+	}
+
+	if err := repo.DeleteByQuoteID(context.Background(), "quote_a"); err != nil {
+		t.Errorf("delete reactions of quote_a: %v", err)
+	}
+
+	got, err := repo.FindByQuoteIDs(context.Background(), []string{"quote_a", "quote_b"})
+	if err != nil {
+		t.Errorf("find reactions: %v", err)
+	}
+	if want := reactions[2:]; !cmp.Equal(got, want) {
+		t.Errorf("got reactions %v, want %v", got, want)
+	}
+
+	if err := repo.DeleteByQuoteID(context.Background(), "quote_c"); err != nil {
+		t.Errorf("delete reactions of quote without reactions: %v", err)
+	}
+}
+
+func reactionRepository_ReassignUser(t *testing.T, repo service.ReactionRepository) {
+	now := time.Now()
+	reactions := []model.Reaction{
+		{QuoteID: "quote_a", UserID: "user_a", Emoji: "👍", Created: now},
+		{QuoteID: "quote_a", UserID: "user_a", Emoji: "😂", Created: now},
+		{QuoteID: "quote_a", UserID: "user_b", Emoji: "👍", Created: now.Add(-time.Hour)},
+		{QuoteID: "quote_b", UserID: "user_a", Emoji: "👍", Created: now},
+	}
+	for _, r := range reactions {
+		if err := repo.Create(context.Background(), r); err != nil {
+			t.Errorf("create reaction %v: %v", r, err)
+		}
+	}
+
+	if err := repo.ReassignUser(context.Background(), "user_a", "user_b"); err != nil {
+		t.Errorf("reassign reactions of user_a: %v", err)
+	}
+
+	got, err := repo.FindByQuoteIDs(context.Background(), []string{"quote_a", "quote_b"})
+	if err != nil {
+		t.Errorf("find reactions: %v", err)
+	}
+	sortReactions(got)
+	// the reaction user_b already made is kept, rather than duplicated
+	want := []model.Reaction{reactions[2], reactions[1], reactions[3]}
+	want[1].UserID = "user_b"
+	want[2].UserID = "user_b"
+	if !cmp.Equal(got, want) {
+		t.Errorf("got reactions %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// UserIdentityRepository validates a type implementing the UserIdentityRepository interface
+func UserIdentityRepository(t *testing.T, repoFactory func() (repo service.UserIdentityRepository, close func())) {
+	t.Run("Create_FindByID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		userIdentityRepository_Create_FindByID(t, repo)
+	})
+
+	t.Run("FindByUserID_ReassignUser", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		userIdentityRepository_FindByUserID_ReassignUser(t, repo)
+	})
+}
+
+func userIdentityRepository_Create_FindByID(t *testing.T, repo service.UserIdentityRepository) {
+	ui := model.UserIdentity{
+		ID:      "accounts.google.com/1234",
+		UserID:  "user_id",
+		Domain:  "accounts.google.com",
+		Email:   "fn@example.com",
+		Created: time.Now(),
+	}
+
+	if _, err := repo.FindByID(context.Background(), ui.ID); err != storage.ErrNotFound {
+		t.Errorf("find identity before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), ui); err != nil {
+		t.Errorf("create identity: %v", err)
+	}
+
+	got, err := repo.FindByID(context.Background(), ui.ID)
+	if err != nil {
+		t.Errorf("find identity: %v", err)
+	}
+	if !cmp.Equal(got, ui) {
+		t.Errorf("got identity %v, want %v", got, ui)
+	}
+
+	if err := repo.Create(context.Background(), ui); err != storage.ErrAlreadyExists {
+		t.Errorf("create identity again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+}
+
+func userIdentityRepository_FindByUserID_ReassignUser(t *testing.T, repo service.UserIdentityRepository) {
+	now := time.Now()
+	identities := []model.UserIdentity{
+		{ID: "accounts.google.com/1", UserID: "user_a", Domain: "accounts.google.com", Email: "a@example.com", Created: now.Add(-time.Hour)},
+		{ID: "dex.example.com/2", UserID: "user_a", Domain: "dex.example.com", Email: "a@example.com", Created: now},
+		{ID: "accounts.google.com/3", UserID: "user_b", Domain: "accounts.google.com", Email: "b@example.com", Created: now.Add(-2 * time.Hour)},
+	}
+	for _, ui := range identities {
+		if err := repo.Create(context.Background(), ui); err != nil {
+			t.Errorf("create identity %v: %v", ui.ID, err)
+		}
+	}
+
+	got, err := repo.FindByUserID(context.Background(), "user_a")
+	if err != nil {
+		t.Errorf("find identities of user_a: %v", err)
+	}
+	if want := []model.UserIdentity{identities[0], identities[1]}; !cmp.Equal(got, want) {
+		t.Errorf("got identities %v, want %v", got, want)
+	}
+
+	if err := repo.ReassignUser(context.Background(), "user_a", "user_b"); err != nil {
+		t.Errorf("reassign identities of user_a: %v", err)
+	}
+
+	got, err = repo.FindByUserID(context.Background(), "user_a")
+	if err != nil {
+		t.Errorf("find identities of user_a after reassign: %v", err)
+	}
+	if !cmp.Equal(got, []model.UserIdentity{}) {
+		t.Errorf("user_a should have no identities after reassign, got %v", got)
+	}
+
+	got, err = repo.FindByUserID(context.Background(), "user_b")
+	if err != nil {
+		t.Errorf("find identities of user_b: %v", err)
+	}
+	want := []model.UserIdentity{identities[2], identities[0], identities[1]}
+	for i := range want {
+		want[i].UserID = "user_b"
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("got identities %v, want %v", got, want)
+	}
+}
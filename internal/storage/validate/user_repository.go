@@ -14,39 +14,30 @@ import (
 
 var (
 	u1 = model.User{
-		ID:           "user_id",
-		Name:         "Ficky Neldo",
-		Email:        "fn@example.com",
-		PictureURL:   "https://example.com/fn.jpg",
-		Created:      time.Now(),
-		QuizPassed:   false,
-		QuizAttempts: 1,
-		Banned:       true,
-		Admin:        false,
+		ID:         "user_id",
+		Name:       "Ficky Neldo",
+		Email:      "fn@example.com",
+		PictureURL: "https://example.com/fn.jpg",
+		Created:    time.Now(),
+		Admin:      false,
 	}
 
 	u2 = model.User{
-		ID:           "user_id2",
-		Name:         "Bill Wicks",
-		Email:        "bw@example.com",
-		PictureURL:   "https://example.com/bw.jpg",
-		Created:      time.Now(),
-		QuizPassed:   true,
-		QuizAttempts: 2,
-		Banned:       false,
-		Admin:        true,
+		ID:         "user_id2",
+		Name:       "Bill Wicks",
+		Email:      "bw@example.com",
+		PictureURL: "https://example.com/bw.jpg",
+		Created:    time.Now(),
+		Admin:      true,
 	}
 
 	u3 = model.User{
-		ID:           "user_id3",
-		Name:         "Rawb",
-		Email:        "rlhllc@rtcexample.edu",
-		PictureURL:   "https://www.rtcexample.edu/wp-content/uploads/2018/08/rlhllc-300x300.jpg",
-		Created:      time.Now(),
-		QuizPassed:   true,
-		QuizAttempts: 1,
-		Banned:       false,
-		Admin:        false,
+		ID:         "user_id3",
+		Name:       "Rawb",
+		Email:      "rlhllc@rtcexample.edu",
+		PictureURL: "https://www.rtcexample.edu/wp-content/uploads/2018/08/rlhllc-300x300.jpg",
+		Created:    time.Now(),
+		Admin:      false,
 	}
 )
 
@@ -64,6 +55,12 @@ func UserRepository(t *testing.T, repoFactory func() (repo service.UserRepositor
 		t.Parallel()
 		userRepository_Update(t, repo)
 	})
+	t.Run("Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		userRepository_Delete(t, repo)
+	})
 	t.Run("FindAll", func(t *testing.T) {
 		repo, close := repoFactory()
 		defer close()
@@ -129,9 +126,6 @@ func userRepository_Update(t *testing.T, repo service.UserRepository) {
 	uEdit.Name = "Ficky Neldo II"
 	uEdit.Email = "fn2@example.com"
 	uEdit.PictureURL = "https://example.com/fn2.jpg"
-	uEdit.QuizAttempts = 2
-	uEdit.QuizPassed = true
-	uEdit.Banned = false
 	uEdit.Admin = true
 
 	if err := repo.Update(context.Background(), uEdit); err != nil {
@@ -158,6 +152,33 @@ func userRepository_Update(t *testing.T, repo service.UserRepository) {
 	}
 }
 
+func userRepository_Delete(t *testing.T, repo service.UserRepository) {
+	if err := repo.Create(context.Background(), u1); err != nil {
+		t.Errorf("create user u1: %v", err)
+	}
+	if err := repo.Create(context.Background(), u2); err != nil {
+		t.Errorf("create user u2: %v", err)
+	}
+
+	if err := repo.Delete(context.Background(), u1.ID); err != nil {
+		t.Errorf("delete user u1: %v", err)
+	}
+	if _, err := repo.FindByID(context.Background(), u1.ID); err != storage.ErrNotFound {
+		t.Errorf("find deleted user u1: got error %v, want %v", err, storage.ErrNotFound)
+	}
+	if err := repo.Delete(context.Background(), u1.ID); err != storage.ErrNotFound {
+		t.Errorf("delete user u1 again: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	gotU2, err := repo.FindByID(context.Background(), u2.ID)
+	if err != nil {
+		t.Errorf("find u2: %v", err)
+	}
+	if !cmp.Equal(gotU2, u2) {
+		t.Errorf("u2 should be unchanged, got user %v, want %v", gotU2, u2)
+	}
+}
+
 func userRepository_FindAll(t *testing.T, repo service.UserRepository) {
 	gotUsers, err := repo.FindAll(context.Background())
 	if err != nil {
@@ -14,10 +14,43 @@ import (
 
 // UserSessionRepository tests a type implementing the UserSessionRepository interface
 func UserSessionRepository(t *testing.T, repoFactory func() (repo service.UserSessionRepository, closer func())) {
+	t.Run("Create_FindByID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		userSessionRepository_Create_FindByID(t, repo)
+	})
 
-	repo, close := repoFactory()
-	defer close()
+	t.Run("Update", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		userSessionRepository_Update(t, repo)
+	})
 
+	t.Run("Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		userSessionRepository_Delete(t, repo)
+	})
+
+	t.Run("FindByUserID_DeleteByUserID", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		userSessionRepository_FindByUserID_DeleteByUserID(t, repo)
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		userSessionRepository_DeleteExpired(t, repo)
+	})
+}
+
+func userSessionRepository_Create_FindByID(t *testing.T, repo service.UserSessionRepository) {
 	us1 := model.UserSession{
 		ID:      "sess_id",
 		UserID:  "user_id",
@@ -70,3 +103,144 @@ func UserSessionRepository(t *testing.T, repoFactory func() (repo service.UserSe
 		t.Errorf("creating duplicate user session should return ErrAlreadyExists, got %v", err)
 	}
 }
+
+func userSessionRepository_Update(t *testing.T, repo service.UserSessionRepository) {
+	us := model.UserSession{
+		ID:      "sess_id",
+		UserID:  "user_id",
+		Created: time.Now(),
+		Expires: time.Now().Add(time.Hour),
+		IP:      "192.168.0.1",
+	}
+	if err := repo.Create(context.Background(), us); err != nil {
+		t.Errorf("create user session: %v", err)
+	}
+
+	us.Expires = us.Expires.Add(24 * time.Hour)
+	if err := repo.Update(context.Background(), us); err != nil {
+		t.Errorf("update user session: %v", err)
+	}
+
+	got, err := repo.FindByID(context.Background(), us.ID)
+	if err != nil {
+		t.Errorf("find updated user session: %v", err)
+	}
+	if !cmp.Equal(got, us) {
+		t.Errorf("got user session %v, want %v", got, us)
+	}
+
+	missing := model.UserSession{ID: "missing", UserID: "user_id", Created: time.Now(), Expires: time.Now()}
+	if err := repo.Update(context.Background(), missing); err != storage.ErrNotFound {
+		t.Errorf("updating non-existent user session should return ErrNotFound, got %v", err)
+	}
+}
+
+func userSessionRepository_Delete(t *testing.T, repo service.UserSessionRepository) {
+	us := model.UserSession{
+		ID:      "sess_id",
+		UserID:  "user_id",
+		Created: time.Now(),
+		Expires: time.Now().Add(time.Hour),
+		IP:      "192.168.0.1",
+	}
+	if err := repo.Create(context.Background(), us); err != nil {
+		t.Errorf("create user session: %v", err)
+	}
+
+	if err := repo.Delete(context.Background(), us.ID); err != nil {
+		t.Errorf("delete user session: %v", err)
+	}
+
+	if _, err := repo.FindByID(context.Background(), us.ID); err != storage.ErrNotFound {
+		t.Errorf("deleted user session should return ErrNotFound, got %v", err)
+	}
+
+	if err := repo.Delete(context.Background(), us.ID); err != storage.ErrNotFound {
+		t.Errorf("deleting non-existent user session should return ErrNotFound, got %v", err)
+	}
+}
+
+func userSessionRepository_FindByUserID_DeleteByUserID(t *testing.T, repo service.UserSessionRepository) {
+	now := time.Now()
+	sessions := []model.UserSession{
+		{ID: "sess_a1", UserID: "user_a", Created: now.Add(-2 * time.Hour), Expires: now.Add(time.Hour), IP: "10.0.0.1"},
+		{ID: "sess_a2", UserID: "user_a", Created: now.Add(-time.Hour), Expires: now.Add(time.Hour), IP: "10.0.0.2"},
+		{ID: "sess_b1", UserID: "user_b", Created: now, Expires: now.Add(time.Hour), IP: "10.0.0.3"},
+	}
+	for _, us := range sessions {
+		if err := repo.Create(context.Background(), us); err != nil {
+			t.Errorf("create user session %v: %v", us.ID, err)
+		}
+	}
+
+	got, err := repo.FindByUserID(context.Background(), "user_a")
+	if err != nil {
+		t.Errorf("find sessions of user_a: %v", err)
+	}
+	if want := []model.UserSession{sessions[1], sessions[0]}; !cmp.Equal(got, want) {
+		t.Errorf("got sessions %v, want %v", got, want)
+	}
+
+	got, err = repo.FindByUserID(context.Background(), "user_c")
+	if err != nil {
+		t.Errorf("find sessions of user_c: %v", err)
+	}
+	if !cmp.Equal(got, []model.UserSession{}) {
+		t.Errorf("user without sessions should return empty slice, got %v", got)
+	}
+
+	if err := repo.DeleteByUserID(context.Background(), "user_a"); err != nil {
+		t.Errorf("delete sessions of user_a: %v", err)
+	}
+
+	got, err = repo.FindByUserID(context.Background(), "user_a")
+	if err != nil {
+		t.Errorf("find sessions of user_a after delete: %v", err)
+	}
+	if !cmp.Equal(got, []model.UserSession{}) {
+		t.Errorf("sessions of user_a should be deleted, got %v", got)
+	}
+
+	if _, err := repo.FindByID(context.Background(), "sess_b1"); err != nil {
+		t.Errorf("sessions of other users should not be deleted, got %v", err)
+	}
+}
+
+func userSessionRepository_DeleteExpired(t *testing.T, repo service.UserSessionRepository) {
+	now := time.Now()
+	sessions := []model.UserSession{
+		{ID: "expired_1", UserID: "user_a", Created: now.Add(-3 * time.Hour), Expires: now.Add(-time.Hour), IP: "10.0.0.1"},
+		{ID: "expired_2", UserID: "user_b", Created: now.Add(-3 * time.Hour), Expires: now.Add(-time.Minute), IP: "10.0.0.2"},
+		{ID: "active", UserID: "user_a", Created: now.Add(-time.Hour), Expires: now.Add(time.Hour), IP: "10.0.0.3"},
+	}
+	for _, us := range sessions {
+		if err := repo.Create(context.Background(), us); err != nil {
+			t.Errorf("create user session %v: %v", us.ID, err)
+		}
+	}
+
+	n, err := repo.DeleteExpired(context.Background(), now)
+	if err != nil {
+		t.Errorf("delete expired sessions: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("got %d sessions deleted, want 2", n)
+	}
+
+	for _, id := range []string{"expired_1", "expired_2"} {
+		if _, err := repo.FindByID(context.Background(), id); err != storage.ErrNotFound {
+			t.Errorf("expired session %v should be deleted, got %v", id, err)
+		}
+	}
+	if _, err := repo.FindByID(context.Background(), "active"); err != nil {
+		t.Errorf("active session should not be deleted, got %v", err)
+	}
+
+	n, err = repo.DeleteExpired(context.Background(), now)
+	if err != nil {
+		t.Errorf("delete expired sessions again: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("got %d sessions deleted on second purge, want 0", n)
+	}
+}
@@ -0,0 +1,115 @@
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/willbicks/epigram/internal/model"
+	"github.com/willbicks/epigram/internal/service"
+	"github.com/willbicks/epigram/internal/storage"
+)
+
+// WebhookDeliveryRepository validates a type implementing the WebhookDeliveryRepository interface
+func WebhookDeliveryRepository(t *testing.T, repoFactory func() (repo service.WebhookDeliveryRepository, close func())) {
+	t.Run("Create_Update_Delete", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		webhookDeliveryRepository_Create_Update_Delete(t, repo)
+	})
+
+	t.Run("FindDue", func(t *testing.T) {
+		repo, close := repoFactory()
+		defer close()
+		t.Parallel()
+		webhookDeliveryRepository_FindDue(t, repo)
+	})
+}
+
+func webhookDeliveryRepository_Create_Update_Delete(t *testing.T, repo service.WebhookDeliveryRepository) {
+	now := time.Now()
+	d := model.WebhookDelivery{
+		ID:          "delivery_id",
+		Webhook:     "slack",
+		Payload:     `{"text":"hello"}`,
+		NextAttempt: now,
+		Created:     now,
+	}
+
+	if err := repo.Update(context.Background(), d); err != storage.ErrNotFound {
+		t.Errorf("update delivery before created: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	if err := repo.Create(context.Background(), d); err != nil {
+		t.Errorf("create delivery: %v", err)
+	}
+
+	if err := repo.Create(context.Background(), d); err != storage.ErrAlreadyExists {
+		t.Errorf("create delivery again: got error %v, want %v", err, storage.ErrAlreadyExists)
+	}
+
+	d.Attempts = 1
+	d.LastError = "webhook responded 500 Internal Server Error"
+	d.NextAttempt = now.Add(-time.Minute)
+	if err := repo.Update(context.Background(), d); err != nil {
+		t.Errorf("update delivery: %v", err)
+	}
+
+	got, err := repo.FindDue(context.Background(), now, 0)
+	if err != nil {
+		t.Errorf("find due deliveries: %v", err)
+	}
+	if want := []model.WebhookDelivery{d}; !cmp.Equal(got, want) {
+		t.Errorf("got deliveries %v, want %v", got, want)
+	}
+
+	if err := repo.Delete(context.Background(), d.ID); err != nil {
+		t.Errorf("delete delivery: %v", err)
+	}
+
+	if err := repo.Delete(context.Background(), d.ID); err != storage.ErrNotFound {
+		t.Errorf("delete delivery again: got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	got, err = repo.FindDue(context.Background(), now, 0)
+	if err != nil {
+		t.Errorf("find due deliveries after delete: %v", err)
+	}
+	if !cmp.Equal(got, []model.WebhookDelivery{}) {
+		t.Errorf("there should be no deliveries after delete, got %v", got)
+	}
+}
+
+func webhookDeliveryRepository_FindDue(t *testing.T, repo service.WebhookDeliveryRepository) {
+	now := time.Now()
+	deliveries := []model.WebhookDelivery{
+		{ID: "delivery_1", Webhook: "a", Payload: "{}", NextAttempt: now.Add(-time.Minute), Created: now},
+		{ID: "delivery_2", Webhook: "a", Payload: "{}", NextAttempt: now.Add(-time.Hour), Created: now},
+		{ID: "delivery_3", Webhook: "a", Payload: "{}", NextAttempt: now.Add(time.Hour), Created: now},
+		{ID: "delivery_4", Webhook: "b", Payload: "{}", NextAttempt: now.Add(-time.Minute), Created: now},
+	}
+	for _, d := range deliveries {
+		if err := repo.Create(context.Background(), d); err != nil {
+			t.Errorf("create delivery %v: %v", d.ID, err)
+		}
+	}
+
+	got, err := repo.FindDue(context.Background(), now, 0)
+	if err != nil {
+		t.Errorf("find due deliveries: %v", err)
+	}
+	if want := []model.WebhookDelivery{deliveries[1], deliveries[0], deliveries[3]}; !cmp.Equal(got, want) {
+		t.Errorf("got deliveries %v, want %v", got, want)
+	}
+
+	got, err = repo.FindDue(context.Background(), now, 2)
+	if err != nil {
+		t.Errorf("find limited due deliveries: %v", err)
+	}
+	if want := []model.WebhookDelivery{deliveries[1], deliveries[0]}; !cmp.Equal(got, want) {
+		t.Errorf("got limited deliveries %v, want %v", got, want)
+	}
+}